@@ -3,40 +3,54 @@ package cmd
 import (
 	"errors"
 	"fmt"
-	"regexp"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/stalwartgiraffe/cmr/internal/config"
 	"github.com/stalwartgiraffe/cmr/internal/gitutil"
 
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing"
 )
 
-func addBranchCommand(parent *cobra.Command) {
-	parent.AddCommand(NewBranchCommand(nil))
+func addBranchCommand(cfg *CmdConfig, parent *cobra.Command) {
+	parent.AddCommand(NewBranchCommand(cfg, nil))
 }
 
-func NewBranchCommand(repo *git.Repository) *cobra.Command {
-	return &cobra.Command{
+func NewBranchCommand(cfg *CmdConfig, repo *git.Repository) *cobra.Command {
+	var branchType string
+	branchCmd := &cobra.Command{
 		Use:   "branch [jira] [name]",
 		Short: "Start a new branch with a jira and name",
-		Long: `A new branch.
-FIXME: detailed explanation.
-FIXME: include examples.
-`,
+		Long: `Start a new branch at the head with a jira issue and a name and check it out.
+
+The branch is named by the branches policy of the config, by default {issue}_{slug}:
+
+  cmr init branch DEALS-1234 big_deal
+
+makes DEALS-1234_big_deal. A template such as {type}/{issue}-{slug} also needs --type.`,
 
 		Args: func(cmd *cobra.Command, args []string) error {
-			_, _, err := parseBranchArgs(args)
-			return err
+			if len(args) != 2 {
+				return errors.New("requires two arguments")
+			}
+			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runRepoBranch(cmd, args, repo)
+			return runRepoBranch(cmd, cfg.Config.BranchPolicy(), branchType, args, repo)
 		},
 	}
+	branchCmd.Flags().StringVar(&branchType, "type", "", "the type of the branch when the template has {type}")
+	return branchCmd
 }
 
-func runRepoBranch(cmd *cobra.Command, args []string, repo *git.Repository) error {
-	jiraIssue, branchLabel, err := parseBranchArgs(args)
+func runRepoBranch(
+	cmd *cobra.Command,
+	branches *config.Branches,
+	branchType string,
+	args []string,
+	repo *git.Repository,
+) error {
+	jiraIssue, branchLabel, err := parseBranchArgs(branches, args)
 	if err != nil {
 		return err
 	}
@@ -48,35 +62,15 @@ func runRepoBranch(cmd *cobra.Command, args []string, repo *git.Repository) erro
 		}
 	}
 
-	// Get the HEAD reference.
-	headRef, err := repo.Head()
-	if err != nil {
-		return err
-	}
-
-	// Create a new plumbing.HashReference object with the name of the branch
-	// and the hash from the HEAD. The reference name should be a full reference
-	// name and not an abbreviated one, as is used on the git cli.
-	//
-	// For tags we should use `refs/tags/%s` instead of `refs/heads/%s` used
-	// for branches.
-	branchShortName := fmt.Sprintf("%s_%s", jiraIssue, branchLabel)
-	branchRefName := plumbing.NewBranchReferenceName(branchShortName)
-	branchRef := plumbing.NewHashReference(branchRefName, headRef.Hash())
-
-	// The created reference is saved in the storage.
-	if err = repo.Storer.SetReference(branchRef); err != nil {
-		return err
-	}
-	worktree, err := repo.Worktree()
+	_, err = gitutil.CheckoutCreatePolicyBranch(repo, branches, config.Branch{
+		Type:        branchType,
+		Issue:       string(jiraIssue),
+		Description: strings.ReplaceAll(string(branchLabel), branches.SlugSeparator, " "),
+	})
 	if err != nil {
 		return err
 	}
-	// git checkout branchShortName
-	err = worktree.Checkout(&git.CheckoutOptions{
-		Branch: branchRefName,
-		Create: false,
-	})
+	headRef, err := repo.Head()
 	if err != nil {
 		return err
 	}
@@ -86,7 +80,7 @@ func runRepoBranch(cmd *cobra.Command, args []string, repo *git.Repository) erro
 	if err != nil {
 		return err
 	}
-	_, err = fmt.Fprintln(cmd.OutOrStdout(), branchRef)
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), headRef)
 	if err != nil {
 		return err
 	}
@@ -94,41 +88,21 @@ func runRepoBranch(cmd *cobra.Command, args []string, repo *git.Repository) erro
 	return nil
 }
 
-func match[T ~string](re *regexp.Regexp, txt string, p *T) error {
-	if !re.MatchString(txt) {
-		return fmt.Errorf("%s is not in the expected format", txt)
-	}
-	*p = T(txt)
-	return nil
-}
-
-func parseBranchArgs(args []string) (JiraIssue, BranchLabel, error) {
+func parseBranchArgs(branches *config.Branches, args []string) (JiraIssue, BranchLabel, error) {
 	if len(args) != 2 {
 		return "", "", errors.New("requires two arguments")
 	}
-
-	var jiraIssue JiraIssue
-	if err := match(jiraIssueRegexp, args[0], &jiraIssue); err != nil {
+	if err := branches.CheckIssue(args[0]); err != nil {
 		return "", "", err
 	}
-	var branchLabel BranchLabel
-	if err := match(branchLabelRegexp, args[1], &branchLabel); err != nil {
+	if err := branches.CheckSlug(args[1]); err != nil {
 		return "", "", err
 	}
-	return jiraIssue, branchLabel, nil
+	return JiraIssue(args[0]), BranchLabel(args[1]), nil
 }
 
+// JiraIssue is a jira issue of the branches policy such as DEALS-1234 or OPD-12345.
 type JiraIssue string
 
-// jiraIssueRegexp is legal jira issue:
-// DEALS-1234
-// OPD-12345
-var jiraIssueRegexp = regexp.MustCompile(`^[A-Z]+\-\d{4,5}$`)
-
+// BranchLabel is the slug of a branch such as foo, foo_bar or a_important_pr.
 type BranchLabel string
-
-// branchLabelRegexp is a legal label
-// foo
-// foo_bar
-// a_important_pr
-var branchLabelRegexp = regexp.MustCompile(`^([a-z]+\_)*([a-z]+)$`)
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
 )
 
 func TestJiraIssue(t *testing.T) {
@@ -29,10 +32,9 @@ func TestJiraIssue(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			var have JiraIssue
-			err := match(jiraIssueRegexp, tc.txt, &have)
+			haveJira, _, err := parseBranchArgs(config.DefaultBranches(), []string{tc.txt, "a_label"})
 			r.Equal(tc.wantErr, err != nil)
-			r.Equal(tc.want, have)
+			r.Equal(tc.want, haveJira)
 		})
 	}
 }
@@ -48,12 +50,14 @@ func TestBrandLabel(t *testing.T) {
 		{"one", "word", "word", false},
 		{"two", "aaa_bbb", "aaa_bbb", false},
 		{"three", "a_b_c", "a_b_c", false},
+		{"digits", "v2_api", "v2_api", false},
 
 		{"underscore 1", "_", "", true},
 		{"underscore 2", "a_", "", true},
 		{"underscore 3", "_b", "", true},
 
 		{"bad txt", "w!!!d", "", true},
+		{"casing", "Big_deal", "", true},
 
 		{"space 1", " wordd", "", true},
 		{"space 2", "wo d", "", true},
@@ -62,10 +66,9 @@ func TestBrandLabel(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			var have BranchLabel
-			err := match(branchLabelRegexp, tc.txt, &have)
+			_, haveBranch, err := parseBranchArgs(config.DefaultBranches(), []string{"DEALS-1234", tc.txt})
 			r.Equal(tc.wantErr, err != nil)
-			r.Equal(tc.want, have)
+			r.Equal(tc.want, haveBranch)
 		})
 	}
 }
@@ -92,10 +95,31 @@ func TestParseBranchArgs(t *testing.T) {
 
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
-			haveJira, haveBranch, err := parseBranchArgs(tc.args)
+			haveJira, haveBranch, err := parseBranchArgs(config.DefaultBranches(), tc.args)
 			r.Equal(tc.wantJira, haveJira)
 			r.Equal(tc.wantBranch, haveBranch)
 			r.Equal(tc.wantErr, err != nil)
 		})
 	}
 }
+
+func TestParseBranchArgsPolicy(t *testing.T) {
+	r := require.New(t)
+	cfg, err := config.LoadConfig(strings.NewReader(`branches:
+  template: "{type}/{issue}-{slug}"
+  issues: ['GH-\d+']
+  slug_separator: "-"
+`))
+	r.NoError(err)
+	branches := cfg.BranchPolicy()
+
+	haveJira, haveBranch, err := parseBranchArgs(branches, []string{"GH-7", "big-deal"})
+	r.NoError(err)
+	r.Equal(JiraIssue("GH-7"), haveJira)
+	r.Equal(BranchLabel("big-deal"), haveBranch)
+
+	_, _, err = parseBranchArgs(branches, []string{"DEALS-1234", "big-deal"})
+	r.Error(err)
+	_, _, err = parseBranchArgs(branches, []string{"GH-7", "big_deal"})
+	r.Error(err)
+}
@@ -128,7 +128,7 @@ var _ = Describe("Branch Cmd", func() {
 	BeforeEach(func() {
 		rootFS = memfs.New()
 		repo = initMemRepo(rootFS)
-		cmd = NewBranchCommand(repo)
+		cmd = NewBranchCommand(&CmdConfig{}, repo)
 		outBuf = &bytes.Buffer{}
 		cmd.SetOut(outBuf)
 		errBuf = &bytes.Buffer{}
@@ -1 +1,31 @@
 package cmd
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/bulk"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+)
+
+func TestCloneFailedIsRetried(t *testing.T) {
+	r := require.New(t)
+	dir := filepath.Join(t.TempDir(), "team", "project")
+	project := gitlab.ProjectModel{HTTPURLToRepo: filepath.Join(t.TempDir(), "missing")}
+
+	state, err := Clone(context.Background(), project, dir, "")
+	r.Equal(bulk.Failed, state)
+	r.Error(err)
+	r.NoDirExists(dir)
+	r.False(isCloned(dir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	state, err = Clone(ctx, project, dir, "")
+	r.Equal(bulk.Failed, state)
+	r.Error(err)
+	r.NoDirExists(dir)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/commitlint"
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+type commitlintFlags struct {
+	from string
+	to   string
+	edit string
+}
+
+// NewCommitlintCommand initializes the command.
+func NewCommitlintCommand(repo *git.Repository) *cobra.Command {
+	flags := &commitlintFlags{}
+	lintCmd := &cobra.Command{
+		Use:   "commitlint",
+		Short: "lint commit messages by the conventional commit rules",
+		Long: `Lint commit messages by the conventional commit rules of gac.
+
+The message is read from stdin, from the file of --edit as the commit-msg hook
+passes it, or from the commits of --from..--to.
+The rules of .commitlintrc, .commitlintrc.json, .commitlintrc.yaml or
+.commitlintrc.yml in the root of the repo override the default rules.
+The extends of the config is ignored, and the rules that gac can not check
+are skipped with a warning.
+The exit code is non zero if a message breaks a rule of level 2.`,
+		SilenceUsage: true,
+		// the commit-msg hook runs without the cmr config
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		Args: func(cmd *cobra.Command, args []string) error {
+			return NoArgs(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed("to") && flags.from == "" {
+				return fmt.Errorf("--to needs --from")
+			}
+			return runCommitlint(repo, cmd.InOrStdin(), cmd.OutOrStdout(), flags)
+		},
+	}
+	lintCmd.Flags().StringVar(&flags.from, "from", "", "lint the commits after this revision")
+	lintCmd.Flags().StringVar(&flags.to, "to", "HEAD", "lint the commits up to this revision")
+	lintCmd.Flags().StringVarP(&flags.edit, "edit", "e", "", "lint the message of this file, such as .git/COMMIT_EDITMSG")
+	lintCmd.MarkFlagsMutuallyExclusive("edit", "from")
+	lintCmd.MarkFlagsMutuallyExclusive("edit", "to")
+	return lintCmd
+}
+
+func runCommitlint(repo *git.Repository, in io.Reader, out io.Writer, flags *commitlintFlags) error {
+	var err error
+	if repo == nil {
+		if repo, err = gitutil.OpenCwd(); err != nil {
+			return err
+		}
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return withstack.Errorf("Could not get worktree: %w", err)
+	}
+	cfg, err := commitlint.LoadConfig(worktree.Filesystem)
+	if err != nil {
+		return err
+	}
+	for _, name := range cfg.Skipped {
+		fmt.Fprintf(out, "⚠   skipped the rule %s, it can not be checked\n", name)
+	}
+
+	var messages []string
+	switch {
+	case flags.from != "":
+		commits, err := gitutil.CommitsBetween(repo, flags.from, flags.to)
+		if err != nil {
+			return err
+		}
+		for _, c := range commits {
+			messages = append(messages, c.Message)
+		}
+	case flags.edit != "":
+		b, err := os.ReadFile(flags.edit)
+		if err != nil {
+			return withstack.Errorf("%w", err)
+		}
+		messages = append(messages, commitlint.Clean(string(b)))
+	default:
+		b, err := io.ReadAll(in)
+		if err != nil {
+			return withstack.Errorf("%w", err)
+		}
+		messages = append(messages, string(b))
+	}
+	return lintMessages(out, cfg, messages)
+}
+
+// lintMessages prints the problems of each message the way commitlint does,
+// it returns an error when any of them is an error.
+func lintMessages(out io.Writer, cfg *commitlint.Config, messages []string) error {
+	var errs, warnings int
+	for _, msg := range messages {
+		problems := cfg.Lint(msg)
+		if len(problems) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "⧗   input: %s\n", commitlint.Parse(msg).Header)
+		for _, p := range problems {
+			fmt.Fprintln(out, p)
+			if p.Level == commitlint.Error {
+				errs++
+			} else {
+				warnings++
+			}
+		}
+		fmt.Fprintln(out)
+	}
+	if errs == 0 && warnings == 0 {
+		return nil
+	}
+	summary := fmt.Sprintf("found %d problems, %d warnings", errs, warnings)
+	if errs == 0 {
+		fmt.Fprintln(out, "⚠   "+summary)
+		return nil
+	}
+	fmt.Fprintln(out, "✖   "+summary)
+	return fmt.Errorf("%s", summary)
+}
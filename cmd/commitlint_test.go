@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+)
+
+func TestCommitlint(t *testing.T) {
+	rootFS := memfs.New()
+	repo, err := gitutil.MakeEmptyRepo(rootFS)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	for _, msg := range []string{"fix: Handle empty pages [DEALS-1234]", "fixed it", "docs: Add the readme [DEALS-1235]"} {
+		_, err := worktree.Commit(msg, gitutil.NewEmptyCommitOptions("Annie Mouse"))
+		require.NoError(t, err)
+	}
+
+	lint := func(in string, args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := NewCommitlintCommand(repo)
+		cmd.SetIn(strings.NewReader(in))
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := lint("fix: Handle empty pages [DEALS-1234]\n")
+	require.NoError(t, err)
+	require.Empty(t, out)
+
+	out, err = lint("", "--from", "HEAD~3")
+	require.EqualError(t, err, "found 3 problems, 0 warnings")
+	require.Contains(t, out, "⧗   input: fixed it\n✖   issue must not be empty [issue-empty]\n")
+	require.NotContains(t, out, "DEALS")
+
+	_, err = lint("", "--from", "HEAD~1", "--to", "HEAD")
+	require.NoError(t, err)
+
+	// the config of the repo disables the rule
+	require.NoError(t, util.WriteFile(rootFS, ".commitlintrc.yaml", []byte("rules:\n  issue-empty: [1, never]\n"), 0o644))
+	edit := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	require.NoError(t, os.WriteFile(edit, []byte("fix: Handle empty pages\n# Please enter the commit message\n"), 0o644))
+	out, err = lint("", "--edit", edit)
+	require.NoError(t, err)
+	require.Contains(t, out, "⚠   issue must not be empty [issue-empty]")
+
+	_, err = lint("", "--to", "HEAD")
+	require.Error(t, err)
+	_, err = lint("", "--edit", edit, "--from", "HEAD~1")
+	require.Error(t, err)
+}
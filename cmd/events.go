@@ -4,14 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/mailru/easyjson/jlexer"
-	"github.com/rivo/tview"
 	"github.com/spf13/cobra"
 
 	"github.com/stalwartgiraffe/cmr/internal/gitlab"
-	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+	"github.com/stalwartgiraffe/cmr/internal/store"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
 	"github.com/stalwartgiraffe/cmr/internal/utils"
 	"github.com/stalwartgiraffe/cmr/kam"
 	rc "github.com/stalwartgiraffe/cmr/restclient"
@@ -19,7 +22,8 @@ import (
 )
 
 func NewEventsCommand(app App, cfg *CmdConfig, cancel context.CancelFunc) *cobra.Command {
-	return &cobra.Command{
+	flags := &findFlags{}
+	eventsCmd := &cobra.Command{
 		Use:   "events",
 		Short: "run events",
 		Long:  `Run Events.`,
@@ -31,12 +35,14 @@ func NewEventsCommand(app App, cfg *CmdConfig, cancel context.CancelFunc) *cobra
 			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			runEventsCmd(app, cancel, cmd)
+			runEventsCmd(app, cfg, cancel, cmd, flags)
 		},
 	}
+	flags.add(eventsCmd, "events")
+	return eventsCmd
 }
 
-func runEventsCmd(app App, cancel context.CancelFunc, cmd *cobra.Command) {
+func runEventsCmd(app App, cfg *CmdConfig, cancel context.CancelFunc, cmd *cobra.Command, flags *findFlags) {
 	ctx := cmd.Context()
 	ctx, span := app.StartSpan(ctx, "runEventsCmd")
 	defer span.End()
@@ -47,19 +53,15 @@ func runEventsCmd(app App, cancel context.CancelFunc, cmd *cobra.Command) {
 		return
 	}
 
-	filepath := "ignore/my_recent_events.yaml"
+	filepath := gitlab.MyEventsFile
 	route := "events/"
-	authToken, err := loadGitlabAuthToken(ctx)
+	opts, err := gitlabOptions(ctx, cfg, rc.WithIsVerbose(true))
 	if err != nil {
 		utils.Redln(err)
 		return
 	}
 
-	client := NewEventsClient(
-		rc.WithBaseURL("https://gitlab.indexexchange.com/"),
-		rc.WithAuthToken(authToken),
-		rc.WithIsVerbose(true),
-	)
+	client := NewEventsClient(opts...)
 	app.Println("start updating recentEvents")
 	events, err := client.updateRecentEvents(ctx, app, cancel, filepath, route)
 	if err != nil {
@@ -68,14 +70,8 @@ func runEventsCmd(app App, cancel context.CancelFunc, cmd *cobra.Command) {
 	}
 	app.Printf("we got events %d", len(events))
 
-	content := tw.NewTwoBandTableContent(
-		tw.NewEventsTextTable(
-			events,
-			projects,
-		),
-	)
-
-	appTableRun(content, cancel)
+	spec := records.EventSpec(records.Lookups{Projects: projects})
+	runRecordScreen(cfg, spec, slices.Collect(maps.Values(events)), flags)
 }
 
 type EventsClient struct {
@@ -103,12 +99,21 @@ func (ec *EventsClient) updateRecentEvents(
 		return nil, err
 	}
 
-	recentEvents, err := ec.getEvents(ctx, app, cancel, route, events.LastDate())
+	watermark, err := gitlab.Watermark(ctx, store.Events, filepath)
+	if err != nil {
+		return nil, err
+	}
+	afterThisDate := ""
+	if !watermark.IsZero() {
+		afterThisDate = watermark.Format(time.DateOnly)
+	}
+
+	recentEvents, err := ec.getEvents(ctx, app, cancel, route, afterThisDate)
 	if err != nil {
 		return nil, err
 	}
 	events.Insert(recentEvents)
-	err = events.WriteToYamlFile(filepath)
+	err = recentEvents.Save(ctx, filepath)
 	return events, err
 }
 
@@ -207,11 +212,3 @@ func (ec *EventsClient) getEvents(
 
 	return eventsMap, nil
 }
-
-func appTableRun(tableContent tview.TableContent, _ context.CancelFunc) {
-	tviewApp := tview.NewApplication()
-	table := tw.MakeContentTable(tableContent, tviewApp.Stop)
-	if err := tviewApp.SetRoot(table, true).SetFocus(table).Run(); err != nil {
-		panic(err)
-	}
-}
@@ -26,9 +26,13 @@ func TestGetEvents(t *testing.T) {
 		route,
 		lastDateStr)
 	require.NoError(t, err)
-	require.NotNil(t, recentEvents)
+	require.NotEmpty(t, recentEvents)
+	usernames := map[int]string{}
+	for _, u := range server.Store().Dataset().Users {
+		usernames[u.ID] = u.Username
+	}
 	for k, e := range recentEvents {
 		require.Equal(t, k, e.ID)
-		require.Equal(t, "event_user", e.AuthorUsername)
+		require.Equal(t, usernames[e.AuthorID], e.AuthorUsername)
 	}
 }
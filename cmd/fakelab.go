@@ -0,0 +1,116 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// fakeLabTokenEnv is the env the localhost instance reads the fakelab token from.
+const fakeLabTokenEnv = "FAKELAB_TOKEN"
+
+// defaultFakeLabAddr only listens on localhost, the admin endpoints can rewrite the dataset.
+const defaultFakeLabAddr = "127.0.0.1:8089"
+
+// fakeLabFlags are the settings of cmr fakelab.
+type fakeLabFlags struct {
+	addr string
+	cfg  localhost.LabConfig
+}
+
+// NewFakeLabCommand initializes the command.
+func NewFakeLabCommand() *cobra.Command {
+	flags := &fakeLabFlags{}
+	fakeLabCmd := &cobra.Command{
+		Use:   "fakelab",
+		Short: "serve a fake gitlab to run the other commands against",
+		Long: `Run FakeLab.
+
+FakeLab serves the fake gitlab of the tests on a real port, seeded with
+made up users, projects, merge requests and events, or loaded from the
+dataset.json of the fixtures dir. Point the localhost gitlab instance of the
+config at it and pass --gitlab localhost to the other commands.
+
+The /__admin endpoints inject errors and latency and replace, reset or save
+the dataset, for example
+
+  curl -X PUT -d '{"server_error_every":3,"latency":"250ms"}' http://127.0.0.1:8089/__admin/simulation
+  curl -X POST http://127.0.0.1:8089/__admin/save
+
+Without a token the /__admin endpoints only serve requests from localhost.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if 0 < len(args) {
+				return fmt.Errorf("unexpected args %v", args)
+			} else {
+				return nil
+			}
+		},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFakeLab(cmd.Context(), cmd.OutOrStdout(), flags)
+		},
+	}
+	fakeLabCmd.Flags().StringVar(&flags.addr, "addr", defaultFakeLabAddr, "address to listen on")
+	fakeLabCmd.Flags().Uint64Var(&flags.cfg.Seed, "seed", localhost.DefaultSeed, "seed of the made up dataset")
+	fakeLabCmd.Flags().StringVar(&flags.cfg.Fixtures, "fixtures", "", "dir to load the dataset from and save it to")
+	fakeLabCmd.Flags().StringVar(&flags.cfg.Token, "token", os.Getenv(fakeLabTokenEnv),
+		"token every request must send, defaults to $"+fakeLabTokenEnv)
+	return fakeLabCmd
+}
+
+// runFakeLab serves the lab until ctx is done.
+func runFakeLab(ctx context.Context, out io.Writer, flags *fakeLabFlags) error {
+	lab, err := localhost.NewLab(flags.cfg)
+	if err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", flags.addr)
+	if err != nil {
+		return withstack.Errorf("Could not listen on %s: %w", flags.addr, err)
+	}
+	server := &http.Server{
+		Handler:           lab,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	printFakeLabConfig(out, listener.Addr(), flags.cfg.Token != "")
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Serve(listener)
+	}()
+	select {
+	case err := <-done:
+		return withstack.Errorf("Fakelab stopped: %w", err)
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return withstack.Errorf("Could not shut down fakelab: %w", err)
+	}
+	if err := <-done; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return withstack.Errorf("Fakelab stopped: %w", err)
+	}
+	return nil
+}
+
+// printFakeLabConfig prints the gitlab instance of the config that uses the lab at addr.
+func printFakeLabConfig(out io.Writer, addr net.Addr, withToken bool) {
+	port := addr.(*net.TCPAddr).Port
+	fmt.Fprintf(out, "fakelab listening on %s, add to the gitlab instances of the config:\n\n", addr)
+	fmt.Fprintf(out, "  - name: localhost\n    base_url: http://127.0.0.1:%d/\n    api: api/v4/\n", port)
+	if withToken {
+		fmt.Fprintf(out, "    token:\n      env: %s\n", fakeLabTokenEnv)
+	}
+	fmt.Fprintln(out)
+}
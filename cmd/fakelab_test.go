@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+)
+
+func TestFakeLab(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	out, in := io.Pipe()
+	flags := &fakeLabFlags{
+		addr: "127.0.0.1:0",
+		cfg:  localhost.LabConfig{Seed: localhost.DefaultSeed, Token: "secret"},
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- runFakeLab(ctx, in, flags)
+		_ = in.Close()
+	}()
+
+	var baseURL string
+	lines := bufio.NewScanner(out)
+	for lines.Scan() {
+		if m := regexp.MustCompile(`base_url: (\S+)`).FindStringSubmatch(lines.Text()); m != nil {
+			baseURL = m[1]
+			break
+		}
+	}
+	require.NotEmpty(t, baseURL)
+	go func() { _, _ = io.Copy(io.Discard, out) }()
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"api/v4/projects", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	cancel()
+	require.NoError(t, <-done)
+}
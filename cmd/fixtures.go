@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// fixturesFlags are the dirs cmr fixtures writes to.
+type fixturesFlags struct {
+	examples string
+	dataset  string
+}
+
+// NewFixturesCommand initializes the command.
+func NewFixturesCommand() *cobra.Command {
+	flags := &fixturesFlags{}
+	fixturesCmd := &cobra.Command{
+		Use:   "fixtures CASSETTE",
+		Short: "regenerate the test fixtures from a recorded cassette",
+		Long: `Run Fixtures.
+
+Fixtures writes the merge request lists of a cassette recorded with --record
+as the merge_request_NNN.json examples of the models tests, and the users,
+groups, projects, merge requests and events of the cassette as the
+dataset.json of the fake gitlab, see cmr fakelab --fixtures. For example
+
+  cmr mergerequests --record /tmp/mr.yaml
+  cmr fixtures /tmp/mr.yaml --examples internal/gitlab/localhost/api/merge_request_examples`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 1 {
+				return fmt.Errorf("expected the cassette, got args %v", args)
+			} else {
+				return nil
+			}
+		},
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cassette, err := rc.LoadCassette(args[0])
+			if err != nil {
+				return err
+			}
+			return writeFixtures(cassette, flags)
+		},
+	}
+	fixturesCmd.Flags().StringVar(&flags.examples, "examples", "", "dir to write the merge request examples to")
+	fixturesCmd.Flags().StringVar(&flags.dataset, "dataset", "", "dir to write the dataset of the fake gitlab to")
+	return fixturesCmd
+}
+
+// writeFixtures writes the fixtures the flags ask for.
+func writeFixtures(cassette *rc.Cassette, flags *fixturesFlags) error {
+	if flags.examples == "" && flags.dataset == "" {
+		return withstack.Errorf("Nothing to write, set --examples or --dataset")
+	}
+	if flags.examples != "" {
+		if err := writeMergeRequestExamples(cassette, flags.examples); err != nil {
+			return err
+		}
+	}
+	if flags.dataset != "" {
+		data, err := cassetteDataset(cassette)
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(flags.dataset, localhost.FixtureFile)
+		if err := localhost.NewStore(data).Save(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordedLists returns the bodies of the successful gets of lists at paths ending in suffix.
+func recordedLists(cassette *rc.Cassette, suffix string) ([]string, error) {
+	var bodies []string
+	for _, in := range cassette.Interactions {
+		if in.Request.Method != http.MethodGet || in.Response.StatusCode != http.StatusOK {
+			continue
+		}
+		u, err := url.Parse(in.Request.URL)
+		if err != nil {
+			return nil, withstack.Errorf("Could not parse recorded url %s: %w", in.Request.URL, err)
+		}
+		if strings.HasSuffix(strings.TrimSuffix(u.Path, "/"), suffix) {
+			bodies = append(bodies, in.Response.Body)
+		}
+	}
+	return bodies, nil
+}
+
+// writeMergeRequestExamples writes each recorded list of merge requests as pretty json.
+func writeMergeRequestExamples(cassette *rc.Cassette, dir string) error {
+	bodies, err := recordedLists(cassette, "/merge_requests")
+	if err != nil {
+		return err
+	}
+	for i, body := range bodies {
+		pretty, err := utils.PrettyJSON([]byte(body))
+		if err != nil {
+			return withstack.Errorf("Recorded merge requests are not json: %w", err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("merge_request_%03d.json", i+1))
+		if err := utils.WriteStringToFile(path, pretty); err != nil {
+			return withstack.Errorf("Could not write example %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// cassetteDataset returns what the recorded lists hold, the last recorded of each id wins.
+func cassetteDataset(cassette *rc.Cassette) (localhost.Dataset, error) {
+	var data localhost.Dataset
+	var err error
+	if data.Users, err = recordedItems(cassette, "/users", func(u localhost.UserBasic) int { return u.ID }); err != nil {
+		return data, err
+	}
+	if data.Groups, err = recordedItems(cassette, "/groups", func(g localhost.Group) int { return g.ID }); err != nil {
+		return data, err
+	}
+	if data.Projects, err = recordedItems(cassette, "/projects", func(p localhost.Project) int { return p.ID }); err != nil {
+		return data, err
+	}
+	if data.MergeRequests, err = recordedItems(cassette, "/merge_requests", func(mr localhost.MergeRequest) int { return mr.ID }); err != nil {
+		return data, err
+	}
+	if data.Events, err = recordedItems(cassette, "/events", func(e localhost.Event) int { return e.ID }); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// recordedItems decodes the recorded lists at paths ending in suffix, one item per id.
+func recordedItems[T any](cassette *rc.Cassette, suffix string, id func(T) int) ([]T, error) {
+	bodies, err := recordedLists(cassette, suffix)
+	if err != nil {
+		return nil, err
+	}
+	items := []T{}
+	index := map[int]int{}
+	for _, body := range bodies {
+		var page []T
+		if err := json.Unmarshal([]byte(body), &page); err != nil {
+			return nil, withstack.Errorf("Could not decode the recorded %s: %w", suffix, err)
+		}
+		for _, item := range page {
+			if i, ok := index[id(item)]; ok {
+				items[i] = item
+				continue
+			}
+			index[id(item)] = len(items)
+			items = append(items, item)
+		}
+	}
+	slices.SortStableFunc(items, func(a, b T) int { return id(a) - id(b) })
+	return items, nil
+}
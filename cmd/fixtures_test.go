@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+func TestRecordReplay(t *testing.T) {
+	server := localhost.NewServer()
+	t.Setenv("CMR_TEST_TOKEN", "looksligit")
+	c, err := config.LoadConfig(strings.NewReader(`
+gitlab:
+  instances:
+  - name: localhost
+    base_url: ` + server.URL() + `
+    token:
+      env: CMR_TEST_TOKEN
+`))
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	ctx := context.Background()
+
+	getProjects := func(cfg *CmdConfig) map[int]gitlab.ProjectModel {
+		opts, err := gitlabOptions(ctx, cfg)
+		require.NoError(t, err)
+		projects := make(map[int]gitlab.ProjectModel)
+		require.NoError(t, NewProjectsClient(opts...).getProjects(ctx, fixtures.NewApp(), nil, projects))
+		return projects
+	}
+
+	recorded := getProjects(&CmdConfig{Config: c, Record: path})
+	require.Len(t, recorded, 3)
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "looksligit")
+	require.Contains(t, string(b), rc.Scrubbed)
+
+	// the replay needs neither the server nor the token
+	server.Close()
+	t.Setenv("CMR_TEST_TOKEN", "")
+	require.Equal(t, recorded, getProjects(&CmdConfig{Config: c, Replay: path}))
+}
+
+func TestWriteFixtures(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cassette.yaml")
+	client := &http.Client{Transport: rc.NewRecorder(path, nil)}
+	for _, p := range []string{"merge_requests?per_page=50", "merge_requests?per_page=50&page=2", "projects", "users"} {
+		resp, err := client.Get(server.URL() + "/api/v4/" + p)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	cassette, err := rc.LoadCassette(path)
+	require.NoError(t, err)
+	flags := &fixturesFlags{examples: filepath.Join(dir, "examples"), dataset: filepath.Join(dir, "dataset")}
+	require.NoError(t, os.MkdirAll(flags.examples, 0755))
+	require.NoError(t, writeFixtures(cassette, flags))
+
+	examples, err := filepath.Glob(filepath.Join(flags.examples, "merge_request_*.json"))
+	require.NoError(t, err)
+	require.Len(t, examples, 2)
+
+	store, err := localhost.LoadStore(filepath.Join(flags.dataset, localhost.FixtureFile))
+	require.NoError(t, err)
+	data := store.Dataset()
+	require.Len(t, data.MergeRequests, 75)
+	require.Len(t, data.Projects, 3)
+	require.NotEmpty(t, data.Users)
+}
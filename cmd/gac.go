@@ -2,22 +2,33 @@ package cmd
 
 import (
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
-	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rivo/tview"
 	"github.com/spf13/cobra"
+	"github.com/stalwartgiraffe/cmr/internal/config"
 	"github.com/stalwartgiraffe/cmr/internal/gitutil"
 	"github.com/stalwartgiraffe/cmr/internal/prompts"
+	"github.com/stalwartgiraffe/cmr/internal/tui/staging"
 	"github.com/stalwartgiraffe/cmr/withstack"
 )
 
 // initCmd represents the init command
 func NewGacCommand(cfg *CmdConfig, repo *git.Repository) *cobra.Command {
+	var amend bool
 	pushCmd := &cobra.Command{
 		Use:   "gac",
 		Short: "git add and commit",
-		Long:  `git add and commit.`,
+		Long: `git add and commit.
+
+The staging screen moves whole files or single hunks between the worktree and
+the staging index, Enter commits exactly the staged changes.
+
+The author and committer are read from the git config as git does,
+GIT_AUTHOR_NAME, GIT_AUTHOR_EMAIL, GIT_COMMITTER_NAME and GIT_COMMITTER_EMAIL win.
+The commit is signed with gpg, ssh-keygen or gpgsm when commit.gpgsign is set,
+see gpg.format and user.signingkey.`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if 0 < len(args) {
 				return fmt.Errorf("unexpected args %v", args)
@@ -26,30 +37,35 @@ func NewGacCommand(cfg *CmdConfig, repo *git.Repository) *cobra.Command {
 			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			RunGac()
+			RunGac(repo, cfg.Config.BranchPolicy(), amend)
 		},
 	}
+	pushCmd.Flags().BoolVar(&amend, "amend", false, "replace the last commit with the staged files and keep its message")
 
 	return pushCmd
 }
-func RunGac() {
-	repo, err := gitutil.OpenCwd()
+func RunGac(repo *git.Repository, branches *config.Branches, amend bool) {
+	var err error
+	if repo == nil {
+		if repo, err = gitutil.OpenCwd(); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
+	env, err := gitutil.NewConfigEnv()
 	if err != nil {
 		fmt.Println(err)
+		return
 	}
-	err = runRepoGac(repo)
+	gitConfig, err := gitutil.LoadConfig(repo, env)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	err = runRepoGac(repo, gitConfig, branches, amend)
 	if err != nil {
 		fmt.Println(err)
 	}
-}
-
-// files with these status in worktree get added to staging index
-var worktreeFilter = []git.StatusCode{
-	git.Untracked,
-	git.Modified,
-	git.Deleted,
-	git.Renamed,
-	git.Copied,
 }
 
 // files with these status in staging index get commited to local repo
@@ -61,62 +77,50 @@ var stagingFilter = []git.StatusCode{
 	git.Copied,
 }
 
-func runRepoGac(repo *git.Repository) error {
+func runRepoGac(repo *git.Repository, gitConfig *gitutil.Config, branches *config.Branches, amend bool) error {
 	worktree, err := repo.Worktree()
 	if err != nil {
 		return withstack.Errorf("Could not get worktree: %w", err)
 	}
 
-	workTreeMatches, err := gitutil.FindByWorktreeStatus(worktree, worktreeFilter)
+	index, err := gitutil.NewStaging(repo)
 	if err != nil {
 		return err
 	}
+	ok, err := staging.NewScreen(tview.NewApplication(), index).Run()
+	if err != nil {
+		return withstack.Errorf("Could not run the staging screen: %w", err)
+	}
+	if !ok {
+		return nil
+	}
 
-	if 0 < len(workTreeMatches) {
-		worktreeFiles, err := prompts.SelectFiles(workTreeMatches, prompts.ToWorkTreeStatus)
-		if err != nil {
-			return err
-		}
-		if len(worktreeFiles) < 1 {
-			return nil
-		}
-
-		if err = gitutil.AddAll(worktree, worktreeFiles); err != nil {
-			return err
-		}
+	if amend {
+		_, err = gitutil.Commit(repo, gitConfig, "", true)
+		return err
 	}
 
-	filePaths, commitMsg, err := getCommit(repo, worktree)
+	filePaths, commitMsg, err := getCommit(repo, worktree, gitConfig, branches)
 	if err != nil {
 		return err
 	}
 	if len(filePaths) < 1 {
 		return nil
 	}
-
-	// Commits the current staging area to the repository, with the new file
-	// just created. We should provide the object.Signature of Author of the
-	// commit Since version 5.0.1, we can omit the Author signature, being read
-	// from the git config files.
-	_, err = worktree.Commit(commitMsg,
-		&git.CommitOptions{
-			Author: &object.Signature{
-				Name:  "John Doe",
-				Email: "john@doe.org",
-				When:  time.Now(),
-			},
-		})
-	if err != nil {
-		return err
-	}
-
-	// push using default options
-	//return repo.Gac(&git.GacOptions{})
-	return nil
+	_, err = gitutil.Commit(repo, gitConfig, commitMsg, false)
+	return err
 }
 
-func getCommit(repo *git.Repository, worktree *git.Worktree) ([]string, string, error) {
-	issue, description, err := getJiraTitleFromBranch(repo)
+// recentAuthors is how many recent authors are offered as co-authors.
+const recentAuthors = 8
+
+func getCommit(
+	repo *git.Repository,
+	worktree *git.Worktree,
+	gitConfig *gitutil.Config,
+	branches *config.Branches,
+) ([]string, string, error) {
+	draft, err := getDraftFromBranch(repo, branches)
 	if err != nil {
 		return nil, "", err
 	}
@@ -124,20 +128,37 @@ func getCommit(repo *git.Repository, worktree *git.Worktree) ([]string, string,
 	if err != nil {
 		return nil, "", err
 	}
-	filePaths, opTxt, descriptionTxt, issueTxt, err := prompts.CommitToLocal(stagingMatches, issue, description)
+	authors, err := coAuthors(repo, gitConfig)
+	if err != nil {
+		return nil, "", err
+	}
+	filePaths, msg, err := prompts.CommitToLocal(stagingMatches, draft, authors)
 	if err != nil {
 		return nil, "", err
 	}
 	if len(filePaths) < 1 {
 		return nil, "", nil
 	}
-
-	msg := formatConventionalCommit(opTxt, descriptionTxt, issueTxt)
-	return filePaths, msg, nil
+	return filePaths, msg.String(), nil
 }
 
-func formatConventionalCommit(opTxt, descriptionTxt, issueTxt string) string {
-	return fmt.Sprintf("%s: %s [%s]", opTxt, descriptionTxt, issueTxt)
+// coAuthors returns the recent authors of the repo other than the committer, Name <email>.
+func coAuthors(repo *git.Repository, gitConfig *gitutil.Config) ([]string, error) {
+	committer, err := gitConfig.Committer()
+	if err != nil {
+		return nil, err
+	}
+	recent, err := gitutil.RecentAuthors(repo, recentAuthors+1)
+	if err != nil {
+		return nil, err
+	}
+	var authors []string
+	for _, a := range recent {
+		if !strings.EqualFold(a.Email, committer.Email) && len(authors) < recentAuthors {
+			authors = append(authors, a.String())
+		}
+	}
+	return authors, nil
 }
 
 // see https://nomix.gpages.indexexchange.com/arc3/doc/pol/commit-message-guidelines/
@@ -148,12 +169,10 @@ func formatConventionalCommit(opTxt, descriptionTxt, issueTxt string) string {
 // ['sentence-case', 'start-case', 'pascal-case', 'upper-case']
 // very annoying at this time 2023-12-19  support for acronyms is still a feature request
 // https://github.com/conventional-changelog/commitlint/issues/3312
-func getJiraTitleFromBranch(repo *git.Repository) (string, string, error) {
-	branchName, err := gitutil.BranchShortName(repo)
+func getDraftFromBranch(repo *git.Repository, branches *config.Branches) (prompts.CommitMessage, error) {
+	branch, err := gitutil.ReadBranch(repo, branches)
 	if err != nil {
-		return "", "", withstack.Errorf("Could not get branch name: %w", err)
+		return prompts.CommitMessage{}, withstack.Errorf("Could not get branch name: %w", err)
 	}
-
-	issue, description := gitutil.ParseBranchJiraTitle(branchName)
-	return issue, description, nil
+	return prompts.CommitMessage{Type: branch.Type, Issue: branch.Issue, Description: branch.Description}, nil
 }
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"net/url"
+	"sync"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+// gitlabInstance returns the instance chosen with --gitlab from the loaded config.
+func (c *CmdConfig) gitlabInstance() (config.GitlabInstance, error) {
+	var g config.Gitlab
+	if c.Config != nil {
+		g = c.Config.Gitlab
+	}
+	return g.Instance(c.GitlabName)
+}
+
+// gitlabHost returns the host name that credentials are stored under.
+func gitlabHost(inst config.GitlabInstance) (string, error) {
+	u, err := url.Parse(inst.BaseURL)
+	if err != nil {
+		return "", err
+	}
+	return u.Host, nil
+}
+
+// gitlabOptions returns the client options that connect to the chosen gitlab instance.
+// The overrides are applied last.
+func gitlabOptions(ctx context.Context, cfg *CmdConfig, overrides ...rc.Option) ([]rc.Option, error) {
+	opts, token, err := baseGitlabOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	authToken, err := token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, rc.WithAuthToken(authToken))
+	return append(opts, overrides...), nil
+}
+
+// lazyGitlabOptions are the gitlabOptions that load the token on the first request,
+// for a command such as mvc that may never reach gitlab. token loads the same token for git.
+func lazyGitlabOptions(cfg *CmdConfig, overrides ...rc.Option) (opts []rc.Option, token func(context.Context) (string, error), err error) {
+	opts, token, err = baseGitlabOptions(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts = append(opts, rc.WithAuthTokenFunc(token))
+	return append(opts, overrides...), token, nil
+}
+
+// baseGitlabOptions returns the client options of the chosen gitlab instance but its token,
+// and a func that loads the token once.
+func baseGitlabOptions(cfg *CmdConfig) ([]rc.Option, func(context.Context) (string, error), error) {
+	inst, err := cfg.gitlabInstance()
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := []rc.Option{
+		rc.WithBaseURL(inst.BaseURL),
+		rc.WithAPI(inst.API),
+	}
+	cassette, err := cfg.loadCassette()
+	if err != nil {
+		return nil, nil, err
+	}
+	if cassette != nil {
+		opts = append(opts, rc.WithTransport(cassette))
+	}
+
+	var once sync.Once
+	var authToken string
+	var tokenErr error
+	token := func(ctx context.Context) (string, error) {
+		// a replay never reaches gitlab so it needs no token
+		if cfg.Replay != "" {
+			return "", nil
+		}
+		once.Do(func() {
+			authToken, tokenErr = loadGitlabAuthToken(ctx, inst)
+		})
+		return authToken, tokenErr
+	}
+	return opts, token, nil
+}
+
+// loadCassette returns the cassette chosen with --record or --replay, nil without one.
+func (c *CmdConfig) loadCassette() (*rc.Cassette, error) {
+	if c.cassette != nil {
+		return c.cassette, nil
+	}
+	switch {
+	case c.Record != "":
+		c.cassette = rc.NewRecorder(c.Record, nil)
+	case c.Replay != "":
+		cassette, err := rc.LoadCassette(c.Replay)
+		if err != nil {
+			return nil, err
+		}
+		c.cassette = cassette
+	}
+	return c.cassette, nil
+}
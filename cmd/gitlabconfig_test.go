@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+)
+
+func TestGitlabOptions(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+	t.Setenv("CMR_TEST_TOKEN", "looksligit")
+
+	yaml := `
+gitlab:
+  default: remote
+  instances:
+  - name: remote
+    base_url: https://gitlab.example.com/
+  - name: localhost
+    base_url: ` + server.URL() + `
+    token:
+      env: CMR_TEST_TOKEN
+`
+	c, err := config.LoadConfig(strings.NewReader(yaml))
+	require.NoError(t, err)
+	cfg := &CmdConfig{Config: c, GitlabName: "localhost"}
+
+	inst, err := cfg.gitlabInstance()
+	require.NoError(t, err)
+	require.Equal(t, "localhost", inst.Name)
+	require.Equal(t, server.URL()+"/", inst.BaseURL)
+
+	ctx := context.Background()
+	opts, err := gitlabOptions(ctx, cfg)
+	require.NoError(t, err)
+	projects := make(map[int]gitlab.ProjectModel)
+	err = NewProjectsClient(opts...).getProjects(ctx, fixtures.NewApp(), nil, projects)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(projects))
+
+	cfg.GitlabName = "missing"
+	_, err = gitlabOptions(ctx, cfg)
+	require.Error(t, err)
+}
+
+func TestLazyGitlabOptions(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+	t.Setenv("CMR_TEST_TOKEN", "")
+
+	yaml := `
+gitlab:
+  instances:
+  - name: localhost
+    base_url: ` + server.URL() + `
+    token:
+      env: CMR_TEST_TOKEN
+`
+	c, err := config.LoadConfig(strings.NewReader(yaml))
+	require.NoError(t, err)
+	cfg := &CmdConfig{Config: c}
+
+	ctx := context.Background()
+	_, err = gitlabOptions(ctx, cfg)
+	require.Error(t, err)
+
+	// without a token the options load, the first request fails
+	opts, token, err := lazyGitlabOptions(cfg)
+	require.NoError(t, err)
+	projects := make(map[int]gitlab.ProjectModel)
+	err = NewProjectsClient(opts...).getProjects(ctx, fixtures.NewApp(), nil, projects)
+	require.Error(t, err)
+	_, err = token(ctx)
+	require.Error(t, err)
+
+	t.Setenv("CMR_TEST_TOKEN", "looksligit")
+	opts, token, err = lazyGitlabOptions(cfg)
+	require.NoError(t, err)
+	err = NewProjectsClient(opts...).getProjects(ctx, fixtures.NewApp(), nil, projects)
+	require.NoError(t, err)
+	require.Equal(t, 3, len(projects))
+	authToken, err := token(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "looksligit", authToken)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+)
+
+// commitMsgHook lints the message of each commit, git passes the file of the message.
+const commitMsgHook = `exec cmr commitlint --edit "$1"
+`
+
+func addHooksCommand(parent *cobra.Command) {
+	hooksCmd := NewHooksCommand()
+	hooksCmd.AddCommand(NewHooksInstallCommand(nil, nil))
+	parent.AddCommand(hooksCmd)
+}
+
+// NewHooksCommand initializes the command.
+func NewHooksCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hooks",
+		Short: "manage the git hooks of the repo",
+		// the hooks do not need the cmr config
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+		Args: func(cmd *cobra.Command, args []string) error {
+			return fmt.Errorf("hooks must be called with a sub command")
+		},
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+}
+
+// NewHooksInstallCommand initializes the command, env is nil for the env of the os.
+func NewHooksInstallCommand(repo *git.Repository, env *gitutil.ConfigEnv) *cobra.Command {
+	var force bool
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "install the commit-msg hook that runs cmr commitlint",
+		Long: `Install the commit-msg hook that runs cmr commitlint on each commit.
+
+The hook is written to core.hooksPath when it is set, else to .git/hooks.
+A commit-msg hook that cmr did not install is only replaced with --force.`,
+		SilenceUsage: true,
+		Args: func(cmd *cobra.Command, args []string) error {
+			return NoArgs(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runHooksInstall(repo, env, cmd.OutOrStdout(), force)
+		},
+	}
+	installCmd.Flags().BoolVar(&force, "force", false, "replace a commit-msg hook that cmr did not install")
+	return installCmd
+}
+
+func runHooksInstall(repo *git.Repository, env *gitutil.ConfigEnv, out io.Writer, force bool) error {
+	var err error
+	if repo == nil {
+		if repo, err = gitutil.OpenCwd(); err != nil {
+			return err
+		}
+	}
+	if env == nil {
+		osEnv, err := gitutil.NewConfigEnv()
+		if err != nil {
+			return err
+		}
+		env = &osEnv
+	}
+	gitConfig, err := gitutil.LoadConfig(repo, *env)
+	if err != nil {
+		return err
+	}
+	file, err := gitutil.InstallHook(repo, gitConfig, "commit-msg", commitMsgHook, force)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "installed", file)
+	return nil
+}
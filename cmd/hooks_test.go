@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+)
+
+func TestHooksInstall(t *testing.T) {
+	rootFS := memfs.New()
+	repo, err := gitutil.MakeEmptyRepo(rootFS)
+	require.NoError(t, err)
+	env := &gitutil.ConfigEnv{
+		FS:     memfs.New(),
+		Home:   "/home/annie",
+		Getenv: func(key string) string { return map[string]string{"GIT_CONFIG_NOSYSTEM": "1"}[key] },
+		Now:    time.Now,
+	}
+
+	install := func(args ...string) (string, error) {
+		var out bytes.Buffer
+		cmd := NewHooksInstallCommand(repo, env)
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	out, err := install()
+	require.NoError(t, err)
+	require.Equal(t, "installed /.git/hooks/commit-msg\n", out)
+	hook, err := util.ReadFile(rootFS, ".git/hooks/commit-msg")
+	require.NoError(t, err)
+	require.Contains(t, string(hook), `exec cmr commitlint --edit "$1"`)
+
+	require.NoError(t, util.WriteFile(rootFS, ".git/hooks/commit-msg", []byte("#!/bin/sh\nnpx commitlint --edit\n"), 0o755))
+	_, err = install()
+	require.ErrorIs(t, err, gitutil.ErrHookExists)
+	_, err = install("--force")
+	require.NoError(t, err)
+}
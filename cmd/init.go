@@ -8,7 +8,7 @@ import (
 
 func addInitCommand(cfg *CmdConfig, parent *cobra.Command) {
 	initCmd := NewInitCommand()
-	addBranchCommand(initCmd)
+	addBranchCommand(cfg, initCmd)
 	parent.AddCommand(initCmd)
 }
 
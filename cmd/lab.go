@@ -4,23 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"os"
 	"sync"
 
 	"github.com/spf13/cobra"
 
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/credentials"
 	"github.com/stalwartgiraffe/cmr/internal/gitlab"
 	"github.com/stalwartgiraffe/cmr/internal/utils"
 	rc "github.com/stalwartgiraffe/cmr/restclient"
-	"github.com/stalwartgiraffe/cmr/xr"
+)
+
+const (
+	projectsPartialFile    = "ignore/projects.partial.yaml"
+	projectsCheckpointFile = "ignore/projects.checkpoint.yaml"
 )
 
 // NewLabCommand initializes the command.
 func NewLabCommand(app App, cfg *CmdConfig) *cobra.Command {
-	return &cobra.Command{
+	var restart bool
+	labCmd := &cobra.Command{
 		Use:   "lab",
-		Short: "fetch the collection of projects and write them to projects file",
-		Long:  `Run Lab.`,
+		Short: "fetch the collection of projects and write them to the cache",
+		Long: `Run Lab.
+
+The pages fetched so far are checkpointed when lab is interrupted or fails,
+the next run resumes with the pages that are left.`,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if 0 < len(args) {
 				return fmt.Errorf("unexpected args %v", args)
@@ -29,44 +38,98 @@ func NewLabCommand(app App, cfg *CmdConfig) *cobra.Command {
 			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			runLabCmd(app, cmd)
+			runLabCmd(app, cfg, cmd, restart)
 		},
 	}
+	labCmd.Flags().BoolVar(&restart, "restart", false, "ignore the checkpoint of an interrupted run")
+	return labCmd
 }
 
-func runLabCmd(app App, cmd *cobra.Command) {
+func runLabCmd(app App, cfg *CmdConfig, cmd *cobra.Command, restart bool) {
 	ctx := cmd.Context()
 	ctx, span := app.StartSpan(ctx, "RunLab")
 	defer span.End()
 
-	var err error
-	authToken, err := loadGitlabAuthToken(ctx)
+	opts, err := gitlabOptions(ctx, cfg)
 	if err != nil {
 		utils.Redln(err)
 		return
 	}
 
-	client := NewProjectsClient(
-		rc.WithBaseURL("https://gitlab.indexexchange.com/"),
-		rc.WithAuthToken(authToken),
-	)
-	projects, errs := client.getProjects(
+	checkpoint := gitlab.NewCheckpoint()
+	projects := make(map[int]gitlab.ProjectModel)
+	if !restart {
+		if checkpoint, projects, err = loadProjectsCheckpoint(); err != nil {
+			utils.Redln(err)
+			return
+		}
+	}
+
+	client := NewProjectsClient(opts...)
+	errs := client.getProjects(
 		ctx,
-		app)
+		app,
+		checkpoint,
+		projects)
 
 	if errs != nil {
 		utils.Redln(errs)
+		if err := saveProjectsCheckpoint(checkpoint, projects); err != nil {
+			utils.Redln(err)
+			return
+		}
+		fmt.Println("saved", len(projects), "projects, run lab again to resume")
 		return
 	}
 
 	fmt.Println("num projects", len(projects))
-	if err := utils.WriteToYamlFile("ignore/projects.yaml", utils.ToSortedSlice(projects)); err != nil {
+	if err := gitlab.SaveProjects(ctx, utils.ToSortedSlice(projects)); err != nil {
+		utils.Redln(err)
+		return
+	}
+	if err := removeProjectsCheckpoint(); err != nil {
 		utils.Redln(err)
 		return
 	}
 	fmt.Println("done reading")
 }
 
+// loadProjectsCheckpoint reads the checkpoint and the projects of an interrupted run.
+func loadProjectsCheckpoint() (*gitlab.Checkpoint, map[int]gitlab.ProjectModel, error) {
+	projects := make(map[int]gitlab.ProjectModel)
+	checkpoint, err := gitlab.LoadCheckpoint(projectsCheckpointFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if checkpoint.IsEmpty() {
+		return checkpoint, projects, nil
+	}
+	var partial []gitlab.ProjectModel
+	if err := utils.ReadFromYamlFile(projectsPartialFile, &partial); err != nil {
+		return nil, nil, err
+	}
+	for _, p := range partial {
+		projects[p.ID] = p
+	}
+	fmt.Println("resuming with", len(projects), "projects")
+	return checkpoint, projects, nil
+}
+
+// saveProjectsCheckpoint writes the projects before the checkpoint that records them.
+func saveProjectsCheckpoint(checkpoint *gitlab.Checkpoint, projects map[int]gitlab.ProjectModel) error {
+	if err := utils.WriteToYamlFile(projectsPartialFile, utils.ToSortedSlice(projects)); err != nil {
+		return err
+	}
+	return checkpoint.Save(projectsCheckpointFile)
+}
+
+func removeProjectsCheckpoint() error {
+	if err := gitlab.RemoveCheckpoint(projectsCheckpointFile); err != nil {
+		return err
+	}
+	return gitlab.RemoveCheckpoint(projectsPartialFile)
+}
+
 type ProjectsClient struct {
 	client *gitlab.Client
 }
@@ -77,28 +140,39 @@ func NewProjectsClient(overrides ...rc.Option) *ProjectsClient {
 	}
 }
 
+// getProjects adds the projects of the pages not yet in the checkpoint.
 func (pc *ProjectsClient) getProjects(
 	ctx context.Context,
 	app App,
-) (map[int]gitlab.ProjectModel, error) {
+	checkpoint *gitlab.Checkpoint,
+	projectsMap map[int]gitlab.ProjectModel,
+) error {
 	const startPage = 1
+	return pc.gatherProjects(ctx, app, checkpoint, *gitlab.NewPageQuery("projects/", startPage), projectsMap)
+}
 
+// gatherProjects adds the projects of every page of the query.
+func (pc *ProjectsClient) gatherProjects(
+	ctx context.Context,
+	app App,
+	checkpoint *gitlab.Checkpoint,
+	first gitlab.UrlQuery,
+	projectsMap map[int]gitlab.ProjectModel,
+) error {
 	firstQueries := make(chan gitlab.UrlQuery)
 	totalPagesLimit := 1000
-	projectCalls, gatherProjectErrs := gitlab.GatherPageCallsDualApp[[]gitlab.ProjectModel](
+	projectCalls, gatherProjectErrs := gitlab.GatherPageCallsDualCheckpoint[[]gitlab.ProjectModel](
 		ctx,
 		app,
 		pc.client,
 		firstQueries,
 		totalPagesLimit,
+		checkpoint,
 	)
 	errorsFan := []<-chan error{}
 	errorsFan = append(errorsFan, gatherProjectErrs)
 
-	firstQueries <- *gitlab.NewPageQuery(
-		"projects/",
-		startPage,
-	)
+	firstQueries <- first
 	close(firstQueries)
 	transformCap := 5
 	projectResults := gitlab.TransformToOne(
@@ -117,7 +191,6 @@ func (pc *ProjectsClient) getProjects(
 			errs = errors.Join(errs, e)
 		}
 	}()
-	projectsMap := make(map[int]gitlab.ProjectModel)
 	go func() {
 		defer wg.Done()
 		for p := range projectResults {
@@ -126,35 +199,15 @@ func (pc *ProjectsClient) getProjects(
 	}()
 
 	wg.Wait()
-	return projectsMap, errs
+	return errs
 }
 
-func loadGitlabAuthToken(ctx context.Context) (string, error) {
-	token := os.Getenv("GIT_LAB_ACCESS_TOKEN")
-	if token != "" {
-		return token, nil
-	}
-	fmt.Println("Xsecret-tool:")
-	stArgs := []string{"lookup", "pat", "gitlab"}
-	const secretTool = "secret-tool"
-	const expectedStatus int = 3
-	token, err := xr.Run(ctx, secretTool, expectedStatus, nil, stArgs...)
-	fmt.Println("pat gitlab", token)
+// loadGitlabAuthToken returns the token of the instance from its chain of credential providers.
+func loadGitlabAuthToken(ctx context.Context, inst config.GitlabInstance) (string, error) {
+	host, err := gitlabHost(inst)
 	if err != nil {
 		return "", err
 	}
-	if token != "" {
-		return token, nil
-	}
-
-	stArgs = []string{"lookup", "pat", "publicus"}
-	token, err = xr.Run(ctx, secretTool, expectedStatus, nil, stArgs...)
-	fmt.Println("pat publicus", token)
-	if err != nil {
-		return "", err
-	}
-	if token != "" {
-		return token, nil
-	}
-	return "", fmt.Errorf("error: empty authToken")
+	token, _, err := credentials.FromConfig(inst.Token, nil).Get(ctx, host)
+	return token, err
 }
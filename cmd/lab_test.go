@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
 	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
 	rc "github.com/stalwartgiraffe/cmr/restclient"
 )
@@ -18,11 +19,38 @@ func TestGetProjects(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	_ = cancel
 	app := fixtures.NewApp()
-	projects, errs := client.getProjects(
+	projects := make(map[int]gitlab.ProjectModel)
+	errs := client.getProjects(
 		ctx,
-		app)
+		app,
+		nil,
+		projects)
 
 	require.NoError(t, errs)
 	require.NotNil(t, projects)
 	require.Equal(t, 3, len(projects))
 }
+
+func TestGetProjectsResume(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+	client := NewProjectsClient(rc.WithBaseURL(server.URL()))
+	client.client.SetRetryPolicy(gitlab.RetryPolicy{MaxAttempts: 1})
+	ctx := context.Background()
+	app := fixtures.NewApp()
+	checkpoint := gitlab.NewCheckpoint()
+	projects := make(map[int]gitlab.ProjectModel)
+
+	server.Simulate(localhost.Simulation{ServerErrorEvery: 1})
+	require.Error(t, client.getProjects(ctx, app, checkpoint, projects))
+	require.Empty(t, projects)
+
+	server.Simulate(localhost.Simulation{})
+	require.NoError(t, client.getProjects(ctx, app, checkpoint, projects))
+	require.Equal(t, 3, len(projects))
+	require.Equal(t, 1, server.Gets())
+
+	// every page is in the checkpoint so nothing is fetched again
+	require.NoError(t, client.getProjects(ctx, app, checkpoint, projects))
+	require.Equal(t, 1, server.Gets())
+}
@@ -3,23 +3,28 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"maps"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/mailru/easyjson/jlexer"
 	"github.com/spf13/cobra"
 
 	"github.com/stalwartgiraffe/cmr/internal/gitlab"
-	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+	"github.com/stalwartgiraffe/cmr/internal/store"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
 	"github.com/stalwartgiraffe/cmr/internal/utils"
 	"github.com/stalwartgiraffe/cmr/kam"
 	rc "github.com/stalwartgiraffe/cmr/restclient"
 )
 
 func NewMergeRequestCommand(app App, cfg *CmdConfig, cancel context.CancelFunc) *cobra.Command {
-	return &cobra.Command{
+	flags := &findFlags{}
+	mrCmd := &cobra.Command{
 		Use:   "mergerequests",
 		Short: "run mergerequests",
 		Long:  `Run MergeRequest.`,
@@ -27,32 +32,27 @@ func NewMergeRequestCommand(app App, cfg *CmdConfig, cancel context.CancelFunc)
 			return NoArgs(args)
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			runMergeRequestCmd(app, cancel, cmd)
+			runMergeRequestCmd(app, cfg, cancel, cmd, flags)
 		},
 	}
+	flags.add(mrCmd, "merge requests")
+	return mrCmd
 }
-func runMergeRequestCmd(app App, cancel context.CancelFunc, cmd *cobra.Command) {
+
+func runMergeRequestCmd(app App, cfg *CmdConfig, cancel context.CancelFunc, cmd *cobra.Command, flags *findFlags) {
 	ctx := cmd.Context()
 	ctx, span := app.StartSpan(ctx, "runMergeRequestCmd")
 	defer span.End()
 
-	filepath := "ignore/my_recent_merge_request.yaml"
+	filepath := gitlab.MyMergeRequestsFile
 	route := "merge_requests/"
-	var err error
-	authToken, err := loadGitlabAuthToken(ctx)
-
-	app.Println("we got authToken")
-
+	opts, err := gitlabOptions(ctx, cfg, rc.WithIsVerbose(true))
 	if err != nil {
 		utils.Redln(err)
 		return
 	}
 
-	client := NewMergeRequestClient(
-		rc.WithBaseURL("https://gitlab.indexexchange.com/"),
-		rc.WithAuthToken(authToken),
-		rc.WithIsVerbose(true),
-	)
+	client := NewMergeRequestClient(opts...)
 	app.Println("start updating recentEvents")
 	requests, err := client.updateRecentMergeRequest(ctx, app, cancel, filepath, route)
 	app.Printf("we got events %d", len(requests))
@@ -61,13 +61,13 @@ func runMergeRequestCmd(app App, cancel context.CancelFunc, cmd *cobra.Command)
 		return
 	}
 
-	tableContent := tw.NewTwoBandTableContent(
-		tw.NewMergeRequestTextTable(
-			nil, requests,
-		),
-	)
-
-	appTableRun(tableContent, cancel)
+	projects, err := gitlab.ReadProjects()
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	spec := records.MergeRequestSpec(records.Lookups{Projects: projects})
+	runRecordScreen(cfg, spec, slices.Collect(maps.Values(requests)), flags)
 }
 
 type MergeRequestClient struct {
@@ -95,12 +95,21 @@ func (mrc *MergeRequestClient) updateRecentMergeRequest(
 		return nil, err
 	}
 
-	recentRequests, err := mrc.getMergeRequests(ctx, app, cancel, route, requests.LastCreatedDate())
+	watermark, err := gitlab.Watermark(ctx, store.MergeRequests, filepath)
+	if err != nil {
+		return nil, err
+	}
+	updatedAfter := ""
+	if !watermark.IsZero() {
+		updatedAfter = watermark.Format(time.RFC3339)
+	}
+
+	recentRequests, err := mrc.getMergeRequests(ctx, app, cancel, route, updatedAfter)
 	if err != nil {
 		return nil, err
 	}
 	requests.Insert(recentRequests)
-	err = requests.WriteToYamlFile(filepath)
+	err = recentRequests.Save(ctx, filepath)
 	return requests, err
 }
 
@@ -109,7 +118,7 @@ func (mrc *MergeRequestClient) getMergeRequests(
 	app App,
 	cancel context.CancelFunc,
 	route string,
-	afterThisDate string,
+	updatedAfter string,
 ) (
 	gitlab.MergeRequestMap,
 	error,
@@ -135,8 +144,8 @@ func (mrc *MergeRequestClient) getMergeRequests(
 			// action - include only particular action type
 			// target_type - include only a particular target type
 
-			"updated_after": afterThisDate, // 2006-01-02 format expected
-			"sort":          "desc",        // newest first
+			"updated_after": updatedAfter, // ISO 8601 date time
+			"sort":          "desc",       // newest first
 
 			"page":     startPage,
 			"per_page": per_page,
@@ -178,19 +187,10 @@ func unmarshalMergeRequestModel(
 	return unmarshalModels(app, resp.Body())
 }
 
-var countModel int
-
 func unmarshalModels(app App, jsonBlob []byte) (
 	*[]gitlab.MergeRequestModel,
 	error) {
 
-	prettyJson, err := utils.PrettyJSON(jsonBlob)
-	if err == nil {
-		exampleName := fmt.Sprintf("internal/gitlab/localhost/api/merge_request_examples/merge_request_%03d.json", countModel)
-		countModel++
-		utils.WriteStringToFile(exampleName, prettyJson)
-	}
-
 	lexer := jlexer.Lexer{Data: jsonBlob}
 	var em gitlab.MergeRequestModelSlice
 
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/review"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+	"github.com/stalwartgiraffe/cmr/kam"
+)
+
+// NewMRsCommand initializes the command.
+func NewMRsCommand(app App, cfg *CmdConfig) *cobra.Command {
+	var username string
+	var asJSON bool
+	flags := &findFlags{}
+	mrsCmd := &cobra.Command{
+		Use:   "mrs",
+		Short: "show the dashboard of my open merge requests, most urgent first",
+		Long: `Show the dashboard of my open merge requests.
+
+The dashboard splits the open merge requests into the ones I authored,
+the ones I review or am assigned to, and the approved ones that are not merged yet.
+The state of each is derived from its merge status, conflicts, discussions,
+approvals and latest pipeline. The merge requests that wait on me come first,
+then the ones that waited longest since they were last updated.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			return NoArgs(args)
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runMRsCmd(cmd.Context(), app, cfg, cmd.OutOrStdout(), username, asJSON, flags); err != nil {
+				utils.Redln(err)
+			}
+		},
+	}
+	mrsCmd.Flags().StringVar(&username, "user", "", "gitlab username of the dashboard (default is the username of the gitlab instance, then the user of the token)")
+	mrsCmd.Flags().BoolVar(&asJSON, "json", false, "print the dashboard as json instead of showing the tui")
+	flags.add(mrsCmd, "merge requests")
+	return mrsCmd
+}
+
+func runMRsCmd(ctx context.Context, app App, cfg *CmdConfig, out io.Writer, username string, asJSON bool, flags *findFlags) error {
+	ctx, span := app.StartSpan(ctx, "runMRsCmd")
+	defer span.End()
+
+	opts, err := gitlabOptions(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	client := gitlab.NewClient(opts...)
+	if username == "" {
+		if inst, err := cfg.gitlabInstance(); err == nil {
+			username = inst.Username
+		}
+	}
+	if username == "" {
+		user, err := client.GetCurrentUser(ctx, app)
+		if err != nil {
+			return err
+		}
+		username = user.Username
+	}
+
+	inputs, err := dashboardInputs(ctx, app, client, username)
+	if err != nil {
+		return err
+	}
+	items := review.Build(username, inputs, time.Now())
+	if asJSON {
+		return writeDashboardJSON(out, items)
+	}
+
+	projects, err := gitlab.ReadProjects()
+	if err != nil {
+		return err
+	}
+	spec := records.DashboardSpec(records.Lookups{Projects: projects}, config.DashboardTable)
+	runRecordScreen(cfg, spec, items, flags)
+	return nil
+}
+
+// dashboardInputs returns the open merge requests the user authored, reviews or is assigned to,
+// with their approvals and latest pipeline.
+func dashboardInputs(ctx context.Context, app App, client *gitlab.Client, username string) ([]review.Input, error) {
+	requests := gitlab.MergeRequestMap{}
+	for _, role := range []string{"author_username", "reviewer_username", "assignee_username"} {
+		found, err := gitlab.GetAllPages[gitlab.MergeRequestModel](ctx, app, client, "merge_requests", kam.Map{
+			"scope": "all",
+			"state": "opened",
+			role:    username,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range found {
+			requests[m.ID] = m
+		}
+	}
+
+	var inputs []review.Input
+	for _, id := range slices.Sorted(maps.Keys(requests)) {
+		mr := requests[id]
+		approvals, err := client.GetMergeRequestApprovals(ctx, app, mr.ProjectID, mr.Iid)
+		if err != nil {
+			return nil, err
+		}
+		pipelines, err := client.GetMergeRequestPipelines(ctx, app, mr.ProjectID, mr.Iid)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, review.Input{
+			MergeRequest: mr,
+			Approvals:    approvals,
+			Pipeline:     gitlab.LatestPipeline(pipelines),
+		})
+	}
+	return inputs, nil
+}
+
+// writeDashboardJSON writes the items as an indented json array, an empty dashboard is [].
+func writeDashboardJSON(out io.Writer, items []review.Item) error {
+	if items == nil {
+		items = []review.Item{}
+	}
+	b, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(b))
+	return err
+}
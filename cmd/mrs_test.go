@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	"github.com/stalwartgiraffe/cmr/internal/review"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+func TestDashboard(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+	client := gitlab.NewClient(rc.WithBaseURL(server.URL()))
+	ctx := context.Background()
+	app := fixtures.NewApp()
+
+	me, err := client.GetCurrentUser(ctx, app)
+	require.NoError(t, err)
+	mr, err := findOrCreateMergeRequest(ctx, app, client, config.DefaultBranches(), "gitlab-org/awesome-project", "ABC-1234_fix_the_thing")
+	require.NoError(t, err)
+
+	inputs, err := dashboardInputs(ctx, app, client, me.Username)
+	require.NoError(t, err)
+	items := review.Build(me.Username, inputs, time.Now())
+	var mine *review.Item
+	for i := range items {
+		require.Equal(t, i+1, items[i].Rank)
+		if items[i].ID == mr.ID {
+			mine = &items[i]
+		}
+	}
+	require.NotNil(t, mine)
+	require.Equal(t, me.Username, mine.Author)
+	require.NotEqual(t, review.Reviewing, mine.Section)
+
+	var out bytes.Buffer
+	require.NoError(t, writeDashboardJSON(&out, items))
+	var decoded []review.Item
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded, len(items))
+
+	out.Reset()
+	require.NoError(t, writeDashboardJSON(&out, nil))
+	require.Equal(t, "[]\n", out.String())
+}
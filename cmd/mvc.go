@@ -3,11 +3,15 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
 	"github.com/stalwartgiraffe/cmr/internal/reload"
 	"github.com/stalwartgiraffe/cmr/internal/tui/merges"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
 )
 
 func NewMVCCommand(app App, cfg *CmdConfig, cancel context.CancelFunc) *cobra.Command {
@@ -31,7 +35,7 @@ func NewMVCCommand(app App, cfg *CmdConfig, cancel context.CancelFunc) *cobra.Co
 			return nil
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			runMVC(cancel, app, cmd)
+			runMVC(cancel, app, cfg, cmd)
 		},
 	}
 }
@@ -44,22 +48,44 @@ func NoArgs(args []string) error {
 	}
 }
 
-func runMVC(cancel context.CancelFunc, app App, cmd *cobra.Command) {
+func runMVC(cancel context.CancelFunc, app App, cfg *CmdConfig, cmd *cobra.Command) {
 	ctx := cmd.Context()
-	repo := merges.NewInMemoryMergesRepository()
+	home, err := os.UserHomeDir()
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	// the token is loaded when mvc first reaches gitlab, browsing the local repository needs none
+	opts, token, err := lazyGitlabOptions(cfg)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	client := gitlab.NewClient(opts...)
+	source := merges.NewClientDetailSource(app, client)
+	actions := merges.NewClientMergeActions(app, client, home, cfg.Config.Repos.Root, token)
+
+	repo := merges.NewInMemoryMergesRepository(cfg.Config.Tables)
 
 	// TODO handle in go rouine
 	if err := repo.Load(); err != nil {
-		panic(err)
+		utils.Redln(err)
+		return
 	}
-	renderer := merges.NewTuiMergesRenderer(ctx, repo)
+	loaded := repo.Layout()
+	renderer := merges.NewTuiMergesRenderer(ctx, repo, source)
 
 	controller := merges.NewMergesController(
 		repo,
 		renderer,
+		actions,
 	)
 
-	if err := controller.Run(); err != nil {
-		panic(err)
+	if err := controller.Run(ctx); err != nil {
+		utils.Redln(err)
+		return
+	}
+	if err := cfg.saveTable(config.MergeRequestsTable, loaded, repo.Layout()); err != nil {
+		utils.Redln(err)
 	}
 }
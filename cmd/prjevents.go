@@ -2,13 +2,14 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/spf13/cobra"
 
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
 	"github.com/stalwartgiraffe/cmr/internal/utils"
-	rc "github.com/stalwartgiraffe/cmr/restclient"
 )
 
 func NewPrjEventsCommand(app App, cfg *CmdConfig, cancel context.CancelFunc) *cobra.Command {
@@ -41,54 +42,60 @@ func NewPrjEventsCommand(app App, cfg *CmdConfig, cancel context.CancelFunc) *co
 
 			cmdCtx := cmd.Context()
 
-			authToken, err := loadGitlabAuthToken(cmdCtx)
+			opts, err := gitlabOptions(cmdCtx, cfg)
 			if err != nil {
 				utils.Redln(err)
 				return
 			}
 
-			ec := NewEventsClient(
-				rc.WithBaseURL("https://gitlab.indexexchange.com/"),
-				rc.WithAuthToken(authToken),
-			)
-			filepath := "ignore/my_recent_events.yaml"
-			route := "events/"
-			myEvents, err := ec.updateRecentEvents(cmdCtx, app, cancel, filepath, route)
+			ec := NewEventsClient(opts...)
+			myEvents, err := ec.updateRecentEvents(cmdCtx, app, cancel, gitlab.MyEventsFile, "events/")
 			if err != nil {
 				utils.Redln(err)
 				return
 			}
-			myProjectIDs := myEvents.ProjectIDs()
-
-			numWorkers := 200
-			pendingIDs := make(chan int, numWorkers)
-
-			var wg sync.WaitGroup
-			wg.Add(numWorkers)
-			for range numWorkers {
-				// if we capture worker, rember to alias
-				go func() {
-					defer wg.Done()
+			if err := ec.updateProjectEvents(cmdCtx, app, cancel, myEvents.ProjectIDs()); err != nil {
+				utils.Redln(err)
+			}
+			fmt.Println("done updating project_x_events")
+		},
+	}
+}
 
-					for id := range pendingIDs {
+// updateProjectEvents updates the cached events of each project.
+func (ec *EventsClient) updateProjectEvents(
+	ctx context.Context,
+	app App,
+	cancel context.CancelFunc,
+	projectIDs []int,
+) error {
+	numWorkers := min(200, len(projectIDs))
+	pendingIDs := make(chan int, numWorkers)
 
-						filepath := fmt.Sprintf("ignore/project_%d_events.yaml", id)
-						route := fmt.Sprintf("projects/%d/events", id)
-						_, err := ec.updateRecentEvents(cmdCtx, app, cancel, filepath, route)
-						if err != nil {
-							utils.Redln(err)
-							return
-						}
-					}
+	var mu sync.Mutex
+	var errs error
+	var wg sync.WaitGroup
+	wg.Add(numWorkers)
+	for range numWorkers {
+		// if we capture worker, rember to alias
+		go func() {
+			defer wg.Done()
 
-				}()
-			}
-			for _, id := range myProjectIDs {
-				pendingIDs <- id
+			for id := range pendingIDs {
+				route := fmt.Sprintf("projects/%d/events", id)
+				_, err := ec.updateRecentEvents(ctx, app, cancel, gitlab.ProjectEventsFile(id), route)
+				if err != nil {
+					mu.Lock()
+					errs = errors.Join(errs, err)
+					mu.Unlock()
+				}
 			}
-			close(pendingIDs)
-			wg.Wait()
-			fmt.Println("done updating project_x_events")
-		},
+		}()
+	}
+	for _, id := range projectIDs {
+		pendingIDs <- id
 	}
+	close(pendingIDs)
+	wg.Wait()
+	return errs
 }
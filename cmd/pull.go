@@ -1,22 +1,29 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/stalwartgiraffe/cmr/internal/elog"
+
+	"github.com/stalwartgiraffe/cmr/internal/bulk"
 	"github.com/stalwartgiraffe/cmr/internal/gitlab"
 	"github.com/stalwartgiraffe/cmr/internal/gitutil"
-	"gopkg.in/yaml.v3"
 )
 
 func NewPullCommand(cfg *CmdConfig) *cobra.Command {
-	return &cobra.Command{
+	flags := &reposFlags{}
+	pullCmd := &cobra.Command{
 		Use:   "pull",
 		Short: "pull git repos",
-		Long:  `pull well known repos using bulk commands`,
+		Long: `Pull the selected repos on a pool of workers.
+
+The repos are selected from the cached projects by the globs, groups and
+topics of the repos section of the config, or by the flags.
+A repo with uncommitted changes is left alone and reported as dirty.
+The exit code is non zero if any repo failed.`,
+		SilenceUsage: true,
 
 		Args: func(cmd *cobra.Command, args []string) error {
 			if 0 < len(args) {
@@ -25,68 +32,39 @@ func NewPullCommand(cfg *CmdConfig) *cobra.Command {
 				return nil
 			}
 		},
-		Run: func(cmd *cobra.Command, args []string) {
+		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
-			logger := elog.New()
-
-			logger.Println("so pully")
-
-			file, err := os.Open("ignore/projects.yaml")
+			inst, err := cfg.gitlabInstance()
 			if err != nil {
-				fmt.Println(err)
-				return
+				return err
 			}
-			defer file.Close()
-
-			var projects []gitlab.ProjectModel
-			err = yaml.NewDecoder(file).Decode(&projects)
+			token, err := loadGitlabAuthToken(ctx, inst)
 			if err != nil {
-				fmt.Println(err)
-				return
-			}
-
-			home, err := os.UserHomeDir()
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			elapsed := []time.Duration{}
-			// Now you can use the groups variable
-			token, err := loadGitlabAuthToken(ctx)
-			if err != nil {
-				fmt.Println(err)
-				return
-			}
-			for _, project := range projects {
-				begin := time.Now()
-				err := Pull(cfg, project, home, token)
-				end := time.Now()
-
-				elapsed = append(elapsed, end.Sub(begin))
-
-				if err != nil {
-					fmt.Println("ERROR", err)
-				}
-			}
-			for i, project := range projects {
-				fmt.Println(project.NameWithNamespace, elapsed[i])
+				return err
 			}
+			return runRepos(ctx, cfg, flags, func(ctx context.Context, project gitlab.ProjectModel, dir string) (bulk.State, error) {
+				return Pull(ctx, dir, token)
+			})
 		},
 	}
+	flags.add(pullCmd)
+	return pullCmd
 }
 
-func Pull(cfg *CmdConfig, project gitlab.ProjectModel, home string, token string) error {
-	path := gitlab.RepoFilePath(
-		home,
-		cfg.Config.Repos.Root,
-		project,
-	)
-
-	fmt.Println(path)
-	err := os.MkdirAll(path, os.ModeDir|0755)
-	if err != nil {
-		return err
+// Pull pulls the repo cloned in dir.
+func Pull(ctx context.Context, dir string, token string) (bulk.State, error) {
+	if !isCloned(dir) {
+		return bulk.Failed, fmt.Errorf("%s is not cloned, see cmr clone", dir)
+	}
+	pulled, err := gitutil.Pull(ctx, dir, token, nil)
+	switch {
+	case errors.Is(err, gitutil.ErrDirty):
+		return bulk.Dirty, err
+	case err != nil:
+		return bulk.Failed, err
+	case pulled:
+		return bulk.Updated, nil
+	default:
+		return bulk.UpToDate, nil
 	}
-
-	return gitutil.Pull(path, project.HTTPURLToRepo, token, os.Stdout)
 }
@@ -1,21 +1,36 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"os"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
 )
 
-// initCmd represents the init command
-func NewPushCommand(cfg *CmdConfig, repo *git.Repository) *cobra.Command {
+const originRemote = "origin"
+
+// NewPushCommand pushes the current branch and opens its merge request.
+func NewPushCommand(app App, cfg *CmdConfig, repo *git.Repository) *cobra.Command {
 	pushCmd := &cobra.Command{
 		Use:   "push",
-		Short: "run pushers",
-		Long: `Run project pushers.
+		Short: "push the current branch and open a merge request",
+		Long: `Push the current branch to origin and open a merge request.
 
-FIXME: detailed explanation.
-FIXME: include examples.
+The branch is pushed with the gitlab auth token and set to track origin.
+If the branch already has an open merge request its web url is printed,
+otherwise a merge request is created against the project default branch.
+The title is read from the branch name by the branches policy of the config, for example
+	ABC-1234_fix_the_thing
+has the title
+	ABC-1234 fix the thing
 `,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if 0 < len(args) {
@@ -25,11 +40,118 @@ FIXME: include examples.
 			}
 		},
 		Run: func(cmd *cobra.Command, args []string) {
-			RunPush()
+			RunPush(cmd.Context(), app, cfg, repo)
 		},
 	}
 
 	return pushCmd
 }
-func RunPush() {
+
+func RunPush(ctx context.Context, app App, cfg *CmdConfig, repo *git.Repository) {
+	ctx, span := app.StartSpan(ctx, "RunPush")
+	defer span.End()
+
+	var err error
+	if repo == nil {
+		if repo, err = gitutil.OpenCwd(); err != nil {
+			utils.Redln(err)
+			return
+		}
+	}
+	inst, err := cfg.gitlabInstance()
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	authToken, err := loadGitlabAuthToken(ctx, inst)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	client := gitlab.NewClient(
+		rc.WithBaseURL(inst.BaseURL),
+		rc.WithAPI(inst.API),
+		rc.WithAuthToken(authToken),
+	)
+	mr, err := runRepoPush(ctx, app, client, repo, cfg.Config.BranchPolicy(), authToken)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	fmt.Println(mr.WebURL)
+}
+
+// runRepoPush pushes the current branch of the repo and returns
+// the open merge request for the branch, creating it when needed.
+func runRepoPush(
+	ctx context.Context,
+	app App,
+	client *gitlab.Client,
+	repo *git.Repository,
+	branches *config.Branches,
+	authToken string,
+) (*gitlab.MergeRequestModel, error) {
+	branch, err := gitutil.BranchShortName(repo)
+	if err != nil {
+		return nil, err
+	}
+	if err = gitutil.PushBranch(repo, originRemote, branch, authToken, os.Stdout); err != nil {
+		return nil, err
+	}
+	remoteURL, err := gitutil.RemoteURL(repo, originRemote)
+	if err != nil {
+		return nil, err
+	}
+	projectPath, err := gitutil.ParseRemotePath(remoteURL)
+	if err != nil {
+		return nil, err
+	}
+	return findOrCreateMergeRequest(ctx, app, client, branches, projectPath, branch)
+}
+
+// findOrCreateMergeRequest returns the open merge request of the source branch
+// or creates one that targets the project default branch.
+func findOrCreateMergeRequest(
+	ctx context.Context,
+	app App,
+	client *gitlab.Client,
+	branches *config.Branches,
+	projectPath string,
+	branch string,
+) (*gitlab.MergeRequestModel, error) {
+	project, err := client.GetProject(ctx, app, gitlab.ProjectPathID(projectPath))
+	if err != nil {
+		return nil, err
+	}
+	mr, err := client.FindOpenMergeRequest(ctx, app, project.ID, branch)
+	if err != nil {
+		return nil, err
+	}
+	if mr != nil {
+		return mr, nil
+	}
+	if project.DefaultBranch == "" {
+		return nil, fmt.Errorf("project %s has no default branch", projectPath)
+	}
+	if project.DefaultBranch == branch {
+		return nil, fmt.Errorf("can not open a merge request from the default branch %s", branch)
+	}
+	return client.CreateMergeRequest(ctx, app, project.ID, &gitlab.CreateMergeRequestOptions{
+		SourceBranch:       branch,
+		TargetBranch:       project.DefaultBranch,
+		Title:              mergeRequestTitle(branches, branch),
+		RemoveSourceBranch: true,
+	})
+}
+
+// mergeRequestTitle formats the jira issue and description read from the branch name.
+func mergeRequestTitle(branches *config.Branches, branch string) string {
+	b := branches.Read(branch)
+	if b.Issue == "" {
+		return b.Description
+	}
+	if b.Description == "" {
+		return b.Issue
+	}
+	return b.Issue + " " + b.Description
 }
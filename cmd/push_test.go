@@ -1,5 +1,64 @@
 package cmd
 
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+func TestFindOrCreateMergeRequest(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+	client := gitlab.NewClient(rc.WithBaseURL(server.URL()))
+	ctx := context.Background()
+	app := fixtures.NewApp()
+
+	const branch = "ABC-1234_fix_the_thing"
+	created, err := findOrCreateMergeRequest(ctx, app, client, config.DefaultBranches(), "gitlab-org/awesome-project", branch)
+	require.NoError(t, err)
+	require.NotNil(t, created)
+	require.Equal(t, 30, created.ProjectID)
+	require.Equal(t, branch, created.SourceBranch)
+	require.Equal(t, "develop", created.TargetBranch)
+	require.Equal(t, "ABC-1234 fix the thing", created.Title)
+	require.Equal(t, "opened", created.State)
+	require.NotEmpty(t, created.WebURL)
+
+	found, err := findOrCreateMergeRequest(ctx, app, client, config.DefaultBranches(), "gitlab-org/awesome-project", branch)
+	require.NoError(t, err)
+	require.Equal(t, created.ID, found.ID)
+	require.Equal(t, created.WebURL, found.WebURL)
+
+	_, err = findOrCreateMergeRequest(ctx, app, client, config.DefaultBranches(), "gitlab-org/awesome-project", "develop")
+	require.Error(t, err)
+
+	_, err = findOrCreateMergeRequest(ctx, app, client, config.DefaultBranches(), "gitlab-org/missing", branch)
+	require.Error(t, err)
+}
+
+func TestMergeRequestTitle(t *testing.T) {
+	tests := []struct {
+		branch string
+		title  string
+	}{
+		{"ABC-1234_fix_the_thing", "ABC-1234 fix the thing"},
+		{"fix_the_thing", "fix the thing"},
+		{"ABC-1234", "ABC-1234"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			require.Equal(t, tt.title, mergeRequestTitle(config.DefaultBranches(), tt.branch))
+		})
+	}
+}
+
 /*
 	func  TestPush(c *C) {
 		url, clean := s.TemporalDir()
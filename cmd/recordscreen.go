@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+)
+
+// findFlags narrow the records shown.
+type findFlags struct {
+	find   string
+	ranked bool
+}
+
+func (f *findFlags) add(cmd *cobra.Command, what string) {
+	cmd.Flags().StringVar(&f.find, "find", "", "show the "+what+" that match the find query, with the matches highlighted")
+	cmd.Flags().BoolVar(&f.ranked, "ranked", false, "show the best matches of --find first")
+}
+
+// runRecordScreen shows the records until the user escapes,
+// then saves the layout of the table when the user changed it.
+func runRecordScreen[T any](cfg *CmdConfig, spec records.Spec[T], recs []T, flags *findFlags) {
+	table, err := records.NewRecordTable(spec, cfg.Config.Tables, recs)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	if err := table.Filter(flags.find); err != nil {
+		utils.Redln(err)
+		return
+	}
+	loaded := table.Layout()
+
+	tviewApp := tview.NewApplication()
+	screen := records.NewScreen(tviewApp, table, tw.NewStyle(), tw.NewFocusRing(tviewApp), tviewApp.Stop)
+	screen.SetFilter(flags.find)
+	screen.SetRanked(flags.ranked)
+	if err := screen.Run(); err != nil {
+		panic(err)
+	}
+	if err := cfg.saveTable(spec.Table, loaded, table.Layout()); err != nil {
+		utils.Redln(err)
+	}
+}
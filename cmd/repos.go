@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/stalwartgiraffe/cmr/internal/bulk"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+)
+
+// reposFlags override the repos section of the config.
+type reposFlags struct {
+	workers int
+	timeout time.Duration
+	include []string
+	exclude []string
+	groups  []string
+	topics  []string
+}
+
+func (f *reposFlags) add(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&f.workers, "workers", 0, "repos worked on at once (default is repos.workers)")
+	cmd.Flags().DurationVar(&f.timeout, "timeout", 0, "time allowed for each repo (default is repos.timeout)")
+	cmd.Flags().StringSliceVar(&f.include, "include", nil, "globs of the project paths to select (default is repos.include)")
+	cmd.Flags().StringSliceVar(&f.exclude, "exclude", nil, "globs of the project paths to leave out (default is repos.exclude)")
+	cmd.Flags().StringSliceVar(&f.groups, "group", nil, "gitlab groups to select the projects of (default is repos.groups)")
+	cmd.Flags().StringSliceVar(&f.topics, "topic", nil, "gitlab topics to select the projects of (default is repos.topics)")
+}
+
+// repos returns the config with the flags that were set applied.
+func (f *reposFlags) repos(cfg *CmdConfig) config.MyRepos {
+	var r config.MyRepos
+	if cfg.Config != nil {
+		r = cfg.Config.Repos
+	}
+	if 0 < f.workers {
+		r.Workers = f.workers
+	}
+	if 0 < f.timeout {
+		r.Timeout = f.timeout
+	}
+	// a selection on the command line replaces the configured selection
+	if 0 < len(f.include) || 0 < len(f.groups) || 0 < len(f.topics) {
+		r.Include, r.Groups, r.Topics = f.include, f.groups, f.topics
+	}
+	if 0 < len(f.exclude) {
+		r.Exclude = f.exclude
+	}
+	return r
+}
+
+// selectProjects returns the cached projects the repos select, sorted by id.
+func selectProjects(repos config.MyRepos) ([]gitlab.ProjectModel, error) {
+	if !repos.HasSelection() {
+		return nil, fmt.Errorf("no repos are selected, set repos.include, repos.groups or repos.topics in the config or use --include, --group or --topic")
+	}
+	projectMap, err := gitlab.ReadProjects()
+	if err != nil {
+		return nil, err
+	}
+	if len(projectMap) == 0 {
+		return nil, fmt.Errorf("there are no projects in the cache, run cmr lab or cmr sync first")
+	}
+	selected := []gitlab.ProjectModel{}
+	for _, project := range utils.ToSortedSlice(projectMap) {
+		if repos.Selects(project.PathWithNamespace, project.Topics) {
+			selected = append(selected, project)
+		}
+	}
+	return selected, nil
+}
+
+// repoTask works on the local repo of the project in dir.
+type repoTask func(ctx context.Context, project gitlab.ProjectModel, dir string) (bulk.State, error)
+
+// runRepos runs the task on each selected project while drawing their status.
+// An error is returned if any of them failed.
+func runRepos(ctx context.Context, cfg *CmdConfig, flags *reposFlags, task repoTask) error {
+	repos := flags.repos(cfg)
+	projects, err := selectProjects(repos)
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	tasks := make([]bulk.Task, len(projects))
+	for i, project := range projects {
+		dir := gitlab.RepoFilePath(home, repos.Root, project)
+		tasks[i] = bulk.Task{
+			Name: project.PathWithNamespace,
+			Run: func(ctx context.Context) (bulk.State, error) {
+				return task(ctx, project, dir)
+			},
+		}
+	}
+
+	fd := int(os.Stdout.Fd())
+	dashboard := bulk.NewDashboard(os.Stdout, term.IsTerminal(fd))
+	if _, height, err := term.GetSize(fd); err == nil {
+		dashboard.Height = height - 1
+	}
+	runner := &bulk.Runner{
+		Workers:  repos.Workers,
+		Timeout:  repos.Timeout,
+		OnChange: dashboard.Update,
+	}
+	statuses := runner.Run(ctx, tasks)
+
+	fmt.Println()
+	bulk.Summary(os.Stdout, statuses)
+	if n := bulk.Failures(statuses); 0 < n {
+		return fmt.Errorf("%d of %d repos failed", n, len(statuses))
+	}
+	return nil
+}
+
+// isCloned returns true if dir has a git repo.
+func isCloned(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
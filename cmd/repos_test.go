@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/bulk"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+)
+
+func TestReposFlags(t *testing.T) {
+	cfg := &CmdConfig{Config: &config.Config{Repos: config.MyRepos{
+		Workers: 8,
+		Timeout: time.Minute,
+		Include: []string{"team/**"},
+		Topics:  []string{"go"},
+		Exclude: []string{"team/old"},
+	}}}
+
+	flags := &reposFlags{}
+	require.Equal(t, cfg.Config.Repos, flags.repos(cfg))
+
+	flags = &reposFlags{workers: 2, groups: []string{"other"}}
+	repos := flags.repos(cfg)
+	require.Equal(t, 2, repos.Workers)
+	require.Equal(t, time.Minute, repos.Timeout)
+	require.Empty(t, repos.Include)
+	require.Empty(t, repos.Topics)
+	require.Equal(t, []string{"other"}, repos.Groups)
+	require.Equal(t, []string{"team/old"}, repos.Exclude)
+}
+
+func TestPullNotCloned(t *testing.T) {
+	state, err := Pull(context.Background(), t.TempDir(), "")
+	require.Equal(t, bulk.Failed, state)
+	require.ErrorContains(t, err, "is not cloned")
+}
@@ -5,14 +5,37 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"reflect"
 
 	"github.com/spf13/cobra"
 
 	"github.com/stalwartgiraffe/cmr/internal/config"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
 )
 
 type CmdConfig struct {
 	Config *config.Config
+
+	// ConfigFile is where Config was loaded from, the tables save their layout to it.
+	ConfigFile string
+
+	// GitlabName selects the gitlab instance in Config.
+	GitlabName string
+
+	// Record and Replay are the paths of the cassette the gitlab traffic is recorded to
+	// or replayed from, see rc.Cassette.
+	Record string
+	Replay string
+
+	cassette *rc.Cassette // shared by the clients of one run
+}
+
+// saveTable saves the layout of the table to the config file when the user changed it from loaded.
+func (c *CmdConfig) saveTable(name string, loaded config.TableLayout, layout config.TableLayout) error {
+	if reflect.DeepEqual(loaded, layout) {
+		return nil
+	}
+	return config.SaveTable(c.ConfigFile, name, layout)
 }
 
 func NewRootCmd(cfg *CmdConfig) *cobra.Command {
@@ -29,6 +52,7 @@ FIXME - write examples here.`,
 
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
 			var err error
+			cfg.ConfigFile = cfgFilepath
 			cfg.Config, err = config.LoadConfigFile(cfgFilepath)
 			if err != nil {
 				log.Fatalf("Could not load config %s: %s", cfgFilepath, err)
@@ -49,6 +73,10 @@ FIXME - write examples here.`,
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 	rootCmd.PersistentFlags().StringVar(&cfgFilepath, "config", "", "config file (default is $HOME/.cmr.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfg.GitlabName, "gitlab", "", "name of the gitlab instance in the config file (default is gitlab.default)")
+	rootCmd.PersistentFlags().StringVar(&cfg.Record, "record", "", "record the gitlab traffic to this cassette, with the secrets scrubbed")
+	rootCmd.PersistentFlags().StringVar(&cfg.Replay, "replay", "", "replay the gitlab traffic from this cassette instead of calling gitlab")
+	rootCmd.MarkFlagsMutuallyExclusive("record", "replay")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -81,14 +109,33 @@ func AddRootCommand(app App, cancel context.CancelFunc) *cobra.Command {
 	// fetch merge requests from gitlab
 	rootCmd.AddCommand(NewMergeRequestCommand(app, cfg, cancel))
 
+	// show the dashboard of my open merge requests, most urgent first
+	rootCmd.AddCommand(NewMRsCommand(app, cfg))
+
+	// keep the projects, events and merge requests in the cache up to date
+	rootCmd.AddCommand(NewSyncCommand(app, cfg))
+
 	rootCmd.AddCommand(NewMVCCommand(app, cfg, cancel))
 
+	// browse the cache in the tabs of one tui
+	rootCmd.AddCommand(NewUICommand(app, cfg))
+
 	rootCmd.AddCommand(NewCloneCommand(cfg))
 	rootCmd.AddCommand(NewPullCommand(cfg))
 	rootCmd.AddCommand(NewLintCommand(cfg))
 	rootCmd.AddCommand(NewGacCommand(cfg, nil))
-	rootCmd.AddCommand(NewPushCommand(cfg, nil))
+
+	// lint commit messages and install the commit-msg hook that does
+	rootCmd.AddCommand(NewCommitlintCommand(nil))
+	addHooksCommand(rootCmd)
+	rootCmd.AddCommand(NewPushCommand(app, cfg, nil))
 
 	rootCmd.AddCommand(NewSecretToolCommand(cfg))
+
+	// serve the fake gitlab for local runs of the other commands
+	rootCmd.AddCommand(NewFakeLabCommand())
+
+	// regenerate the test fixtures from a cassette recorded with --record
+	rootCmd.AddCommand(NewFixturesCommand())
 	return rootCmd
 }
@@ -1,24 +1,32 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-
-	"github.com/stalwartgiraffe/cmr/xr"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/stalwartgiraffe/cmr/internal/credentials"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
 )
 
-// initCmd represents the init command
+// NewSecretToolCommand looks up and stores the gitlab auth token.
 func NewSecretToolCommand(cfg *CmdConfig) *cobra.Command {
-	const expectedStatus int = 1
-	const secretTool = "secret-tool"
-	return &cobra.Command{
+	stCmd := &cobra.Command{
 		Use:   "st",
-		Short: "run secret-tool",
-		Long: `Run project linters.
+		Short: "look up or store the gitlab auth token",
+		Long: `Look up or store the gitlab auth token of the --gitlab instance.
 
-FIXME: detailed explanation.
-FIXME: include examples.
+The token is found with the chain of credential providers in the config,
+	gitlab.instances[].token.providers
+for example env, secret-tool, git-credential, file and netrc.
+Without a sub command the token is looked up.
+The token is never printed in full.
 `,
 		Args: func(cmd *cobra.Command, args []string) error {
 			if 0 < len(args) {
@@ -27,16 +35,133 @@ FIXME: include examples.
 				return nil
 			}
 		},
+		Run: func(cmd *cobra.Command, args []string) {
+			runLookupToken(cmd.Context(), cfg, cmd.OutOrStdout())
+		},
+	}
+	stCmd.AddCommand(newLookupTokenCommand(cfg))
+	stCmd.AddCommand(newStoreTokenCommand(cfg))
+	return stCmd
+}
 
+func newLookupTokenCommand(cfg *CmdConfig) *cobra.Command {
+	return &cobra.Command{
+		Use:   "lookup",
+		Short: "show which provider has the gitlab auth token",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if 0 < len(args) {
+				return fmt.Errorf("unexpected args %v", args)
+			} else {
+				return nil
+			}
+		},
 		Run: func(cmd *cobra.Command, args []string) {
-			ctx := cmd.Context()
-			fmt.Println(">secret-tool:")
-			stargs := []string{"lookup", "pat", "gitlab"}
-			out, err := xr.Run(ctx, secretTool, expectedStatus, nil, stargs...)
-			if err != nil {
-				fmt.Println("error:", err)
+			runLookupToken(cmd.Context(), cfg, cmd.OutOrStdout())
+		},
+	}
+}
+
+func newStoreTokenCommand(cfg *CmdConfig) *cobra.Command {
+	var providerType string
+	storeCmd := &cobra.Command{
+		Use:   "store",
+		Short: "store the gitlab auth token read from stdin",
+		Long: `Store the gitlab auth token read from stdin.
+
+The token is stored in the first provider of the chain that can store it,
+or in the provider of --provider type.
+When stdin is a terminal the token is read without echo.
+`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if 0 < len(args) {
+				return fmt.Errorf("unexpected args %v", args)
+			} else {
+				return nil
 			}
-			fmt.Println(out)
 		},
+		Run: func(cmd *cobra.Command, args []string) {
+			runStoreToken(cmd.Context(), cfg, providerType, cmd.OutOrStdout())
+		},
+	}
+	storeCmd.Flags().StringVar(&providerType, "provider", "",
+		"provider type to store in: secret-tool, git-credential or file")
+	return storeCmd
+}
+
+func tokenChain(cfg *CmdConfig) (credentials.Chain, string, error) {
+	inst, err := cfg.gitlabInstance()
+	if err != nil {
+		return nil, "", err
+	}
+	host, err := gitlabHost(inst)
+	if err != nil {
+		return nil, "", err
+	}
+	return credentials.FromConfig(inst.Token, nil), host, nil
+}
+
+func runLookupToken(ctx context.Context, cfg *CmdConfig, out io.Writer) {
+	chain, host, err := tokenChain(cfg)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	token, provider, err := chain.Get(ctx, host)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	fmt.Fprintf(out, "%s %s %s\n", host, provider.Name(), credentials.Mask(token))
+}
+
+func runStoreToken(ctx context.Context, cfg *CmdConfig, providerType string, out io.Writer) {
+	chain, host, err := tokenChain(cfg)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	if providerType != "" {
+		p := chain.Find(providerType)
+		if p == nil {
+			utils.Redln(fmt.Errorf("provider %s is not in the chain", providerType))
+			return
+		}
+		chain = credentials.Chain{p}
+	}
+	token, err := readToken(os.Stdin, out)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	provider, err := chain.Store(ctx, host, token)
+	if err != nil {
+		utils.Redln(err)
+		return
+	}
+	fmt.Fprintf(out, "stored %s in %s\n", host, provider.Name())
+}
+
+// readToken reads one line without echo from a terminal or the first line of a pipe.
+func readToken(in *os.File, prompt io.Writer) (string, error) {
+	var token string
+	if fd := int(in.Fd()); term.IsTerminal(fd) {
+		fmt.Fprint(prompt, "token: ")
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(prompt)
+		if err != nil {
+			return "", err
+		}
+		token = string(b)
+	} else {
+		line, err := bufio.NewReader(in).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		token = line
+	}
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("empty token")
 	}
+	return token, nil
 }
@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/daemon"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/store"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+const (
+	syncLockFile   = gitlab.CacheDir + "/sync.lock"
+	syncStatusFile = gitlab.CacheDir + "/sync.status.yaml"
+)
+
+// NewSyncCommand initializes the command.
+func NewSyncCommand(app App, cfg *CmdConfig) *cobra.Command {
+	d := &daemon.Daemon{
+		StatusPath: syncStatusFile,
+	}
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "keep the cache of projects, events and merge requests up to date",
+		Long: `Run Sync.
+
+Sync fetches the projects, events and merge requests that changed since the
+last run and writes them to the cache, then waits for the next run.
+Only one sync runs on a cache at a time. The outcome of the last run is kept
+in the status file, see sync status.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if 0 < len(args) {
+				return fmt.Errorf("unexpected args %v", args)
+			} else {
+				return nil
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runSyncCmd(cmd.Context(), app, cfg, d); err != nil {
+				utils.Redln(err)
+			}
+		},
+	}
+	syncCmd.Flags().DurationVar(&d.Schedule.Interval, "interval", 15*time.Minute, "time between runs")
+	syncCmd.Flags().DurationVar(&d.Schedule.Jitter, "jitter", 2*time.Minute, "random spread of the time between runs")
+	syncCmd.Flags().BoolVar(&d.Once, "once", false, "run once and exit")
+
+	syncCmd.AddCommand(NewSyncStatusCommand())
+	return syncCmd
+}
+
+// NewSyncStatusCommand initializes the command.
+func NewSyncStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "print the outcome of the last sync",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if 0 < len(args) {
+				return fmt.Errorf("unexpected args %v", args)
+			} else {
+				return nil
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			status, err := daemon.ReadStatus(syncStatusFile)
+			if err != nil {
+				utils.Redln(err)
+				return
+			}
+			printSyncStatus(status)
+		},
+	}
+}
+
+func runSyncCmd(ctx context.Context, app App, cfg *CmdConfig, d *daemon.Daemon) error {
+	ctx, span := app.StartSpan(ctx, "runSyncCmd")
+	defer span.End()
+
+	lock, err := daemon.TryLock(syncLockFile)
+	if err != nil {
+		return err
+	}
+	if !addShutdown(app, func(context.Context) error { return lock.Unlock() }) {
+		defer lock.Unlock()
+	}
+
+	opts, err := gitlabOptions(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	s := newSyncer(app, opts...)
+	d.Jobs = s.jobs()
+	d.OnJobError = func(name string, err error) {
+		utils.Redln(name, err)
+	}
+	return d.Run(ctx)
+}
+
+// addShutdown runs f with the app shutdowns, if the app has them.
+func addShutdown(app App, f func(context.Context) error) bool {
+	s, ok := app.(interface {
+		AddShutdown(func(context.Context) error)
+	})
+	if ok {
+		s.AddShutdown(f)
+	}
+	return ok
+}
+
+// syncer updates the cache with the incremental calls of the other commands.
+type syncer struct {
+	app      App
+	projects *ProjectsClient
+	events   *EventsClient
+	requests *MergeRequestClient
+}
+
+func newSyncer(app App, opts ...rc.Option) *syncer {
+	return &syncer{
+		app:      app,
+		projects: NewProjectsClient(opts...),
+		events:   NewEventsClient(opts...),
+		requests: NewMergeRequestClient(opts...),
+	}
+}
+
+func (s *syncer) jobs() []daemon.Job {
+	return []daemon.Job{
+		{Name: "projects", Run: s.syncProjects},
+		{Name: "merge_requests", Run: s.syncMergeRequests},
+		{Name: "events", Run: s.syncEvents},
+		{Name: "project_events", Run: s.syncProjectEvents},
+	}
+}
+
+// syncProjects fetches the projects active since the latest activity in the cache.
+func (s *syncer) syncProjects(ctx context.Context) error {
+	cache, err := gitlab.OpenCache(ctx, gitlab.CacheDir)
+	if err != nil {
+		return err
+	}
+	watermark, err := cache.Watermark(ctx, store.Projects, "")
+	if err != nil {
+		return err
+	}
+	const startPage = 1
+	query := *gitlab.NewPageQuery("projects/", startPage)
+	if !watermark.IsZero() {
+		query.Params["last_activity_after"] = watermark.Format(time.RFC3339)
+	}
+
+	projects := make(map[int]gitlab.ProjectModel)
+	// a partial result would move the watermark past the pages that failed
+	if err := s.projects.gatherProjects(ctx, s.app, nil, query, projects); err != nil {
+		return err
+	}
+	return cache.PutProjects(ctx, utils.ToSortedSlice(projects))
+}
+
+func (s *syncer) syncMergeRequests(ctx context.Context) error {
+	_, err := s.requests.updateRecentMergeRequest(ctx, s.app, nil, gitlab.MyMergeRequestsFile, "merge_requests/")
+	return err
+}
+
+func (s *syncer) syncEvents(ctx context.Context) error {
+	_, err := s.events.updateRecentEvents(ctx, s.app, nil, gitlab.MyEventsFile, "events/")
+	return err
+}
+
+// syncProjectEvents updates the events of the projects the user was active in.
+func (s *syncer) syncProjectEvents(ctx context.Context) error {
+	myEvents, err := gitlab.NewEventMapFromYaml(ctx, s.app, gitlab.MyEventsFile)
+	if err != nil {
+		return err
+	}
+	return s.events.updateProjectEvents(ctx, s.app, nil, myEvents.ProjectIDs())
+}
+
+func printSyncStatus(status *daemon.Status) {
+	alive := ""
+	if status.State == daemon.Running || status.State == daemon.Waiting {
+		if process, err := os.FindProcess(status.PID); err == nil && process.Signal(syscall.Signal(0)) != nil {
+			alive = " (process is gone)"
+		}
+	}
+	fmt.Printf("state      %s%s\n", status.State, alive)
+	fmt.Printf("pid        %d\n", status.PID)
+	fmt.Printf("runs       %d\n", status.Runs)
+	fmt.Printf("last start %s\n", formatStatusTime(status.LastStart))
+	fmt.Printf("last end   %s\n", formatStatusTime(status.LastEnd))
+	if !status.NextRun.IsZero() {
+		fmt.Printf("next run   %s\n", formatStatusTime(status.NextRun))
+	}
+	for _, name := range slices.Sorted(maps.Keys(status.Jobs)) {
+		job := status.Jobs[name]
+		if job.LastError == "" {
+			fmt.Printf("%-15s ok   %s in %s\n", name, formatStatusTime(job.LastSuccess), job.Elapsed.Round(time.Millisecond))
+		} else {
+			utils.Redln(fmt.Sprintf("%-15s fail %s %s", name, formatStatusTime(job.LastStart), job.LastError))
+		}
+	}
+}
+
+func formatStatusTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format(time.DateTime)
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	cmrapp "github.com/stalwartgiraffe/cmr/internal/app"
+	"github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/daemon"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+func TestSyncOnce(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	defer os.Chdir(wd)
+	require.NoError(t, os.Mkdir(gitlab.CacheDir, 0755))
+
+	ctx := context.Background()
+	app := fixtures.NewApp()
+	lock, err := daemon.TryLock(syncLockFile)
+	require.NoError(t, err)
+	_, err = daemon.TryLock(syncLockFile)
+	require.ErrorIs(t, err, daemon.ErrLocked)
+	require.NoError(t, lock.Unlock())
+
+	unlock := func(context.Context) error { return nil }
+	require.False(t, addShutdown(app, unlock))
+	require.True(t, addShutdown(cmrapp.NewAppErr().App, unlock))
+
+	s := newSyncer(app, rc.WithBaseURL(server.URL()))
+	d := &daemon.Daemon{
+		StatusPath: syncStatusFile,
+		Once:       true,
+		Jobs:       s.jobs(),
+	}
+	require.NoError(t, d.Run(ctx))
+
+	status, err := daemon.ReadStatus(syncStatusFile)
+	require.NoError(t, err)
+	require.Equal(t, daemon.Finished, status.State)
+	for name, job := range status.Jobs {
+		require.Empty(t, job.LastError, name)
+	}
+	require.Len(t, status.Jobs, 4)
+
+	cache, err := gitlab.OpenCache(ctx, gitlab.CacheDir)
+	require.NoError(t, err)
+	defer cache.Close()
+	projects, err := cache.Projects(ctx)
+	require.NoError(t, err)
+	require.Len(t, projects, 3)
+	requests, err := gitlab.NewMergeRequestMapFromYaml(gitlab.MyMergeRequestsFile)
+	require.NoError(t, err)
+	require.NotEmpty(t, requests)
+	events, err := gitlab.NewEventMapFromYaml(ctx, app, gitlab.MyEventsFile)
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+}
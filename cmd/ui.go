@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/rivo/tview"
+	"github.com/spf13/cobra"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/daemon"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+	"github.com/stalwartgiraffe/cmr/internal/review"
+	"github.com/stalwartgiraffe/cmr/internal/tui/merges"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
+	"github.com/stalwartgiraffe/cmr/internal/tui/shell"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+)
+
+// syncStatusInterval is how often the ui reads the sync status.
+const syncStatusInterval = 5 * time.Second
+
+// NewUICommand initializes the command.
+func NewUICommand(app App, cfg *CmdConfig) *cobra.Command {
+	var username string
+	uiCmd := &cobra.Command{
+		Use:   "ui",
+		Short: "browse the merge requests, activity, projects, groups, pipelines and local repos in tabs",
+		Long: `Run UI.
+
+The ui shows my merge requests, the merge requests to review, the dashboard
+of my open merge requests, my activity, the projects and the local repos
+from the cache in tabs of one screen. Enter on one of my merge requests shows
+its details, the keys of its status bar approve, comment and so on, see mvc.
+The groups tab shows the groups from gitlab and the pipelines tab the latest
+pipeline of each open merge request, they fill in while the ui is shown.
+A merge request whose pipelines can not be fetched is left out, the failures
+are printed when the ui quits. The dashboard of the cache does not know the
+approvals and pipelines, see mrs for the dashboard from gitlab.
+The keys 1-9 switch tabs, : or Ctrl-P opens the command palette.
+The status bar shows the state of the last sync, see sync status.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if 0 < len(args) {
+				return fmt.Errorf("unexpected args %v", args)
+			} else {
+				return nil
+			}
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runUICmd(cmd.Context(), app, cfg, username); err != nil {
+				utils.Redln(err)
+			}
+		},
+	}
+	uiCmd.Flags().StringVar(&username, "user", "", "gitlab username of my merge requests and reviews (default is the username of the gitlab instance)")
+	return uiCmd
+}
+
+func runUICmd(ctx context.Context, app App, cfg *CmdConfig, username string) error {
+	ctx, span := app.StartSpan(ctx, "runUICmd")
+	defer span.End()
+
+	if username == "" {
+		if inst, err := cfg.gitlabInstance(); err == nil {
+			username = inst.Username
+		}
+	}
+	projects, err := gitlab.ReadProjects()
+	if err != nil {
+		return err
+	}
+	requests, err := gitlab.NewMergeRequestMapFromYaml(gitlab.MyMergeRequestsFile)
+	if err != nil {
+		return err
+	}
+	events, err := gitlab.NewEventMapFromYaml(ctx, app, gitlab.MyEventsFile)
+	if err != nil {
+		return err
+	}
+	repos, err := localRepos(cfg, projects)
+	if err != nil {
+		return err
+	}
+
+	// the fetches from gitlab stop when the ui quits
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	tviewApp := tview.NewApplication()
+	ui := shell.NewShell(tviewApp)
+	lookups := records.Lookups{Projects: projects}
+	mergeRequests := slices.Collect(maps.Values(requests))
+	reviews := records.MergeRequestSpec(lookups)
+	reviews.Table = config.ReviewsTable
+
+	var saves []func() error
+	addTab := func(save func() error, err error) {
+		if err != nil {
+			utils.Redln(err)
+			return
+		}
+		saves = append(saves, save)
+	}
+	// the failures of the tabs loaded in the background, appended on the ui goroutine
+	var failures []error
+	failed := func(err error) {
+		failures = append(failures, err)
+	}
+
+	// the token is loaded when the ui first reaches gitlab
+	opts, token, gitlabErr := lazyGitlabOptions(cfg)
+	var client *gitlab.Client
+	if gitlabErr != nil {
+		utils.Redln(gitlabErr)
+		addTab(addUITab(ui, cfg, "my MRs", records.MergeRequestSpec(lookups), myMergeRequests(mergeRequests, username)))
+	} else {
+		client = gitlab.NewClient(opts...)
+		addTab(addMergesUITab(ctx, app, tviewApp, ui, cfg, client, token, records.MergeRequestSpec(lookups), myMergeRequests(mergeRequests, username)))
+	}
+	addTab(addUITab(ui, cfg, "to review", reviews, toReview(mergeRequests, username)))
+	addTab(addUITab(ui, cfg, "dashboard", records.DashboardSpec(lookups, config.DashboardTable), cachedDashboard(mergeRequests, username)))
+	addTab(addUITab(ui, cfg, "activity", records.EventSpec(lookups), slices.Collect(maps.Values(events))))
+	addTab(addUITab(ui, cfg, "projects", records.ProjectSpec(lookups), slices.Collect(maps.Values(projects))))
+	if client != nil {
+		addTab(loadUITab(tviewApp, ui, cfg, "groups", records.GroupSpec(), failed, func() ([]gitlab.GroupModel, error) {
+			return client.GetGroups(ctx, app)
+		}))
+		addTab(loadUITab(tviewApp, ui, cfg, "pipelines", records.PipelineSpec(lookups), failed, func() ([]gitlab.PipelineModel, error) {
+			return latestPipelines(ctx, app, client, mergeRequests)
+		}))
+	}
+	addTab(addUITab(ui, cfg, "local repos", records.LocalRepoSpec(), repos))
+
+	ui.AddCommand(shell.Command{Name: "clear the filter", Run: func() { ui.Screen().SetFilter("") }})
+	ui.AddCommand(shell.Command{Name: "quit", Run: tviewApp.Stop})
+
+	ui.WatchSync(ctx, func() (*daemon.Status, error) {
+		return daemon.ReadStatus(syncStatusFile)
+	}, syncStatusInterval)
+
+	if err := ui.Run(); err != nil {
+		return err
+	}
+	for _, err := range failures {
+		utils.Redln(err)
+	}
+	for _, save := range saves {
+		if err := save(); err != nil {
+			utils.Redln(err)
+		}
+	}
+	return nil
+}
+
+// addUITab adds a tab of the records to the ui,
+// save saves the layout of its table when the user changed it.
+func addUITab[T any](ui *shell.Shell, cfg *CmdConfig, name string, spec records.Spec[T], recs []T) (save func() error, err error) {
+	table, save, err := newUITable(cfg, spec, recs)
+	if err != nil {
+		return nil, err
+	}
+	ui.NewScreen(name, table)
+	return save, nil
+}
+
+// loadUITab adds a tab that is empty until load returns its records.
+// load runs off the ui goroutine, the records it returns fill the tab even when it fails,
+// and failed gets its error on the ui goroutine.
+func loadUITab[T any](
+	tviewApp *tview.Application,
+	ui *shell.Shell,
+	cfg *CmdConfig,
+	name string,
+	spec records.Spec[T],
+	failed func(error),
+	load func() ([]T, error),
+) (save func() error, err error) {
+	table, save, err := newUITable(cfg, spec, nil)
+	if err != nil {
+		return nil, err
+	}
+	ui.NewScreen(name, table)
+	go func() {
+		recs, err := load()
+		tviewApp.QueueUpdateDraw(func() {
+			if err != nil {
+				failed(err)
+			}
+			table.SetRecords(recs)
+		})
+	}()
+	return save, nil
+}
+
+// addMergesUITab adds the tab of my merge requests that shows the details
+// of a merge request and carries out the actions on it, see mvc.
+func addMergesUITab(
+	ctx context.Context,
+	app App,
+	tviewApp *tview.Application,
+	ui *shell.Shell,
+	cfg *CmdConfig,
+	client *gitlab.Client,
+	token func(context.Context) (string, error),
+	spec records.Spec[gitlab.MergeRequestModel],
+	recs []gitlab.MergeRequestModel,
+) (save func() error, err error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	table, save, err := newUITable(cfg, spec, recs)
+	if err != nil {
+		return nil, err
+	}
+	repo := merges.NewLoadedMergesRepository(table)
+	source := merges.NewClientDetailSource(app, client)
+	actions := merges.NewClientMergeActions(app, client, home, cfg.Config.Repos.Root, token)
+	ui.NewPage("my MRs", repo, func(screen *records.Screen) shell.Page {
+		renderer := merges.NewTuiMergesPage(ctx, tviewApp, screen, source, ui.Style())
+		merges.NewMergesController(repo, renderer, actions).Listen(ctx)
+		return renderer
+	})
+	return save, nil
+}
+
+// newUITable lays out the records as configured,
+// save saves the layout of the table when the user changed it.
+func newUITable[T any](cfg *CmdConfig, spec records.Spec[T], recs []T) (table *records.RecordTable[T], save func() error, err error) {
+	table, err = records.NewRecordTable(spec, cfg.Config.Tables, recs)
+	if err != nil {
+		return nil, nil, err
+	}
+	loaded := table.Layout()
+	return table, func() error {
+		return cfg.saveTable(spec.Table, loaded, table.Layout())
+	}, nil
+}
+
+// myMergeRequests returns the merge requests the user authored, all of them when the user is not known.
+func myMergeRequests(requests []gitlab.MergeRequestModel, username string) []gitlab.MergeRequestModel {
+	if username == "" {
+		return requests
+	}
+	return slices.DeleteFunc(slices.Clone(requests), func(m gitlab.MergeRequestModel) bool {
+		return m.Author == nil || m.Author.Username != username
+	})
+}
+
+// toReview returns the open merge requests the user is a reviewer of.
+func toReview(requests []gitlab.MergeRequestModel, username string) []gitlab.MergeRequestModel {
+	return slices.DeleteFunc(slices.Clone(requests), func(m gitlab.MergeRequestModel) bool {
+		isReviewer := slices.ContainsFunc(m.Reviewers, func(u gitlab.UserModel) bool {
+			return u.Username == username
+		})
+		return username == "" || m.State != "opened" || !isReviewer
+	})
+}
+
+// cachedDashboard returns the dashboard of the cached merge requests, their state is derived from the merge status alone.
+func cachedDashboard(requests []gitlab.MergeRequestModel, username string) []review.Item {
+	inputs := make([]review.Input, 0, len(requests))
+	for _, m := range requests {
+		inputs = append(inputs, review.Input{MergeRequest: m})
+	}
+	return review.Build(username, inputs, time.Now())
+}
+
+// latestPipelines returns the latest pipeline of each open merge request that has one.
+// The merge requests whose pipelines can not be fetched are skipped, their errors are joined.
+func latestPipelines(ctx context.Context, app App, client *gitlab.Client, requests []gitlab.MergeRequestModel) ([]gitlab.PipelineModel, error) {
+	var pipelines []gitlab.PipelineModel
+	var errs error
+	for _, m := range requests {
+		if m.State != "opened" {
+			continue
+		}
+		mrPipelines, err := client.GetMergeRequestPipelines(ctx, app, m.ProjectID, m.Iid)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+		if latest := gitlab.LatestPipeline(mrPipelines); latest != nil {
+			pipelines = append(pipelines, *latest)
+		}
+	}
+	return pipelines, errs
+}
+
+// localRepos returns the projects that are cloned under the repos root, see clone.
+func localRepos(cfg *CmdConfig, projects map[int]gitlab.ProjectModel) ([]records.LocalRepo, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	var root string
+	if cfg.Config != nil {
+		root = cfg.Config.Repos.Root
+	}
+
+	var repos []records.LocalRepo
+	for _, project := range projects {
+		dir := gitlab.RepoFilePath(home, root, project)
+		if !isCloned(dir) {
+			continue
+		}
+		repo := records.LocalRepo{ID: project.ID, Path: project.PathWithNamespace, Dir: dir}
+		if r, err := git.PlainOpen(dir); err == nil {
+			repo.Branch, _ = gitutil.BranchShortName(r)
+		}
+		repos = append(repos, repo)
+	}
+	return repos, nil
+}
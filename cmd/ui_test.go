@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+func TestUIMergeRequests(t *testing.T) {
+	annie := gitlab.UserModel{Username: "annie"}
+	bob := gitlab.UserModel{Username: "bob"}
+	requests := []gitlab.MergeRequestModel{
+		{ID: 1, State: "opened", Author: &annie, Reviewers: []gitlab.UserModel{bob}},
+		{ID: 2, State: "opened", Author: &bob, Reviewers: []gitlab.UserModel{annie}},
+		{ID: 3, State: "merged", Author: &bob, Reviewers: []gitlab.UserModel{annie}},
+		{ID: 4, State: "opened"},
+	}
+	ids := func(requests []gitlab.MergeRequestModel) []int {
+		var ids []int
+		for _, m := range requests {
+			ids = append(ids, m.ID)
+		}
+		return ids
+	}
+
+	require.Equal(t, []int{1}, ids(myMergeRequests(requests, "annie")))
+	require.Equal(t, []int{1, 2, 3, 4}, ids(myMergeRequests(requests, "")))
+	require.Equal(t, []int{2}, ids(toReview(requests, "annie")))
+	require.Empty(t, toReview(requests, ""))
+	require.Len(t, requests, 4)
+
+	var dashboard []int
+	for _, item := range cachedDashboard(requests, "annie") {
+		dashboard = append(dashboard, item.ID)
+	}
+	require.ElementsMatch(t, []int{1, 2}, dashboard)
+}
+
+func TestLocalRepos(t *testing.T) {
+	root := t.TempDir()
+	cfg := &CmdConfig{Config: &config.Config{Repos: config.MyRepos{Root: root}}}
+	projects := map[int]gitlab.ProjectModel{
+		1: {ID: 1, PathWithNamespace: "tools/cmr"},
+		2: {ID: 2, PathWithNamespace: "tools/lint"},
+	}
+	dir := filepath.Join(root, "repos", "tools/cmr")
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o755))
+
+	repos, err := localRepos(cfg, projects)
+	require.NoError(t, err)
+	require.Equal(t, []records.LocalRepo{{ID: 1, Path: "tools/cmr", Dir: dir}}, repos)
+}
+
+func TestLatestPipelines(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+	client := gitlab.NewClient(rc.WithBaseURL(server.URL()))
+	ctx := context.Background()
+	app := fixtures.NewApp()
+
+	mr, err := findOrCreateMergeRequest(ctx, app, client, config.DefaultBranches(), "gitlab-org/awesome-project", "ABC-1234_fix_the_thing")
+	require.NoError(t, err)
+	merged := *mr
+	merged.State = "merged"
+
+	missing := *mr
+	missing.Iid = 9999
+
+	// the merge request whose pipelines can not be fetched is skipped
+	pipelines, err := latestPipelines(ctx, app, client, []gitlab.MergeRequestModel{missing, *mr, merged})
+	require.Error(t, err)
+	require.Len(t, pipelines, 1)
+	require.Equal(t, mr.ProjectID, pipelines[0].ProjectID)
+
+	table, err := records.NewRecordTable(records.PipelineSpec(records.Lookups{}), nil, pipelines)
+	require.NoError(t, err)
+	require.NotNil(t, table)
+}
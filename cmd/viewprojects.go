@@ -3,14 +3,19 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"maps"
+	"slices"
 
 	"github.com/spf13/cobra"
 	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
 	"github.com/stalwartgiraffe/cmr/internal/utils"
 )
 
 func NewViewProjectsCommand(cfg *CmdConfig, cancel context.CancelFunc) *cobra.Command {
-	return &cobra.Command{
+	flags := &findFlags{}
+	var table bool
+	viewCmd := &cobra.Command{
 		Use:   "viewprojects",
 		Short: "view projects",
 		Long:  `View local projects.`,
@@ -28,7 +33,15 @@ func NewViewProjectsCommand(cfg *CmdConfig, cancel context.CancelFunc) *cobra.Co
 				return
 			}
 
-			fmt.Println("number of projects ", len(projects))
+			if !table {
+				fmt.Println("number of projects ", len(projects))
+				return
+			}
+			spec := records.ProjectSpec(records.Lookups{})
+			runRecordScreen(cfg, spec, slices.Collect(maps.Values(projects)), flags)
 		},
 	}
+	viewCmd.Flags().BoolVar(&table, "table", false, "browse the projects in a table")
+	flags.add(viewCmd, "projects")
+	return viewCmd
 }
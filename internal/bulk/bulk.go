@@ -0,0 +1,149 @@
+// Package bulk runs a task per repo on a bounded pool of workers.
+package bulk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State is where the task of a repo is at.
+type State string
+
+const (
+	Queued   State = "queued"
+	Fetching State = "fetching"
+	UpToDate State = "up-to-date"
+	Updated  State = "updated"
+	Cloned   State = "cloned"
+	Exists   State = "exists"
+	Dirty    State = "dirty"
+	Failed   State = "failed"
+	Canceled State = "canceled"
+)
+
+// Done returns true if the task will not change state again.
+func (s State) Done() bool {
+	return s != Queued && s != Fetching
+}
+
+// Ok returns true if the state does not fail the run.
+func (s State) Ok() bool {
+	return s != Failed && s != Canceled
+}
+
+// Task is the work on one repo.
+// Run returns the state the repo was left in.
+type Task struct {
+	Name string
+	Run  func(ctx context.Context) (State, error)
+}
+
+// Status is the progress of one task.
+type Status struct {
+	Name    string
+	State   State
+	Err     error
+	Elapsed time.Duration
+}
+
+// Runner runs the tasks on Workers goroutines, each with a Timeout.
+type Runner struct {
+	Workers int
+	Timeout time.Duration
+
+	// OnChange is called with the index of the task whenever its status changes.
+	// The calls are serialized.
+	OnChange func(i int, s Status)
+}
+
+// Run returns the final status of each task in the order of the tasks.
+// The tasks that did not start before ctx was done are Canceled.
+func (r *Runner) Run(ctx context.Context, tasks []Task) []Status {
+	var mu sync.Mutex
+	statuses := make([]Status, len(tasks))
+	set := func(i int, s Status) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses[i] = s
+		if r.OnChange != nil {
+			r.OnChange(i, s)
+		}
+	}
+	for i, t := range tasks {
+		set(i, Status{Name: t.Name, State: Queued})
+	}
+
+	workers := max(1, min(r.Workers, len(tasks)))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				set(i, r.runTask(ctx, tasks[i], func(s Status) { set(i, s) }))
+			}
+		}()
+	}
+
+	cancelFrom := func(i int) {
+		for j := i; j < len(tasks); j++ {
+			set(j, Status{Name: tasks[j].Name, State: Canceled, Err: ctx.Err()})
+		}
+	}
+feed:
+	for i := range tasks {
+		if ctx.Err() != nil {
+			cancelFrom(i)
+			break
+		}
+		select {
+		case next <- i:
+		case <-ctx.Done():
+			cancelFrom(i)
+			break feed
+		}
+	}
+	close(next)
+	wg.Wait()
+	return statuses
+}
+
+func (r *Runner) runTask(ctx context.Context, t Task, set func(Status)) Status {
+	begin := time.Now()
+	set(Status{Name: t.Name, State: Fetching})
+
+	taskCtx := ctx
+	if 0 < r.Timeout {
+		var cancel context.CancelFunc
+		taskCtx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+	state, err := t.Run(taskCtx)
+	s := Status{Name: t.Name, State: state, Err: err, Elapsed: time.Since(begin)}
+	switch {
+	case err == nil:
+	case errors.Is(taskCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+		s.State = Failed
+		s.Err = fmt.Errorf("timed out after %s: %w", r.Timeout, err)
+	case ctx.Err() != nil:
+		s.State = Canceled
+	case state.Ok() && state != Dirty:
+		s.State = Failed
+	}
+	return s
+}
+
+// Failures returns the count of the tasks that failed or were canceled.
+func Failures(statuses []Status) int {
+	n := 0
+	for _, s := range statuses {
+		if !s.State.Ok() {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,129 @@
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestBulk(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "bulk_test")
+}
+
+var _ = Describe("Runner", func() {
+	It("bounds the tasks running at once", func() {
+		var running, most atomic.Int32
+		tasks := []Task{}
+		for i := range 6 {
+			tasks = append(tasks, Task{
+				Name: fmt.Sprint("repo", i),
+				Run: func(ctx context.Context) (State, error) {
+					n := running.Add(1)
+					defer running.Add(-1)
+					for {
+						m := most.Load()
+						if n <= m || most.CompareAndSwap(m, n) {
+							break
+						}
+					}
+					time.Sleep(20 * time.Millisecond)
+					return Updated, nil
+				},
+			})
+		}
+		changes := 0
+		r := &Runner{Workers: 2, OnChange: func(int, Status) { changes++ }}
+		statuses := r.Run(context.Background(), tasks)
+
+		Expect(most.Load()).To(BeNumerically("<=", 2))
+		Expect(statuses).To(HaveLen(6))
+		for i, s := range statuses {
+			Expect(s.Name).To(Equal(fmt.Sprint("repo", i)))
+			Expect(s.State).To(Equal(Updated))
+		}
+		// queued, fetching and done for each
+		Expect(changes).To(Equal(18))
+		Expect(Failures(statuses)).To(BeZero())
+	})
+
+	It("fails the tasks that time out or err", func() {
+		tasks := []Task{
+			{Name: "slow", Run: func(ctx context.Context) (State, error) {
+				<-ctx.Done()
+				return Failed, ctx.Err()
+			}},
+			{Name: "broken", Run: func(ctx context.Context) (State, error) {
+				return UpToDate, errors.New("boom")
+			}},
+			{Name: "dirty", Run: func(ctx context.Context) (State, error) {
+				return Dirty, errors.New("worktree has uncommitted changes")
+			}},
+		}
+		r := &Runner{Workers: 3, Timeout: 10 * time.Millisecond}
+		statuses := r.Run(context.Background(), tasks)
+
+		Expect(statuses[0].State).To(Equal(Failed))
+		Expect(statuses[0].Err.Error()).To(ContainSubstring("timed out after 10ms"))
+		Expect(statuses[1].State).To(Equal(Failed))
+		Expect(statuses[2].State).To(Equal(Dirty))
+		Expect(Failures(statuses)).To(Equal(2))
+	})
+
+	It("cancels the tasks not started", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ran := false
+		tasks := []Task{{Name: "never", Run: func(ctx context.Context) (State, error) {
+			ran = true
+			return Updated, nil
+		}}}
+		statuses := (&Runner{Workers: 1}).Run(ctx, tasks)
+		Expect(ran).To(BeFalse())
+		Expect(statuses[0].State).To(Equal(Canceled))
+	})
+})
+
+var _ = Describe("Dashboard", func() {
+	It("writes a line per task done", func() {
+		var buf bytes.Buffer
+		d := NewDashboard(&buf, false)
+		d.Update(0, Status{Name: "a", State: Queued})
+		d.Update(0, Status{Name: "a", State: Fetching})
+		Expect(buf.String()).To(BeEmpty())
+		d.Update(0, Status{Name: "a", State: UpToDate, Elapsed: time.Second})
+		Expect(buf.String()).To(ContainSubstring("up-to-date"))
+		Expect(buf.String()).To(ContainSubstring("1s"))
+	})
+
+	It("redraws a live table in place", func() {
+		var buf bytes.Buffer
+		d := NewDashboard(&buf, true)
+		d.Update(0, Status{Name: "a", State: Queued})
+		d.Update(1, Status{Name: "b", State: Queued})
+		buf.Reset()
+		d.Update(1, Status{Name: "b", State: Fetching})
+		Expect(buf.String()).To(HavePrefix("\033[3A"))
+		Expect(buf.String()).To(ContainSubstring("2 repos: 1 queued, 1 fetching"))
+	})
+
+	It("summarizes", func() {
+		var buf bytes.Buffer
+		Summary(&buf, []Status{
+			{Name: "a", State: Updated},
+			{Name: "b", State: Failed, Err: errors.New("boom\nstack")},
+			{Name: "c", State: Dirty},
+		})
+		Expect(buf.String()).To(ContainSubstring("3 repos: 1 updated, 1 dirty, 1 failed"))
+		Expect(buf.String()).To(ContainSubstring("boom"))
+		Expect(buf.String()).ToNot(ContainSubstring("stack"))
+		Expect(buf.String()).ToNot(ContainSubstring(" a "))
+	})
+})
@@ -0,0 +1,135 @@
+package bulk
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/TwiN/go-color"
+)
+
+// stateOrder is the order of the states in the summary.
+var stateOrder = []State{Queued, Fetching, Cloned, Updated, UpToDate, Exists, Dirty, Failed, Canceled}
+
+// Dashboard draws the status of every task.
+// A live dashboard redraws the table in place, for a terminal.
+// Otherwise a line is written as each task is done.
+type Dashboard struct {
+	w    io.Writer
+	live bool
+
+	// Height bounds the lines of a live table, zero is unbounded.
+	// Over the bound only the busy, dirty and failed tasks are drawn.
+	Height int
+
+	statuses []Status
+	drawn    int
+}
+
+func NewDashboard(w io.Writer, live bool) *Dashboard {
+	return &Dashboard{
+		w:    w,
+		live: live,
+	}
+}
+
+// Update is a Runner.OnChange that draws the changed status.
+func (d *Dashboard) Update(i int, s Status) {
+	for len(d.statuses) <= i {
+		d.statuses = append(d.statuses, Status{})
+	}
+	d.statuses[i] = s
+	if d.live {
+		d.redraw()
+	} else if s.State.Done() {
+		fmt.Fprintln(d.w, formatStatus(s))
+	}
+}
+
+func (d *Dashboard) redraw() {
+	lines := []string{formatCounts(d.statuses)}
+	bounded := 0 < d.Height && d.Height < len(d.statuses)+1
+	for _, s := range d.statuses {
+		if bounded && (s.State == Queued || (s.State.Ok() && s.State.Done() && s.State != Dirty)) {
+			continue
+		}
+		lines = append(lines, formatStatus(s))
+	}
+	if bounded && d.Height < len(lines) {
+		lines = lines[:d.Height]
+	}
+
+	var sb strings.Builder
+	if 0 < d.drawn {
+		// back to the top of the previous table
+		fmt.Fprintf(&sb, "\033[%dA", d.drawn)
+	}
+	for _, line := range lines {
+		sb.WriteString("\033[2K")
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	// clear what is left of a longer previous table
+	for i := len(lines); i < d.drawn; i++ {
+		sb.WriteString("\033[2K\n")
+	}
+	if len(lines) < d.drawn {
+		fmt.Fprintf(&sb, "\033[%dA", d.drawn-len(lines))
+	}
+	d.drawn = len(lines)
+	fmt.Fprint(d.w, sb.String())
+}
+
+// Summary writes the count of each state then each task that is not ok.
+func Summary(w io.Writer, statuses []Status) {
+	fmt.Fprintln(w, formatCounts(statuses))
+	for _, s := range statuses {
+		if !s.State.Ok() || s.State == Dirty {
+			fmt.Fprintln(w, formatStatus(s))
+		}
+	}
+}
+
+func formatCounts(statuses []Status) string {
+	counts := map[State]int{}
+	for _, s := range statuses {
+		counts[s.State]++
+	}
+	parts := []string{}
+	for _, state := range stateOrder {
+		if n := counts[state]; 0 < n {
+			parts = append(parts, fmt.Sprintf("%d %s", n, state))
+		}
+	}
+	return fmt.Sprintf("%d repos: %s", len(statuses), strings.Join(parts, ", "))
+}
+
+func formatStatus(s Status) string {
+	line := fmt.Sprintf("%s %-50s", colorState(s.State), s.Name)
+	if s.State.Done() {
+		line += fmt.Sprintf(" %8s", s.Elapsed.Round(10*time.Millisecond))
+	}
+	if s.Err != nil {
+		// the stack of the error does not fit the table
+		first, _, _ := strings.Cut(s.Err.Error(), "\n")
+		line += " " + first
+	}
+	return line
+}
+
+func colorState(state State) string {
+	padded := fmt.Sprintf("%-10s", state)
+	switch state {
+	case Cloned, Updated, UpToDate, Exists:
+		return color.Colorize(color.Green, padded)
+	case Fetching:
+		return color.Colorize(color.Cyan, padded)
+	case Dirty:
+		return color.Colorize(color.Yellow, padded)
+	case Failed, Canceled:
+		return color.Colorize(color.Red, padded)
+	default:
+		return color.Colorize(color.Gray, padded)
+	}
+}
@@ -0,0 +1,171 @@
+package columns
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aarondl/opt/omitnull"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+)
+
+func TestFieldValue(t *testing.T) {
+	created := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	mr := gitlab.MergeRequestModel{
+		ID:                   7,
+		Title:                "fix the bug",
+		Author:               &gitlab.UserModel{Username: "annie"},
+		References:           &gitlab.ReferencesModel{Full: "group/project!12"},
+		CreatedAt:            gitlab.Time{Time: created},
+		ApprovalsBeforeMerge: omitnull.From(2),
+	}
+
+	tests := []struct {
+		path     string
+		expected any
+	}{
+		{path: "id", expected: int64(7)},
+		{path: "title", expected: "fix the bug"},
+		{path: "author.username", expected: "annie"},
+		{path: "references.full", expected: "group/project!12"},
+		{path: "created_at", expected: created},
+		{path: "approvals_before_merge", expected: int64(2)},
+		{path: "merged_by.username", expected: nil},
+		{path: "closed_at", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			f, err := NewField(reflect.TypeFor[gitlab.MergeRequestModel](), tt.path)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, f.Value(&mr))
+		})
+	}
+}
+
+func TestFieldErrors(t *testing.T) {
+	for _, path := range []string{"nope", "title.size", "author.nope", "created_at.year"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := NewField(reflect.TypeFor[gitlab.MergeRequestModel](), path)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     any
+		expected int
+	}{
+		{name: "numbers by value", a: int64(9), b: int64(10), expected: -1},
+		{name: "text ignores case", a: "Bob", b: "annie", expected: 1},
+		{name: "times", a: time.Unix(2, 0), b: time.Unix(1, 0), expected: 1},
+		{name: "false first", a: false, b: true, expected: -1},
+		{name: "nil first", a: nil, b: "", expected: -1},
+		{name: "equal", a: "x", b: "x", expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, Compare(tt.a, tt.b))
+		})
+	}
+}
+
+// record is a row of the layout tests.
+type record struct {
+	ID     int    `json:"id"`
+	Author string `json:"author"`
+	Title  string `json:"title"`
+}
+
+func newTestTable(t *testing.T, sort ...string) *Table[record] {
+	t.Helper()
+	layout, err := NewLayout(config.TableLayout{
+		Columns: []config.Column{{Field: "id", Title: "ID"}, {Field: "author"}, {Field: "shout"}},
+		Sort:    sort,
+	}, map[string]ValueFunc[record]{
+		"shout": func(r *record) any { return r.Title + "!" },
+	})
+	require.NoError(t, err)
+	return NewTable(layout, []record{
+		{ID: 1, Author: "bob", Title: "b"},
+		{ID: 2, Author: "annie", Title: "a"},
+		{ID: 3, Author: "bob", Title: "c"},
+	})
+}
+
+func ids(table *Table[record]) []int {
+	ids := []int{}
+	for _, r := range table.Records() {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
+
+func TestTableSortBy(t *testing.T) {
+	table := newTestTable(t, "-id")
+	require.Equal(t, []int{3, 2, 1}, ids(table))
+	require.Equal(t, "ID ▼", table.GetHeader(0))
+	require.Equal(t, "shout", table.GetColumn(2))
+	require.Equal(t, "b!", table.GetCell(2, 2))
+
+	table.SortBy(0, false)
+	require.Equal(t, []int{1, 2, 3}, ids(table))
+	require.Equal(t, "ID ▲", table.GetHeader(0))
+
+	table.SortBy(1, false)
+	require.Equal(t, []int{2, 1, 3}, ids(table))
+	require.Equal(t, "ID", table.GetHeader(0))
+
+	table.SortBy(0, true)
+	table.SortBy(0, true)
+	require.Equal(t, []int{2, 3, 1}, ids(table))
+	require.Equal(t, "author ▲1", table.GetHeader(1))
+	require.Equal(t, "ID ▼2", table.GetHeader(0))
+	require.Equal(t, []string{"author", "-id"}, table.Layout().Sort)
+
+	table.SortBy(0, true)
+	require.Equal(t, []string{"author"}, table.Layout().Sort)
+}
+
+func TestTableResizeAndHide(t *testing.T) {
+	table := newTestTable(t)
+	require.Equal(t, 0, table.GetColumnWidth(1))
+	table.Resize(1, 2)
+	require.Equal(t, len("author")+2, table.GetColumnWidth(1)) // the title is the widest
+	table.Resize(1, -100)
+	require.Equal(t, 1, table.GetColumnWidth(1))
+
+	table.Hide(0)
+	table.Hide(0)
+	table.Hide(0)
+	require.Equal(t, 1, table.GetColumnCount())
+	require.Equal(t, "shout", table.GetColumn(0))
+
+	table.ShowAll()
+	require.Equal(t, 3, table.GetColumnCount())
+	require.Equal(t, config.Column{Field: "author", Title: "author", Width: 1}, table.Layout().Columns[1])
+}
+
+func TestNewLayoutErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout config.TableLayout
+	}{
+		{name: "unknown column", layout: config.TableLayout{Columns: []config.Column{{Field: "nope"}}}},
+		{name: "unknown sort key", layout: config.TableLayout{Columns: []config.Column{{Field: "id"}}, Sort: []string{"-nope"}}},
+		{name: "all hidden", layout: config.TableLayout{Columns: []config.Column{{Field: "id", Hidden: true}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLayout[record](tt.layout, nil)
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Package columns lays out the columns of the tables of records in the tui.
+// A column reads a field of the record by the json names of its path,
+// such as author.username, see config.Tables.
+package columns
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// TimeFormat formats the time fields.
+var TimeFormat = time.Stamp
+
+var timeType = reflect.TypeFor[time.Time]()
+var stringerType = reflect.TypeFor[fmt.Stringer]()
+
+// Field reads the value at a path of json names out of a record.
+type Field struct {
+	path  string
+	index [][]int
+}
+
+// NewField resolves the path in the records of type t.
+func NewField(t reflect.Type, path string) (*Field, error) {
+	f := &Field{path: path}
+	for _, name := range strings.Split(path, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || isTime(t) {
+			return nil, fmt.Errorf("field %s: %s has no fields", path, t)
+		}
+		sf, ok := jsonField(t, name)
+		if !ok {
+			return nil, fmt.Errorf("field %s: %s has no field %s", path, t, name)
+		}
+		f.index = append(f.index, sf.Index)
+		t = sf.Type
+	}
+	return f, nil
+}
+
+// jsonField finds the field by its json name, or by its name ignoring case when it has none.
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() || sf.Anonymous {
+			continue
+		}
+		tag, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+		if tag == name || tag == "" && strings.EqualFold(sf.Name, name) {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// Value returns the value of the field in the record, nil when it is missing.
+// Numbers are int64 or float64 and times are time.Time.
+func (f *Field) Value(record any) any {
+	v := reflect.ValueOf(record)
+	for _, index := range f.index {
+		for v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return nil
+			}
+			v = v.Elem()
+		}
+		var err error
+		if v, err = v.FieldByIndexErr(index); err != nil {
+			return nil
+		}
+	}
+	return leaf(v)
+}
+
+// leaf returns the value as a plain go value.
+func leaf(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if t, ok := asTime(v); ok {
+		return t
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil
+		}
+		if v.Type().Implements(stringerType) {
+			return v.Interface().(fmt.Stringer).String()
+		}
+		return leaf(v.Elem())
+	}
+	// the optional values of omitnull
+	if get := v.MethodByName("Get"); get.IsValid() && get.Type().NumIn() == 0 && get.Type().NumOut() == 2 {
+		out := get.Call(nil)
+		if !out[1].Bool() {
+			return nil
+		}
+		return leaf(out[0])
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.String:
+		return v.String()
+	case reflect.Slice, reflect.Array:
+		items := make([]string, v.Len())
+		for i := range v.Len() {
+			items[i] = Text(leaf(v.Index(i)))
+		}
+		return strings.Join(items, ", ")
+	}
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// asTime is true for a time or a struct that embeds one first, such as gitlab.Time.
+func asTime(v reflect.Value) (time.Time, bool) {
+	if !isTime(v.Type()) {
+		return time.Time{}, false
+	}
+	if v.Type() != timeType {
+		v = v.Field(0)
+	}
+	return v.Interface().(time.Time), true
+}
+
+func isTime(t reflect.Type) bool {
+	if t == timeType {
+		return true
+	}
+	return t.Kind() == reflect.Struct && 0 < t.NumField() &&
+		t.Field(0).Anonymous && t.Field(0).Type == timeType
+}
+
+// Text formats a value of a field.
+func Text(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format(TimeFormat)
+	}
+	return fmt.Sprint(value)
+}
+
+// Compare orders the values of a field, nil first.
+// Numbers and times compare by value and text ignores case.
+func Compare(a, b any) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch x := a.(type) {
+	case int64:
+		if y, ok := b.(int64); ok {
+			return cmp.Compare(x, y)
+		}
+	case float64:
+		if y, ok := b.(float64); ok {
+			return cmp.Compare(x, y)
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return x.Compare(y)
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return cmp.Compare(boolInt(x), boolInt(y))
+		}
+	}
+	ta, tb := Text(a), Text(b)
+	if c := cmp.Compare(strings.ToLower(ta), strings.ToLower(tb)); c != 0 {
+		return c
+	}
+	return cmp.Compare(ta, tb)
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
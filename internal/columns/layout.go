@@ -0,0 +1,218 @@
+package columns
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+)
+
+// ValueFunc reads the value of a column from a record.
+type ValueFunc[T any] func(record *T) any
+
+// Column is one column of a layout.
+type Column[T any] struct {
+	config.Column
+	value ValueFunc[T]
+}
+
+// Layout is the columns of a table of T and how its records sort.
+type Layout[T any] struct {
+	columns []Column[T]
+	sort    []string
+	named   map[string]ValueFunc[T]
+}
+
+// NewLayout resolves the fields of the columns and sort keys of the config.
+// A named column, such as a join to the project name, is used before a field of that name.
+func NewLayout[T any](cfg config.TableLayout, named map[string]ValueFunc[T]) (*Layout[T], error) {
+	l := &Layout[T]{named: named}
+	for _, c := range cfg.Columns {
+		value, err := l.valueFunc(c.Field)
+		if err != nil {
+			return nil, err
+		}
+		if c.Title == "" {
+			c.Title = c.Field
+		}
+		l.columns = append(l.columns, Column[T]{Column: c, value: value})
+	}
+	for _, key := range cfg.Sort {
+		field, _ := config.SortKey(key)
+		if _, err := l.valueFunc(field); err != nil {
+			return nil, err
+		}
+	}
+	l.sort = slices.Clone(cfg.Sort)
+	if len(l.visible()) == 0 {
+		return nil, fmt.Errorf("table has no visible columns")
+	}
+	return l, nil
+}
+
+func (l *Layout[T]) valueFunc(field string) (ValueFunc[T], error) {
+	if value, ok := l.named[field]; ok {
+		return value, nil
+	}
+	f, err := NewField(reflect.TypeFor[T](), field)
+	if err != nil {
+		return nil, err
+	}
+	return func(record *T) any { return f.Value(record) }, nil
+}
+
+// Config returns the layout as it is now, to be saved.
+func (l *Layout[T]) Config() config.TableLayout {
+	cfg := config.TableLayout{Sort: slices.Clone(l.sort)}
+	for _, c := range l.columns {
+		cfg.Columns = append(cfg.Columns, c.Column)
+	}
+	return cfg
+}
+
+// visible returns the indexes of the columns that are not hidden.
+func (l *Layout[T]) visible() []int {
+	cols := []int{}
+	for i, c := range l.columns {
+		if !c.Hidden {
+			cols = append(cols, i)
+		}
+	}
+	return cols
+}
+
+// column returns the visible column col.
+func (l *Layout[T]) column(col int) *Column[T] {
+	return &l.columns[l.visible()[col]]
+}
+
+// ColumnCount returns the count of visible columns.
+func (l *Layout[T]) ColumnCount() int {
+	return len(l.visible())
+}
+
+// Title returns the title of the visible column.
+func (l *Layout[T]) Title(col int) string {
+	return l.column(col).Title
+}
+
+// Header returns the title with an arrow when the rows sort by the column,
+// and the rank of its sort key when there are several.
+func (l *Layout[T]) Header(col int) string {
+	c := l.column(col)
+	for i, key := range l.sort {
+		field, desc := config.SortKey(key)
+		if field != c.Field {
+			continue
+		}
+		arrow := " ▲"
+		if desc {
+			arrow = " ▼"
+		}
+		if 1 < len(l.sort) {
+			arrow += strconv.Itoa(i + 1)
+		}
+		return c.Title + arrow
+	}
+	return c.Title
+}
+
+// Width returns the width of the visible column, 0 fits the contents.
+func (l *Layout[T]) Width(col int) int {
+	return l.column(col).Width
+}
+
+// Text returns the text of the visible column of the record.
+func (l *Layout[T]) Text(col int, record *T) string {
+	return Text(l.column(col).value(record))
+}
+
+// Sort sorts the records by the sort keys, the ties keep their order.
+func (l *Layout[T]) Sort(records []T) {
+	if len(l.sort) == 0 {
+		return
+	}
+	type key struct {
+		value ValueFunc[T]
+		desc  bool
+	}
+	keys := make([]key, 0, len(l.sort))
+	for _, k := range l.sort {
+		field, desc := config.SortKey(k)
+		value, err := l.valueFunc(field)
+		if err != nil {
+			continue // checked by NewLayout
+		}
+		keys = append(keys, key{value: value, desc: desc})
+	}
+	slices.SortStableFunc(records, func(a, b T) int {
+		for _, k := range keys {
+			c := Compare(k.value(&a), k.value(&b))
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// SortBy sorts by the visible column, or flips its direction when the rows already sort by it alone.
+// With then the column is added as the last sort key instead, flipped when it is one,
+// and dropped when it was flipped already.
+func (l *Layout[T]) SortBy(col int, then bool) {
+	field := l.column(col).Field
+	i := slices.IndexFunc(l.sort, func(key string) bool {
+		f, _ := config.SortKey(key)
+		return f == field
+	})
+	switch {
+	case !then && len(l.sort) == 1 && i == 0:
+		l.sort[0] = flip(l.sort[0])
+	case !then:
+		l.sort = []string{field}
+	case i < 0:
+		l.sort = append(l.sort, field)
+	case l.sort[i] == field:
+		l.sort[i] = flip(field)
+	default:
+		l.sort = slices.Delete(l.sort, i, i+1)
+	}
+}
+
+func flip(key string) string {
+	if field, desc := config.SortKey(key); desc {
+		return field
+	}
+	return "-" + key
+}
+
+// Resize changes the width of the visible column by delta from its width, or from fit when it fits the contents.
+func (l *Layout[T]) Resize(col int, delta int, fit int) {
+	c := l.column(col)
+	width := c.Width
+	if width == 0 {
+		width = fit
+	}
+	c.Width = max(1, width+delta)
+}
+
+// Hide hides the visible column, the last visible column stays.
+func (l *Layout[T]) Hide(col int) bool {
+	if l.ColumnCount() < 2 {
+		return false
+	}
+	l.column(col).Hidden = true
+	return true
+}
+
+// ShowAll shows the hidden columns.
+func (l *Layout[T]) ShowAll() {
+	for i := range l.columns {
+		l.columns[i].Hidden = false
+	}
+}
@@ -0,0 +1,91 @@
+package columns
+
+import (
+	"unicode/utf8"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+)
+
+// Table is the records laid out in the columns of a layout, a row per record.
+// It keeps the records sorted as the user sorts the columns.
+type Table[T any] struct {
+	layout  *Layout[T]
+	records []T
+}
+
+// NewTable sorts the records by the layout.
+func NewTable[T any](layout *Layout[T], records []T) *Table[T] {
+	layout.Sort(records)
+	return &Table[T]{
+		layout:  layout,
+		records: records,
+	}
+}
+
+func (t *Table[T]) GetColumnCount() int {
+	return t.layout.ColumnCount()
+}
+
+// GetColumn returns the title of the column, the find key of the column.
+func (t *Table[T]) GetColumn(col int) string {
+	return t.layout.Title(col)
+}
+
+// GetHeader returns the title of the column with how the rows sort by it.
+func (t *Table[T]) GetHeader(col int) string {
+	return t.layout.Header(col)
+}
+
+// GetColumnWidth returns the width of the column, 0 fits the contents.
+func (t *Table[T]) GetColumnWidth(col int) int {
+	return t.layout.Width(col)
+}
+
+func (t *Table[T]) GetRowCount() int {
+	return len(t.records)
+}
+
+func (t *Table[T]) GetCell(row int, col int) string {
+	return t.layout.Text(col, &t.records[row])
+}
+
+// Records returns the records in the order of the rows.
+func (t *Table[T]) Records() []T {
+	return t.records
+}
+
+// SetRecords replaces the records and sorts them by the layout.
+func (t *Table[T]) SetRecords(records []T) {
+	t.layout.Sort(records)
+	t.records = records
+}
+
+// SortBy sorts the rows by the column, see Layout.SortBy.
+func (t *Table[T]) SortBy(col int, then bool) {
+	t.layout.SortBy(col, then)
+	t.layout.Sort(t.records)
+}
+
+// Resize changes the width of the column by delta.
+func (t *Table[T]) Resize(col int, delta int) {
+	fit := utf8.RuneCountInString(t.layout.Title(col))
+	for i := range t.records {
+		fit = max(fit, utf8.RuneCountInString(t.layout.Text(col, &t.records[i])))
+	}
+	t.layout.Resize(col, delta, fit)
+}
+
+// Hide hides the column unless it is the last one shown.
+func (t *Table[T]) Hide(col int) {
+	t.layout.Hide(col)
+}
+
+// ShowAll shows the hidden columns.
+func (t *Table[T]) ShowAll() {
+	t.layout.ShowAll()
+}
+
+// Layout returns the layout as the user left it, to be saved.
+func (t *Table[T]) Layout() config.TableLayout {
+	return t.layout.Config()
+}
@@ -0,0 +1,93 @@
+package commitlint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Types are the types of the conventional commits.
+var Types = []string{
+	"chore", "build", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
+
+// IssuePattern matches a jira issue.
+const IssuePattern = `^([A-Z]{1,5})(-)([0-9]{1,5})$`
+
+// var partialIssueRE = regexp.MustCompile(`^([A-Z]{1,5})?(-)?([0-9]{1,5})?$`)
+var jiraIssueRE = regexp.MustCompile(IssuePattern)
+
+/*
+// these are the default case rules for conventional commitlint
+'sentence-case', // Sentence case
+  'start-case'.    // Start Case
+  'pascal-case',   // PascalCase
+  'upper-case',    // UPPERCASE
+*/
+
+// Common punctuation: Periods, commas, semicolons, colons, hyphens, question marks, exclamation marks, parentheses, brackets
+// Some special characters: Underscores, ampersands, at signs, plus signs, equal signs, asterisks, percent signs, slashes (forward and backward)
+// var specialCh = `\.\,\;\:\-\?\!\(\)\_\&\@\+\=\*\%\\\/`
+var specialCh = `\:`
+var digitSpecialCh = `0-9` + specialCh
+var lowerCh = `a-z` + digitSpecialCh
+var upperCh = `A-Z` + digitSpecialCh
+
+// 'sentence-case', // Sentence case
+var sentenceCaseTxt = `^([A-Z]?[` + lowerCh + `]*)(\s+[` + lowerCh + `]+)*$`
+var sentenceCaseRE = regexp.MustCompile(sentenceCaseTxt)
+
+var startWord = `[A-Z][` + lowerCh + `]*`
+var digitSpecialWord = `[` + digitSpecialCh + `]+`
+
+var startOrDigitSpecialWord = startWord + `|` + digitSpecialWord
+
+// 'start-case'.    // Start Case
+var startTxt = `^(` + startOrDigitSpecialWord + `)(\s+(` + startOrDigitSpecialWord + `))*$`
+var startRe = regexp.MustCompile(startTxt)
+
+// 'pascal-case',   // PascalCase
+// are space allowed?
+var pascalWord = `[A-Z][` + lowerCh + `]+`
+var pascalTxt = `^(` + pascalWord + `)+$`
+var pascalRe = regexp.MustCompile(pascalTxt)
+
+var upperCaseTxt = `^([` + upperCh + `]+)(\s+[` + upperCh + `]+)*$`
+var upperRe = regexp.MustCompile(upperCaseTxt)
+
+var camelRe = regexp.MustCompile(`^[a-z][a-zA-Z0-9]*$`)
+var kebabRe = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+var snakeRe = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// cases are the case names of commitlint.
+var cases = map[string]func(string) bool{
+	"lower-case":    func(s string) bool { return s == strings.ToLower(s) },
+	"upper-case":    upperRe.MatchString,
+	"sentence-case": sentenceCaseRE.MatchString,
+	"start-case":    startRe.MatchString,
+	"pascal-case":   pascalRe.MatchString,
+	"camel-case":    camelRe.MatchString,
+	"kebab-case":    kebabRe.MatchString,
+	"snake-case":    snakeRe.MatchString,
+}
+
+// SubjectCases are the cases a subject may have.
+var SubjectCases = []string{"sentence-case", "start-case", "pascal-case", "upper-case"}
+
+// IsCase is whether the text has one of the cases, unknown case names never match.
+func IsCase(txt string, names ...string) bool {
+	for _, name := range names {
+		if isCase, ok := cases[name]; ok && isCase(txt) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSubject is whether the text has one of the SubjectCases.
+func IsSubject(txt string) bool {
+	return IsCase(txt, SubjectCases...)
+}
+
+// IsIssue is whether the text is a jira issue.
+func IsIssue(txt string) bool {
+	return jiraIssueRE.MatchString(txt)
+}
@@ -1,4 +1,4 @@
-package prompts
+package commitlint
 
 import (
 	. "github.com/onsi/ginkgo/v2"
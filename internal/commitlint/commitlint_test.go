@@ -0,0 +1,133 @@
+package commitlint
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+)
+
+func TestCommitlint(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "commitlint")
+}
+
+var _ = Describe("parse", func() {
+	It("splits the header, body and footer", func() {
+		m := Parse(`feat(cmd)!: Add the mrs command [DEALS-1234]
+
+List the merge requests.
+
+Refs: DEALS-1200
+Co-authored-by: Annie Mouse <annie@home.org>
+`)
+		Expect(m.Type).To(Equal("feat"))
+		Expect(m.Scope).To(Equal("cmd"))
+		Expect(m.Breaking).To(BeTrue())
+		Expect(m.Subject).To(Equal("Add the mrs command"))
+		Expect(m.Issue).To(Equal("DEALS-1234"))
+		Expect(m.Body).To(Equal("List the merge requests."))
+		Expect(m.Footer).To(Equal("Refs: DEALS-1200\nCo-authored-by: Annie Mouse <annie@home.org>"))
+	})
+
+	It("finds the breaking change of the footer", func() {
+		m := Parse("fix: Drop v1 [DEALS-1]\n\nBREAKING CHANGE: v1 is gone")
+		Expect(m.Breaking).To(BeTrue())
+		Expect(m.Body).To(BeEmpty())
+	})
+
+	It("strips the comments that git adds", func() {
+		Expect(Clean("fix: Tidy [X-1]\n# Please enter the commit message\n\n# ------------------------ >8 ------------------------\ndiff\n")).
+			To(Equal("fix: Tidy [X-1]\n"))
+	})
+})
+
+var _ = Describe("lint", func() {
+	rules := DefaultRules()
+
+	rulesOf := func(problems []Problem) []string {
+		var names []string
+		for _, p := range problems {
+			names = append(names, p.Rule)
+		}
+		return names
+	}
+
+	DescribeTable("the default rules",
+		func(msg string, want ...string) {
+			Expect(rulesOf(rules.Lint(msg))).To(ConsistOf(want))
+		},
+		Entry(nil, "fix: Handle empty pages [DEALS-1234]"),
+		Entry(nil, "fix(gitlab): Handle empty pages [DEALS-1234]\n\nThe last page has no items."),
+		Entry(nil, "fixed: Handle empty pages [DEALS-1234]", "type-enum"),
+		Entry(nil, "Handle empty pages", "issue-empty", "subject-empty", "type-empty"),
+		Entry(nil, "fix(GitLab): Handle empty pages [DEALS-1234]", "scope-case"),
+		Entry(nil, "fix: handle Empty pages [DEALS-1234]", "subject-case"),
+		Entry(nil, "fix: Handle empty pages [deals-1234]", "issue-pattern"),
+		Entry(nil, "fix: Handle empty pages [DEALS-1234]\nThe last page.", "body-leading-blank"),
+	)
+
+	It("words the problem as commitlint does", func() {
+		problems := rules.Lint("fixed: Handle empty pages [DEALS-1234]")
+		Expect(problems[0].String()).To(Equal(
+			"✖   type must be one of [chore, build, ci, docs, feat, fix, perf, refactor, revert, style, test] [type-enum]"))
+		Expect(HasErrors(problems)).To(BeTrue())
+		Expect(HasErrors(rules.Lint("fix: Handle empty pages [DEALS-1234]\nThe last page."))).To(BeFalse())
+	})
+
+	It("ignores merges", func() {
+		Expect(DefaultConfig().Lint("Merge branch 'main' into DEALS-1234_big_deal")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("config", func() {
+	It("overrides the default rules", func() {
+		fs := memfs.New()
+		Expect(util.WriteFile(fs, ".commitlintrc.yml", []byte(`extends: ['@commitlint/config-conventional']
+rules:
+  type-enum: [2, always, [feat, fix, wip]]
+  subject-case: [1, always, lower-case]
+  issue-empty: [0]
+defaultIgnores: false
+`), 0o644)).To(Succeed())
+		cfg, err := LoadConfig(fs)
+		Expect(err).To(Succeed())
+		Expect(cfg.DefaultIgnores).To(BeFalse())
+		Expect(cfg.Rules["header-max-length"]).To(Equal(DefaultRules()["header-max-length"]))
+
+		problems := cfg.Lint("wip: Try it")
+		Expect(problems).To(HaveLen(1))
+		Expect(problems[0].String()).To(Equal("⚠   subject must be lower-case [subject-case]"))
+	})
+
+	It("skips the rules it can not check", func() {
+		fs := memfs.New()
+		Expect(util.WriteFile(fs, ".commitlintrc.json", []byte(`{"rules": {
+  "footer-leading-blank": [1, "always"],
+  "body-case": [2, "always", "lower-case"],
+  "type-enum": [2, "always", ["feat", "fix"]]
+}}`), 0o644)).To(Succeed())
+		cfg, err := LoadConfig(fs)
+		Expect(err).To(Succeed())
+		Expect(cfg.Skipped).To(Equal([]string{"body-case", "footer-leading-blank"}))
+		Expect(cfg.Rules).ToNot(HaveKey("body-case"))
+		Expect(cfg.Lint("feat: Add it [DEALS-1234]")).To(BeEmpty())
+	})
+
+	DescribeTable("rejects the rules it can not check",
+		func(rules string) {
+			fs := memfs.New()
+			Expect(util.WriteFile(fs, ".commitlintrc.json", []byte(`{"rules": {`+rules+`}}`), 0o644)).To(Succeed())
+			_, err := LoadConfig(fs)
+			Expect(err).ToNot(Succeed())
+		},
+		Entry(nil, `"type-enum": [3, "always", ["feat"]]`),
+		Entry(nil, `"type-enum": [2, "sometimes", ["feat"]]`),
+		Entry(nil, `"header-max-length": [2, "always", "long"]`),
+		Entry(nil, `"subject-case": [2, "always", "title-case"]`),
+		Entry(nil, `"issue-pattern": [2, "always", "("]`),
+	)
+})
@@ -0,0 +1,177 @@
+package commitlint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// ConfigFiles are the commitlint config files that are read, the first found wins.
+// JSON is read as YAML, the js configs of commitlint are not supported.
+var ConfigFiles = []string{
+	".commitlintrc",
+	".commitlintrc.json",
+	".commitlintrc.yaml",
+	".commitlintrc.yml",
+}
+
+// Config is the linting of the commit messages.
+type Config struct {
+	Rules Rules
+
+	// DefaultIgnores skips the messages that git writes, see IsIgnored.
+	DefaultIgnores bool
+
+	// Skipped are the rules of the file that can not be checked, such as footer-leading-blank.
+	Skipped []string
+}
+
+// DefaultConfig lints by the DefaultRules.
+func DefaultConfig() *Config {
+	return &Config{Rules: DefaultRules(), DefaultIgnores: true}
+}
+
+// fileConfig is the part of a commitlint config that is read, extends is ignored.
+type fileConfig struct {
+	Rules          map[string][]any `yaml:"rules"`
+	DefaultIgnores *bool            `yaml:"defaultIgnores"`
+}
+
+// LoadConfig reads the config file in the root of the fs over the DefaultConfig,
+// the rules of the file override the default rules of the same name.
+// The rules that can not be checked are left in Skipped rather than failing the load.
+func LoadConfig(fs billy.Filesystem) (*Config, error) {
+	cfg := DefaultConfig()
+	for _, name := range ConfigFiles {
+		b, err := util.ReadFile(fs, name)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, withstack.Errorf("%w", err)
+		}
+		var file fileConfig
+		if err := yaml.Unmarshal(b, &file); err != nil {
+			return nil, withstack.Errorf("Could not read %s: %w", name, err)
+		}
+		for ruleName, values := range file.Rules {
+			if _, ok := checks[ruleName]; !ok {
+				cfg.Skipped = append(cfg.Skipped, ruleName)
+				continue
+			}
+			rule, err := parseRule(ruleName, values)
+			if err != nil {
+				return nil, withstack.Errorf("%s: %w", name, err)
+			}
+			cfg.Rules[ruleName] = rule
+		}
+		slices.Sort(cfg.Skipped)
+		if file.DefaultIgnores != nil {
+			cfg.DefaultIgnores = *file.DefaultIgnores
+		}
+		break
+	}
+	return cfg, nil
+}
+
+// parseRule parses [level, "always" | "never", value], a disabled rule may leave out the rest.
+func parseRule(name string, values []any) (Rule, error) {
+	c, ok := checks[name]
+	if !ok {
+		return Rule{}, fmt.Errorf("unknown rule %s", name)
+	}
+	if len(values) < 1 {
+		return Rule{}, fmt.Errorf("rule %s has no level", name)
+	}
+	level, ok := values[0].(int)
+	if !ok || level < int(Disabled) || int(Error) < level {
+		return Rule{}, fmt.Errorf("rule %s has the level %v, not 0, 1 or 2", name, values[0])
+	}
+	rule := Rule{Level: Level(level), Always: true}
+	if rule.Level == Disabled {
+		return rule, nil
+	}
+	if 1 < len(values) {
+		switch values[1] {
+		case "always":
+		case "never":
+			rule.Always = false
+		default:
+			return Rule{}, fmt.Errorf("rule %s has %v, not always or never", name, values[1])
+		}
+	}
+
+	var value any
+	if 2 < len(values) {
+		value = values[2]
+	}
+	var err error
+	if rule.Value, err = parseValue(c.kind, value); err != nil {
+		return Rule{}, fmt.Errorf("rule %s: %w", name, err)
+	}
+	if name == "issue-pattern" {
+		if _, err := regexp.Compile(rule.Value.(string)); err != nil {
+			return Rule{}, fmt.Errorf("rule %s: %w", name, err)
+		}
+	}
+	if strings.HasSuffix(name, "-case") {
+		for _, caseName := range rule.Value.([]string) {
+			if _, ok := cases[caseName]; !ok {
+				return Rule{}, fmt.Errorf("rule %s has the unknown case %s", name, caseName)
+			}
+		}
+	}
+	return rule, nil
+}
+
+func parseValue(kind valueKind, value any) (any, error) {
+	switch kind {
+	case listValue:
+		// a case rule may name one case
+		if s, ok := value.(string); ok {
+			return []string{s}, nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("the value %v is not a list", value)
+		}
+		list := make([]string, 0, len(items))
+		for _, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("the value %v is not a list of strings", value)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	case intValue:
+		n, ok := value.(int)
+		if !ok {
+			return nil, fmt.Errorf("the value %v is not a number", value)
+		}
+		return n, nil
+	case stringValue:
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("the value %v is not a string", value)
+		}
+		return s, nil
+	default:
+		return nil, nil
+	}
+}
+
+// Lint returns the problems of the message, none for an ignored message.
+func (c *Config) Lint(msg string) []Problem {
+	if c.DefaultIgnores && IsIgnored(msg) {
+		return nil
+	}
+	return c.Rules.Lint(msg)
+}
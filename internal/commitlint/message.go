@@ -0,0 +1,91 @@
+// Package commitlint checks commit messages against the conventional commit rules
+// of commitlint, with the jira issue of the header as this repo writes it.
+//
+//	type(scope)!: subject [ISSUE]
+//
+//	body
+//
+//	BREAKING CHANGE: what breaks
+//	Refs: ISSUE-2
+package commitlint
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Message is a parsed commit message.
+type Message struct {
+	Header   string
+	Type     string
+	Scope    string
+	Breaking bool // the ! of the header or a BREAKING CHANGE footer
+	Subject  string
+	Issue    string // without the brackets
+	Body     string
+	Footer   string
+
+	// bodyLeadingBlank is whether a blank line separates the header from what follows it
+	bodyLeadingBlank bool
+}
+
+var headerRE = regexp.MustCompile(`^(\w*)(?:\(([^()]*)\))?(!)?: (.*)$`)
+
+var issueSuffixRE = regexp.MustCompile(`^(.*?)\s*\[([^\[\]]*)\]$`)
+
+var footerRE = regexp.MustCompile(`^(BREAKING CHANGE|BREAKING-CHANGE|[\w-]+)(: | #)`)
+
+// Parse splits the message into its header, body and footer. The footer starts at the
+// first token: line after a blank line. A header that is not conventional keeps empty parts.
+func Parse(msg string) Message {
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	m := Message{Header: lines[0]}
+	if parts := headerRE.FindStringSubmatch(m.Header); parts != nil {
+		m.Type, m.Scope, m.Breaking, m.Subject = parts[1], parts[2], parts[3] == "!", parts[4]
+		if issue := issueSuffixRE.FindStringSubmatch(m.Subject); issue != nil {
+			m.Subject, m.Issue = issue[1], issue[2]
+		}
+	}
+
+	rest := lines[1:]
+	m.bodyLeadingBlank = len(rest) == 0 || rest[0] == ""
+	footerAt := len(rest)
+	for i := 1; i < len(rest); i++ {
+		if rest[i-1] == "" && footerRE.MatchString(rest[i]) {
+			footerAt = i
+			break
+		}
+	}
+	m.Body = strings.Trim(strings.Join(rest[:footerAt], "\n"), "\n")
+	m.Footer = strings.Trim(strings.Join(rest[footerAt:], "\n"), "\n")
+	for _, line := range rest[footerAt:] {
+		if strings.HasPrefix(line, "BREAKING CHANGE: ") || strings.HasPrefix(line, "BREAKING-CHANGE: ") {
+			m.Breaking = true
+		}
+	}
+	return m
+}
+
+// scissors ends the message that git commit --verbose edits, the diff follows it.
+const scissors = "# ------------------------ >8 ------------------------"
+
+// Clean strips the comments of the message that git commit edits, as its default cleanup does.
+func Clean(msg string) string {
+	var lines []string
+	for _, line := range strings.Split(msg, "\n") {
+		if line == scissors {
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n") + "\n"
+}
+
+var ignoredRE = regexp.MustCompile(`^((Merge|Revert|Reapply) |(fixup|squash|amend)! |Automatic merge|Auto-merged .* into )`)
+
+// IsIgnored is whether the message is written by git, a merge or revert, which commitlint ignores by default.
+func IsIgnored(msg string) bool {
+	return ignoredRE.MatchString(msg)
+}
@@ -0,0 +1,205 @@
+package commitlint
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Level is the severity of a rule as commitlint numbers it.
+type Level int
+
+const (
+	Disabled Level = 0
+	Warning  Level = 1
+	Error    Level = 2
+)
+
+func (l Level) String() string {
+	switch l {
+	case Warning:
+		return "warning"
+	case Error:
+		return "error"
+	default:
+		return "disabled"
+	}
+}
+
+// Rule is a configured rule, commitlint writes it [level, "always" | "never", value].
+type Rule struct {
+	Level  Level
+	Always bool
+	Value  any // []string, int, string or nil by the kind of the rule
+}
+
+// Rules are the configured rules by name.
+type Rules map[string]Rule
+
+// valueKind is the kind of value a rule takes.
+type valueKind int
+
+const (
+	noValue valueKind = iota
+	listValue
+	intValue
+	stringValue
+)
+
+// check is a rule of commitlint, holds is whether the message has the property of the rule
+// and applies is false when the rule has nothing to check, such as the case of an empty scope.
+type check struct {
+	kind     valueKind
+	describe func(value any) string
+	holds    func(m Message, value any) (holds bool, applies bool)
+}
+
+var checks = map[string]check{
+	"type-empty":    emptyCheck(func(m Message) string { return m.Type }),
+	"type-enum":     enumCheck(func(m Message) string { return m.Type }),
+	"type-case":     caseCheck(func(m Message) string { return m.Type }),
+	"scope-empty":   emptyCheck(func(m Message) string { return m.Scope }),
+	"scope-enum":    enumCheck(func(m Message) string { return m.Scope }),
+	"scope-case":    caseCheck(func(m Message) string { return m.Scope }),
+	"subject-empty": emptyCheck(func(m Message) string { return m.Subject }),
+	"subject-case":  caseCheck(func(m Message) string { return m.Subject }),
+	"subject-full-stop": {
+		kind:     stringValue,
+		describe: func(value any) string { return fmt.Sprintf("end with %q", value) },
+		holds: func(m Message, value any) (bool, bool) {
+			return strings.HasSuffix(m.Subject, value.(string)), m.Subject != ""
+		},
+	},
+	"header-max-length": maxLengthCheck(func(m Message) string { return m.Header }),
+	"body-leading-blank": {
+		kind:     noValue,
+		describe: func(any) string { return "have a leading blank line" },
+		holds: func(m Message, _ any) (bool, bool) {
+			return m.bodyLeadingBlank, m.Body != "" || m.Footer != ""
+		},
+	},
+	"body-max-line-length":   maxLengthCheck(func(m Message) string { return m.Body }),
+	"footer-max-line-length": maxLengthCheck(func(m Message) string { return m.Footer }),
+	"issue-empty":            emptyCheck(func(m Message) string { return m.Issue }),
+	"issue-pattern": {
+		kind:     stringValue,
+		describe: func(value any) string { return fmt.Sprintf("match %s", value) },
+		holds: func(m Message, value any) (bool, bool) {
+			re, err := regexp.Compile(value.(string))
+			return err == nil && re.MatchString(m.Issue), m.Issue != ""
+		},
+	},
+}
+
+func emptyCheck(field func(Message) string) check {
+	return check{
+		kind:     noValue,
+		describe: func(any) string { return "be empty" },
+		holds:    func(m Message, _ any) (bool, bool) { return field(m) == "", true },
+	}
+}
+
+func enumCheck(field func(Message) string) check {
+	return check{
+		kind:     listValue,
+		describe: func(value any) string { return fmt.Sprintf("be one of [%s]", strings.Join(value.([]string), ", ")) },
+		holds: func(m Message, value any) (bool, bool) {
+			return slices.Contains(value.([]string), field(m)), field(m) != ""
+		},
+	}
+}
+
+func caseCheck(field func(Message) string) check {
+	return check{
+		kind:     listValue,
+		describe: func(value any) string { return "be " + strings.Join(value.([]string), " or ") },
+		holds: func(m Message, value any) (bool, bool) {
+			return IsCase(field(m), value.([]string)...), field(m) != ""
+		},
+	}
+}
+
+// maxLengthCheck checks the longest line of the field.
+func maxLengthCheck(field func(Message) string) check {
+	return check{
+		kind:     intValue,
+		describe: func(value any) string { return fmt.Sprintf("have lines of at most %d characters", value) },
+		holds: func(m Message, value any) (bool, bool) {
+			longest := 0
+			for _, line := range strings.Split(field(m), "\n") {
+				longest = max(longest, len([]rune(line)))
+			}
+			return longest <= value.(int), field(m) != ""
+		},
+	}
+}
+
+// DefaultRules are the rules of the conventional commits of this repo, the types and
+// cases of the commit prompt with the lengths of @commitlint/config-conventional.
+func DefaultRules() Rules {
+	return Rules{
+		"type-empty":             {Level: Error, Always: false},
+		"type-enum":              {Level: Error, Always: true, Value: slices.Clone(Types)},
+		"type-case":              {Level: Error, Always: true, Value: []string{"lower-case"}},
+		"scope-case":             {Level: Error, Always: true, Value: []string{"lower-case"}},
+		"subject-empty":          {Level: Error, Always: false},
+		"subject-case":           {Level: Error, Always: true, Value: slices.Clone(SubjectCases)},
+		"subject-full-stop":      {Level: Error, Always: false, Value: "."},
+		"header-max-length":      {Level: Error, Always: true, Value: 100},
+		"body-leading-blank":     {Level: Warning, Always: true},
+		"body-max-line-length":   {Level: Error, Always: true, Value: 100},
+		"footer-max-line-length": {Level: Error, Always: true, Value: 100},
+		"issue-empty":            {Level: Error, Always: false},
+		"issue-pattern":          {Level: Error, Always: true, Value: IssuePattern},
+	}
+}
+
+// Problem is a rule the message breaks.
+type Problem struct {
+	Rule    string
+	Level   Level
+	Message string
+}
+
+func (p Problem) String() string {
+	mark := "✖"
+	if p.Level == Warning {
+		mark = "⚠"
+	}
+	return fmt.Sprintf("%s   %s [%s]", mark, p.Message, p.Rule)
+}
+
+// Lint returns the problems of the message by the rules, sorted by rule.
+func (rules Rules) Lint(msg string) []Problem {
+	m := Parse(msg)
+	var problems []Problem
+	for _, name := range slices.Sorted(maps.Keys(rules)) {
+		rule := rules[name]
+		c, ok := checks[name]
+		if !ok || rule.Level == Disabled {
+			continue
+		}
+		holds, applies := c.holds(m, rule.Value)
+		if !applies || holds == rule.Always {
+			continue
+		}
+		field, _, _ := strings.Cut(name, "-")
+		must := "must"
+		if !rule.Always {
+			must = "must not"
+		}
+		problems = append(problems, Problem{
+			Rule:    name,
+			Level:   rule.Level,
+			Message: fmt.Sprintf("%s %s %s", field, must, c.describe(rule.Value)),
+		})
+	}
+	return problems
+}
+
+// HasErrors is whether any of the problems is an error.
+func HasErrors(problems []Problem) bool {
+	return slices.ContainsFunc(problems, func(p Problem) bool { return p.Level == Error })
+}
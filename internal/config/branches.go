@@ -0,0 +1,259 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	DefaultBranchTemplate      = "{issue}_{slug}"
+	DefaultBranchSlugSeparator = "_"
+	DefaultBranchMaxLength     = 100
+)
+
+// DefaultBranchIssues are the jira issues such as DEALS-1234 or OPD-12345.
+var DefaultBranchIssues = []string{`[A-Z]+-\d{4,5}`}
+
+// DefaultBranchLegacyIssues are the issues of the branches named before the policy, such as ABC-12.
+var DefaultBranchLegacyIssues = []string{`[A-Z]{3,}-\d{2,4}`}
+
+// Branches is the naming policy of the branches that init branch creates and gac and push read.
+// The template places the {type}, the {issue} and the {slug} of the description, {type} may be left out.
+// The issue matches one of the issue patterns, the type is one of the types or any lower case word
+// when there are none, and the slug is lower case words joined by the slug separator.
+// The legacy issues are only read from the names that break the policy, they are not created.
+//
+//	branches:
+//	  template: "{type}/{issue}-{slug}"
+//	  issues:
+//	  - '[A-Z]+-\d{4,5}'
+//	  legacy_issues:
+//	  - '[A-Z]{3,}-\d{2,4}'
+//	  types: [feat, fix, chore]
+//	  slug_separator: "-"
+//	  max_length: 60
+type Branches struct {
+	Template      string   `yaml:"template,omitempty"`
+	Issues        []string `yaml:"issues,omitempty"`
+	LegacyIssues  []string `yaml:"legacy_issues,omitempty" mapstructure:"legacy_issues"`
+	Types         []string `yaml:"types,omitempty"`
+	SlugSeparator string   `yaml:"slug_separator,omitempty" mapstructure:"slug_separator"`
+	MaxLength     int      `yaml:"max_length,omitempty" mapstructure:"max_length"`
+
+	issueRE    *regexp.Regexp
+	anyIssueRE *regexp.Regexp
+	slugRE     *regexp.Regexp
+	nameRE     *regexp.Regexp
+}
+
+// Branch is what a branch name holds, the description is the words of its slug.
+type Branch struct {
+	Type        string
+	Issue       string
+	Description string
+}
+
+// DefaultBranches returns the policy of an empty branches section.
+func DefaultBranches() *Branches {
+	b := &Branches{}
+	if err := b.parse(); err != nil {
+		panic(err) // the defaults parse
+	}
+	return b
+}
+
+var templateFieldRE = regexp.MustCompile(`\{(\w+)\}`)
+
+func (b *Branches) parse() error {
+	if b.Template == "" {
+		b.Template = DefaultBranchTemplate
+	}
+	if len(b.Issues) == 0 {
+		b.Issues = slices.Clone(DefaultBranchIssues)
+	}
+	if b.SlugSeparator == "" {
+		b.SlugSeparator = DefaultBranchSlugSeparator
+	}
+	if b.MaxLength < 0 {
+		return fmt.Errorf("branches max_length is negative:%d", b.MaxLength)
+	}
+	if b.MaxLength == 0 {
+		b.MaxLength = DefaultBranchMaxLength
+	}
+
+	if b.LegacyIssues == nil {
+		b.LegacyIssues = slices.Clone(DefaultBranchLegacyIssues)
+	}
+
+	issue, err := issuesPattern(b.Issues)
+	if err != nil {
+		return err
+	}
+	b.issueRE = regexp.MustCompile("^" + issue + "$")
+	// the issues of the policy come first so DEALS-12345 is not read as DEALS-1234
+	anyIssue, err := issuesPattern(append(slices.Clone(b.Issues), b.LegacyIssues...))
+	if err != nil {
+		return err
+	}
+	b.anyIssueRE = regexp.MustCompile(anyIssue)
+
+	word := `[a-z0-9]+`
+	slug := word + "(?:" + regexp.QuoteMeta(b.SlugSeparator) + word + ")*"
+	b.slugRE = regexp.MustCompile("^" + slug + "$")
+
+	typ := `[a-z]+`
+	if 0 < len(b.Types) {
+		quoted := make([]string, len(b.Types))
+		for i, t := range b.Types {
+			quoted[i] = regexp.QuoteMeta(t)
+		}
+		typ = "(?:" + strings.Join(quoted, "|") + ")"
+	}
+
+	var name strings.Builder
+	seen := map[string]bool{}
+	last := 0
+	for _, m := range templateFieldRE.FindAllStringSubmatchIndex(b.Template, -1) {
+		name.WriteString(regexp.QuoteMeta(b.Template[last:m[0]]))
+		field := b.Template[m[2]:m[3]]
+		if seen[field] {
+			return fmt.Errorf("branches template %s repeats {%s}", b.Template, field)
+		}
+		seen[field] = true
+		switch field {
+		case "type":
+			name.WriteString("(?P<type>" + typ + ")")
+		case "issue":
+			name.WriteString("(?P<issue>" + issue + ")")
+		case "slug":
+			name.WriteString("(?P<slug>" + slug + ")")
+		default:
+			return fmt.Errorf("branches template %s has the unknown {%s}", b.Template, field)
+		}
+		last = m[1]
+	}
+	name.WriteString(regexp.QuoteMeta(b.Template[last:]))
+	if !seen["issue"] || !seen["slug"] {
+		return fmt.Errorf("branches template %s needs {issue} and {slug}", b.Template)
+	}
+	b.nameRE = regexp.MustCompile("^" + name.String() + "$")
+	return nil
+}
+
+// issuesPattern returns the pattern that matches any of the issues.
+func issuesPattern(issues []string) (string, error) {
+	var patterns []string
+	for _, issue := range issues {
+		issue = strings.TrimSuffix(strings.TrimPrefix(issue, "^"), "$")
+		if _, err := regexp.Compile(issue); err != nil {
+			return "", fmt.Errorf("branches issue %s: %w", issue, err)
+		}
+		patterns = append(patterns, "(?:"+issue+")")
+	}
+	return "(?:" + strings.Join(patterns, "|") + ")", nil
+}
+
+// HasType is whether the template places a type.
+func (b *Branches) HasType() bool {
+	return strings.Contains(b.Template, "{type}")
+}
+
+// CheckIssue returns an error when the issue matches none of the issue patterns.
+func (b *Branches) CheckIssue(issue string) error {
+	if !b.issueRE.MatchString(issue) {
+		return fmt.Errorf("%s is not in the expected issue format %s", issue, strings.Join(b.Issues, " or "))
+	}
+	return nil
+}
+
+// CheckSlug returns an error when the slug is not lower case words joined by the slug separator.
+func (b *Branches) CheckSlug(slug string) error {
+	if !b.slugRE.MatchString(slug) {
+		return fmt.Errorf("%s is not in the expected format of lower case words joined by %q", slug, b.SlugSeparator)
+	}
+	return nil
+}
+
+// Slug joins the lower case words of the description with the slug separator.
+func (b *Branches) Slug(description string) string {
+	words := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !('a' <= r && r <= 'z' || '0' <= r && r <= '9')
+	})
+	return strings.Join(words, b.SlugSeparator)
+}
+
+// Format returns the name of the branch by the template, or an error when it breaks the policy.
+func (b *Branches) Format(branch Branch) (string, error) {
+	if b.HasType() && branch.Type == "" {
+		return "", fmt.Errorf("branches template %s needs a type", b.Template)
+	}
+	if b.HasType() && 0 < len(b.Types) && !slices.Contains(b.Types, branch.Type) {
+		return "", fmt.Errorf("branch type %s is not one of %s", branch.Type, strings.Join(b.Types, ", "))
+	}
+	if err := b.CheckIssue(branch.Issue); err != nil {
+		return "", err
+	}
+	slug := b.Slug(branch.Description)
+	if slug == "" {
+		return "", fmt.Errorf("branch description %q has no words", branch.Description)
+	}
+	name := strings.NewReplacer("{type}", branch.Type, "{issue}", branch.Issue, "{slug}", slug).Replace(b.Template)
+	if _, err := b.Parse(name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// Parse returns what the branch name holds, or an error when it breaks the policy.
+func (b *Branches) Parse(name string) (Branch, error) {
+	if b.MaxLength < len(name) {
+		return Branch{}, fmt.Errorf("branch %s is longer than %d", name, b.MaxLength)
+	}
+	m := b.nameRE.FindStringSubmatch(name)
+	if m == nil {
+		return Branch{}, fmt.Errorf("branch %s does not match %s", name, b.Template)
+	}
+	var branch Branch
+	if i := b.nameRE.SubexpIndex("type"); 0 <= i {
+		branch.Type = m[i]
+	}
+	branch.Issue = m[b.nameRE.SubexpIndex("issue")]
+	branch.Description = strings.ReplaceAll(m[b.nameRE.SubexpIndex("slug")], b.SlugSeparator, " ")
+	return branch, nil
+}
+
+// Read returns what the branch name holds as Parse does. A name that breaks the policy, such as
+// one made before it, holds the first issue or legacy issue found in it and the rest of its words
+// as the description.
+func (b *Branches) Read(name string) Branch {
+	if branch, err := b.Parse(name); err == nil {
+		return branch
+	}
+	var branch Branch
+	if loc := b.anyIssueRE.FindStringIndex(name); loc != nil {
+		branch.Issue = name[loc[0]:loc[1]]
+		name = name[:loc[0]] + " " + name[loc[1]:]
+	}
+	var words []string
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '/' || unicode.IsSpace(r) || strings.ContainsRune(b.SlugSeparator, r)
+	}) {
+		// the dashes around the issue of a kebab case name
+		if word = strings.Trim(word, "-"); word != "" {
+			words = append(words, word)
+		}
+	}
+	branch.Description = strings.Join(words, " ")
+	return branch
+}
+
+// BranchPolicy returns the branches of the config, the default policy when there is no config.
+func (c *Config) BranchPolicy() *Branches {
+	if c == nil || c.Branches.nameRE == nil {
+		return DefaultBranches()
+	}
+	return &c.Branches
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("branches", func() {
+	It("defaults to the jira issue and a snake case slug", func() {
+		cfg, err := LoadConfig(strings.NewReader(`
+repos:
+  root: cmr
+`))
+		Expect(err).To(Succeed())
+		b := cfg.BranchPolicy()
+		Expect(b.Template).To(Equal(DefaultBranchTemplate))
+		Expect(b.MaxLength).To(Equal(DefaultBranchMaxLength))
+		Expect(b.HasType()).To(BeFalse())
+
+		name, err := b.Format(Branch{Issue: "DEALS-1234", Description: "Big deal!"})
+		Expect(err).To(Succeed())
+		Expect(name).To(Equal("DEALS-1234_big_deal"))
+		Expect(b.Parse(name)).To(Equal(Branch{Issue: "DEALS-1234", Description: "big deal"}))
+	})
+
+	It("reads the policy of the config", func() {
+		cfg, err := LoadConfig(strings.NewReader(`
+branches:
+  template: "{type}/{issue}-{slug}"
+  issues: ['GH-\d+']
+  types: [feat, fix]
+  slug_separator: "-"
+  max_length: 24
+`))
+		Expect(err).To(Succeed())
+		b := cfg.BranchPolicy()
+		Expect(b.SlugSeparator).To(Equal("-"))
+		Expect(b.MaxLength).To(Equal(24))
+		Expect(b.HasType()).To(BeTrue())
+
+		branch := Branch{Type: "fix", Issue: "GH-7", Description: "empty pages"}
+		name, err := b.Format(branch)
+		Expect(err).To(Succeed())
+		Expect(name).To(Equal("fix/GH-7-empty-pages"))
+		Expect(b.Parse(name)).To(Equal(branch))
+
+		_, err = b.Format(Branch{Issue: "GH-7", Description: "empty pages"})
+		Expect(err).ToNot(Succeed())
+		_, err = b.Format(Branch{Type: "chore", Issue: "GH-7", Description: "empty pages"})
+		Expect(err).ToNot(Succeed())
+		_, err = b.Format(Branch{Type: "fix", Issue: "GH-7", Description: "the last of the empty pages"})
+		Expect(err).ToNot(Succeed())
+		_, err = b.Parse("fix/GH-7_empty_pages")
+		Expect(err).ToNot(Succeed())
+	})
+
+	DescribeTable("reads the names made before the policy",
+		func(name string, issue string, description string) {
+			Expect(DefaultBranches().Read(name)).To(Equal(Branch{Issue: issue, Description: description}))
+		},
+		Entry(nil, "x", "", "x"),
+		Entry(nil, "xy", "", "xy"),
+		Entry(nil, "x_y", "", "x y"),
+		Entry(nil, "JIRA-1234_x_y", "JIRA-1234", "x y"),
+		Entry(nil, "JIRA-1234__x1?x_y_", "JIRA-1234", "x1?x y"),
+		Entry(nil, "ABC-12__xx_y_", "ABC-12", "xx y"),
+		Entry(nil, "AB-12__xx_y_", "", "AB-12 xx y"),
+		Entry(nil, "xx_y_ABC-12", "ABC-12", "xx y"),
+		Entry(nil, "xx_y_ABC-123", "ABC-123", "xx y"),
+		Entry(nil, "xx_y_ABC-1234", "ABC-1234", "xx y"),
+		// the issues of the policy come before the legacy issues
+		Entry(nil, "xx_y_ABC-123456y", "ABC-12345", "xx y 6y"),
+		Entry(nil, "feature/ABC-1234-xx-y", "ABC-1234", "feature xx-y"),
+	)
+
+	It("reads the legacy issues of the config", func() {
+		b := &Branches{LegacyIssues: []string{`GH-\d+`}}
+		Expect(b.parse()).To(Succeed())
+		Expect(b.Read("GH-7_fix")).To(Equal(Branch{Issue: "GH-7", Description: "fix"}))
+		Expect(b.Read("ABC-12_fix")).To(Equal(Branch{Description: "ABC-12 fix"}))
+		Expect(b.CheckIssue("GH-7")).ToNot(Succeed())
+	})
+
+	DescribeTable("rejects a bad policy",
+		func(b Branches) {
+			Expect(b.parse()).ToNot(Succeed())
+		},
+		Entry(nil, Branches{Template: "{issue}"}),
+		Entry(nil, Branches{Template: "{slug}"}),
+		Entry(nil, Branches{Template: "{issue}_{slug}_{issue}"}),
+		Entry(nil, Branches{Template: "{owner}/{issue}_{slug}"}),
+		Entry(nil, Branches{Issues: []string{"("}}),
+		Entry(nil, Branches{LegacyIssues: []string{"("}}),
+		Entry(nil, Branches{MaxLength: -1}),
+	)
+})
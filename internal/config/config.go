@@ -13,10 +13,9 @@ import (
 type Config struct {
 	Repos    MyRepos   `yaml:"repos"`
 	Projects []Project `yaml:"projects"`
-}
-
-type MyRepos struct {
-	Root string `yaml:"root"`
+	Gitlab   Gitlab    `yaml:"gitlab"`
+	Tables   Tables    `yaml:"tables"`
+	Branches Branches  `yaml:"branches"`
 }
 
 type Project struct {
@@ -29,14 +28,23 @@ type Linter struct {
 	CmdArgs []string `yaml:"-"`
 }
 
+// ConfigFilePath returns the filepath, or $HOME/.cmr.yaml when it is empty.
+func ConfigFilePath(filepath string) (string, error) {
+	if filepath != "" {
+		return filepath, nil
+	}
+	// Find home directory.
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(home, ".cmr.yaml"), nil
+}
+
 func LoadConfigFile(filepath string) (*Config, error) {
-	if filepath == "" {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, err
-		}
-		filepath = path.Join(home, ".cmr.yaml")
+	filepath, err := ConfigFilePath(filepath)
+	if err != nil {
+		return nil, err
 	}
 	file, err := os.Open(filepath)
 	if err != nil {
@@ -77,7 +85,16 @@ func (c *Config) parse() error {
 			return err
 		}
 	}
-	return nil
+	if err := c.Repos.parse(); err != nil {
+		return err
+	}
+	if err := c.Tables.parse(); err != nil {
+		return err
+	}
+	if err := c.Branches.parse(); err != nil {
+		return err
+	}
+	return c.Gitlab.parse()
 }
 
 func (p *Project) parse() error {
@@ -11,7 +11,11 @@ import (
 )
 
 //go:embed data/loadtests/bad_quoted_001.yaml
+//go:embed data/loadtests/bad_gitlab_default.yaml
+//go:embed data/loadtests/bad_gitlab_url.yaml
+//go:embed data/loadtests/bad_gitlab_token.yaml
 //go:embed data/loadtests/ok_test1.yaml
+//go:embed data/loadtests/ok_gitlab.yaml
 var loadTestsFS embed.FS
 
 var _ = Describe("for each data file, loadtests", func() {
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	DefaultGitlabName    = "gitlab"
+	DefaultGitlabBaseURL = "https://gitlab.com/"
+	DefaultGitlabAPI     = "api/v4/"
+)
+
+// Gitlab lists the named gitlab instances.
+//
+//	gitlab:
+//	  default: work
+//	  instances:
+//	  - name: work
+//	    base_url: https://gitlab.example.com/
+//	    api: api/v4/
+//	    username: annie # finds my merge requests and reviews
+//	    token:
+//	      providers:
+//	      - type: env
+//	        env: GIT_LAB_ACCESS_TOKEN
+//	      - type: secret-tool
+//	        attributes: [pat, gitlab]
+//	      - type: git-credential
+//	      - type: file
+//	        path: ~/.config/cmr/gitlab.token
+//	      - type: netrc
+//	  - name: localhost # cmr fakelab
+//	    base_url: http://127.0.0.1:8089/
+//	    token:
+//	      env: FAKELAB_TOKEN
+type Gitlab struct {
+	Default   string           `yaml:"default"`
+	Instances []GitlabInstance `yaml:"instances"`
+}
+
+// GitlabInstance is where and how to connect to one gitlab server.
+type GitlabInstance struct {
+	Name    string      `yaml:"name"`
+	BaseURL string      `yaml:"base_url" mapstructure:"base_url"`
+	API     string      `yaml:"api"`
+	Token   TokenSource `yaml:"token"`
+
+	// Username is the user the token belongs to.
+	Username string `yaml:"username"`
+}
+
+// TokenSource names where the auth token of an instance is found.
+// Env is shorthand for an env provider ahead of the Providers.
+// The providers are tried in order until one has the token.
+type TokenSource struct {
+	Env       string          `yaml:"env"`
+	Providers []TokenProvider `yaml:"providers"`
+}
+
+// Token provider types
+const (
+	EnvProvider           = "env"
+	SecretToolProvider    = "secret-tool"
+	GitCredentialProvider = "git-credential"
+	FileProvider          = "file"
+	NetrcProvider         = "netrc"
+)
+
+// TokenProvider configures one credential provider.
+// Env is used by env, Attributes by secret-tool, and Path by file and netrc.
+type TokenProvider struct {
+	Type       string   `yaml:"type"`
+	Env        string   `yaml:"env,omitempty"`
+	Attributes []string `yaml:"attributes,omitempty"`
+	Path       string   `yaml:"path,omitempty"`
+}
+
+// Chain returns the providers in the order they are tried.
+func (s *TokenSource) Chain() []TokenProvider {
+	chain := []TokenProvider{}
+	if s.Env != "" {
+		chain = append(chain, TokenProvider{Type: EnvProvider, Env: s.Env})
+	}
+	return append(chain, s.Providers...)
+}
+
+func (s *TokenSource) parse() error {
+	for _, p := range s.Providers {
+		if err := p.parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *TokenProvider) parse() error {
+	switch p.Type {
+	case EnvProvider:
+		if len(p.Env) < 1 {
+			return fmt.Errorf("env token provider has empty env")
+		}
+	case SecretToolProvider:
+		if len(p.Attributes) < 2 || len(p.Attributes)%2 != 0 {
+			return fmt.Errorf("secret-tool token provider needs attribute value pairs:%v", p.Attributes)
+		}
+	case FileProvider:
+		if len(p.Path) < 1 {
+			return fmt.Errorf("file token provider has empty path")
+		}
+	case GitCredentialProvider, NetrcProvider:
+	default:
+		return fmt.Errorf("unknown token provider type:%s", p.Type)
+	}
+	return nil
+}
+
+// Instance returns the named instance.
+// An empty name selects the default instance,
+// or the only instance if there is no default.
+// With no instances configured the gitlab.com defaults are returned.
+func (g *Gitlab) Instance(name string) (GitlabInstance, error) {
+	if name == "" {
+		name = g.Default
+	}
+	if name == "" {
+		switch len(g.Instances) {
+		case 0:
+			return GitlabInstance{
+				Name:    DefaultGitlabName,
+				BaseURL: DefaultGitlabBaseURL,
+				API:     DefaultGitlabAPI,
+			}, nil
+		case 1:
+			return g.Instances[0], nil
+		default:
+			return GitlabInstance{}, fmt.Errorf("gitlab has %d instances and no default", len(g.Instances))
+		}
+	}
+	for _, inst := range g.Instances {
+		if inst.Name == name {
+			return inst, nil
+		}
+	}
+	return GitlabInstance{}, fmt.Errorf("gitlab instance %s is not in the config", name)
+}
+
+func (g *Gitlab) parse() error {
+	names := map[string]struct{}{}
+	for i := range g.Instances {
+		inst := &g.Instances[i]
+		if err := inst.parse(); err != nil {
+			return err
+		}
+		if _, ok := names[inst.Name]; ok {
+			return fmt.Errorf("gitlab instance %s is repeated", inst.Name)
+		}
+		names[inst.Name] = struct{}{}
+	}
+	if g.Default != "" {
+		if _, ok := names[g.Default]; !ok {
+			return fmt.Errorf("gitlab default %s is not an instance", g.Default)
+		}
+	}
+	return nil
+}
+
+func (inst *GitlabInstance) parse() error {
+	if len(inst.Name) < 1 {
+		return fmt.Errorf("gitlab instance has empty name")
+	}
+	if len(inst.BaseURL) < 1 {
+		return fmt.Errorf("gitlab instance %s has empty base_url", inst.Name)
+	}
+	u, err := url.Parse(inst.BaseURL)
+	if err != nil {
+		return fmt.Errorf("gitlab instance %s base_url: %w", inst.Name, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("gitlab instance %s base_url is not http or https:%s", inst.Name, inst.BaseURL)
+	}
+	inst.BaseURL = withTrailingSlash(inst.BaseURL)
+
+	if len(inst.API) < 1 {
+		inst.API = DefaultGitlabAPI
+	}
+	inst.API = withTrailingSlash(strings.TrimPrefix(inst.API, "/"))
+	if err := inst.Token.parse(); err != nil {
+		return fmt.Errorf("gitlab instance %s token: %w", inst.Name, err)
+	}
+	return nil
+}
+
+func withTrailingSlash(s string) string {
+	if strings.HasSuffix(s, "/") {
+		return s
+	}
+	return s + "/"
+}
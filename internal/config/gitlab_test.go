@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const gitlabYaml = `
+gitlab:
+  default: work
+  instances:
+  - name: work
+    base_url: https://gitlab.example.com
+    api: /api/v4
+    username: annie
+    token:
+      env: WORK_TOKEN
+  - name: localhost
+    base_url: http://127.0.0.1:8080/
+`
+
+var _ = Describe("gitlab instances", func() {
+	It("selects the named or default instance", func() {
+		cfg, err := LoadConfig(strings.NewReader(gitlabYaml))
+		Expect(err).To(Succeed())
+
+		work, err := cfg.Gitlab.Instance("")
+		Expect(err).To(Succeed())
+		Expect(work.Name).To(Equal("work"))
+		Expect(work.BaseURL).To(Equal("https://gitlab.example.com/"))
+		Expect(work.API).To(Equal("api/v4/"))
+		Expect(work.Token.Env).To(Equal("WORK_TOKEN"))
+		Expect(work.Username).To(Equal("annie"))
+
+		local, err := cfg.Gitlab.Instance("localhost")
+		Expect(err).To(Succeed())
+		Expect(local.BaseURL).To(Equal("http://127.0.0.1:8080/"))
+		Expect(local.API).To(Equal(DefaultGitlabAPI))
+
+		_, err = cfg.Gitlab.Instance("missing")
+		Expect(err).ToNot(Succeed())
+	})
+
+	It("chains the token providers", func() {
+		cfg, err := LoadConfig(strings.NewReader(`
+gitlab:
+  instances:
+  - name: work
+    base_url: https://gitlab.example.com
+    token:
+      env: WORK_TOKEN
+      providers:
+      - type: secret-tool
+        attributes: [pat, gitlab]
+      - type: file
+        path: ~/.config/cmr/token
+      - type: netrc
+`))
+		Expect(err).To(Succeed())
+		work, err := cfg.Gitlab.Instance("")
+		Expect(err).To(Succeed())
+		Expect(work.Token.Chain()).To(Equal([]TokenProvider{
+			{Type: EnvProvider, Env: "WORK_TOKEN"},
+			{Type: SecretToolProvider, Attributes: []string{"pat", "gitlab"}},
+			{Type: FileProvider, Path: "~/.config/cmr/token"},
+			{Type: NetrcProvider},
+		}))
+	})
+
+	DescribeTable("rejects bad token providers",
+		func(provider TokenProvider) {
+			src := TokenSource{Providers: []TokenProvider{provider}}
+			Expect(src.parse()).ToNot(Succeed())
+		},
+		Entry("unknown", TokenProvider{Type: "keychain"}),
+		Entry("env without var", TokenProvider{Type: EnvProvider}),
+		Entry("odd attributes", TokenProvider{Type: SecretToolProvider, Attributes: []string{"pat"}}),
+		Entry("file without path", TokenProvider{Type: FileProvider}),
+	)
+
+	It("defaults without instances", func() {
+		g := Gitlab{}
+		inst, err := g.Instance("")
+		Expect(err).To(Succeed())
+		Expect(inst.BaseURL).To(Equal(DefaultGitlabBaseURL))
+		Expect(inst.API).To(Equal(DefaultGitlabAPI))
+	})
+
+	It("needs a default to choose between instances", func() {
+		g := Gitlab{Instances: []GitlabInstance{{Name: "a"}, {Name: "b"}}}
+		_, err := g.Instance("")
+		Expect(err).ToNot(Succeed())
+	})
+})
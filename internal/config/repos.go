@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultRepoWorkers = 8
+	DefaultRepoTimeout = 5 * time.Minute
+)
+
+// MyRepos is where the local clones live and which projects are cloned and pulled.
+// A project is selected when its path with namespace matches an include glob,
+// or it is in one of the groups, or it has one of the topics,
+// and it matches none of the exclude globs.
+// In the globs * matches within a path segment and ** matches any segments.
+//
+//	repos:
+//	  root: cmr
+//	  workers: 8
+//	  timeout: 5m
+//	  include:
+//	  - platform/**
+//	  - tools/cmr
+//	  exclude:
+//	  - platform/archive/**
+//	  groups:
+//	  - exchange-node
+//	  topics:
+//	  - golang
+type MyRepos struct {
+	Root    string        `yaml:"root"`
+	Workers int           `yaml:"workers,omitempty"`
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	Include []string      `yaml:"include,omitempty"`
+	Exclude []string      `yaml:"exclude,omitempty"`
+	Groups  []string      `yaml:"groups,omitempty"`
+	Topics  []string      `yaml:"topics,omitempty"`
+}
+
+// HasSelection returns true if any project can be selected.
+func (r *MyRepos) HasSelection() bool {
+	return 0 < len(r.Include) || 0 < len(r.Groups) || 0 < len(r.Topics)
+}
+
+// Selects returns true if the project is selected.
+func (r *MyRepos) Selects(pathWithNamespace string, topics []string) bool {
+	for _, glob := range r.Exclude {
+		if MatchGlob(glob, pathWithNamespace) {
+			return false
+		}
+	}
+	for _, glob := range r.Include {
+		if MatchGlob(glob, pathWithNamespace) {
+			return true
+		}
+	}
+	for _, group := range r.Groups {
+		group = strings.Trim(group, "/")
+		if strings.HasPrefix(pathWithNamespace, group+"/") {
+			return true
+		}
+	}
+	for _, topic := range r.Topics {
+		for _, t := range topics {
+			if strings.EqualFold(topic, t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (r *MyRepos) parse() error {
+	if r.Workers < 0 {
+		return fmt.Errorf("repos workers is negative:%d", r.Workers)
+	}
+	if r.Workers == 0 {
+		r.Workers = DefaultRepoWorkers
+	}
+	if r.Timeout < 0 {
+		return fmt.Errorf("repos timeout is negative:%s", r.Timeout)
+	}
+	if r.Timeout == 0 {
+		r.Timeout = DefaultRepoTimeout
+	}
+	for _, globs := range [][]string{r.Include, r.Exclude} {
+		for _, glob := range globs {
+			if err := checkGlob(glob); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func checkGlob(glob string) error {
+	if len(glob) < 1 {
+		return fmt.Errorf("repos glob is empty")
+	}
+	for _, segment := range strings.Split(glob, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("repos glob %s: %w", glob, err)
+		}
+	}
+	return nil
+}
+
+// MatchGlob returns true if the slash separated name matches the glob.
+// A ** segment matches zero or more segments, the other segments match as in path.Match.
+func MatchGlob(glob string, name string) bool {
+	return matchSegments(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(globs []string, names []string) bool {
+	for len(globs) > 0 {
+		if globs[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(globs[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(names) == 0 {
+			return false
+		}
+		if ok, err := path.Match(globs[0], names[0]); err != nil || !ok {
+			return false
+		}
+		globs, names = globs[1:], names[1:]
+	}
+	return len(names) == 0
+}
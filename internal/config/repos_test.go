@@ -0,0 +1,73 @@
+package config
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("repos", func() {
+	It("defaults the workers and timeout", func() {
+		cfg, err := LoadConfig(strings.NewReader(`
+repos:
+  root: cmr
+  include:
+  - platform/**
+`))
+		Expect(err).To(Succeed())
+		Expect(cfg.Repos.Root).To(Equal("cmr"))
+		Expect(cfg.Repos.Workers).To(Equal(DefaultRepoWorkers))
+		Expect(cfg.Repos.Timeout).To(Equal(DefaultRepoTimeout))
+		Expect(cfg.Repos.HasSelection()).To(BeTrue())
+	})
+
+	It("reads the workers and timeout", func() {
+		cfg, err := LoadConfig(strings.NewReader(`
+repos:
+  workers: 3
+  timeout: 90s
+`))
+		Expect(err).To(Succeed())
+		Expect(cfg.Repos.Workers).To(Equal(3))
+		Expect(cfg.Repos.Timeout).To(Equal(90 * time.Second))
+		Expect(cfg.Repos.HasSelection()).To(BeFalse())
+	})
+
+	It("rejects a bad glob", func() {
+		r := MyRepos{Include: []string{"platform/[a"}}
+		Expect(r.parse()).ToNot(Succeed())
+	})
+
+	DescribeTable("globs",
+		func(glob string, name string, isMatch bool) {
+			Expect(MatchGlob(glob, name)).To(Equal(isMatch))
+		},
+		Entry(nil, "tools/cmr", "tools/cmr", true),
+		Entry(nil, "tools/*", "tools/cmr", true),
+		Entry(nil, "tools/*", "tools/go/cmr", false),
+		Entry(nil, "tools/**", "tools/go/cmr", true),
+		Entry(nil, "tools/**", "tools", true),
+		Entry(nil, "**/cmr", "tools/go/cmr", true),
+		Entry(nil, "**/cmr", "tools/go/cmr2", false),
+		Entry(nil, "tools/**/cmr", "tools/cmr", true),
+		Entry(nil, "tools/c?r", "tools/cmr", true),
+		Entry(nil, "tools", "tools/cmr", false),
+	)
+
+	It("selects by glob, group and topic", func() {
+		r := MyRepos{
+			Include: []string{"tools/*"},
+			Exclude: []string{"**/archive*"},
+			Groups:  []string{"exchange-node/"},
+			Topics:  []string{"golang"},
+		}
+		Expect(r.Selects("tools/cmr", nil)).To(BeTrue())
+		Expect(r.Selects("tools/archived", nil)).To(BeFalse())
+		Expect(r.Selects("exchange-node/deployment/demand", nil)).To(BeTrue())
+		Expect(r.Selects("exchange-node-other/demand", nil)).To(BeFalse())
+		Expect(r.Selects("web/site", []string{"GoLang"})).To(BeTrue())
+		Expect(r.Selects("web/site", []string{"js"})).To(BeFalse())
+	})
+})
@@ -0,0 +1,163 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Table names
+const (
+	MergeRequestsTable = "merge_requests"
+	EventsTable        = "events"
+	ProjectsTable      = "projects"
+	GroupsTable        = "groups"
+	PipelinesTable     = "pipelines"
+	ReviewsTable       = "reviews"
+	LocalReposTable    = "local_repos"
+	DashboardTable     = "dashboard"
+)
+
+// Tables are the layouts of the tui tables by table name.
+// A column is a field of the record by its json name, or a path
+// into the nested records such as author.username or references.full.
+// A sort key is a field, descending when it starts with -.
+// The tui saves the layout back when the columns are sorted, resized or hidden.
+//
+//	tables:
+//	  merge_requests:
+//	    columns:
+//	    - field: id
+//	    - field: references.full
+//	      title: Ref
+//	      width: 20
+//	    - field: author.username
+//	      title: Author
+//	    - field: title
+//	    - field: description
+//	      hidden: true
+//	    sort: [-updated_at, id]
+type Tables map[string]TableLayout
+
+// TableLayout is the columns of a table and how its rows are sorted.
+type TableLayout struct {
+	Columns []Column `yaml:"columns"`
+	Sort    []string `yaml:"sort,omitempty"`
+}
+
+// Column is one column of a table, a zero width fits the contents.
+type Column struct {
+	Field  string `yaml:"field"`
+	Title  string `yaml:"title,omitempty"`
+	Width  int    `yaml:"width,omitempty"`
+	Hidden bool   `yaml:"hidden,omitempty"`
+}
+
+// SortKey splits a sort key into its field and direction.
+func SortKey(key string) (field string, desc bool) {
+	if strings.HasPrefix(key, "-") {
+		return key[1:], true
+	}
+	return key, false
+}
+
+// Get returns the layout of the table, the columns and sort of def fill in what is not configured.
+func (t Tables) Get(name string, def TableLayout) TableLayout {
+	layout, ok := t[name]
+	if !ok {
+		return def
+	}
+	if len(layout.Columns) == 0 {
+		layout.Columns = def.Columns
+		if len(layout.Sort) == 0 {
+			layout.Sort = def.Sort
+		}
+	}
+	return layout
+}
+
+func (t Tables) parse() error {
+	for name, layout := range t {
+		if err := layout.parse(); err != nil {
+			return fmt.Errorf("table %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (l *TableLayout) parse() error {
+	for _, c := range l.Columns {
+		if len(c.Field) < 1 {
+			return fmt.Errorf("column has empty field")
+		}
+		if c.Width < 0 {
+			return fmt.Errorf("column %s width is negative:%d", c.Field, c.Width)
+		}
+	}
+	for _, key := range l.Sort {
+		if field, _ := SortKey(key); len(field) < 1 {
+			return fmt.Errorf("sort key is empty")
+		}
+	}
+	return nil
+}
+
+// SaveTable writes the layout of the table into the config file.
+// The rest of the file is kept as it is.
+func SaveTable(filepath string, name string, layout TableLayout) error {
+	filepath, err := ConfigFilePath(filepath)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	b, err := os.ReadFile(filepath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return fmt.Errorf("config %s: %w", filepath, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("config %s is not a mapping", filepath)
+	}
+	var value yaml.Node
+	if err := value.Encode(layout); err != nil {
+		return err
+	}
+	tables := mappingValue(root, "tables")
+	if tables.Kind != yaml.MappingNode {
+		*tables = yaml.Node{Kind: yaml.MappingNode}
+	}
+	*mappingValue(tables, name) = value
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath, out.Bytes(), 0644)
+}
+
+// mappingValue returns the value of the key in the mapping, adding the key when it is missing.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	k := &yaml.Node{Kind: yaml.ScalarNode, Value: key}
+	v := &yaml.Node{Kind: yaml.MappingNode}
+	m.Content = append(m.Content, k, v)
+	return v
+}
@@ -0,0 +1,97 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("tables", func() {
+	defaults := TableLayout{
+		Columns: []Column{{Field: "id"}, {Field: "title"}},
+		Sort:    []string{"-id"},
+	}
+
+	It("reads the layout of a table", func() {
+		cfg, err := LoadConfig(strings.NewReader(`
+tables:
+  merge_requests:
+    columns:
+    - field: author.username
+      title: Author
+      width: 12
+    - field: description
+      hidden: true
+    sort: [-updated_at, id]
+`))
+		Expect(err).To(Succeed())
+		Expect(cfg.Tables.Get(MergeRequestsTable, defaults)).To(Equal(TableLayout{
+			Columns: []Column{
+				{Field: "author.username", Title: "Author", Width: 12},
+				{Field: "description", Hidden: true},
+			},
+			Sort: []string{"-updated_at", "id"},
+		}))
+	})
+
+	It("defaults what is not configured", func() {
+		tables := Tables{EventsTable: {Sort: []string{"title"}}}
+		Expect(tables.Get(MergeRequestsTable, defaults)).To(Equal(defaults))
+		Expect(tables.Get(EventsTable, defaults)).To(Equal(TableLayout{
+			Columns: defaults.Columns,
+			Sort:    []string{"title"},
+		}))
+	})
+
+	DescribeTable("rejects a bad layout",
+		func(layout TableLayout) {
+			Expect(Tables{EventsTable: layout}.parse()).ToNot(Succeed())
+		},
+		Entry("empty field", TableLayout{Columns: []Column{{Field: ""}}}),
+		Entry("negative width", TableLayout{Columns: []Column{{Field: "id", Width: -1}}}),
+		Entry("empty sort key", TableLayout{Sort: []string{"-"}}),
+	)
+
+	It("saves the layout and keeps the rest of the file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "cmr.yaml")
+		Expect(os.WriteFile(path, []byte(`# my config
+repos:
+  root: cmr # the clones
+tables:
+  events:
+    columns:
+    - field: id
+`), 0644)).To(Succeed())
+
+		layout := TableLayout{Columns: []Column{{Field: "title", Width: 30}}, Sort: []string{"-id"}}
+		Expect(SaveTable(path, MergeRequestsTable, layout)).To(Succeed())
+		Expect(SaveTable(path, EventsTable, defaults)).To(Succeed())
+
+		saved := mustRead(path)
+		Expect(saved).To(ContainSubstring("# my config"))
+		Expect(saved).To(ContainSubstring("root: cmr # the clones"))
+
+		cfg, err := LoadConfig(strings.NewReader(saved))
+		Expect(err).To(Succeed())
+		Expect(cfg.Repos.Root).To(Equal("cmr"))
+		Expect(cfg.Tables[MergeRequestsTable]).To(Equal(layout))
+		Expect(cfg.Tables[EventsTable]).To(Equal(defaults))
+	})
+
+	It("saves to a new file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "cmr.yaml")
+		Expect(SaveTable(path, EventsTable, defaults)).To(Succeed())
+		cfg, err := LoadConfig(strings.NewReader(mustRead(path)))
+		Expect(err).To(Succeed())
+		Expect(cfg.Tables[EventsTable]).To(Equal(defaults))
+	})
+})
+
+func mustRead(path string) string {
+	b, err := os.ReadFile(path)
+	Expect(err).To(Succeed())
+	return string(b)
+}
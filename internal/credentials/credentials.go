@@ -0,0 +1,142 @@
+// Package credentials finds and stores the gitlab auth token.
+//
+// A token is looked up through a chain of providers:
+// env vars, secret-tool, git credential, a private file and netrc.
+// Tokens are never written into errors or logs.
+package credentials
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/xr"
+)
+
+var (
+	// ErrNotFound is returned when a provider has no token for the host.
+	ErrNotFound = errors.New("token not found")
+	// ErrReadOnly is returned when a provider can not store a token.
+	ErrReadOnly = errors.New("provider is read only")
+)
+
+// Provider looks up and stores the token of a host.
+type Provider interface {
+	Name() string
+	// Type is the provider type of the config, such as git-credential.
+	Type() string
+	Get(ctx context.Context, host string) (string, error)
+	Store(ctx context.Context, host string, token string) error
+}
+
+// Chain is the providers tried in order.
+type Chain []Provider
+
+// Get returns the first token found and the provider that had it.
+func (c Chain) Get(ctx context.Context, host string) (string, Provider, error) {
+	var errs []error
+	for _, p := range c {
+		token, err := p.Get(ctx, host)
+		if err == nil && token != "" {
+			return token, p, nil
+		}
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		}
+	}
+	if len(errs) < 1 {
+		return "", nil, fmt.Errorf("no token for %s in %s: %w", host, c.names(), ErrNotFound)
+	}
+	return "", nil, fmt.Errorf("no token for %s in %s: %w", host, c.names(), errors.Join(errs...))
+}
+
+// Store saves the token in the first provider that can store it.
+func (c Chain) Store(ctx context.Context, host string, token string) (Provider, error) {
+	for _, p := range c {
+		err := p.Store(ctx, host, token)
+		if err == nil {
+			return p, nil
+		}
+		if !errors.Is(err, ErrReadOnly) {
+			return nil, fmt.Errorf("%s: %w", p.Name(), err)
+		}
+	}
+	return nil, fmt.Errorf("no provider in %s can store a token", c.names())
+}
+
+// Find returns the first provider of the type or nil.
+func (c Chain) Find(providerType string) Provider {
+	for _, p := range c {
+		if p.Type() == providerType {
+			return p
+		}
+	}
+	return nil
+}
+
+func (c Chain) names() string {
+	names := make([]string, len(c))
+	for i, p := range c {
+		names[i] = p.Name()
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// DefaultChain is used when the config does not name any providers.
+func DefaultChain(fn xr.Funcs) Chain {
+	return Chain{
+		&EnvProvider{Var: "GIT_LAB_ACCESS_TOKEN"},
+		&SecretToolProvider{Attributes: []string{"pat", "gitlab"}, Funcs: fn},
+		&SecretToolProvider{Attributes: []string{"pat", "publicus"}, Funcs: fn},
+	}
+}
+
+// FromConfig returns the chain of the token source.
+// A nil fn runs the real programs.
+func FromConfig(src config.TokenSource, fn xr.Funcs) Chain {
+	providers := src.Chain()
+	if len(providers) < 1 {
+		return DefaultChain(fn)
+	}
+	chain := make(Chain, 0, len(providers))
+	for _, p := range providers {
+		switch p.Type {
+		case config.EnvProvider:
+			chain = append(chain, &EnvProvider{Var: p.Env})
+		case config.SecretToolProvider:
+			chain = append(chain, &SecretToolProvider{Attributes: p.Attributes, Funcs: fn})
+		case config.GitCredentialProvider:
+			chain = append(chain, &GitCredentialProvider{Funcs: fn})
+		case config.FileProvider:
+			chain = append(chain, &FileProvider{Path: p.Path})
+		case config.NetrcProvider:
+			chain = append(chain, &NetrcProvider{Path: p.Path})
+		}
+	}
+	return chain
+}
+
+// Mask hides all but the last few characters of the token.
+func Mask(token string) string {
+	const shown = 4
+	if len(token) <= shown*2 {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", len(token)-shown) + token[len(token)-shown:]
+}
+
+// expandHome replaces a leading ~ with the home dir.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
@@ -0,0 +1,210 @@
+package credentials
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/xr"
+)
+
+func TestCredentials(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "credentials_test")
+}
+
+// fakeRunner writes the output and records the input of one run.
+type fakeRunner struct {
+	out    string
+	stdOut io.Writer
+	stdIn  io.Reader
+	calls  *[]fakeCall
+	call   fakeCall
+}
+
+type fakeCall struct {
+	env   []string
+	args  []string
+	input string
+}
+
+func (r *fakeRunner) SetStdin(stdIn io.Reader) {
+	r.stdIn = stdIn
+}
+
+func (r *fakeRunner) Run() error {
+	if r.stdIn != nil {
+		b, _ := io.ReadAll(r.stdIn)
+		r.call.input = string(b)
+	}
+	*r.calls = append(*r.calls, r.call)
+	_, err := io.WriteString(r.stdOut, r.out)
+	return err
+}
+
+type fakeFuncs struct {
+	out   string
+	calls []fakeCall
+}
+
+func (f *fakeFuncs) Environ() []string                    { return []string{"HOME=/home/test"} }
+func (f *fakeFuncs) Getwd() (string, error)               { return "/", nil }
+func (f *fakeFuncs) LookPath(file string) (string, error) { return "/usr/bin/" + file, nil }
+func (f *fakeFuncs) MakeRunner(
+	_ context.Context,
+	_ string,
+	env []string,
+	stdOut io.Writer,
+	_ io.Writer,
+	_ string,
+	args ...string) xr.Runner {
+	return &fakeRunner{
+		out:    f.out,
+		stdOut: stdOut,
+		calls:  &f.calls,
+		call:   fakeCall{env: env, args: args},
+	}
+}
+
+var _ = Describe("credentials", func() {
+	ctx := context.Background()
+	const host = "gitlab.example.com"
+
+	It("reads env", func() {
+		GinkgoT().Setenv("CMR_TEST_TOKEN", "env-token")
+		p := &EnvProvider{Var: "CMR_TEST_TOKEN"}
+		Expect(p.Get(ctx, host)).To(Equal("env-token"))
+		Expect(p.Store(ctx, host, "x")).To(MatchError(ErrReadOnly))
+
+		_, err := (&EnvProvider{Var: "CMR_TEST_TOKEN_UNSET"}).Get(ctx, host)
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	It("stores and reads a private file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "cmr", "token")
+		p := &FileProvider{Path: path}
+		_, err := p.Get(ctx, host)
+		Expect(err).To(MatchError(ErrNotFound))
+
+		Expect(p.Store(ctx, host, "file-token")).To(Succeed())
+		info, err := os.Stat(path)
+		Expect(err).To(Succeed())
+		Expect(info.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+		Expect(p.Get(ctx, host)).To(Equal("file-token"))
+
+		Expect(os.Chmod(path, 0o644)).To(Succeed())
+		_, err = p.Get(ctx, host)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).ToNot(ContainSubstring("file-token"))
+	})
+
+	DescribeTable("parse netrc",
+		func(text string, machine string, expected string) {
+			Expect(parseNetrc(text)[machine]).To(Equal(expected))
+		},
+		Entry("one line", "machine gitlab.example.com login oauth2 password tok1", host, "tok1"),
+		Entry("multi line",
+			"machine github.com\n  login me\n  password gh\nmachine gitlab.example.com\n  login oauth2\n  password tok2\n",
+			host, "tok2"),
+		Entry("default", "machine github.com login me password gh\ndefault login any password dflt", "", "dflt"),
+		Entry("missing", "machine github.com login me password gh", host, ""),
+	)
+
+	It("reads netrc", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "netrc")
+		Expect(os.WriteFile(path, []byte("machine gitlab.example.com login oauth2 password netrc-token\n"), 0o600)).To(Succeed())
+		p := &NetrcProvider{Path: path}
+		Expect(p.Get(ctx, host)).To(Equal("netrc-token"))
+		_, err := p.Get(ctx, "other.example.com")
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	It("looks up and stores with secret-tool", func() {
+		fn := &fakeFuncs{out: "st-token\n"}
+		p := &SecretToolProvider{Attributes: []string{"pat", "gitlab"}, Funcs: fn}
+		Expect(p.Get(ctx, host)).To(Equal("st-token"))
+		Expect(fn.calls[0].args).To(Equal([]string{"lookup", "pat", "gitlab"}))
+
+		Expect(p.Store(ctx, host, "new-token")).To(Succeed())
+		Expect(fn.calls[1].args[0]).To(Equal("store"))
+		Expect(fn.calls[1].args[2:]).To(Equal([]string{"pat", "gitlab"}))
+		Expect(fn.calls[1].input).To(Equal("new-token"))
+
+		fn.out = ""
+		_, err := p.Get(ctx, host)
+		Expect(err).To(MatchError(ErrNotFound))
+	})
+
+	It("fills and approves with git credential", func() {
+		fn := &fakeFuncs{out: "protocol=https\nhost=gitlab.example.com\nusername=oauth2\npassword=git-token\n"}
+		p := &GitCredentialProvider{Funcs: fn}
+		Expect(p.Get(ctx, host)).To(Equal("git-token"))
+		Expect(fn.calls[0].args).To(Equal([]string{"credential", "fill"}))
+		Expect(fn.calls[0].input).To(Equal("protocol=https\nhost=gitlab.example.com\n\n"))
+		Expect(fn.calls[0].env).To(ContainElement("GIT_TERMINAL_PROMPT=0"))
+
+		Expect(p.Store(ctx, host, "new-token")).To(Succeed())
+		Expect(fn.calls[1].args).To(Equal([]string{"credential", "approve"}))
+		Expect(fn.calls[1].input).To(ContainSubstring("password=new-token\n"))
+	})
+
+	It("chains providers in config order", func() {
+		dir := GinkgoT().TempDir()
+		GinkgoT().Setenv("CMR_TEST_TOKEN_UNSET", "")
+		src := config.TokenSource{
+			Env: "CMR_TEST_TOKEN_UNSET",
+			Providers: []config.TokenProvider{
+				{Type: config.NetrcProvider, Path: filepath.Join(dir, "netrc")},
+				{Type: config.FileProvider, Path: filepath.Join(dir, "token")},
+			},
+		}
+		chain := FromConfig(src, nil)
+		Expect(chain).To(HaveLen(3))
+
+		_, _, err := chain.Get(ctx, host)
+		Expect(err).To(MatchError(ErrNotFound))
+
+		stored, err := chain.Store(ctx, host, "chain-token")
+		Expect(err).To(Succeed())
+		Expect(stored.Name()).To(HavePrefix("file"))
+
+		token, found, err := chain.Get(ctx, host)
+		Expect(err).To(Succeed())
+		Expect(token).To(Equal("chain-token"))
+		Expect(found).To(Equal(stored))
+	})
+
+	It("finds the provider of each type", func() {
+		chain := FromConfig(config.TokenSource{
+			Providers: []config.TokenProvider{
+				{Type: config.EnvProvider, Env: "CMR_TEST_TOKEN"},
+				{Type: config.SecretToolProvider, Attributes: []string{"pat", "gitlab"}},
+				{Type: config.GitCredentialProvider},
+				{Type: config.FileProvider, Path: "~/.config/cmr/token"},
+				{Type: config.NetrcProvider},
+			},
+		}, nil)
+		for i, providerType := range []string{
+			config.EnvProvider,
+			config.SecretToolProvider,
+			config.GitCredentialProvider,
+			config.FileProvider,
+			config.NetrcProvider,
+		} {
+			Expect(chain.Find(providerType)).To(BeIdenticalTo(chain[i]), providerType)
+		}
+		Expect(chain.Find("keychain")).To(BeNil())
+	})
+
+	It("masks tokens", func() {
+		Expect(Mask("glpat-abcdefgh1234")).To(Equal(strings.Repeat("*", 14) + "1234"))
+		Expect(Mask("short")).To(Equal("*****"))
+	})
+})
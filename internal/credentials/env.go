@@ -0,0 +1,32 @@
+package credentials
+
+import (
+	"context"
+	"os"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+)
+
+// EnvProvider reads the token from an env var.
+type EnvProvider struct {
+	Var string
+}
+
+func (p *EnvProvider) Name() string {
+	return "env " + p.Var
+}
+
+func (p *EnvProvider) Type() string {
+	return config.EnvProvider
+}
+
+func (p *EnvProvider) Get(_ context.Context, _ string) (string, error) {
+	if token := os.Getenv(p.Var); token != "" {
+		return token, nil
+	}
+	return "", ErrNotFound
+}
+
+func (p *EnvProvider) Store(_ context.Context, _ string, _ string) error {
+	return ErrReadOnly
+}
@@ -0,0 +1,66 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// FileProvider reads and stores the token in a file only the user can read.
+type FileProvider struct {
+	Path string
+}
+
+func (p *FileProvider) Name() string {
+	return "file " + p.Path
+}
+
+func (p *FileProvider) Type() string {
+	return config.FileProvider
+}
+
+func (p *FileProvider) Get(_ context.Context, _ string) (string, error) {
+	path, err := expandHome(p.Path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	} else if err != nil {
+		return "", err
+	}
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		return "", withstack.Errorf("%s has mode %o and can be read by others, it must be 600", path, perm)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (p *FileProvider) Store(_ context.Context, _ string, token string) error {
+	path, err := expandHome(p.Path)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err = os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return err
+	}
+	// WriteFile keeps the mode of an existing file
+	return os.Chmod(path, 0o600)
+}
@@ -0,0 +1,89 @@
+package credentials
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/withstack"
+	"github.com/stalwartgiraffe/cmr/xr"
+)
+
+// GitCredentialProvider reads and stores the token with the git credential helpers.
+type GitCredentialProvider struct {
+	Funcs xr.Funcs
+}
+
+func (p *GitCredentialProvider) Name() string {
+	return "git credential"
+}
+
+func (p *GitCredentialProvider) Type() string {
+	return config.GitCredentialProvider
+}
+
+func (p *GitCredentialProvider) Get(ctx context.Context, host string) (string, error) {
+	const noAllowedStatus = 0
+	out, err := xr.RunWithInput(ctx, "git", noAllowedStatus, p.funcs(),
+		strings.NewReader(credentialRequest(host, "")), "credential", "fill")
+	if err != nil {
+		// the output may hold the secret so it is not in the error
+		return "", withstack.Errorf("git credential fill failed for %s", host)
+	}
+	if token := parseCredential(out)["password"]; token != "" {
+		return token, nil
+	}
+	return "", ErrNotFound
+}
+
+func (p *GitCredentialProvider) Store(ctx context.Context, host string, token string) error {
+	const noAllowedStatus = 0
+	if _, err := xr.RunWithInput(ctx, "git", noAllowedStatus, p.funcs(),
+		strings.NewReader(credentialRequest(host, token)), "credential", "approve"); err != nil {
+		return withstack.Errorf("git credential approve failed for %s", host)
+	}
+	return nil
+}
+
+// funcs never lets git prompt on the terminal.
+func (p *GitCredentialProvider) funcs() xr.Funcs {
+	fn := p.Funcs
+	if fn == nil {
+		fn = xr.NewFuncs()
+	}
+	return &noPromptFuncs{fn}
+}
+
+type noPromptFuncs struct {
+	xr.Funcs
+}
+
+func (f *noPromptFuncs) Environ() []string {
+	return append(f.Funcs.Environ(), "GIT_TERMINAL_PROMPT=0")
+}
+
+// credentialRequest formats the git credential input.
+// The token is the password of the oauth2 user that gitlab accepts.
+func credentialRequest(host string, token string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "protocol=https\nhost=%s\n", host)
+	if token != "" {
+		fmt.Fprintf(&sb, "username=oauth2\npassword=%s\n", token)
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// parseCredential parses the key=value lines of git credential output.
+func parseCredential(out string) map[string]string {
+	kv := map[string]string{}
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if k, v, ok := strings.Cut(scanner.Text(), "="); ok {
+			kv[k] = v
+		}
+	}
+	return kv
+}
@@ -0,0 +1,93 @@
+package credentials
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+)
+
+const defaultNetrc = "~/.netrc"
+
+// NetrcProvider reads the token from the password of the host in a netrc file.
+// An empty Path reads ~/.netrc.
+type NetrcProvider struct {
+	Path string
+}
+
+func (p *NetrcProvider) Name() string {
+	return "netrc " + p.path()
+}
+
+func (p *NetrcProvider) Type() string {
+	return config.NetrcProvider
+}
+
+func (p *NetrcProvider) path() string {
+	if p.Path == "" {
+		return defaultNetrc
+	}
+	return p.Path
+}
+
+func (p *NetrcProvider) Get(_ context.Context, host string) (string, error) {
+	path, err := expandHome(p.path())
+	if err != nil {
+		return "", err
+	}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", ErrNotFound
+	} else if err != nil {
+		return "", err
+	}
+	if token := parseNetrc(string(b))[host]; token != "" {
+		return token, nil
+	}
+	if token := parseNetrc(string(b))[""]; token != "" {
+		return token, nil
+	}
+	return "", ErrNotFound
+}
+
+func (p *NetrcProvider) Store(_ context.Context, _ string, _ string) error {
+	return ErrReadOnly
+}
+
+// parseNetrc returns the password of each machine.
+// The default entry has the empty machine name.
+func parseNetrc(text string) map[string]string {
+	passwords := map[string]string{}
+	fields := strings.Fields(text)
+	machine := ""
+	inEntry := false
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "machine":
+			if i+1 < len(fields) {
+				i++
+				machine = fields[i]
+				inEntry = true
+			}
+		case "default":
+			machine = ""
+			inEntry = true
+		case "macdef":
+			// a macro runs to the end of the entry, skip the rest
+			inEntry = false
+		case "login", "account":
+			i++
+		case "password":
+			if i+1 < len(fields) {
+				i++
+				if _, ok := passwords[machine]; inEntry && !ok {
+					passwords[machine] = fields[i]
+				}
+			}
+		}
+	}
+	return passwords
+}
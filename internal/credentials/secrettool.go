@@ -0,0 +1,52 @@
+package credentials
+
+import (
+	"context"
+	"strings"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/withstack"
+	"github.com/stalwartgiraffe/cmr/xr"
+)
+
+const secretTool = "secret-tool"
+
+// SecretToolProvider reads and stores the token in the desktop keyring
+// with secret-tool. Attributes are the attribute value pairs of the secret.
+type SecretToolProvider struct {
+	Attributes []string
+	Funcs      xr.Funcs
+}
+
+func (p *SecretToolProvider) Name() string {
+	return secretTool + " " + strings.Join(p.Attributes, " ")
+}
+
+func (p *SecretToolProvider) Type() string {
+	return config.SecretToolProvider
+}
+
+func (p *SecretToolProvider) Get(ctx context.Context, _ string) (string, error) {
+	// secret-tool exits 1 when the secret is missing.
+	const notFoundStatus = 1
+	args := append([]string{"lookup"}, p.Attributes...)
+	out, err := xr.Run(ctx, secretTool, notFoundStatus, p.Funcs, args...)
+	if err != nil {
+		// the output may hold the secret so it is not in the error
+		return "", withstack.Errorf("%s lookup failed", secretTool)
+	}
+	token := strings.TrimSpace(out)
+	if token == "" {
+		return "", ErrNotFound
+	}
+	return token, nil
+}
+
+func (p *SecretToolProvider) Store(ctx context.Context, _ string, token string) error {
+	const noAllowedStatus = 0
+	args := append([]string{"store", "--label=cmr gitlab token"}, p.Attributes...)
+	if _, err := xr.RunWithInput(ctx, secretTool, noAllowedStatus, p.Funcs, strings.NewReader(token), args...); err != nil {
+		return withstack.Errorf("%s store failed", secretTool)
+	}
+	return nil
+}
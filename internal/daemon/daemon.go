@@ -0,0 +1,144 @@
+// Package daemon runs jobs on a schedule in the background.
+package daemon
+
+import (
+	"context"
+	"math/rand/v2"
+	"os"
+	"time"
+)
+
+// Job is one unit of work of a run.
+type Job struct {
+	Name string
+	Run  func(ctx context.Context) error
+}
+
+// Schedule spaces the runs by the interval plus or minus a random jitter,
+// so that many clients do not hit the server at the same moment.
+type Schedule struct {
+	Interval time.Duration
+	Jitter   time.Duration
+}
+
+// Next is the wait until the next run.
+func (s Schedule) Next(r *rand.Rand) time.Duration {
+	wait := s.Interval
+	if 0 < s.Jitter {
+		wait += time.Duration(r.Int64N(int64(2*s.Jitter))) - s.Jitter
+	}
+	return max(wait, 0)
+}
+
+// Daemon runs the jobs in order on each run and records the outcome in the status file.
+type Daemon struct {
+	Schedule   Schedule
+	Jobs       []Job
+	StatusPath string
+
+	// Once stops after the first run.
+	Once bool
+
+	// OnJobError is told of each failed job. The run goes on with the next job.
+	OnJobError func(name string, err error)
+
+	status *Status
+	now    func() time.Time
+	rand   *rand.Rand
+}
+
+// Run runs the jobs until the context is done.
+// It returns nil when the context is cancelled, the status records the stop.
+func (d *Daemon) Run(ctx context.Context) error {
+	if d.now == nil {
+		d.now = time.Now
+	}
+	if d.rand == nil {
+		d.rand = rand.New(rand.NewPCG(uint64(time.Now().UnixNano()), uint64(os.Getpid())))
+	}
+	d.status = &Status{
+		PID:       os.Getpid(),
+		StartedAt: d.now(),
+		Jobs:      map[string]*JobStatus{},
+	}
+	if old, err := ReadStatus(d.StatusPath); err == nil {
+		// keep the outcome of the jobs across restarts
+		for name, job := range old.Jobs {
+			d.status.Jobs[name] = job
+		}
+	}
+
+	for {
+		if err := d.runOnce(ctx); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return d.stop(Stopped)
+		}
+		if d.Once {
+			return d.stop(Finished)
+		}
+
+		wait := d.Schedule.Next(d.rand)
+		d.status.State = Waiting
+		d.status.NextRun = d.now().Add(wait)
+		if err := d.status.Write(d.StatusPath); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return d.stop(Stopped)
+		case <-timer.C:
+		}
+	}
+}
+
+func (d *Daemon) runOnce(ctx context.Context) error {
+	d.status.State = Running
+	d.status.Runs++
+	d.status.LastStart = d.now()
+	d.status.NextRun = time.Time{}
+	if err := d.status.Write(d.StatusPath); err != nil {
+		return err
+	}
+
+	for _, job := range d.Jobs {
+		if ctx.Err() != nil {
+			break
+		}
+		js, ok := d.status.Jobs[job.Name]
+		if !ok {
+			js = &JobStatus{}
+			d.status.Jobs[job.Name] = js
+		}
+		js.LastStart = d.now()
+		err := job.Run(ctx)
+		js.Elapsed = d.now().Sub(js.LastStart)
+		if err != nil {
+			if ctx.Err() == nil {
+				js.LastError = err.Error()
+				js.Failures++
+				if d.OnJobError != nil {
+					d.OnJobError(job.Name, err)
+				}
+			}
+		} else {
+			js.LastError = ""
+			js.LastSuccess = d.now()
+		}
+		if err := d.status.Write(d.StatusPath); err != nil {
+			return err
+		}
+	}
+	d.status.LastEnd = d.now()
+	return d.status.Write(d.StatusPath)
+}
+
+func (d *Daemon) stop(state State) error {
+	d.status.State = state
+	d.status.NextRun = time.Time{}
+	return d.status.Write(d.StatusPath)
+}
@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestDaemon(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "daemon_test")
+}
+
+var _ = Describe("Lock", func() {
+	It("is held by one owner at a time", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "sync.lock")
+		first, err := TryLock(path)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = TryLock(path)
+		Expect(err).To(MatchError(ErrLocked))
+		Expect(err.Error()).To(ContainSubstring("pid"))
+
+		Expect(first.Unlock()).To(Succeed())
+		Expect(first.Unlock()).To(Succeed())
+		second, err := TryLock(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.Unlock()).To(Succeed())
+	})
+})
+
+var _ = Describe("Schedule", func() {
+	It("jitters around the interval", func() {
+		s := Schedule{Interval: time.Minute, Jitter: 10 * time.Second}
+		r := rand.New(rand.NewPCG(1, 2))
+		for range 100 {
+			Expect(s.Next(r)).To(BeNumerically("~", time.Minute, 10*time.Second))
+		}
+		Expect(Schedule{Interval: time.Second, Jitter: time.Minute}.Next(r)).To(BeNumerically(">=", 0))
+		Expect(Schedule{Interval: time.Second}.Next(r)).To(Equal(time.Second))
+	})
+})
+
+var _ = Describe("Daemon", func() {
+	var statusPath string
+
+	BeforeEach(func() {
+		statusPath = filepath.Join(GinkgoT().TempDir(), "sync.status.yaml")
+	})
+
+	It("records the outcome of each job", func() {
+		var ran []string
+		var failed []string
+		d := &Daemon{
+			StatusPath: statusPath,
+			Once:       true,
+			Jobs: []Job{
+				{Name: "projects", Run: func(ctx context.Context) error {
+					ran = append(ran, "projects")
+					return errors.New("boom")
+				}},
+				{Name: "events", Run: func(ctx context.Context) error {
+					ran = append(ran, "events")
+					return nil
+				}},
+			},
+			OnJobError: func(name string, err error) { failed = append(failed, name) },
+		}
+		Expect(d.Run(context.Background())).To(Succeed())
+		Expect(ran).To(Equal([]string{"projects", "events"}))
+		Expect(failed).To(Equal([]string{"projects"}))
+
+		status, err := ReadStatus(statusPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.State).To(Equal(Finished))
+		Expect(status.Runs).To(Equal(1))
+		Expect(status.Ok()).To(BeFalse())
+		Expect(status.Jobs["projects"].LastError).To(Equal("boom"))
+		Expect(status.Jobs["projects"].Failures).To(Equal(1))
+		Expect(status.Jobs["events"].LastSuccess.IsZero()).To(BeFalse())
+
+		// a later success clears the error and keeps the failure count
+		d.Jobs[0].Run = func(ctx context.Context) error { return nil }
+		Expect(d.Run(context.Background())).To(Succeed())
+		status, err = ReadStatus(statusPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.Ok()).To(BeTrue())
+		Expect(status.Jobs["projects"].Failures).To(Equal(1))
+	})
+
+	It("runs on the schedule until cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		runs := 0
+		d := &Daemon{
+			StatusPath: statusPath,
+			Schedule:   Schedule{Interval: 10 * time.Millisecond},
+			Jobs: []Job{{Name: "tick", Run: func(ctx context.Context) error {
+				runs++
+				if runs == 3 {
+					cancel()
+				}
+				return ctx.Err()
+			}}},
+		}
+		Expect(d.Run(ctx)).To(Succeed())
+		Expect(runs).To(Equal(3))
+
+		status, err := ReadStatus(statusPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.State).To(Equal(Stopped))
+		Expect(status.Runs).To(Equal(3))
+		Expect(status.Jobs["tick"].LastError).To(BeEmpty())
+	})
+})
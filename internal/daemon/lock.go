@@ -0,0 +1,62 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// ErrLocked is returned when another process holds the lock.
+var ErrLocked = errors.New("locked by another process")
+
+// Lock is an exclusive advisory lock on a file.
+// The lock is released by the kernel when the process dies.
+type Lock struct {
+	file *os.File
+}
+
+// TryLock takes the lock without waiting and writes the pid to the file.
+func TryLock(path string) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, withstack.Errorf("open lock %s: %w", path, err)
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		owner := readOwner(file)
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%s %w %s", path, ErrLocked, owner)
+		}
+		return nil, withstack.Errorf("lock %s: %w", path, err)
+	}
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: file}, nil
+}
+
+// Unlock releases the lock. It is safe to call more than once.
+func (l *Lock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	file := l.file
+	l.file = nil
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	return errors.Join(err, file.Close())
+}
+
+func readOwner(file *os.File) string {
+	b := make([]byte, 32)
+	n, _ := file.ReadAt(b, 0)
+	pid := strings.TrimSpace(string(b[:n]))
+	if pid == "" {
+		return ""
+	}
+	return "pid " + pid
+}
@@ -0,0 +1,89 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// State of the daemon in the status file.
+type State string
+
+const (
+	Running  State = "running"
+	Waiting  State = "waiting"
+	Stopped  State = "stopped"
+	Finished State = "finished"
+)
+
+// Status is the last known state of the daemon.
+type Status struct {
+	PID       int                   `yaml:"pid"`
+	State     State                 `yaml:"state"`
+	StartedAt time.Time             `yaml:"started_at"`
+	Runs      int                   `yaml:"runs"`
+	LastStart time.Time             `yaml:"last_start,omitempty"`
+	LastEnd   time.Time             `yaml:"last_end,omitempty"`
+	NextRun   time.Time             `yaml:"next_run,omitempty"`
+	Jobs      map[string]*JobStatus `yaml:"jobs"`
+}
+
+// JobStatus is the outcome of the last run of a job.
+type JobStatus struct {
+	LastStart   time.Time     `yaml:"last_start"`
+	LastSuccess time.Time     `yaml:"last_success,omitempty"`
+	LastError   string        `yaml:"last_error,omitempty"`
+	Elapsed     time.Duration `yaml:"elapsed"`
+	Failures    int           `yaml:"failures"`
+}
+
+// Ok is true when the last run of every job succeeded.
+func (s *Status) Ok() bool {
+	for _, j := range s.Jobs {
+		if j.LastError != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadStatus reads the status file.
+func ReadStatus(path string) (*Status, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	status := &Status{}
+	if err := yaml.Unmarshal(b, status); err != nil {
+		return nil, withstack.Errorf("status %s: %w", path, err)
+	}
+	return status, nil
+}
+
+// Write replaces the status file so that readers never see a partial file.
+func (s *Status) Write(path string) error {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return withstack.Errorf("%w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	return nil
+}
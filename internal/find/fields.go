@@ -4,8 +4,6 @@ package find
 import (
 	"sort"
 	"strings"
-
-	"github.com/stalwartgiraffe/cmr/internal/utils"
 )
 
 type TextTable interface {
@@ -25,15 +23,30 @@ func getColumnKeysToLower(src TextTable) keyCols {
 	return colMap
 }
 
-func Find(rawPattern string, kvSrc TextTable) []int {
+// Find returns the rows that match the query in rawPattern, in order, see Query.
+func Find(rawPattern string, kvSrc TextTable) ([]int, error) {
+	q, err := ParseQuery(rawPattern)
+	if err != nil {
+		return nil, err
+	}
+	return q.Find(kvSrc)
+}
+
+// Find returns the rows of the table that match, in order.
+func (q *Query) Find(kvSrc TextTable) ([]int, error) {
 	src := newFindSrc(kvSrc)
-	patterns := newTerms(rawPattern)
-	skipColumns := utils.Set[int]{}
-	excluded := everyElement(src.numRows())
-	excluded = src.removeAllMatches(excluded, skipColumns, patterns)
-	rows := subtractFromAll(excluded, src.numRows())
-	sort.Ints(rows)
-	return rows
+	cols := getColumnKeysToLower(kvSrc)
+	if err := q.check(cols); err != nil {
+		return nil, err
+	}
+	rows := []int{}
+	r := &row{src: src, cols: cols}
+	for r.idx = range src.numRows() {
+		if q.root.match(r) {
+			rows = append(rows, r.idx)
+		}
+	}
+	return rows, nil
 }
 func FindOld(rawPattern string, kvSrc TextTable) []int {
 	src := newFindSrc(kvSrc)
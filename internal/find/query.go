@@ -0,0 +1,596 @@
+package find
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Query is a parsed find pattern.
+//
+//	bug fix          rows with bug and fix in any column, words match fuzzily as kmeis does karl.meissner
+//	bug OR fix       rows with either, | works too
+//	-draft           rows without draft
+//	"fix the bug"    the phrase as is
+//	/ABC-\d+/        the regexp
+//	(a | b) -c       grouping
+//	?title:fix       fuzzy match in the title column, also ?title:"a b" and ?title:/re/
+//	?upvotes>=2      typed comparison with = != > >= < <=, numbers and dates compare as such
+//	?created>2024-01-01
+type Query struct {
+	root node
+	keys []keyRef // the columns the query names, checked against the table
+}
+
+// keyRef is a column named in the query.
+type keyRef struct {
+	key string
+	pos int
+}
+
+// ParseError is a malformed query, Pos is the byte offset of the problem.
+type ParseError struct {
+	Pos int
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Pos+1, e.Msg)
+}
+
+// node is a part of a query that matches a row.
+type node interface {
+	match(r *row) bool
+	// score is how well a matching row matches, higher is better.
+	score(r *row) int
+	// spans adds where the node matches the cell of the column.
+	spans(r *row, col int, add func(Span))
+}
+
+// row is the row of the table a query is matched against.
+type row struct {
+	src  *findSrc
+	cols keyCols
+	idx  int
+}
+
+func (r *row) cell(col int) string {
+	return r.src.kvSrc.GetCell(r.idx, col)
+}
+
+// anyCell is true when f is true for a cell of the row.
+func (r *row) anyCell(f func(string) bool) bool {
+	for col := range r.src.kvSrc.GetColumnCount() {
+		if f(r.cell(col)) {
+			return true
+		}
+	}
+	return false
+}
+
+// fuzzyMatch returns true if the letters of the value are in the cell in order.
+func (r *row) fuzzyMatch(value, cell string) bool {
+	return 0 < r.src.findNoSort(value, []string{cell}).Len()
+}
+
+// ParseQuery parses the pattern, an empty pattern matches every row.
+func ParseQuery(rawPattern string) (*Query, error) {
+	p := &parser{lex: lexer{src: rawPattern}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	q := &Query{}
+	if p.tok.kind == tokEOF {
+		q.root = andNode{}
+		return q, nil
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	q.root = root
+	q.keys = p.keys
+	return q, nil
+}
+
+// check returns an error for a column the table does not have.
+func (q *Query) check(cols keyCols) error {
+	for _, k := range q.keys {
+		if _, ok := cols[strings.ToLower(k.key)]; !ok {
+			return &ParseError{Pos: k.pos, Msg: fmt.Sprintf("unknown column %q", k.key)}
+		}
+	}
+	return nil
+}
+
+type andNode []node
+
+func (n andNode) match(r *row) bool {
+	for _, c := range n {
+		if !c.match(r) {
+			return false
+		}
+	}
+	return true
+}
+
+type orNode []node
+
+func (n orNode) match(r *row) bool {
+	for _, c := range n {
+		if c.match(r) {
+			return true
+		}
+	}
+	return false
+}
+
+type notNode struct {
+	n node
+}
+
+func (n notNode) match(r *row) bool {
+	return !n.n.match(r)
+}
+
+// textNode matches a word fuzzily or a phrase as is in any column, ignoring case.
+type textNode struct {
+	value  string
+	phrase bool
+}
+
+func (n textNode) match(r *row) bool {
+	if n.phrase {
+		return r.anyCell(func(cell string) bool { return containsFold(cell, n.value) })
+	}
+	return r.anyCell(func(cell string) bool { return r.fuzzyMatch(n.value, cell) })
+}
+
+// regexpNode matches a regexp in any column.
+type regexpNode struct {
+	re *regexp.Regexp
+}
+
+func (n regexpNode) match(r *row) bool {
+	return r.anyCell(n.re.MatchString)
+}
+
+// valueKind is how the value of a comparison compares.
+type valueKind int
+
+const (
+	textValue valueKind = iota
+	numberValue
+	dateValue
+)
+
+// dateLayouts are the layouts dates are read in, from query values and cells.
+var dateLayouts = []string{time.RFC3339, time.DateTime, "2006-01-02 15:04", "2006-01-02T15:04", time.DateOnly}
+
+// keyNode compares the cell of one column.
+type keyNode struct {
+	key    string
+	op     string
+	value  string
+	phrase bool
+	re     *regexp.Regexp
+	kind   valueKind
+	number float64
+	date   time.Time
+}
+
+func (n *keyNode) match(r *row) bool {
+	col, ok := r.cols[strings.ToLower(n.key)]
+	if !ok {
+		return false
+	}
+	cell := r.cell(col)
+	if n.op == ":" {
+		switch {
+		case n.re != nil:
+			return n.re.MatchString(cell)
+		case n.phrase:
+			return containsFold(cell, n.value)
+		default:
+			// the fuzzy match of the original ?key:val
+			return r.fuzzyMatch(n.value, cell)
+		}
+	}
+	c, ok := n.compare(cell)
+	if !ok {
+		return false
+	}
+	switch n.op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return 0 < c
+	case ">=":
+		return 0 <= c
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// compare returns how the cell compares to the value, false when the cell is not of its kind.
+func (n *keyNode) compare(cell string) (int, bool) {
+	cell = strings.TrimSpace(cell)
+	switch n.kind {
+	case numberValue:
+		v, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return 0, false
+		}
+		switch {
+		case v < n.number:
+			return -1, true
+		case v > n.number:
+			return 1, true
+		}
+		return 0, true
+	case dateValue:
+		t, ok := parseDate(cell)
+		if !ok {
+			return 0, false
+		}
+		return t.Compare(n.date), true
+	}
+	return strings.Compare(strings.ToLower(cell), strings.ToLower(n.value)), true
+}
+
+func parseDate(s string) (time.Time, bool) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// containsFold is true when sub is in txt, ignoring case.
+func containsFold(txt, sub string) bool {
+	if sub == "" {
+		return true
+	}
+	for start := 0; start < len(txt); {
+		if ok, _ := utfContainsAtFold(txt, sub, start); ok {
+			return true
+		}
+		_, width := utf8.DecodeRuneInString(txt[start:])
+		start += width
+	}
+	return false
+}
+
+// parser is recursive descent over the tokens.
+//
+//	or      = and { ("OR" | "|") and }
+//	and     = unary { unary }
+//	unary   = "-" unary | primary
+//	primary = "(" or ")" | word | phrase | regexp | key
+type parser struct {
+	lex  lexer
+	tok  token
+	keys []keyRef
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return &ParseError{Pos: p.tok.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *parser) parseOr() (node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := orNode{first}
+	for p.tok.kind == tokOr {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return nodes, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	var nodes andNode
+	for p.tok.kind != tokEOF && p.tok.kind != tokOr && p.tok.kind != tokClose {
+		n, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, n)
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, p.errorf("expected a term before %s", p.tok)
+	case 1:
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.tok.kind != tokNot {
+		return p.parsePrimary()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	n, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return notNode{n}, nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokOpen:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokClose {
+			return nil, &ParseError{Pos: tok.pos, Msg: "missing ) for this ("}
+		}
+		return n, p.advance()
+	case tokWord, tokPhrase:
+		return textNode{value: tok.text, phrase: tok.kind == tokPhrase}, p.advance()
+	case tokRegexp:
+		re, err := compileRegexp(tok)
+		if err != nil {
+			return nil, err
+		}
+		return regexpNode{re}, p.advance()
+	case tokKey:
+		n, err := p.keyNode(tok)
+		if err != nil {
+			return nil, err
+		}
+		return n, p.advance()
+	}
+	return nil, p.errorf("unexpected %s", tok)
+}
+
+func (p *parser) keyNode(tok token) (node, error) {
+	n := &keyNode{key: tok.key, op: tok.op, value: tok.text}
+	p.keys = append(p.keys, keyRef{key: tok.key, pos: tok.pos})
+	switch tok.valueKind {
+	case tokRegexp:
+		if tok.op != ":" {
+			return nil, &ParseError{Pos: tok.pos, Msg: fmt.Sprintf("?%s%s cannot compare with a regexp, use ?%s:/re/", tok.key, tok.op, tok.key)}
+		}
+		re, err := compileRegexp(tok)
+		if err != nil {
+			return nil, err
+		}
+		n.re = re
+		return n, nil
+	case tokPhrase:
+		n.phrase = true
+		return n, nil
+	}
+	if tok.op == ":" {
+		return n, nil
+	}
+	if v, err := strconv.ParseFloat(n.value, 64); err == nil {
+		n.kind, n.number = numberValue, v
+	} else if t, ok := parseDate(n.value); ok {
+		n.kind, n.date = dateValue, t
+	}
+	return n, nil
+}
+
+func compileRegexp(tok token) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(tok.text)
+	if err != nil {
+		return nil, &ParseError{Pos: tok.pos, Msg: "bad regexp: " + err.Error()}
+	}
+	return re, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokWord
+	tokPhrase
+	tokRegexp
+	tokKey
+	tokOr
+	tokNot
+	tokOpen
+	tokClose
+)
+
+type token struct {
+	kind tokKind
+	pos  int
+	text string // of a word, phrase or regexp, the value of a key
+
+	// of a key
+	key       string
+	op        string
+	valueKind tokKind // tokWord, tokPhrase or tokRegexp
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "-"
+	case tokOpen:
+		return "("
+	case tokClose:
+		return ")"
+	case tokKey:
+		return fmt.Sprintf("?%s%s%s", t.key, t.op, t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// lexer splits the query into tokens, whitespace separates them.
+type lexer struct {
+	src   string
+	pos   int
+	depth int // of open (, a ) ends a word only inside one
+}
+
+// compareOps are the operators of a key, longest first.
+var compareOps = []string{">=", "<=", "!=", ":", "=", ">", "<"}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) && isSpace(l.src[l.pos]) {
+		l.pos++
+	}
+	start := l.pos
+	if len(l.src) <= start {
+		return token{kind: tokEOF, pos: start}, nil
+	}
+	switch c := l.src[start]; {
+	case c == '(':
+		l.pos++
+		l.depth++
+		return token{kind: tokOpen, pos: start}, nil
+	case c == ')':
+		l.pos++
+		l.depth = max(0, l.depth-1)
+		return token{kind: tokClose, pos: start}, nil
+	case c == '|':
+		l.pos++
+		return token{kind: tokOr, pos: start}, nil
+	case c == '-' && start+1 < len(l.src) && !isSpace(l.src[start+1]):
+		l.pos++
+		return token{kind: tokNot, pos: start}, nil
+	case c == '"':
+		text, err := l.delimited('"', "quote")
+		return token{kind: tokPhrase, pos: start, text: text}, err
+	case c == '/' && start+1 < len(l.src) && !isSpace(l.src[start+1]):
+		text, err := l.delimited('/', "regexp")
+		return token{kind: tokRegexp, pos: start, text: text}, err
+	case c == '?' && start+1 < len(l.src) && !isSpace(l.src[start+1]):
+		return l.key()
+	}
+	word := l.word()
+	if word == "OR" {
+		return token{kind: tokOr, pos: start}, nil
+	}
+	return token{kind: tokWord, pos: start, text: word}, nil
+}
+
+// key reads ?key op value.
+func (l *lexer) key() (token, error) {
+	start := l.pos
+	l.pos++ // the ?
+	keyStart := l.pos
+	for l.pos < len(l.src) && !strings.ContainsRune(":=!<>", rune(l.src[l.pos])) && !isSpace(l.src[l.pos]) {
+		l.pos++
+	}
+	tok := token{kind: tokKey, pos: start, key: l.src[keyStart:l.pos], valueKind: tokWord}
+	if tok.key == "" {
+		return tok, &ParseError{Pos: start, Msg: "missing column name after ?"}
+	}
+	for _, op := range compareOps {
+		if strings.HasPrefix(l.src[l.pos:], op) {
+			tok.op = op
+			l.pos += len(op)
+			break
+		}
+	}
+	if tok.op == "" {
+		return tok, &ParseError{Pos: l.pos, Msg: fmt.Sprintf("expected one of %s after ?%s", strings.Join(compareOps, " "), tok.key)}
+	}
+	var err error
+	switch {
+	case l.pos < len(l.src) && l.src[l.pos] == '"':
+		tok.valueKind = tokPhrase
+		tok.text, err = l.delimited('"', "quote")
+	case l.pos < len(l.src) && l.src[l.pos] == '/':
+		tok.valueKind = tokRegexp
+		tok.text, err = l.delimited('/', "regexp")
+	default:
+		tok.text = l.word()
+	}
+	if err == nil && tok.text == "" && tok.valueKind == tokWord {
+		err = &ParseError{Pos: l.pos, Msg: fmt.Sprintf("missing value after ?%s%s", tok.key, tok.op)}
+	}
+	return tok, err
+}
+
+// delimited reads the text between the delimiters at pos, a backslash escapes a delimiter.
+func (l *lexer) delimited(delim byte, what string) (string, error) {
+	start := l.pos
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\\' && l.pos+1 < len(l.src) && l.src[l.pos+1] == delim:
+			b.WriteByte(delim)
+			l.pos += 2
+			continue
+		case c == delim:
+			l.pos++
+			return b.String(), nil
+		}
+		b.WriteByte(c)
+		l.pos++
+	}
+	return "", &ParseError{Pos: start, Msg: "unterminated " + what}
+}
+
+// word reads up to the next space, or ) inside a group.
+func (l *lexer) word() string {
+	end := l.wordEnd()
+	word := l.src[l.pos:end]
+	l.pos = end
+	return word
+}
+
+func (l *lexer) wordEnd() int {
+	end := l.pos
+	for end < len(l.src) && !isSpace(l.src[end]) && (l.depth == 0 || l.src[end] != ')') {
+		end++
+	}
+	return end
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
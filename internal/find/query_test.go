@@ -0,0 +1,185 @@
+package find
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	mocks "github.com/stalwartgiraffe/cmr/internal/find/fixtures"
+)
+
+func newQueryTable() *mocks.Table {
+	return &mocks.Table{
+		Keys: []string{"ID", "Author", "Title", "Upvotes", "Created"},
+		Values: [][]string{
+			{"1", "karl.meissner", "ABC-12 fix the login bug", "3", "2024-03-01T10:00:00Z"},
+			{"2", "annie", "feat(ui): add dark mode", "0", "2023-12-24T08:30:00Z"},
+			{"3", "bob", "Draft: ABC-7 bump deps", "2", "2024-01-01T00:00:00Z"},
+			{"4", "annie", "fix flaky test", "10", "2024-06-15 09:00"},
+		},
+	}
+}
+
+func TestFindQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []int
+	}{
+		{name: "empty matches all", pattern: "", expected: []int{0, 1, 2, 3}},
+		{name: "blank matches all", pattern: "   ", expected: []int{0, 1, 2, 3}},
+		{name: "word in any column", pattern: "annie", expected: []int{1, 3}},
+		{name: "words are anded", pattern: "fix annie", expected: []int{3}},
+		{name: "case is ignored", pattern: "FIX", expected: []int{0, 3}},
+		{name: "or", pattern: "dark OR bump", expected: []int{1, 2}},
+		{name: "pipe is or", pattern: "dark | bump", expected: []int{1, 2}},
+		{name: "and binds tighter than or", pattern: "fix annie | bump", expected: []int{2, 3}},
+		{name: "not", pattern: "-annie", expected: []int{0, 2}},
+		{name: "not of a group", pattern: "-(annie | bump)", expected: []int{0}},
+		{name: "group", pattern: "(login | dark) -annie", expected: []int{0}},
+		{name: "words are fuzzy", pattern: "kmeis", expected: []int{0}},
+		{name: "phrase", pattern: `"fix the"`, expected: []int{0}},
+		{name: "phrase is not fuzzy", pattern: `"kmeis"`, expected: []int{}},
+		{name: "phrase keeps order", pattern: `"the fix"`, expected: []int{}},
+		{name: "regexp", pattern: `/ABC-\d+/`, expected: []int{0, 2}},
+		{name: "regexp is case sensitive", pattern: `/abc-\d+/`, expected: []int{}},
+		{name: "regexp with an escaped slash", pattern: `/\/x/`, expected: []int{}},
+		{name: "parens in a word", pattern: "feat(ui):", expected: []int{1}},
+		{name: "key fuzzy match", pattern: "?author:kmeis", expected: []int{0}},
+		{name: "key is case insensitive", pattern: "?AUTHOR:annie", expected: []int{1, 3}},
+		{name: "keys are anded", pattern: "?author:annie ?title:flaky", expected: []int{3}},
+		{name: "key phrase", pattern: `?title:"dark mode"`, expected: []int{1}},
+		{name: "key regexp", pattern: `?title:/^Draft:/`, expected: []int{2}},
+		{name: "number greater", pattern: "?upvotes>2", expected: []int{0, 3}},
+		{name: "number at least", pattern: "?upvotes>=2", expected: []int{0, 2, 3}},
+		{name: "number compares as a number", pattern: "?upvotes<3", expected: []int{1, 2}},
+		{name: "number equals", pattern: "?upvotes=10", expected: []int{3}},
+		{name: "number not equals", pattern: "?upvotes!=0", expected: []int{0, 2, 3}},
+		{name: "date after", pattern: "?created>2024-01-01", expected: []int{0, 3}},
+		{name: "date on or before", pattern: "?created<=2024-01-01", expected: []int{1, 2}},
+		{name: "text equals", pattern: "?author=ANNIE", expected: []int{1, 3}},
+		{name: "text compares", pattern: "?author<b", expected: []int{1, 3}},
+		{name: "comparison with or", pattern: "?upvotes>5 | ?author=bob", expected: []int{2, 3}},
+		{name: "not a comparison", pattern: "-?upvotes>=2", expected: []int{1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rows, err := Find(tt.pattern, newQueryTable())
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, rows)
+		})
+	}
+}
+
+func TestFindQueryErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		pattern string
+		pos     int
+		msg     string
+	}{
+		{name: "unterminated quote", pattern: `fix "the bug`, pos: 4, msg: "unterminated quote"},
+		{name: "unterminated regexp", pattern: `/ABC`, pos: 0, msg: "unterminated regexp"},
+		{name: "bad regexp", pattern: `x /a(/`, pos: 2, msg: "bad regexp"},
+		{name: "missing close", pattern: `(a | b`, pos: 0, msg: "missing )"},
+		{name: "unexpected close", pattern: `a )`, pos: 2, msg: "unexpected )"},
+		{name: "dangling or", pattern: `a OR`, pos: 4, msg: "expected a term"},
+		{name: "leading or", pattern: `| a`, pos: 0, msg: "expected a term"},
+		{name: "empty group", pattern: `()`, pos: 1, msg: "expected a term"},
+		{name: "missing operator", pattern: `?title`, pos: 6, msg: "expected one of"},
+		{name: "missing column", pattern: `?:x`, pos: 0, msg: "missing column name"},
+		{name: "missing value", pattern: `?title:`, pos: 7, msg: "missing value"},
+		{name: "regexp comparison", pattern: `?title>/a/`, pos: 0, msg: "cannot compare with a regexp"},
+		{name: "unknown column", pattern: `fix ?nope:x`, pos: 4, msg: `unknown column "nope"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rows, err := Find(tt.pattern, newQueryTable())
+			require.Nil(t, rows)
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr), "expected a ParseError, got %v", err)
+			require.Equal(t, tt.pos, parseErr.Pos)
+			require.Contains(t, parseErr.Msg, tt.msg)
+		})
+	}
+}
+
+func TestTableViewKeepsRowsOnError(t *testing.T) {
+	view := NewTableView(newQueryTable())
+	require.NoError(t, view.UpdateFind("annie"))
+	require.Equal(t, 2, view.GetRowCount())
+	require.Error(t, view.UpdateFind(`annie "unterminated`))
+	require.Equal(t, 2, view.GetRowCount())
+	require.Equal(t, 3, view.GetRowIndex(1))
+}
+
+func FuzzFindQuery(f *testing.F) {
+	for _, seed := range []string{
+		"", "fix", "fix OR bug", "-draft", `"the bug"`, `/ABC-\d+/`, "(a | b) -c",
+		"?title:fix", `?title:"a b"`, `?title:/^x/`, "?upvotes>=2", "?created>2024-01-01",
+		`fix "the`, "((a)", "a )", "?x", "-", "?", `/`, `\"`,
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, pattern string) {
+		table := newQueryTable()
+		rows, err := Find(pattern, table)
+		if err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("expected a ParseError, got %v", err)
+			}
+			if parseErr.Pos < 0 || len(pattern) < parseErr.Pos {
+				t.Fatalf("error at %d is outside the pattern %q", parseErr.Pos, pattern)
+			}
+			return
+		}
+		if !slices.IsSorted(rows) || len(slices.Compact(slices.Clone(rows))) != len(rows) {
+			t.Fatalf("expected sorted unique rows, got %v", rows)
+		}
+		for _, r := range rows {
+			if r < 0 || table.GetRowCount() <= r {
+				t.Fatalf("row %d is outside the table", r)
+			}
+		}
+		// the matches are ordered spans inside their cell
+		view := NewTableView(table)
+		view.SetRanked(true)
+		if err := view.UpdateFind(pattern); err != nil {
+			t.Fatal(err)
+		}
+		for row := range view.GetRowCount() {
+			for col := range table.GetColumnCount() {
+				end := 0
+				for _, s := range view.GetMatchSpans(row, col) {
+					if s.Start < end || s.End <= s.Start || len(view.GetCell(row, col)) < s.End {
+						t.Fatalf("%q has span %v in %q", pattern, s, view.GetCell(row, col))
+					}
+					end = s.End
+				}
+			}
+		}
+		// a query and its negation split the table
+		q, err := ParseQuery(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		negated, err := (&Query{root: notNode{q.root}, keys: q.keys}).Find(table)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows)+len(negated) != table.GetRowCount() {
+			t.Fatalf("%q has %v and its negation %v", pattern, rows, negated)
+		}
+	})
+}
@@ -0,0 +1,242 @@
+package find
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Span is the bytes [Start, End) of a cell a query matched.
+type Span struct {
+	Start, End int
+}
+
+// matchScore is the score of a term that matched, the closeness of the match is added to it.
+const matchScore = 100
+
+// wordStartBonus is added when a term matches at the start of a word.
+const wordStartBonus = 20
+
+func (n andNode) score(r *row) int {
+	total := 0
+	for _, c := range n {
+		total += c.score(r)
+	}
+	return total
+}
+
+func (n andNode) spans(r *row, col int, add func(Span)) {
+	for _, c := range n {
+		c.spans(r, col, add)
+	}
+}
+
+func (n orNode) score(r *row) int {
+	best := 0
+	for _, c := range n {
+		if c.match(r) {
+			best = max(best, c.score(r))
+		}
+	}
+	return best
+}
+
+func (n orNode) spans(r *row, col int, add func(Span)) {
+	for _, c := range n {
+		if c.match(r) {
+			c.spans(r, col, add)
+		}
+	}
+}
+
+// score of a negation is nothing, it matched by what is missing.
+func (n notNode) score(r *row) int {
+	return 0
+}
+
+func (n notNode) spans(r *row, col int, add func(Span)) {}
+
+func (n textNode) score(r *row) int {
+	best := 0
+	for col := range r.src.kvSrc.GetColumnCount() {
+		if n.phrase {
+			best = max(best, textScore(r.cell(col), n.value))
+		} else {
+			best = max(best, fuzzyScore(r, r.cell(col), n.value))
+		}
+	}
+	return best
+}
+
+func (n textNode) spans(r *row, col int, add func(Span)) {
+	if n.phrase {
+		for _, s := range indexFold(r.cell(col), n.value) {
+			add(s)
+		}
+		return
+	}
+	fuzzySpans(r, r.cell(col), n.value, add)
+}
+
+func (n regexpNode) score(r *row) int {
+	best := 0
+	for col := range r.src.kvSrc.GetColumnCount() {
+		best = max(best, regexpScore(r.cell(col), n.re))
+	}
+	return best
+}
+
+func (n regexpNode) spans(r *row, col int, add func(Span)) {
+	regexpSpans(r.cell(col), n.re, add)
+}
+
+func (n *keyNode) score(r *row) int {
+	col := r.cols[strings.ToLower(n.key)]
+	cell := r.cell(col)
+	switch {
+	case n.op != ":":
+		return matchScore
+	case n.re != nil:
+		return regexpScore(cell, n.re)
+	case n.phrase:
+		return textScore(cell, n.value)
+	}
+	return fuzzyScore(r, cell, n.value)
+}
+
+func (n *keyNode) spans(r *row, col int, add func(Span)) {
+	if col != r.cols[strings.ToLower(n.key)] {
+		return
+	}
+	cell := r.cell(col)
+	switch {
+	case n.op != ":":
+		add(Span{0, len(cell)})
+	case n.re != nil:
+		regexpSpans(cell, n.re, add)
+	case n.phrase:
+		for _, s := range indexFold(cell, n.value) {
+			add(s)
+		}
+	default:
+		fuzzySpans(r, cell, n.value, add)
+	}
+}
+
+// fuzzyScore is the score of the fuzzy match of the value in the cell, 0 without a match.
+func fuzzyScore(r *row, cell, value string) int {
+	m := r.src.findNoSort(value, []string{cell})
+	if m.Len() == 0 {
+		return 0
+	}
+	return max(1, matchScore+m[0].Score)
+}
+
+// fuzzySpans adds the runes of the cell that the fuzzy match of the value matched.
+func fuzzySpans(r *row, cell, value string, add func(Span)) {
+	m := r.src.findNoSort(value, []string{cell})
+	if m.Len() == 0 {
+		return
+	}
+	for _, i := range m[0].MatchedIndexes {
+		_, width := utf8.DecodeRuneInString(cell[i:])
+		add(Span{i, i + width})
+	}
+}
+
+// textScore is higher the more of the cell sub covers, and when it starts a word.
+func textScore(cell, sub string) int {
+	best := 0
+	for _, s := range indexFold(cell, sub) {
+		score := matchScore + matchScore*len(sub)/len(cell)
+		if isWordStart(cell, s.Start) {
+			score += wordStartBonus
+		}
+		best = max(best, score)
+	}
+	return best
+}
+
+func regexpScore(cell string, re *regexp.Regexp) int {
+	loc := re.FindStringIndex(cell)
+	if loc == nil {
+		return 0
+	}
+	if len(cell) == 0 {
+		return matchScore
+	}
+	return matchScore + matchScore*(loc[1]-loc[0])/len(cell)
+}
+
+func regexpSpans(cell string, re *regexp.Regexp, add func(Span)) {
+	for _, loc := range re.FindAllStringIndex(cell, -1) {
+		if loc[0] < loc[1] {
+			add(Span{loc[0], loc[1]})
+		}
+	}
+}
+
+// indexFold returns where sub is in txt, ignoring case.
+func indexFold(txt, sub string) []Span {
+	if sub == "" {
+		return nil
+	}
+	var spans []Span
+	for start := 0; start < len(txt); {
+		if ok, end := utfContainsAtFold(txt, sub, start); ok {
+			spans = append(spans, Span{start, end})
+			start = end
+			continue
+		}
+		_, width := utf8.DecodeRuneInString(txt[start:])
+		start += width
+	}
+	return spans
+}
+
+// isWordStart is true at the start of the text or after a separator.
+func isWordStart(txt string, i int) bool {
+	if i == 0 {
+		return true
+	}
+	c := txt[i-1]
+	return !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9')
+}
+
+// Score returns how well the row of the table matches, 0 when it does not.
+func (q *Query) Score(kvSrc TextTable, idx int) int {
+	r := &row{src: newFindSrc(kvSrc), cols: getColumnKeysToLower(kvSrc), idx: idx}
+	if !q.root.match(r) {
+		return 0
+	}
+	return max(1, q.root.score(r))
+}
+
+// Spans returns where the query matches the cell, sorted with the overlaps merged.
+func (q *Query) Spans(kvSrc TextTable, idx, col int) []Span {
+	r := &row{src: newFindSrc(kvSrc), cols: getColumnKeysToLower(kvSrc), idx: idx}
+	var spans []Span
+	q.root.spans(r, col, func(s Span) {
+		spans = append(spans, s)
+	})
+	return mergeSpans(spans)
+}
+
+// mergeSpans sorts the spans and joins the ones that overlap or touch.
+func mergeSpans(spans []Span) []Span {
+	if len(spans) < 2 {
+		return spans
+	}
+	slices.SortFunc(spans, func(a, b Span) int { return a.Start - b.Start })
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if s.Start <= last.End {
+			last.End = max(last.End, s.End)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
@@ -0,0 +1,76 @@
+package find
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableViewRanked(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pattern  string
+		ranked   []int
+		unranked []int
+	}{
+		{name: "more of the cell matched ranks first", pattern: "fix", ranked: []int{3, 0}, unranked: []int{0, 3}},
+		{name: "ties keep table order", pattern: "annie", ranked: []int{1, 3}, unranked: []int{1, 3}},
+		{name: "empty keeps table order", pattern: "", ranked: []int{0, 1, 2, 3}, unranked: []int{0, 1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			view := NewTableView(newQueryTable())
+			view.SetRanked(true)
+			require.NoError(t, view.UpdateFind(tt.pattern))
+			require.Equal(t, tt.ranked, viewRows(view))
+
+			view.SetRanked(false)
+			require.Equal(t, tt.unranked, viewRows(view))
+		})
+	}
+}
+
+func viewRows(view *TableView) []int {
+	rows := []int{}
+	for row := range view.GetRowCount() {
+		rows = append(rows, view.GetRowIndex(row))
+	}
+	return rows
+}
+
+func TestMatchSpans(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		pattern  string
+		col      int
+		expected []Span
+	}{
+		{name: "word", pattern: "fix", col: 2, expected: []Span{{7, 10}}},
+		{name: "word in another column", pattern: "fix", col: 1, expected: nil},
+		{name: "fuzzy word", pattern: "kmeis", col: 1, expected: []Span{{0, 1}, {5, 9}}},
+		{name: "overlaps are merged", pattern: "gin log", col: 2, expected: []Span{{15, 20}}},
+		{name: "phrase", pattern: `"the login"`, col: 2, expected: []Span{{11, 20}}},
+		{name: "regexp", pattern: `/ABC-\d+/`, col: 2, expected: []Span{{0, 6}}},
+		{name: "fuzzy key", pattern: "?author:kmeis", col: 1, expected: []Span{{0, 1}, {5, 9}}},
+		{name: "key only in its column", pattern: "?author:kmeis", col: 2, expected: nil},
+		{name: "comparison is the whole cell", pattern: "?upvotes>2", col: 3, expected: []Span{{0, 1}}},
+		{name: "or of the branches that matched", pattern: "fix | bump", col: 2, expected: []Span{{7, 10}}},
+		{name: "not has no spans", pattern: "-annie", col: 1, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			view := NewTableView(newQueryTable())
+			require.NoError(t, view.UpdateFind(tt.pattern))
+			require.Equal(t, 0, view.GetRowIndex(0))
+			require.Equal(t, tt.expected, view.GetMatchSpans(0, tt.col))
+		})
+	}
+}
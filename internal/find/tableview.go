@@ -1,8 +1,12 @@
 package find
 
+import "slices"
+
 type TableView struct {
-	table TextTable
-	ref   []int
+	table  TextTable
+	ref    []int
+	query  *Query
+	ranked bool
 }
 
 func NewTableView(table TextTable) *TableView {
@@ -13,8 +17,71 @@ func NewTableView(table TextTable) *TableView {
 	}
 }
 
-func (v *TableView) UpdateFind(rawPattern string) {
-	v.ref = Find(rawPattern, v.table)
+// UpdateFind shows the rows that match the query in rawPattern.
+// A malformed query keeps the rows shown and returns a ParseError.
+func (v *TableView) UpdateFind(rawPattern string) error {
+	q, err := ParseQuery(rawPattern)
+	if err != nil {
+		return err
+	}
+	rows, err := q.Find(v.table)
+	if err != nil {
+		return err
+	}
+	v.query = q
+	v.ref = rows
+	v.rank()
+	return nil
+}
+
+// Refresh finds the query again after the rows or columns of the table change.
+// A query of a column that is gone is cleared, all the rows show and the error is returned,
+// as the rows found before may have moved.
+func (v *TableView) Refresh() error {
+	if v.query == nil {
+		v.ref = everyElement(v.table.GetRowCount())
+		return nil
+	}
+	rows, err := v.query.Find(v.table)
+	if err != nil {
+		v.query = nil
+		v.ref = everyElement(v.table.GetRowCount())
+		return err
+	}
+	v.ref = rows
+	v.rank()
+	return nil
+}
+
+// SetRanked shows the best matches first when ranked, otherwise the rows in table order.
+func (v *TableView) SetRanked(ranked bool) {
+	v.ranked = ranked
+	if ranked {
+		v.rank()
+	} else {
+		slices.Sort(v.ref)
+	}
+}
+
+// rank sorts the rows by how well they match, the ties stay in table order.
+func (v *TableView) rank() {
+	if !v.ranked || v.query == nil {
+		return
+	}
+	scores := make(map[int]int, len(v.ref))
+	for _, idx := range v.ref {
+		scores[idx] = v.query.Score(v.table, idx)
+	}
+	slices.Sort(v.ref)
+	slices.SortStableFunc(v.ref, func(a, b int) int { return scores[b] - scores[a] })
+}
+
+// GetMatchSpans returns where the query matched the cell of the view, nil before a find.
+func (v *TableView) GetMatchSpans(row int, col int) []Span {
+	if v.query == nil {
+		return nil
+	}
+	return v.query.Spans(v.table, v.ref[row], col)
 }
 
 func (v *TableView) GetColumnCount() int {
@@ -31,3 +98,8 @@ func (v *TableView) GetRowCount() int {
 func (v *TableView) GetCell(row int, col int) string {
 	return v.table.GetCell(v.ref[row], col)
 }
+
+// GetRowIndex returns the index in the table of the row of the view.
+func (v *TableView) GetRowIndex(row int) int {
+	return v.ref[row]
+}
@@ -0,0 +1,293 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stalwartgiraffe/cmr/internal/store"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+const (
+	// CacheDir is the directory of the cache database.
+	CacheDir = "ignore"
+
+	// MyEventsFile names the cache of the events of the user.
+	MyEventsFile = CacheDir + "/my_recent_events.yaml"
+	// MyMergeRequestsFile names the cache of the merge requests of the user.
+	MyMergeRequestsFile = CacheDir + "/my_recent_merge_request.yaml"
+
+	cacheFile    = "cmr.db"
+	projectsYaml = "projects.yaml"
+)
+
+// ProjectEventsFile names the cache of the events of the project.
+func ProjectEventsFile(id int) string {
+	return fmt.Sprintf("%s/project_%d_events.yaml", CacheDir, id)
+}
+
+// Cache is the local store of the gitlab entities fetched so far.
+// Each list is a scope named after the yaml file that used to hold it,
+// so ignore/project_7_events.yaml is the scope project_7_events.
+type Cache struct {
+	store *store.Store
+	dir   string
+}
+
+var caches = struct {
+	sync.Mutex
+	open map[string]*Cache
+}{open: map[string]*Cache{}}
+
+// OpenCache opens the cache of the directory once per process.
+// The first open imports the yaml files of earlier versions.
+func OpenCache(ctx context.Context, dir string) (*Cache, error) {
+	caches.Lock()
+	defer caches.Unlock()
+	if c, ok := caches.open[dir]; ok {
+		return c, nil
+	}
+
+	if _, err := os.Stat(dir); err != nil {
+		return nil, withstack.Errorf("cache dir: %w", err)
+	}
+	s, err := store.Open(ctx, filepath.Join(dir, cacheFile))
+	if err != nil {
+		return nil, err
+	}
+	c := &Cache{store: s, dir: dir}
+	if err := c.importYaml(ctx); err != nil {
+		s.Close()
+		return nil, err
+	}
+	caches.open[dir] = c
+	return c, nil
+}
+
+// openCacheOf opens the cache that replaced the yaml file and returns the scope of the file.
+func openCacheOf(ctx context.Context, yamlPath string) (*Cache, string, error) {
+	c, err := OpenCache(ctx, filepath.Dir(yamlPath))
+	if err != nil {
+		return nil, "", err
+	}
+	return c, cacheScope(yamlPath), nil
+}
+
+func cacheScope(yamlPath string) string {
+	return strings.TrimSuffix(filepath.Base(yamlPath), filepath.Ext(yamlPath))
+}
+
+// Close closes the database. The next OpenCache of the directory opens it again.
+func (c *Cache) Close() error {
+	caches.Lock()
+	defer caches.Unlock()
+	if caches.open[c.dir] == c {
+		delete(caches.open, c.dir)
+	}
+	return c.store.Close()
+}
+
+// Projects returns all cached projects.
+func (c *Cache) Projects(ctx context.Context) ([]ProjectModel, error) {
+	return all[ProjectModel](ctx, c.store, store.Projects, "")
+}
+
+func (c *Cache) PutProjects(ctx context.Context, projects []ProjectModel) error {
+	return put(ctx, c.store, store.Projects, "", projects, projectRecord)
+}
+
+// Events returns the cached events of the scope.
+func (c *Cache) Events(ctx context.Context, scope string) (EventMap, error) {
+	events, err := all[EventModel](ctx, c.store, store.Events, scope)
+	if err != nil {
+		return nil, err
+	}
+	return NewEventMapFromSlice(events), nil
+}
+
+func (c *Cache) PutEvents(ctx context.Context, scope string, events []EventModel) error {
+	return put(ctx, c.store, store.Events, scope, events, eventRecord)
+}
+
+// MergeRequests returns the cached merge requests of the scope.
+func (c *Cache) MergeRequests(ctx context.Context, scope string) (MergeRequestMap, error) {
+	requests, err := all[MergeRequestModel](ctx, c.store, store.MergeRequests, scope)
+	if err != nil {
+		return nil, err
+	}
+	return NewMergeRequestMapFromSlice(requests), nil
+}
+
+func (c *Cache) PutMergeRequests(ctx context.Context, scope string, requests []MergeRequestModel) error {
+	return put(ctx, c.store, store.MergeRequests, scope, requests, mergeRequestRecord)
+}
+
+// Watermark is the latest updated_at of the scope, the zero time when nothing is cached.
+// Events are never updated so their watermark is the latest created_at.
+func (c *Cache) Watermark(ctx context.Context, kind store.Kind, scope string) (time.Time, error) {
+	return c.store.Watermark(ctx, kind, scope)
+}
+
+// Watermark is the cache watermark of the list that was kept in the yaml file.
+func Watermark(ctx context.Context, kind store.Kind, yamlPath string) (time.Time, error) {
+	c, scope, err := openCacheOf(ctx, yamlPath)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return c.Watermark(ctx, kind, scope)
+}
+
+// importYaml imports each yaml file of earlier versions the first time it is seen.
+func (c *Cache) importYaml(ctx context.Context) error {
+	if err := importOnce(ctx, c.store, filepath.Join(c.dir, projectsYaml), store.Projects,
+		ReadProjectsSlice, projectRecord); err != nil {
+		return err
+	}
+
+	eventFiles, err := filepath.Glob(filepath.Join(c.dir, "*_events.yaml"))
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	for _, path := range eventFiles {
+		if err := importOnce(ctx, c.store, path, store.Events,
+			readYaml[EventModel], eventRecord); err != nil {
+			return err
+		}
+	}
+
+	requestFiles, err := filepath.Glob(filepath.Join(c.dir, "*_merge_request.yaml"))
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	for _, path := range requestFiles {
+		if err := importOnce(ctx, c.store, path, store.MergeRequests,
+			readYaml[MergeRequestModel], mergeRequestRecord); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importOnce[T any](
+	ctx context.Context,
+	s *store.Store,
+	path string,
+	kind store.Kind,
+	read func(path string) ([]T, error),
+	toRecord func(T) (store.Record, error),
+) error {
+	scope := cacheScope(path)
+	if kind == store.Projects {
+		scope = ""
+	}
+	_, err := s.ImportOnce(ctx, filepath.Base(path), kind, scope, func() ([]store.Record, error) {
+		models, err := read(path)
+		if err != nil {
+			return nil, withstack.Errorf("import %s: %w", path, err)
+		}
+		return toRecords(models, toRecord)
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func readYaml[T any](path string) ([]T, error) {
+	var models []T
+	if err := utils.ReadFromYamlFile(path, &models); err != nil {
+		return nil, err
+	}
+	return models, nil
+}
+
+func all[T any](ctx context.Context, s *store.Store, kind store.Kind, scope string) ([]T, error) {
+	records, err := s.All(ctx, kind, scope)
+	if err != nil {
+		return nil, err
+	}
+	models := make([]T, len(records))
+	for i, r := range records {
+		if err := json.Unmarshal(r.Data, &models[i]); err != nil {
+			return nil, withstack.Errorf("cached %s %d: %w", kind, r.ID, err)
+		}
+	}
+	return models, nil
+}
+
+func put[T any](
+	ctx context.Context,
+	s *store.Store,
+	kind store.Kind,
+	scope string,
+	models []T,
+	toRecord func(T) (store.Record, error),
+) error {
+	records, err := toRecords(models, toRecord)
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, kind, scope, records)
+}
+
+func toRecords[T any](models []T, toRecord func(T) (store.Record, error)) ([]store.Record, error) {
+	records := make([]store.Record, len(models))
+	for i, m := range models {
+		r, err := toRecord(m)
+		if err != nil {
+			return nil, err
+		}
+		records[i] = r
+	}
+	return records, nil
+}
+
+func projectRecord(p ProjectModel) (store.Record, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return store.Record{}, withstack.Errorf("project %d: %w", p.ID, err)
+	}
+	return store.Record{
+		ID:        p.ID,
+		ProjectID: p.ID,
+		CreatedAt: p.CreatedAt.Time,
+		UpdatedAt: p.LastActivityAt.Time,
+		Data:      data,
+	}, nil
+}
+
+func eventRecord(e EventModel) (store.Record, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return store.Record{}, withstack.Errorf("event %d: %w", e.ID, err)
+	}
+	return store.Record{
+		ID:        e.ID,
+		ProjectID: e.ProjectID,
+		CreatedAt: e.CreatedAt.Time,
+		UpdatedAt: e.CreatedAt.Time,
+		Data:      data,
+	}, nil
+}
+
+func mergeRequestRecord(mr MergeRequestModel) (store.Record, error) {
+	data, err := json.Marshal(mr)
+	if err != nil {
+		return store.Record{}, withstack.Errorf("merge request %d: %w", mr.ID, err)
+	}
+	return store.Record{
+		ID:        mr.ID,
+		ProjectID: mr.ProjectID,
+		CreatedAt: mr.CreatedAt.Time,
+		UpdatedAt: mr.UpdatedAt.Time,
+		Data:      data,
+	}, nil
+}
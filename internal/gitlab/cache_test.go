@@ -0,0 +1,105 @@
+package gitlab
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appfixtures "github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/store"
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+)
+
+var _ = Describe("Cache", func() {
+	var (
+		ctx context.Context
+		dir string
+	)
+	at := func(d int) Time {
+		return Time{time.Date(2024, 5, d, 8, 30, 0, 0, time.UTC)}
+	}
+	openCache := func() *Cache {
+		c, err := OpenCache(ctx, dir)
+		Expect(err).NotTo(HaveOccurred())
+		return c
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		dir = GinkgoT().TempDir()
+	})
+
+	It("imports the yaml files once", func() {
+		projects := []ProjectModel{{ID: 3, Name: "three", LastActivityAt: at(2)}}
+		events := []EventModel{{ID: 10, ProjectID: 3, CreatedAt: at(4)}}
+		requests := []MergeRequestModel{{ID: 20, ProjectID: 3, Title: "fix", UpdatedAt: at(6)}}
+		Expect(utils.WriteToYamlFile(filepath.Join(dir, "projects.yaml"), projects)).To(Succeed())
+		Expect(utils.WriteToYamlFile(filepath.Join(dir, "project_3_events.yaml"), events)).To(Succeed())
+		Expect(utils.WriteToYamlFile(filepath.Join(dir, "my_recent_merge_request.yaml"), requests)).To(Succeed())
+
+		c := openCache()
+		haveProjects, err := c.Projects(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(haveProjects).To(HaveLen(1))
+		Expect(haveProjects[0].Name).To(Equal("three"))
+
+		haveEvents, err := NewEventMapFromYaml(ctx, appfixtures.NewApp(), filepath.Join(dir, "project_3_events.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(haveEvents).To(HaveKey(10))
+
+		haveRequests, err := NewMergeRequestMapFromYaml(filepath.Join(dir, "my_recent_merge_request.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(haveRequests[20].Title).To(Equal("fix"))
+		Expect(haveRequests[20].UpdatedAt.Equal(at(6).Time)).To(BeTrue())
+
+		// edits of the yaml after the import are not seen
+		Expect(c.Close()).To(Succeed())
+		Expect(utils.WriteToYamlFile(filepath.Join(dir, "projects.yaml"), []ProjectModel{{ID: 4}})).To(Succeed())
+		c = openCache()
+		defer c.Close()
+		haveProjects, err = c.Projects(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(haveProjects).To(HaveLen(1))
+		Expect(haveProjects[0].ID).To(Equal(3))
+	})
+
+	It("saves the maps with the watermark of the file", func() {
+		c := openCache()
+		defer c.Close()
+		path := filepath.Join(dir, "my_recent_merge_request.yaml")
+
+		watermark, err := Watermark(ctx, store.MergeRequests, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watermark.IsZero()).To(BeTrue())
+
+		Expect(MergeRequestMap{
+			1: {ID: 1, CreatedAt: at(9), UpdatedAt: at(10)},
+			2: {ID: 2, CreatedAt: at(1), UpdatedAt: at(12)},
+		}.Save(ctx, path)).To(Succeed())
+		Expect(MergeRequestMap{
+			1: {ID: 1, CreatedAt: at(9), UpdatedAt: at(11)},
+		}.Save(ctx, path)).To(Succeed())
+
+		watermark, err = Watermark(ctx, store.MergeRequests, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watermark).To(Equal(at(12).Time))
+
+		requests, err := NewMergeRequestMapFromYaml(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(HaveLen(2))
+		Expect(requests[1].UpdatedAt.Equal(at(11).Time)).To(BeTrue())
+
+		events, err := c.Events(ctx, "my_recent_merge_request")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events).To(BeEmpty())
+	})
+
+	It("fails without the directory", func() {
+		_, err := OpenCache(ctx, filepath.Join(dir, "missing"))
+		Expect(err).To(MatchError(os.ErrNotExist))
+	})
+})
@@ -0,0 +1,164 @@
+package gitlab
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/stalwartgiraffe/cmr/internal/utils"
+	"github.com/stalwartgiraffe/cmr/kam"
+)
+
+// Checkpoint records the pages of each query that were gathered,
+// so an interrupted gather can resume with the pages that are left.
+// A nil Checkpoint records nothing.
+type Checkpoint struct {
+	mu      sync.Mutex
+	Queries map[string]*QueryProgress `yaml:"queries"`
+}
+
+// QueryProgress is the progress of one paged query.
+type QueryProgress struct {
+	TotalPages int       `yaml:"total_pages,omitempty"` // offset pages, 0 when unknown
+	DonePages  []int     `yaml:"done_pages,omitempty"`  // offset pages gathered
+	Next       *UrlQuery `yaml:"next,omitempty"`        // the keyset page to gather next
+	Complete   bool      `yaml:"complete,omitempty"`
+}
+
+func NewCheckpoint() *Checkpoint {
+	return &Checkpoint{
+		Queries: map[string]*QueryProgress{},
+	}
+}
+
+// LoadCheckpoint reads the checkpoint file or returns an empty checkpoint if there is none.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	c := NewCheckpoint()
+	if err := utils.ReadFromYamlFile(path, c); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return NewCheckpoint(), nil
+		}
+		return nil, err
+	}
+	if c.Queries == nil {
+		c.Queries = map[string]*QueryProgress{}
+	}
+	return c, nil
+}
+
+// Save writes the checkpoint to the file.
+func (c *Checkpoint) Save(path string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return utils.WriteToYamlFile(path, c)
+}
+
+// Remove deletes the checkpoint file once the gather is complete.
+func RemoveCheckpoint(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// IsEmpty is true when nothing was gathered yet.
+func (c *Checkpoint) IsEmpty() bool {
+	if c == nil {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.Queries) < 1
+}
+
+// checkpointKey identifies the query independent of its page.
+func checkpointKey(q UrlQuery) string {
+	params := kam.Map{}
+	for k, v := range q.Params {
+		if k != "page" {
+			params[k] = v
+		}
+	}
+	return q.Path + "?" + params.ToQueryParameters()
+}
+
+// progress returns a copy of the progress of the query.
+func (c *Checkpoint) progress(q UrlQuery) QueryProgress {
+	if c == nil {
+		return QueryProgress{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.Queries[checkpointKey(q)]
+	if !ok {
+		return QueryProgress{}
+	}
+	cp := *p
+	cp.DonePages = slices.Clone(p.DonePages)
+	return cp
+}
+
+func (c *Checkpoint) update(q UrlQuery, fn func(p *QueryProgress)) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := checkpointKey(q)
+	p, ok := c.Queries[key]
+	if !ok {
+		p = &QueryProgress{}
+		c.Queries[key] = p
+	}
+	fn(p)
+}
+
+func (c *Checkpoint) setTotalPages(q UrlQuery, totalPages int) {
+	c.update(q, func(p *QueryProgress) {
+		p.TotalPages = totalPages
+	})
+}
+
+// pageDone records an offset page of the query.
+func (c *Checkpoint) pageDone(q UrlQuery, page int) {
+	c.update(q, func(p *QueryProgress) {
+		i := sort.SearchInts(p.DonePages, page)
+		if i < len(p.DonePages) && p.DonePages[i] == page {
+			return
+		}
+		p.DonePages = slices.Insert(p.DonePages, i, page)
+	})
+}
+
+// setNext records the keyset page to gather after the pages so far.
+func (c *Checkpoint) setNext(q UrlQuery, next *UrlQuery) {
+	c.update(q, func(p *QueryProgress) {
+		p.Next = next
+	})
+}
+
+func (c *Checkpoint) complete(q UrlQuery) {
+	c.update(q, func(p *QueryProgress) {
+		p.Next = nil
+		p.Complete = true
+	})
+}
+
+// isPageDone is true if the offset page was gathered.
+func (p *QueryProgress) isPageDone(page int) bool {
+	_, found := slices.BinarySearch(p.DonePages, page)
+	return found
+}
+
+// queryPage returns the page param of the query or false.
+func queryPage(q UrlQuery) (int, bool) {
+	switch p := q.Params["page"].(type) {
+	case int:
+		return p, true
+	default:
+		return 0, false
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/stalwartgiraffe/cmr/internal/utils"
@@ -11,14 +13,18 @@ import (
 	rc "github.com/stalwartgiraffe/cmr/restclient"
 )
 
+const DefaultAPI = "api/v4/"
+
 type Client struct {
 	client *rc.AuthTokenClient
+	retry  RetryPolicy
 }
 
+// NewClient returns a client of the gitlab api.
+// The overrides must include rc.WithBaseURL.
 func NewClient(overrides ...rc.Option) *Client {
 	opts := []rc.Option{
-		rc.WithBaseURL( "https://gitlab.indexexchange.com/"),
-		rc.WithAPI("api/v4/"),
+		rc.WithAPI(DefaultAPI),
 		rc.WithUserAgent("xlab"),
 		rc.WithIsVerbose(false),
 	}
@@ -27,15 +33,21 @@ func NewClient(overrides ...rc.Option) *Client {
 		client: rc.ConnectClient(
 			opts...,
 		),
+		retry: DefaultRetryPolicy,
 	}
 }
 
+// SetRetryPolicy sets how gets back off on 429 and 5xx.
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.retry = policy
+}
+
 func (c *Client) Get(ctx context.Context, app App, q UrlQuery) (kam.JSONValue, http.Header, error) {
 	return c.GetPathParams(ctx, app, q.Path, q.Params)
 }
 
 func (c *Client) GetPathParams(ctx context.Context, app App, path string, params kam.Map) (kam.JSONValue, http.Header, error) {
-	v, header, err := rc.GetWithHeader[kam.JSONValue](ctx, app, c.client, path, params.ToQueryParameters())
+	v, header, err := GetWithHeader[kam.JSONValue](ctx, app, c, path, params)
 	if err != nil {
 		return kam.JSONValue{}, nil, err
 	}
@@ -53,7 +65,10 @@ func GetWithHeader[RespT any](
 	params kam.Map) (
 	*RespT,
 	http.Header, error) {
-	return rc.GetWithHeader[RespT](ctx, app, c.client, path, params.ToQueryParameters())
+	queries := params.ToQueryParameters()
+	return withRetry(ctx, c.retry, func() (*RespT, http.Header, error) {
+		return rc.GetWithHeader[RespT](ctx, app, c.client, path, queries)
+	})
 }
 
 func GetWithUnmarshal[RespT any](
@@ -64,19 +79,68 @@ func GetWithUnmarshal[RespT any](
 	params kam.Map,
 	unmarshal func(context.Context, rc.App, *resty.Response) (*RespT, error),
 ) (*RespT, http.Header, error) {
-	return rc.GetWithUnmarshal[RespT](
-		ctx,
-		app,
-		c.client,
-		path,
-		params.ToQueryParameters(),
-		unmarshal,
-	)
+	queries := params.ToQueryParameters()
+	return withRetry(ctx, c.retry, func() (*RespT, http.Header, error) {
+		return rc.GetWithUnmarshal[RespT](
+			ctx,
+			app,
+			c.client,
+			path,
+			queries,
+			unmarshal,
+		)
+	})
+}
+
+// Update sends the body with the rc op (rc.POST, rc.PUT ...) and unmarshals the response.
+func Update[BodyT any, RespT any](
+	ctx context.Context,
+	app App,
+	c *Client,
+	op int,
+	path string,
+	body *BodyT,
+) (*RespT, error) {
+	return rc.UpdateWithApp[BodyT, RespT](ctx, app, op, c.client, path, body)
+}
+
+func withSlash(p string) string {
+	if strings.HasSuffix(p, "/") {
+		return p
+	}
+	return p + "/"
 }
 
 type UrlQuery struct {
-	Path   string
-	Params kam.Map
+	Path   string  `yaml:"path"`
+	Params kam.Map `yaml:"params"`
+}
+
+// linkQuery returns the query of a url the server linked to, such as the next page.
+func (c *Client) linkQuery(link string) (UrlQuery, error) {
+	u, err := url.Parse(link)
+	if err != nil {
+		return UrlQuery{}, err
+	}
+	apiURL, err := url.JoinPath(c.client.BaseURL(), c.client.API())
+	if err != nil {
+		return UrlQuery{}, err
+	}
+	base, err := url.Parse(apiURL)
+	if err != nil {
+		return UrlQuery{}, err
+	}
+	p, ok := strings.CutPrefix(u.Path, withSlash(base.Path))
+	if !ok {
+		return UrlQuery{}, fmt.Errorf("link %s is not below the api %s", u.Path, base.Path)
+	}
+	params := kam.Map{}
+	for k, vals := range u.Query() {
+		if 0 < len(vals) {
+			params[k] = vals[0]
+		}
+	}
+	return UrlQuery{Path: p, Params: params}, nil
 }
 
 func (q *UrlQuery) Clone() *UrlQuery {
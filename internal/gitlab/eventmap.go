@@ -2,7 +2,6 @@ package gitlab
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"os"
 	"sort"
@@ -16,20 +15,26 @@ import (
 
 type EventMap map[int]EventModel
 
+// NewEventMapFromYaml reads the cached events that were kept in the yaml file.
 func NewEventMapFromYaml(ctx context.Context, app App, filepath string) (EventMap, error) {
 	ctx, span := app.StartSpan(ctx, "updateRecentEvents")
 	defer span.End()
 
-	var oldEvents []EventModel
-
-	if err := utils.ReadFromYamlFile(filepath, &oldEvents); err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			fmt.Println(color.Ize(color.Red, err.Error()))
-			return nil, err
-		}
+	cache, scope, err := openCacheOf(ctx, filepath)
+	if err != nil {
+		fmt.Println(color.Ize(color.Red, err.Error()))
+		return nil, err
 	}
+	return cache.Events(ctx, scope)
+}
 
-	return NewEventMapFromSlice(oldEvents), nil
+// Save writes the events to the cache of the yaml file.
+func (m EventMap) Save(ctx context.Context, filepath string) error {
+	cache, scope, err := openCacheOf(ctx, filepath)
+	if err != nil {
+		return err
+	}
+	return cache.PutEvents(ctx, scope, maps.Values(m))
 }
 
 func NewEventMapFromSlice(events []EventModel) EventMap {
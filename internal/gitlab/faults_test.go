@@ -0,0 +1,156 @@
+package gitlab
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appfixtures "github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	"github.com/stalwartgiraffe/cmr/kam"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+var _ = Describe("gathering through injected faults", func() {
+	var server *localhost.Server
+	var client *Client
+	var app *appfixtures.MockApp
+
+	// the fake has 75 merge requests, 3 pages of 25
+	const totalPages = 3
+	const route = "GET /api/v4/merge_requests"
+
+	BeforeEach(func() {
+		server = localhost.NewServer()
+		client = NewClient(rc.WithBaseURL(server.URL()))
+		client.SetRetryPolicy(RetryPolicy{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    10 * time.Millisecond,
+		})
+		app = appfixtures.NewApp()
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	unmarshal := func(_ context.Context, _ rc.App, resp *resty.Response) (*[]MergeRequestModel, error) {
+		return rc.Unmarshal[[]MergeRequestModel](resp)
+	}
+	gather := func(ctx context.Context) ([]MergeRequestModel, []error) {
+		firstQueries := make(chan UrlQuery, 1)
+		firstQueries <- UrlQuery{
+			Path:   "merge_requests",
+			Params: kam.Map{"state": "all", "page": 1, "per_page": 25},
+		}
+		close(firstQueries)
+		var requests []MergeRequestModel
+		var errs []error
+		for call := range GatherPageCallsUM(ctx, app, client, firstQueries, unmarshal) {
+			if call.Error != nil {
+				errs = append(errs, call.Error)
+				continue
+			}
+			requests = append(requests, call.Val...)
+		}
+		return requests, errs
+	}
+
+	It("waits out Retry-After on 429", func() {
+		server.Inject(localhost.OnRoute(route, localhost.Times(1, localhost.RateLimit(1))))
+		start := time.Now()
+		requests, errs := gather(context.Background())
+		Expect(errs).To(BeEmpty())
+		Expect(requests).To(HaveLen(75))
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+	})
+
+	It("retries 5xx", func() {
+		server.Inject(localhost.OnRoute(route, localhost.Every(2, localhost.ServerError(http.StatusBadGateway))))
+		requests, errs := gather(context.Background())
+		Expect(errs).To(BeEmpty())
+		Expect(requests).To(HaveLen(75))
+	})
+
+	It("gives up on 5xx when the retries are used up", func() {
+		server.Inject(localhost.OnRoute(route, localhost.ServerError(http.StatusInternalServerError)))
+		requests, errs := gather(context.Background())
+		Expect(requests).To(BeEmpty())
+		Expect(errs).To(HaveLen(1))
+		var failure *rc.FailureResponse
+		Expect(errors.As(errs[0], &failure)).To(BeTrue())
+		Expect(failure.StatusCode).To(Equal(http.StatusInternalServerError))
+	})
+
+	It("leaves the faults of other routes alone", func() {
+		server.Inject(localhost.OnRoute("GET /api/v4/projects/*/merge_requests", localhost.ServerError(http.StatusInternalServerError)))
+		requests, errs := gather(context.Background())
+		Expect(errs).To(BeEmpty())
+		Expect(requests).To(HaveLen(75))
+	})
+
+	It("stops waiting on latency when the context is done", func() {
+		server.Inject(localhost.OnRoute(route, localhost.Latency(time.Second)))
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		requests, errs := gather(ctx)
+		Expect(requests).To(BeEmpty())
+		Expect(errs).To(HaveLen(1))
+		Expect(errors.Is(errs[0], context.DeadlineExceeded)).To(BeTrue())
+	})
+
+	It("retries a dropped connection", func() {
+		server.Inject(localhost.OnRoute(route, localhost.Times(2, localhost.DropConnection())))
+		requests, errs := gather(context.Background())
+		Expect(errs).To(BeEmpty())
+		Expect(requests).To(HaveLen(75))
+	})
+
+	It("gives up when the connection always drops", func() {
+		server.Inject(localhost.OnRoute(route, localhost.DropConnection()))
+		requests, errs := gather(context.Background())
+		Expect(requests).To(BeEmpty())
+		Expect(errs).To(HaveLen(1))
+		Expect(isNetworkError(errs[0])).To(BeTrue())
+	})
+
+	DescribeTable("does not retry a body that is not json",
+		func(fault localhost.Middleware) {
+			// a retry would succeed, so one error shows there was none
+			server.Inject(localhost.OnRoute(route, localhost.Times(1, fault)))
+			requests, errs := gather(context.Background())
+			Expect(requests).To(BeEmpty())
+			Expect(errs).To(HaveLen(1))
+			var syntax *json.SyntaxError
+			Expect(errors.As(errs[0], &syntax)).To(BeTrue())
+		},
+		Entry("truncated", localhost.TruncateJSON()),
+		Entry("invalid", localhost.InvalidJSON()),
+	)
+
+	It("gets only the first page without paging headers", func() {
+		server.Inject(localhost.OnRoute(route, localhost.OmitPaging()))
+		requests, errs := gather(context.Background())
+		Expect(errs).To(BeEmpty())
+		Expect(requests).To(HaveLen(25))
+	})
+
+	It("fails the same pages for the same seed", func() {
+		client.SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+		failed := func(seed uint64) int {
+			server.Inject(localhost.OnRoute(route, localhost.Randomly(seed, 0.5, localhost.ServerError(http.StatusBadGateway))))
+			_, errs := gather(context.Background())
+			return len(errs)
+		}
+		for seed := uint64(1); seed <= 5; seed++ {
+			Expect(failed(seed)).To(Equal(failed(seed)))
+			Expect(failed(seed)).To(BeNumerically("<=", totalPages))
+		}
+	})
+})
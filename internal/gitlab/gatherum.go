@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	"github.com/go-resty/resty/v2"
@@ -89,47 +90,35 @@ func headPageQueriesUM[RespT any](
 		defer span.End()
 		defer close(calls)
 		defer close(queries)
-		for firstQuery := range firstQueries {
-			firstVal, firstHeader, err := GetWithUnmarshal[RespT](
+		onError := func(q UrlQuery, err error) {
+			calls <- Call[RespT]{
+				Query: q,
+				Error: err,
+			}
+		}
+		fetch := func(q UrlQuery) (http.Header, bool) {
+			v, h, err := GetWithUnmarshal[RespT](
 				ctx,
 				app,
 				client,
-				firstQuery.Path,
-				firstQuery.Params,
+				q.Path,
+				q.Params,
 				unmarshal,
 			)
 			if err != nil {
-				calls <- Call[RespT]{
-					Query: firstQuery,
-					Error: err,
-				}
-				continue
-			} else {
-				calls <- Call[RespT]{
-					Query:  firstQuery,
-					Header: firstHeader,
-					Val:    *firstVal,
-				}
-			}
-
-			cursor, err := parsePageCursor(firstHeader)
-			if err != nil {
-				calls <- Call[RespT]{
-					Query: firstQuery,
-					Error: err,
-				}
-				continue
+				onError(q, err)
+				return nil, false
 			}
-			if cursor.page == nil || cursor.totalPages == nil {
-				continue
-			}
-			p := *cursor.page + 1
-			n := *cursor.totalPages
-			for ; p <= n; p++ {
-				next := *firstQuery.Clone()
-				next.Params["page"] = p
-				queries <- next
+			calls <- Call[RespT]{
+				Query:  q,
+				Header: h,
+				Val:    *v,
 			}
+			return h, true
+		}
+		const noPagesLimit = 0
+		for firstQuery := range firstQueries {
+			walkPages(ctx, client, firstQuery, noPagesLimit, nil, fetch, onError, queries)
 		}
 	}()
 	return calls, queries
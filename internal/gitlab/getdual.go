@@ -2,6 +2,7 @@ package gitlab
 
 import (
 	"context"
+	"net/http"
 	"sync"
 
 	"go.opentelemetry.io/otel/trace"
@@ -73,6 +74,33 @@ func GatherPageCallsDual[RespT any](
 	)
 }
 
+// GatherPageCallsDualCheckpoint is GatherPageCallsDualApp that skips the pages
+// recorded in the checkpoint and records each page it gathers.
+func GatherPageCallsDualCheckpoint[RespT any](
+	ctx context.Context,
+	app App,
+	client *Client,
+	initialQueries <-chan UrlQuery,
+	totalPagesLimit int,
+	checkpoint *Checkpoint,
+) (
+	<-chan CallNoError[RespT],
+	<-chan error,
+) {
+	return gatherPageCallsDual[RespT](
+		ctx,
+		app,
+		client,
+		initialQueries,
+		5,               // callCap int,
+		5,               // queryCap int,
+		5,               // workersCap int,
+		1,               // errorCap int,
+		totalPagesLimit, // 0 means no limit
+		checkpoint,
+	)
+}
+
 func GatherPageCallsWithDualApp[RespT any](
 	ctx context.Context,
 	app App,
@@ -86,6 +114,35 @@ func GatherPageCallsWithDualApp[RespT any](
 ) (
 	<-chan CallNoError[RespT],
 	<-chan error,
+) {
+	return gatherPageCallsDual[RespT](
+		ctx,
+		app,
+		client,
+		initialQueries,
+		callCap,
+		queryCap,
+		workersCap,
+		errorCap,
+		totalPagesLimit,
+		nil, // no checkpoint
+	)
+}
+
+func gatherPageCallsDual[RespT any](
+	ctx context.Context,
+	app App,
+	client *Client,
+	initialQueries <-chan UrlQuery,
+	callCap int,
+	queryCap int,
+	workersCap int,
+	errorCap int,
+	totalPagesLimit int, // 0 means no limit
+	checkpoint *Checkpoint,
+) (
+	<-chan CallNoError[RespT],
+	<-chan error,
 ) {
 	if app != nil {
 		var span trace.Span
@@ -105,6 +162,7 @@ func GatherPageCallsWithDualApp[RespT any](
 		queryCap,
 		errorCap,
 		totalPagesLimit,
+		checkpoint,
 	)
 	calls[1], errors[1] = tailPageCallsDual[RespT](
 		ctx,
@@ -113,6 +171,7 @@ func GatherPageCallsWithDualApp[RespT any](
 		queries,
 		workersCap,
 		errorCap,
+		checkpoint,
 	)
 	return FanIn(calls), FanIn(errors)
 }
@@ -153,6 +212,7 @@ func headPageQueriesDual[RespT any](
 	queryCap int,
 	errorCap int,
 	totalPagesLimit int,
+	checkpoint *Checkpoint,
 ) (
 	<-chan CallNoError[RespT],
 	<-chan UrlQuery,
@@ -170,42 +230,29 @@ func headPageQueriesDual[RespT any](
 		defer close(calls)
 		defer close(queries)
 		defer close(errors)
-		for firstQuery := range firstQueries {
-			firstVal, firstHeader, err := GetWithHeader[RespT](
+		onError := func(q UrlQuery, err error) {
+			errors <- &UrlQueryError{err: err, query: q}
+		}
+		fetch := func(q UrlQuery) (http.Header, bool) {
+			v, h, err := GetWithHeader[RespT](
 				ctx,
 				app,
 				client,
-				firstQuery.Path,
-				firstQuery.Params)
+				q.Path,
+				q.Params)
 			if err != nil {
-				errors <- &UrlQueryError{err: err, query: firstQuery}
-				continue
+				onError(q, err)
+				return nil, false
 			}
-
 			calls <- CallNoError[RespT]{
-				Query:  firstQuery,
-				Header: firstHeader,
-				Val:    *firstVal,
-			}
-
-			cursor, err := parsePageCursor(firstHeader)
-			if err != nil {
-				errors <- &UrlQueryError{err: err, query: firstQuery}
-				continue
-			}
-			if cursor.page == nil || cursor.totalPages == nil {
-				continue
-			}
-			p := *cursor.page + 1
-			n := *cursor.totalPages
-			if totalPagesLimit > 0 && n > totalPagesLimit {
-				n = totalPagesLimit
-			}
-			for ; p <= n; p++ {
-				next := *firstQuery.Clone()
-				next.Params["page"] = p
-				queries <- next
+				Query:  q,
+				Header: h,
+				Val:    *v,
 			}
+			return h, true
+		}
+		for firstQuery := range firstQueries {
+			walkPages(ctx, client, firstQuery, totalPagesLimit, checkpoint, fetch, onError, queries)
 		}
 	}()
 	return calls, queries, errors
@@ -218,6 +265,7 @@ func tailPageCallsDual[RespT any](
 	queries <-chan UrlQuery,
 	workersCap int,
 	errorsCap int,
+	checkpoint *Checkpoint,
 ) (<-chan CallNoError[RespT],
 	<-chan error,
 ) {
@@ -255,6 +303,9 @@ func tailPageCallsDual[RespT any](
 						Header: h,
 						Val:    *v,
 					}
+					if page, ok := queryPage(q); ok {
+						checkpoint.pageDone(q, page)
+					}
 				}
 			}()
 		}
@@ -74,6 +74,7 @@ func makeGetPages[RespT any](
 		queryCap,
 		errorCap,
 		totalPageLimit,
+		nil, // no checkpoint
 	)
 	Expect(calls).ToNot(BeNil())
 	Expect(queries).ToNot(BeNil())
@@ -101,6 +102,7 @@ func makeGetPageCalls[RespT any](
 		firstQueries,
 		workersCap,
 		errorCap,
+		nil, // no checkpoint
 	)
 	Expect(calls).ToNot(BeNil())
 	Expect(errors).ToNot(BeNil())
@@ -1,12 +1,21 @@
 package gitlab
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/stalwartgiraffe/cmr/internal/utils"
 	"github.com/stalwartgiraffe/cmr/kam"
 )
 
+// GetGroups returns the groups the user can see.
+func (c *Client) GetGroups(ctx context.Context, app App) ([]GroupModel, error) {
+	ctx, span := app.StartSpan(ctx, "GetGroups")
+	defer span.End()
+
+	return GetAllPages[GroupModel](ctx, app, c, "groups", nil)
+}
+
 type Group struct {
 	Name     string
 	FullPath string
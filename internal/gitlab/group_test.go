@@ -1,11 +1,16 @@
 package gitlab
 
 import (
+	"context"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+
+	appfixtures "github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
 	"github.com/stalwartgiraffe/cmr/kam"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
 )
 
 func TestEnv(t *testing.T) {
@@ -29,3 +34,17 @@ var _ = Describe("getVal", func() {
 		Entry(nil, kam.Map{}, "key", 123, false),
 	)
 })
+
+var _ = Describe("groups", func() {
+	It("gets the groups", func() {
+		server := localhost.NewServer()
+		defer server.Close()
+		client := NewClient(rc.WithBaseURL(server.URL()))
+
+		groups, err := client.GetGroups(context.Background(), appfixtures.NewApp())
+		Expect(err).To(Succeed())
+		Expect(groups).To(HaveLen(1))
+		Expect(groups[0].ID).To(Equal(123))
+		Expect(groups[0].FullPath).To(Equal("gitlab-org"))
+	})
+})
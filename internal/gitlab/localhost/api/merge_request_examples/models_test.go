@@ -7,6 +7,8 @@ import (
 	"testing"
 )
 
+// The merge_request_NNN.json examples are recorded gitlab responses,
+// regenerate them with cmr --record and cmr fixtures --examples.
 func TestUnmarshalMergeRequests(t *testing.T) {
 	// Find all JSON test files
 	files, err := filepath.Glob("merge_request_*.json")
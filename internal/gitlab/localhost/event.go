@@ -43,6 +43,7 @@ type EventsQueryParams struct {
 
 	Action     string
 	TargetType string
+	AuthorID   *int
 	Before     *time.Time
 	After      *time.Time
 	Sort       string
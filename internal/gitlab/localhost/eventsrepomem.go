@@ -1,8 +0,0 @@
-package localhost
-
-type EventsRepoMem struct {
-}
-
-func NewEventsRepoMem() *EventsRepoMem {
-	return &EventsRepoMem{}
-}
@@ -0,0 +1,300 @@
+package localhost
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Middleware wraps a handler, LoggingMiddleware and the faults below are middleware.
+type Middleware func(next http.HandlerFunc) http.HandlerFunc
+
+// Chain composes the middleware, the first one sees the request first.
+func Chain(mws ...Middleware) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		for i := len(mws) - 1; 0 <= i; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
+
+// OnRoute applies mw to the requests that match the pattern and passes the rest through.
+// The pattern is an optional method and a path.Match pattern of the url path,
+// such as "GET /api/v4/projects/*/merge_requests".
+func OnRoute(pattern string, mw Middleware) Middleware {
+	method, pathPattern, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, pathPattern = "", pattern
+	}
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		faulty := mw(next)
+		return func(w http.ResponseWriter, r *http.Request) {
+			if method != "" && method != r.Method {
+				next(w, r)
+				return
+			}
+			if matched, _ := path.Match(pathPattern, r.URL.Path); !matched {
+				next(w, r)
+				return
+			}
+			faulty(w, r)
+		}
+	}
+}
+
+// OnMethod applies mw to the requests of the method, such as GET, and passes the rest through.
+func OnMethod(method string, mw Middleware) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		faulty := mw(next)
+		return func(w http.ResponseWriter, r *http.Request) {
+			if method != r.Method {
+				next(w, r)
+				return
+			}
+			faulty(w, r)
+		}
+	}
+}
+
+// Every applies mw to every nth request that reaches it.
+func Every(n int, mw Middleware) Middleware {
+	var count atomic.Int64
+	return when(func() bool {
+		return 0 < n && count.Add(1)%int64(n) == 0
+	}, mw)
+}
+
+// Times applies mw to the first n requests that reach it.
+func Times(n int, mw Middleware) Middleware {
+	var count atomic.Int64
+	return when(func() bool {
+		return count.Add(1) <= int64(n)
+	}, mw)
+}
+
+// Randomly applies mw to requests with probability p.
+// The same seed picks the same requests in the same order.
+func Randomly(seed uint64, p float64, mw Middleware) Middleware {
+	var mu sync.Mutex
+	rng := rand.New(rand.NewPCG(seed, seed))
+	return when(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return rng.Float64() < p
+	}, mw)
+}
+
+// when applies mw to the requests that apply is true for.
+func when(apply func() bool, mw Middleware) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		faulty := mw(next)
+		return func(w http.ResponseWriter, r *http.Request) {
+			if apply() {
+				faulty(w, r)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
+
+// RateLimit answers 429 Too Many Requests with the headers gitlab sends,
+// Retry-After in seconds unless it is 0.
+// see https://docs.gitlab.com/ee/administration/settings/user_and_ip_rate_limits.html#response-headers
+func RateLimit(retryAfter int) Middleware {
+	const limit = 600
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(limit))
+			header.Set("RateLimit-Observed", strconv.Itoa(limit+1))
+			header.Set("RateLimit-Remaining", "0")
+			header.Set("RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Duration(retryAfter)*time.Second).Unix(), 10))
+			if 0 < retryAfter {
+				header.Set("Retry-After", strconv.Itoa(retryAfter))
+			}
+			http.Error(w, "Retry later", http.StatusTooManyRequests)
+		}
+	}
+}
+
+// ServerError answers with the 5xx status.
+func ServerError(status int) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			msg := fmt.Sprintf(`{"message":"%d %s"}`, status, http.StatusText(status))
+			http.Error(w, msg, status)
+		}
+	}
+}
+
+// Latency waits before answering, or gives up when the client does.
+func Latency(d time.Duration) Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-time.After(d):
+				next(w, r)
+			case <-r.Context().Done():
+			}
+		}
+	}
+}
+
+// DropConnection sends the headers and half the body, then closes the connection.
+func DropConnection() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rec := record(next, r)
+			body := rec.Body.Bytes()
+			copyHeader(w, rec)
+			w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+			w.WriteHeader(rec.Code)
+			_, _ = w.Write(body[:len(body)/2])
+			hijacker, ok := w.(http.Hijacker)
+			if !ok {
+				panic("DropConnection needs a ResponseWriter that can be hijacked")
+			}
+			conn, _, err := hijacker.Hijack()
+			if err != nil {
+				panic(err)
+			}
+			_ = conn.Close()
+		}
+	}
+}
+
+// TruncateJSON sends the first half of the body as if it were all of it.
+func TruncateJSON() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rec := record(next, r)
+			body := rec.Body.Bytes()
+			writeRecorded(w, rec, body[:len(body)/2])
+		}
+	}
+}
+
+// InvalidJSON sends a body that is not json, such as the html page of a proxy.
+func InvalidJSON() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rec := record(next, r)
+			writeRecorded(w, rec, []byte("<html><body>gitlab is warming up</body></html>"))
+		}
+	}
+}
+
+// pagingHeaders are the offset and keyset paging headers gitlab sends with a list.
+var pagingHeaders = []string{"X-Page", "X-Per-Page", "X-Next-Page", "X-Prev-Page", "X-Total", "X-Total-Pages", "Link"}
+
+// OmitPaging drops the paging headers of the response.
+func OmitPaging() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rec := record(next, r)
+			for _, k := range pagingHeaders {
+				rec.Header().Del(k)
+			}
+			writeRecorded(w, rec, rec.Body.Bytes())
+		}
+	}
+}
+
+// KeysetPaging rewrites the offset paging headers of the response into keyset paging,
+// a Link to the next page without X-Total-Pages and the other offset headers.
+func KeysetPaging() Middleware {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			rec := record(next, r)
+			header := rec.Header()
+			page, _ := strconv.Atoi(header.Get("X-Page"))
+			nextPage, _ := strconv.Atoi(header.Get("X-Next-Page"))
+			for _, k := range pagingHeaders {
+				if k != "X-Per-Page" {
+					header.Del(k)
+				}
+			}
+			if page < nextPage {
+				u := *r.URL
+				u.Scheme = "http"
+				u.Host = r.Host
+				q := u.Query()
+				q.Set("page", strconv.Itoa(nextPage))
+				u.RawQuery = q.Encode()
+				header.Set("Link", fmt.Sprintf(`<%s>; rel="next"`, u.String()))
+			}
+			writeRecorded(w, rec, rec.Body.Bytes())
+		}
+	}
+}
+
+// record returns what next answers to the request.
+func record(next http.HandlerFunc, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	next(rec, r)
+	return rec
+}
+
+// writeRecorded writes the recorded status and headers with the body.
+func writeRecorded(w http.ResponseWriter, rec *httptest.ResponseRecorder, body []byte) {
+	copyHeader(w, rec)
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(rec.Code)
+	_, _ = w.Write(body)
+}
+
+func copyHeader(w http.ResponseWriter, rec *httptest.ResponseRecorder) {
+	header := w.Header()
+	for k, v := range rec.Header() {
+		header[k] = v
+	}
+}
+
+// faults holds the middleware a test injected.
+type faults struct {
+	mu sync.Mutex
+	mw Middleware
+}
+
+func (f *faults) set(mw Middleware) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.mw = mw
+}
+
+func (f *faults) get() Middleware {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.mw
+}
+
+// Inject applies the faults, in order, to every api request from now on.
+// Scope a fault with OnRoute and pace it with Every, Times or Randomly.
+// Inject with no faults removes them.
+func (ts *Server) Inject(mws ...Middleware) {
+	if len(mws) == 0 {
+		ts.handler.faults.set(nil)
+		return
+	}
+	ts.handler.faults.set(Chain(mws...))
+}
+
+// FaultMiddleware applies the faults set by Server.Inject.
+func (h *Handler) FaultMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if mw := h.faults.get(); mw != nil {
+			mw(next)(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
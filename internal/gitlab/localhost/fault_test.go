@@ -0,0 +1,87 @@
+package localhost
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func ok(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+}
+
+// statuses returns the status of each of n requests to the path through mw.
+func statuses(mw Middleware, method, path string, n int) []int {
+	handler := mw(ok)
+	codes := make([]int, n)
+	for i := range codes {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(method, path, nil))
+		codes[i] = rec.Code
+	}
+	return codes
+}
+
+func TestRandomlyIsDeterministic(t *testing.T) {
+	fault := func(seed uint64) Middleware {
+		return Randomly(seed, 0.5, ServerError(http.StatusBadGateway))
+	}
+	a := statuses(fault(7), http.MethodGet, "/", 50)
+	b := statuses(fault(7), http.MethodGet, "/", 50)
+	if !slices.Equal(a, b) {
+		t.Error("Expected the same seed to fail the same requests")
+	}
+	if !slices.Contains(a, http.StatusOK) || !slices.Contains(a, http.StatusBadGateway) {
+		t.Errorf("Expected some requests to fail and some to pass, got %v", a)
+	}
+	if slices.Equal(a, statuses(fault(8), http.MethodGet, "/", 50)) {
+		t.Error("Expected another seed to fail other requests")
+	}
+}
+
+func TestOnRoute(t *testing.T) {
+	fault := OnRoute("GET /api/v4/projects/*/merge_requests", ServerError(http.StatusInternalServerError))
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/v4/projects/25/merge_requests", http.StatusInternalServerError},
+		{http.MethodPost, "/api/v4/projects/25/merge_requests", http.StatusOK},
+		{http.MethodGet, "/api/v4/projects/25/merge_requests/1", http.StatusOK},
+		{http.MethodGet, "/api/v4/merge_requests", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := statuses(fault, tt.method, tt.path, 1)[0]; got != tt.want {
+			t.Errorf("%s %s: expected status %d, got %d", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestChainPacing(t *testing.T) {
+	fault := Chain(
+		Times(1, RateLimit(1)),
+		Every(2, ServerError(http.StatusServiceUnavailable)),
+	)
+	want := []int{http.StatusTooManyRequests, http.StatusOK, http.StatusServiceUnavailable, http.StatusOK}
+	if got := statuses(fault, http.MethodGet, "/", 4); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSimulationPreset(t *testing.T) {
+	if (Simulation{}).Middleware() != nil {
+		t.Error("Expected an empty simulation to have no faults")
+	}
+	// the server errors count the GETs that were not rate limited
+	sim := Simulation{RateLimitEvery: 2, ServerErrorEvery: 2}.Middleware()
+	want := []int{http.StatusOK, http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusTooManyRequests}
+	if got := statuses(sim, http.MethodGet, "/", 4); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	// only the GETs misbehave
+	want = []int{http.StatusOK, http.StatusOK}
+	if got := statuses(sim, http.MethodPost, "/", 2); !slices.Equal(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
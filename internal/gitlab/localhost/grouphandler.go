@@ -0,0 +1,108 @@
+package localhost
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const groupsPrefix = "/api/v4/groups"
+
+// RouteGroups dispatches the /api/v4/groups endpoints.
+func (h *Handler) RouteGroups(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path == groupsPrefix {
+		h.GetGroups(w, r)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.EscapedPath(), groupsPrefix+"/")
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	id, err := url.PathUnescape(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid group id: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if id == "" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	group, ok := h.findGroup(id)
+	if !ok {
+		http.Error(w, "404 Group Not Found", http.StatusNotFound)
+		return
+	}
+	switch strings.Join(segments[1:], "/") {
+	case "":
+		h.writeJSON(w, http.StatusOK, group)
+	case "projects":
+		h.GetGroupsProjects(w, r, *group)
+	case "merge_requests":
+		h.GetGroupMergeRequests(w, r, *group)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+// findGroup returns a copy of the group with the numeric id or full path.
+func (h *Handler) findGroup(id string) (*Group, bool) {
+	store := h.store()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	g, ok := store.findGroup(id)
+	if !ok {
+		return nil, false
+	}
+	group := *g
+	return &group, true
+}
+
+// GetGroups lists the groups, search matches the name or path.
+func (h *Handler) GetGroups(w http.ResponseWriter, r *http.Request) {
+	search := strings.ToLower(r.URL.Query().Get("search"))
+	store := h.store()
+	store.mu.Lock()
+	groups := []Group{}
+	for _, g := range store.data.Groups {
+		if search == "" ||
+			strings.Contains(strings.ToLower(g.Name), search) ||
+			strings.Contains(strings.ToLower(g.FullPath), search) {
+			groups = append(groups, g)
+		}
+	}
+	store.mu.Unlock()
+	writePage(h, w, r, groups, parsePage(r))
+}
+
+// GetUsers lists the users, username picks the one user.
+func (h *Handler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	username := r.URL.Query().Get("username")
+	store := h.store()
+	store.mu.Lock()
+	users := []UserBasic{}
+	for _, u := range store.data.Users {
+		if username == "" || strings.EqualFold(u.Username, username) {
+			users = append(users, u)
+		}
+	}
+	store.mu.Unlock()
+	writePage(h, w, r, users, parsePage(r))
+}
+
+// GetCurrentUser returns the user the fake acts as.
+func (h *Handler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, currentUser)
+}
+
+// writeJSON encodes v with status.
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		h.OnServerError(w, "Failed to encode response", err)
+		return
+	}
+}
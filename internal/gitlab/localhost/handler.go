@@ -1,23 +1,22 @@
 package localhost
 
 import (
-	"encoding/json"
-	"math"
+	"cmp"
 	"net/http"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/brianvoe/gofakeit/v7"
-
 	"github.com/stalwartgiraffe/cmr/internal/utils"
-	"github.com/stalwartgiraffe/cmr/withstack"
 )
 
 type Handler struct {
-	service  *Service
-	requests []MergeRequest
+	service *Service
+
+	sim    simulator
+	faults faults
 }
 
 func NewHandler(service *Service) *Handler {
@@ -26,6 +25,11 @@ func NewHandler(service *Service) *Handler {
 	}
 }
 
+// store returns the state the requests read and write.
+func (h *Handler) store() *Store {
+	return h.service.store
+}
+
 func (*Handler) OnServerError(w http.ResponseWriter, msg string, err error) {
 	utils.Redln(msg, "\n", err.Error())
 	http.Error(w, msg, http.StatusInternalServerError)
@@ -39,48 +43,23 @@ func (h *Handler) GetProjects(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// For now, return mock projects based on the API specification
-	// In a real implementation, this would call h.service.projects.GetGroupProjects(groupID, params)
-	projects := h.generateMockProjects(params)
-
-	setOnePagedHeaders(len(projects), params.PageQueryParams, w.Header())
-	if err := json.NewEncoder(w).Encode(projects); err != nil {
-		h.OnServerError(w, "Failed to encode response", err)
-		return
-	}
+	projects := h.queryProjects(params, func(*Project) bool { return true })
+	writePage(h, w, r, projects, params.PageQueryParams)
 }
 
-func (h *Handler) GetGroupsProjects(w http.ResponseWriter, r *http.Request) {
+func (h *Handler) GetGroupsProjects(w http.ResponseWriter, r *http.Request, group Group) {
 	params, err := h.parseProjectsQueryParams(r)
 	if err != nil {
 		http.Error(w, "Invalid query parameters: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// For now, return mock projects based on the API specification
-	// In a real implementation, this would call h.service.projects.GetGroupProjects(groupID, params)
-	projects := h.generateMockProjects(params)
-
-	setOnePagedHeaders(len(projects), params.PageQueryParams, w.Header())
-	if err := json.NewEncoder(w).Encode(projects); err != nil {
-		h.OnServerError(w, "Failed to encode response", err)
-		return
-	}
+	projects := h.queryProjects(params, func(p *Project) bool {
+		return inGroup(p, &group, params.IncludeSubgroups)
+	})
+	writePage(h, w, r, projects, params.PageQueryParams)
 }
 
-// Extract group ID from path parameter using regex
-// Expected path:
-// Handle the GitLab API v4 groups endpoints:l/api/v4/groups/{id}/projects
-/*
-	re := regexp.MustCompile(`/api/v4/groups/([^/]+)`)
-	matches := re.FindStringSubmatch(r.URL.Path)
-	if len(matches) < 2 {
-		http.Error(w, "Group ID is required", http.StatusBadRequest)
-		return
-	}
-	projectID := matches[1]
-*/
-
 // parseProjectsQueryParams parses the query parameters for the projects endpoint
 func (h *Handler) parseProjectsQueryParams(r *http.Request) (*ProjectsQueryParams, error) {
 	params := &ProjectsQueryParams{
@@ -192,20 +171,6 @@ func (h *Handler) parseProjectsQueryParams(r *http.Request) (*ProjectsQueryParam
 		}
 	}
 
-	// Parse page parameter
-	if pageStr := r.URL.Query().Get("page"); pageStr != "" {
-		if page, err := strconv.Atoi(pageStr); err == nil && page > 0 {
-			params.Page = page
-		}
-	}
-
-	// Parse per_page parameter
-	if perPageStr := r.URL.Query().Get("per_page"); perPageStr != "" {
-		if perPage, err := strconv.Atoi(perPageStr); err == nil && perPage > 0 {
-			params.PerPage = perPage
-		}
-	}
-
 	// Parse with_custom_attributes parameter
 	if withCustomAttributesStr := r.URL.Query().Get("with_custom_attributes"); withCustomAttributesStr == "true" {
 		params.WithCustomAttributes = true
@@ -219,120 +184,20 @@ func (h *Handler) parseProjectsQueryParams(r *http.Request) (*ProjectsQueryParam
 	return params, nil
 }
 
-// generateMockProjects generates mock projects for testing purposes
-func (h *Handler) generateMockProjects(params *ProjectsQueryParams) []Project {
-	// Generate mock projects based on the API specification
-
-	projects := []Project{
-		{
-			ID:                       25,
-			Name:                     "gitlab-foss",
-			NameWithNamespace:        "GitLab.org / gitlab-foss",
-			Path:                     "gitlab-foss",
-			PathWithNamespace:        "gitlab-org/gitlab-foss",
-			Description:              "GitLab Community Edition",
-			CreatedAt:                time.Now().Add(-365 * 24 * time.Hour),
-			UpdatedAt:                time.Now().Add(-24 * time.Hour),
-			LastActivityAt:           time.Now().Add(-12 * time.Hour),
-			DefaultBranch:            "main",
-			TagList:                  []string{"ruby", "rails", "git"},
-			Topics:                   []string{"git", "version-control", "collaboration"},
-			SSHURLToRepo:             "git@gitlab.example.com:gitlab-org/gitlab-foss.git",
-			HTTPURLToRepo:            "https://gitlab.example.com/gitlab-org/gitlab-foss.git",
-			WebURL:                   "https://gitlab.example.com/gitlab-org/gitlab-foss",
-			ReadmeURL:                "https://gitlab.example.com/gitlab-org/gitlab-foss/-/blob/main/README.md",
-			AvatarURL:                "https://gitlab.example.com/uploads/project/avatar/25/gitlab_logo.png",
-			StarCount:                2345,
-			ForksCount:               589,
-			Visibility:               "public",
-			IssuesEnabled:            true,
-			MergeRequestsEnabled:     true,
-			WikiEnabled:              true,
-			JobsEnabled:              true,
-			SnippetsEnabled:          true,
-			ContainerRegistryEnabled: true,
-			EmptyRepo:                false,
-			Archived:                 false,
-			Owner: &UserBasic{
-				ID:       1,
-				Username: "root",
-				Name:     "Administrator",
-				State:    "active",
-			},
-		},
-		{
-			ID:                       30,
-			Name:                     "awesome-project",
-			NameWithNamespace:        "GitLab.org / awesome-project",
-			Path:                     "awesome-project",
-			PathWithNamespace:        "gitlab-org/awesome-project",
-			Description:              "An awesome project for demonstration",
-			CreatedAt:                time.Now().Add(-180 * 24 * time.Hour),
-			UpdatedAt:                time.Now().Add(-48 * time.Hour),
-			LastActivityAt:           time.Now().Add(-24 * time.Hour),
-			DefaultBranch:            "develop",
-			TagList:                  []string{"javascript", "nodejs", "react"},
-			Topics:                   []string{"frontend", "web", "javascript"},
-			SSHURLToRepo:             "git@gitlab.example.com:gitlab-org/awesome-project.git",
-			HTTPURLToRepo:            "https://gitlab.example.com/gitlab-org/awesome-project.git",
-			WebURL:                   "https://gitlab.example.com/gitlab-org/awesome-project",
-			ReadmeURL:                "https://gitlab.example.com/gitlab-org/awesome-project/-/blob/develop/README.md",
-			StarCount:                125,
-			ForksCount:               34,
-			Visibility:               "internal",
-			IssuesEnabled:            true,
-			MergeRequestsEnabled:     true,
-			WikiEnabled:              false,
-			JobsEnabled:              true,
-			SnippetsEnabled:          false,
-			ContainerRegistryEnabled: false,
-			EmptyRepo:                false,
-			Archived:                 false,
-			Owner: &UserBasic{
-				ID:       2,
-				Username: "developer",
-				Name:     "Developer User",
-				State:    "active",
-			},
-		},
-		{
-			ID:                       45,
-			Name:                     "archived-legacy",
-			NameWithNamespace:        "GitLab.org / archived-legacy",
-			Path:                     "archived-legacy",
-			PathWithNamespace:        "gitlab-org/archived-legacy",
-			Description:              "Legacy project that has been archived",
-			CreatedAt:                time.Now().Add(-730 * 24 * time.Hour),
-			UpdatedAt:                time.Now().Add(-365 * 24 * time.Hour),
-			LastActivityAt:           time.Now().Add(-365 * 24 * time.Hour),
-			DefaultBranch:            "master",
-			TagList:                  []string{"legacy", "deprecated"},
-			SSHURLToRepo:             "git@gitlab.example.com:gitlab-org/archived-legacy.git",
-			HTTPURLToRepo:            "https://gitlab.example.com/gitlab-org/archived-legacy.git",
-			WebURL:                   "https://gitlab.example.com/gitlab-org/archived-legacy",
-			StarCount:                5,
-			ForksCount:               1,
-			Visibility:               "private",
-			IssuesEnabled:            false,
-			MergeRequestsEnabled:     false,
-			WikiEnabled:              false,
-			JobsEnabled:              false,
-			SnippetsEnabled:          false,
-			ContainerRegistryEnabled: false,
-			EmptyRepo:                false,
-			Archived:                 true,
-			Owner: &UserBasic{
-				ID:       1,
-				Username: "root",
-				Name:     "Administrator",
-				State:    "active",
-			},
-		},
-	}
+// queryProjects returns the stored projects that keep and the params select, in the order they ask for.
+func (h *Handler) queryProjects(params *ProjectsQueryParams, keep func(*Project) bool) []Project {
+	store := h.store()
+	store.mu.Lock()
+	defer store.mu.Unlock()
 
 	// Apply filtering based on parameters
-	var filteredProjects []Project
-	for _, project := range projects {
+	filteredProjects := []Project{}
+	for i := range store.data.Projects {
+		project := &store.data.Projects[i]
+		if !keep(project) {
+			continue
+		}
+
 		// Filter by archived status
 		if params.Archived != nil && project.Archived != *params.Archived {
 			continue
@@ -370,38 +235,78 @@ func (h *Handler) generateMockProjects(params *ProjectsQueryParams) []Project {
 				Name:       project.Name,
 				Path:       project.Path,
 				WebURL:     project.WebURL,
+				CreatedAt:  project.CreatedAt,
 				Visibility: project.Visibility, // Include visibility for filtering validation
 			}
 			filteredProjects = append(filteredProjects, filteredProject)
 		} else {
-			filteredProjects = append(filteredProjects, project)
+			filteredProjects = append(filteredProjects, *project)
 		}
 	}
 
-	// Apply pagination
-	start := (params.Page - 1) * params.PerPage
-	end := start + params.PerPage
+	sortProjects(filteredProjects, params.OrderBy, params.Sort == "asc")
+	return filteredProjects
+}
 
-	if start >= len(filteredProjects) {
-		return []Project{}
-	}
+// sortProjects orders the projects by the field, breaking ties by id.
+func sortProjects(projects []Project, orderBy string, asc bool) {
+	cmpField := func(a, b *Project) int {
+		switch orderBy {
+		case "name":
+			return strings.Compare(a.Name, b.Name)
+		case "path":
+			return strings.Compare(a.Path, b.Path)
+		case "updated_at":
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		case "last_activity_at":
+			return a.LastActivityAt.Compare(b.LastActivityAt)
+		case "id":
+			return 0
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	}
+	slices.SortStableFunc(projects, func(a, b Project) int {
+		c := cmpField(&a, &b)
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if !asc {
+			c = -c
+		}
+		return c
+	})
+}
 
-	if end > len(filteredProjects) {
-		end = len(filteredProjects)
-	}
+// GetMergeRequests lists every merge request.
+func (h *Handler) GetMergeRequests(w http.ResponseWriter, r *http.Request) {
+	h.getMergeRequests(w, r, func(*MergeRequest) bool { return true })
+}
 
-	return filteredProjects[start:end]
+// GetGroupMergeRequests lists the merge requests of the projects of the group and its subgroups.
+func (h *Handler) GetGroupMergeRequests(w http.ResponseWriter, r *http.Request, group Group) {
+	projectIDs := h.groupProjectIDs(group)
+	h.getMergeRequests(w, r, func(mr *MergeRequest) bool {
+		return projectIDs[mr.ProjectID]
+	})
 }
 
-// max returns the maximum of two integers
-func max(a, b int) int {
-	if a > b {
-		return a
+// groupProjectIDs returns the ids of the projects of the group and its subgroups.
+func (h *Handler) groupProjectIDs(group Group) map[int]bool {
+	store := h.store()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	ids := map[int]bool{}
+	for i := range store.data.Projects {
+		if p := &store.data.Projects[i]; inGroup(p, &group, true) {
+			ids[p.ID] = true
+		}
 	}
-	return b
+	return ids
 }
 
-func (h *Handler) GetGroupsMergeRequests(w http.ResponseWriter, r *http.Request) {
+// getMergeRequests writes the page of the stored merge requests that keep and the query selects.
+func (h *Handler) getMergeRequests(w http.ResponseWriter, r *http.Request, keep func(*MergeRequest) bool) {
 	// Parse query parameters
 	params, err := h.parseMergeRequestsQueryParams(r)
 	if err != nil {
@@ -409,19 +314,8 @@ func (h *Handler) GetGroupsMergeRequests(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// For now, return mock merge requests based on the API specification
-	// In a real implementation, this would call h.service.requests.GetGroupMergeRequests(groupID, params)
-	requests, err := h.generateMockMergeRequests(params)
-	if err != nil {
-		h.OnServerError(w, "Failed to generate response", err)
-		return
-	}
-
-	setOnePagedHeaders(len(requests), params.PageQueryParams, w.Header())
-	if err := json.NewEncoder(w).Encode(requests); err != nil {
-		h.OnServerError(w, "Failed to encode response", err)
-		return
-	}
+	requests := h.queryMergeRequests(params, keep)
+	writePage(h, w, r, requests, params.PageQueryParams)
 }
 
 // parseMergeRequestsQueryParams parses the query parameters for the merge requests endpoint
@@ -597,37 +491,67 @@ func (h *Handler) parseMergeRequestsQueryParams(r *http.Request) (*MergeRequests
 	return params, nil
 }
 
-// generateMockMergeRequests generates mock merge requests for testing purposes
-func (h *Handler) generateMockMergeRequests(params *MergeRequestsQueryParamsV0) ([]MergeRequest, error) {
-	if h.requests == nil {
-		requests := []MergeRequest{}
-		for range 75 {
-			request := new(MergeRequest)
-			err := gofakeit.Struct(request)
-			if err != nil {
-				return nil, withstack.Errorf("Unmarshal error:%w", err)
-			}
-			requests = append(requests, *request)
-		}
-
-		h.requests = requests
-	}
+// queryMergeRequests returns the stored merge requests that keep and the params select, in the order they ask for.
+func (h *Handler) queryMergeRequests(params *MergeRequestsQueryParamsV0, keep func(*MergeRequest) bool) []MergeRequest {
+	store := h.store()
+	store.mu.Lock()
+	defer store.mu.Unlock()
 
 	// Apply filtering based on parameters
-	matches := make([]MergeRequest, 0, len(h.requests))
-	for _, mr := range h.requests {
+	matches := []MergeRequest{}
+	for i := range store.data.MergeRequests {
+		mr := &store.data.MergeRequests[i]
+		if !keep(mr) {
+			continue
+		}
+
 		// Filter by state
 		if params.State != "all" && mr.State != params.State {
 			continue
 		}
 
 		// Filter by author_id
-		if params.AuthorID != nil && mr.Author != nil && mr.Author.ID != *params.AuthorID {
+		if params.AuthorID != nil && (mr.Author == nil || mr.Author.ID != *params.AuthorID) {
+			continue
+		}
+
+		// Filter by not[author_id]
+		if params.NotAuthorID != nil && mr.Author != nil && mr.Author.ID == *params.NotAuthorID {
 			continue
 		}
 
 		// Filter by author_username
-		if params.AuthorUsername != "" && mr.Author != nil && mr.Author.Username != params.AuthorUsername {
+		if params.AuthorUsername != "" && (mr.Author == nil || mr.Author.Username != params.AuthorUsername) {
+			continue
+		}
+
+		// Filter by assignee_id
+		if params.AssigneeID != nil && !hasUser(mr.Assignees, *params.AssigneeID) {
+			continue
+		}
+
+		// Filter by assignee_username
+		if 0 < len(params.AssigneeUsername) && !hasUsername(mr.Assignees, params.AssigneeUsername[0]) {
+			continue
+		}
+
+		// Filter by reviewer_id
+		if params.ReviewerID != nil && !hasUser(mr.Reviewers, *params.ReviewerID) {
+			continue
+		}
+
+		// Filter by reviewer_username
+		if params.ReviewerUsername != "" && !hasUsername(mr.Reviewers, params.ReviewerUsername) {
+			continue
+		}
+
+		// Filter by labels, every label must be on the merge request
+		if !hasLabels(mr.Labels, params.Labels) {
+			continue
+		}
+
+		// Filter by wip
+		if (params.WIP == "yes" && !mr.Draft) || (params.WIP == "no" && mr.Draft) {
 			continue
 		}
 
@@ -683,55 +607,102 @@ func (h *Handler) generateMockMergeRequests(params *MergeRequestsQueryParamsV0)
 			}
 		}
 
-		matches = append(matches, mr)
+		matches = append(matches, *mr)
 	}
 
-	return matches, nil
+	sortMergeRequests(matches, params.OrderBy, params.Sort == "asc")
+	return matches
 }
 
-func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
-	// Parse query parameters
-	params, err := h.parseEventsQueryParams(r)
-	if err != nil {
-		http.Error(w, "Invalid query parameters: "+err.Error(), http.StatusBadRequest)
-		return
+// sortMergeRequests orders the merge requests by the field, breaking ties by id.
+func sortMergeRequests(requests []MergeRequest, orderBy string, asc bool) {
+	cmpField := func(a, b *MergeRequest) int {
+		switch orderBy {
+		case "title":
+			return strings.Compare(a.Title, b.Title)
+		case "updated_at":
+			return a.UpdatedAt.Compare(b.UpdatedAt)
+		case "merged_at":
+			return cmpTimes(a.MergedAt, b.MergedAt)
+		default:
+			return a.CreatedAt.Compare(b.CreatedAt)
+		}
+	}
+	slices.SortStableFunc(requests, func(a, b MergeRequest) int {
+		c := cmpField(&a, &b)
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if !asc {
+			c = -c
+		}
+		return c
+	})
+}
+
+// cmpTimes compares the times, nil before any time.
+func cmpTimes(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	default:
+		return a.Compare(*b)
 	}
+}
 
-	// For now, return mock events based on the API specification
-	// In a real implementation, this would call h.service.events.GetUserEvents(userID, params)
-	events, err := h.generateMockEvents(params)
-	if err != nil {
-		h.OnServerError(w, "Failed to generate response", err)
-		return
+func hasUser(users []UserBasic, id int) bool {
+	return slices.ContainsFunc(users, func(u UserBasic) bool { return u.ID == id })
+}
+
+func hasUsername(users []UserBasic, username string) bool {
+	return slices.ContainsFunc(users, func(u UserBasic) bool { return u.Username == username })
+}
+
+// hasLabels is true when every wanted label is in labels.
+// gitlab takes a comma separated list in one or more labels params.
+func hasLabels(labels []string, wanted []string) bool {
+	for _, w := range wanted {
+		for _, l := range splitLabels(w) {
+			if !slices.Contains(labels, l) {
+				return false
+			}
+		}
 	}
+	return true
+}
+
+// GetEvents lists every event.
+func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
+	h.getEvents(w, r, func(*Event) bool { return true })
+}
 
-	setOnePagedHeaders(len(events), params.PageQueryParams, w.Header())
-	if err := json.NewEncoder(w).Encode(events); err != nil {
-		h.OnServerError(w, "Failed to encode response", err)
+// GetProjectEvents lists the events of the project.
+func (h *Handler) GetProjectEvents(w http.ResponseWriter, r *http.Request, id string) {
+	project, ok := h.findProject(id)
+	if !ok {
+		http.Error(w, "404 Project Not Found", http.StatusNotFound)
 		return
 	}
+	h.getEvents(w, r, func(e *Event) bool {
+		return e.ProjectID != nil && *e.ProjectID == project.ID
+	})
 }
 
-// setOnePagedHeaders returns just a single page, ignoring query params
-//
-// we are assume all the items fit in a single page
-// but is actually controlled by Page and PerPage query param
-// so this actually not returning fully valid paging behavor
-// TODO - implement fully paged cursor through the service
-func setOnePagedHeaders(total int, params PageQueryParams, header http.Header) {
-	header.Set("Content-Type", "application/json")
-	// force a single page
-	header.Set("X-Per-Page", strconv.Itoa(params.PerPage))
-
-	totalPages := int(math.Ceil(float64(total) / float64(params.PerPage)))
-	prevPage := max(0, params.Page+1)
-	nextPage := min(params.Page+1, totalPages)
-
-	header.Set("X-Page", strconv.Itoa(params.Page))
-	header.Set("X-Prev-Page", strconv.Itoa(prevPage))
-	header.Set("X-Next-Page", strconv.Itoa(nextPage))
-	header.Set("X-Total-Pages", strconv.Itoa(totalPages))
-	header.Set("X-Total", strconv.Itoa(total))
+// getEvents writes the page of the stored events that keep and the query selects.
+func (h *Handler) getEvents(w http.ResponseWriter, r *http.Request, keep func(*Event) bool) {
+	// Parse query parameters
+	params, err := h.parseEventsQueryParams(r)
+	if err != nil {
+		http.Error(w, "Invalid query parameters: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events := h.queryEvents(params, keep)
+	writePage(h, w, r, events, params.PageQueryParams)
 }
 
 // parseEventsQueryParams parses the query parameters for the events endpoint
@@ -749,6 +720,13 @@ func (h *Handler) parseEventsQueryParams(r *http.Request) (*EventsQueryParams, e
 	// Parse action parameter
 	params.Action = r.URL.Query().Get("action")
 
+	// Parse author_id parameter
+	if authorIDStr := r.URL.Query().Get("author_id"); authorIDStr != "" {
+		if authorID, err := strconv.Atoi(authorIDStr); err == nil {
+			params.AuthorID = &authorID
+		}
+	}
+
 	// Parse target_type parameter
 	targetType := r.URL.Query().Get("target_type")
 	validTargetTypes := map[string]bool{
@@ -794,34 +772,37 @@ func parsePageParams(query url.Values, params *PageQueryParams) {
 	// description: Number of items per page
 	if perPageStr := query.Get("per_page"); perPageStr != "" {
 		if perPage, err := strconv.Atoi(perPageStr); err == nil && perPage > 0 {
-			params.PerPage = perPage
+			params.PerPage = min(perPage, maxPerPage)
 		}
 	}
 }
 
-// generateMockEvents generates mock events for testing purposes
-func (h *Handler) generateMockEvents(params *EventsQueryParams) ([]Event, error) {
-	// Generate mock events based on the API specification
-	events := []Event{}
-	for range 20 {
-		event := new(Event)
-		err := gofakeit.Struct(event)
-		if err != nil {
-			return nil, withstack.Errorf("Unmarshal error:%w", err)
-		}
-		events = append(events, *event)
-	}
+// queryEvents returns the stored events that keep and the params select, in the order they ask for.
+func (h *Handler) queryEvents(params *EventsQueryParams, keep func(*Event) bool) []Event {
+	store := h.store()
+	store.mu.Lock()
+	defer store.mu.Unlock()
 
 	// Apply filtering based on parameters
-	var filteredEvents []Event
-	for _, event := range events {
+	filteredEvents := []Event{}
+	for i := range store.data.Events {
+		event := &store.data.Events[i]
+		if !keep(event) {
+			continue
+		}
+
 		// Filter by action
-		if params.Action != "" && event.ActionName != params.Action {
+		if params.Action != "" && !isAction(event.ActionName, params.Action) {
 			continue
 		}
 
 		// Filter by target_type
-		if params.TargetType != "" && (event.TargetType == nil || *event.TargetType != params.TargetType) {
+		if params.TargetType != "" && (event.TargetType == nil || !isTargetType(*event.TargetType, params.TargetType)) {
+			continue
+		}
+
+		// Filter by author_id
+		if params.AuthorID != nil && event.AuthorID != *params.AuthorID {
 			continue
 		}
 
@@ -835,22 +816,41 @@ func (h *Handler) generateMockEvents(params *EventsQueryParams) ([]Event, error)
 			continue
 		}
 
-		filteredEvents = append(filteredEvents, event)
+		filteredEvents = append(filteredEvents, *event)
 	}
 
-	// Apply pagination
-	start := (params.Page - 1) * params.PerPage
-	end := start + params.PerPage
+	asc := params.Sort == "asc"
+	slices.SortStableFunc(filteredEvents, func(a, b Event) int {
+		c := a.CreatedAt.Compare(b.CreatedAt)
+		if c == 0 {
+			c = cmp.Compare(a.ID, b.ID)
+		}
+		if !asc {
+			c = -c
+		}
+		return c
+	})
+	return filteredEvents
+}
 
-	if start >= len(filteredEvents) {
-		return []Event{}, nil
-	}
+// isTargetType matches the target type of an event, MergeRequest, to the query param, merge_request.
+func isTargetType(targetType string, param string) bool {
+	return strings.EqualFold(strings.ReplaceAll(targetType, "_", ""), strings.ReplaceAll(param, "_", ""))
+}
 
-	if end > len(filteredEvents) {
-		end = len(filteredEvents)
+// isAction matches the action name of an event, pushed to, to the query param, pushed.
+// see https://docs.gitlab.com/ee/api/events.html#actions
+func isAction(actionName string, param string) bool {
+	switch param {
+	case "merged":
+		return actionName == "accepted"
+	case "pushed":
+		return strings.HasPrefix(actionName, "pushed")
+	case "commented":
+		return actionName == "commented on"
+	default:
+		return actionName == param
 	}
-
-	return filteredEvents[start:end], nil
 }
 
 // Helper functions for pointer creation
@@ -0,0 +1,181 @@
+package localhost
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// FixtureFile is the name of the dataset in a fixtures dir.
+const FixtureFile = "dataset.json"
+
+// adminPrefix is where the lab is controlled, it is not part of the gitlab api.
+const adminPrefix = "/__admin"
+
+// LabConfig sets up a fake gitlab served on a real listener.
+type LabConfig struct {
+	Seed     uint64 // seeds the dataset when the fixtures dir has none
+	Fixtures string // dir the dataset is loaded from and saved to, empty keeps it in memory
+	Token    string // bearer or private token every request must send, empty lets anyone in but the admin off localhost
+}
+
+// Lab is the fake gitlab of cmr fakelab.
+// It serves the routes of SetupRouter and the admin endpoints:
+//
+//	GET  /__admin/simulation  the faults in effect
+//	PUT  /__admin/simulation  set the faults, {"server_error_every": 3, "latency": "250ms"}
+//	GET  /__admin/dataset     the whole dataset
+//	PUT  /__admin/dataset     replace the whole dataset
+//	POST /__admin/reset       seed the dataset again
+//	POST /__admin/save        save the dataset to the fixtures dir
+type Lab struct {
+	cfg     LabConfig
+	handler *Handler
+	mux     *http.ServeMux
+}
+
+// NewLab loads the dataset of the fixtures dir, or seeds one as of now.
+func NewLab(cfg LabConfig) (*Lab, error) {
+	store, err := loadFixtures(cfg)
+	if err != nil {
+		return nil, err
+	}
+	l := &Lab{
+		cfg:     cfg,
+		handler: NewHandler(NewService(store)),
+	}
+	l.mux = SetupRouter(l.handler)
+	l.mux.HandleFunc(adminPrefix+"/", LoggingMiddleware(l.routeAdmin))
+	return l, nil
+}
+
+// loadFixtures returns the store of the fixtures dir, seeding it when there is none.
+func loadFixtures(cfg LabConfig) (*Store, error) {
+	if cfg.Fixtures != "" {
+		store, err := LoadStore(filepath.Join(cfg.Fixtures, FixtureFile))
+		if err == nil || !errors.Is(err, fs.ErrNotExist) {
+			return store, err
+		}
+	}
+	return NewStore(SeedDataset(cfg.Seed, time.Now())), nil
+}
+
+// Store returns what the lab holds.
+func (l *Lab) Store() *Store {
+	return l.handler.store()
+}
+
+// ServeHTTP checks the token then serves the request.
+// Without a token the admin endpoints only serve requests from localhost.
+func (l *Lab) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if l.cfg.Token != "" && requestToken(r) != l.cfg.Token {
+		http.Error(w, `{"message":"401 Unauthorized"}`, http.StatusUnauthorized)
+		return
+	}
+	if l.cfg.Token == "" && strings.HasPrefix(r.URL.Path, adminPrefix) && !isLoopback(r.RemoteAddr) {
+		http.Error(w, `{"message":"403 Forbidden, the admin endpoints need a token off localhost"}`, http.StatusForbidden)
+		return
+	}
+	l.mux.ServeHTTP(w, r)
+}
+
+// isLoopback returns true if the host of the remote address is a loopback address.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requestToken returns the token of the Authorization bearer or the PRIVATE-TOKEN header.
+func requestToken(r *http.Request) string {
+	if token := r.Header.Get("PRIVATE-TOKEN"); token != "" {
+		return token
+	}
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "Bearer") {
+		return token
+	}
+	return ""
+}
+
+// simulationBody is the wire form of Simulation, with a readable latency.
+type simulationBody struct {
+	RateLimitEvery   int    `json:"rate_limit_every"`
+	RetryAfter       int    `json:"retry_after"`
+	ServerErrorEvery int    `json:"server_error_every"`
+	Keyset           bool   `json:"keyset"`
+	Latency          string `json:"latency,omitempty"`
+}
+
+func (l *Lab) routeAdmin(w http.ResponseWriter, r *http.Request) {
+	route := r.Method + " " + strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, adminPrefix), "/")
+	switch route {
+	case "GET /simulation":
+		sim := l.handler.sim.get()
+		body := simulationBody{
+			RateLimitEvery:   sim.RateLimitEvery,
+			RetryAfter:       sim.RetryAfter,
+			ServerErrorEvery: sim.ServerErrorEvery,
+			Keyset:           sim.Keyset,
+		}
+		if 0 < sim.Latency {
+			body.Latency = sim.Latency.String()
+		}
+		l.handler.writeJSON(w, http.StatusOK, body)
+	case "PUT /simulation":
+		var body simulationBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		sim := Simulation{
+			RateLimitEvery:   body.RateLimitEvery,
+			RetryAfter:       body.RetryAfter,
+			ServerErrorEvery: body.ServerErrorEvery,
+			Keyset:           body.Keyset,
+		}
+		if body.Latency != "" {
+			latency, err := time.ParseDuration(body.Latency)
+			if err != nil {
+				http.Error(w, "Invalid latency: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+			sim.Latency = latency
+		}
+		l.handler.sim.set(sim)
+		w.WriteHeader(http.StatusNoContent)
+	case "GET /dataset":
+		l.handler.writeJSON(w, http.StatusOK, l.Store().Dataset())
+	case "PUT /dataset":
+		var data Dataset
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		l.Store().Replace(data)
+		w.WriteHeader(http.StatusNoContent)
+	case "POST /reset":
+		l.Store().Replace(SeedDataset(l.cfg.Seed, time.Now()))
+		w.WriteHeader(http.StatusNoContent)
+	case "POST /save":
+		if l.cfg.Fixtures == "" {
+			http.Error(w, "There is no fixtures dir to save to", http.StatusConflict)
+			return
+		}
+		if err := l.Store().Save(filepath.Join(l.cfg.Fixtures, FixtureFile)); err != nil {
+			l.handler.OnServerError(w, "Failed to save fixtures", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
@@ -0,0 +1,153 @@
+package localhost
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// do sends the request and returns the status.
+func do(t *testing.T, method, url, token, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestLabToken(t *testing.T) {
+	lab, err := NewLab(LabConfig{Seed: DefaultSeed, Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(lab)
+	defer server.Close()
+
+	if status := do(t, http.MethodGet, server.URL+"/api/v4/projects", "", ""); status != http.StatusUnauthorized {
+		t.Errorf("Expected status %d without a token, got %d", http.StatusUnauthorized, status)
+	}
+	if status := do(t, http.MethodGet, server.URL+"/api/v4/projects", "wrong", ""); status != http.StatusUnauthorized {
+		t.Errorf("Expected status %d with the wrong token, got %d", http.StatusUnauthorized, status)
+	}
+	if status := do(t, http.MethodGet, server.URL+"/api/v4/projects", "secret", ""); status != http.StatusOK {
+		t.Errorf("Expected status %d with the token, got %d", http.StatusOK, status)
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/api/v4/projects", nil)
+	req.Header.Set("PRIVATE-TOKEN", "secret")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status %d with a private token, got %d", http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestLabAdminOffLocalhost(t *testing.T) {
+	lab, err := NewLab(LabConfig{Seed: DefaultSeed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serve := func(path, remoteAddr string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		lab.ServeHTTP(w, req)
+		return w.Code
+	}
+	if status := serve("/__admin/reset", "192.168.1.7:50000"); status != http.StatusForbidden {
+		t.Errorf("Expected status %d for the admin off localhost, got %d", http.StatusForbidden, status)
+	}
+	if status := serve("/__admin/reset", "[::1]:50000"); status != http.StatusNoContent {
+		t.Errorf("Expected status %d for the admin on localhost, got %d", http.StatusNoContent, status)
+	}
+	if status := serve("/api/v4/projects", "192.168.1.7:50000"); status == http.StatusForbidden {
+		t.Errorf("Expected the api off localhost, got %d", status)
+	}
+
+	lab, err = NewLab(LabConfig{Seed: DefaultSeed, Token: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/__admin/reset", nil)
+	req.RemoteAddr = "192.168.1.7:50000"
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	lab.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Errorf("Expected status %d for the admin with the token, got %d", http.StatusNoContent, w.Code)
+	}
+}
+
+func TestLabAdmin(t *testing.T) {
+	dir := t.TempDir()
+	lab, err := NewLab(LabConfig{Seed: DefaultSeed, Fixtures: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(lab)
+	defer server.Close()
+
+	if status := do(t, http.MethodPut, server.URL+"/__admin/simulation", "",
+		`{"server_error_every":1,"latency":"1ms"}`); status != http.StatusNoContent {
+		t.Fatalf("Expected status %d setting the simulation, got %d", http.StatusNoContent, status)
+	}
+	var body simulationBody
+	getJSON(t, server.URL+"/__admin/simulation", &body)
+	if body.ServerErrorEvery != 1 || body.Latency != "1ms" {
+		t.Errorf("Expected the simulation to be kept, got %+v", body)
+	}
+	if status := do(t, http.MethodGet, server.URL+"/api/v4/projects", "", ""); status != http.StatusServiceUnavailable {
+		t.Errorf("Expected the injected status %d, got %d", http.StatusServiceUnavailable, status)
+	}
+	if status := do(t, http.MethodPut, server.URL+"/__admin/simulation", "", `{"latency":"soon"}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a bad latency, got %d", http.StatusBadRequest, status)
+	}
+	do(t, http.MethodPut, server.URL+"/__admin/simulation", "", `{}`)
+
+	if status := do(t, http.MethodPut, server.URL+"/__admin/dataset", "",
+		`{"projects":[{"id":7,"name":"only","path_with_namespace":"g/only"}]}`); status != http.StatusNoContent {
+		t.Fatalf("Expected status %d replacing the dataset, got %d", http.StatusNoContent, status)
+	}
+	var projects []Project
+	getJSON(t, server.URL+"/api/v4/projects", &projects)
+	if len(projects) != 1 || projects[0].ID != 7 {
+		t.Errorf("Expected the replaced project, got %d projects", len(projects))
+	}
+
+	if status := do(t, http.MethodPost, server.URL+"/__admin/save", "", ""); status != http.StatusNoContent {
+		t.Fatalf("Expected status %d saving, got %d", http.StatusNoContent, status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, FixtureFile)); err != nil {
+		t.Errorf("Expected the fixture to be saved: %v", err)
+	}
+	reloaded, err := NewLab(LabConfig{Seed: DefaultSeed, Fixtures: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := reloaded.Store().Dataset(); len(data.Projects) != 1 {
+		t.Errorf("Expected the saved fixture to be loaded, got %d projects", len(data.Projects))
+	}
+
+	if status := do(t, http.MethodPost, server.URL+"/__admin/reset", "", ""); status != http.StatusNoContent {
+		t.Fatalf("Expected status %d resetting, got %d", http.StatusNoContent, status)
+	}
+	getJSON(t, server.URL+"/api/v4/projects", &projects)
+	if len(projects) != 3 {
+		t.Errorf("Expected the seeded projects after a reset, got %d", len(projects))
+	}
+}
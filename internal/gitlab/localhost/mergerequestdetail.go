@@ -0,0 +1,306 @@
+package localhost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Discussion is a thread of notes.
+type Discussion struct {
+	ID             string `json:"id"`
+	IndividualNote bool   `json:"individual_note"`
+	Notes          []Note `json:"notes"`
+}
+
+// Note is one comment of a discussion.
+type Note struct {
+	ID           int       `json:"id"`
+	Body         string    `json:"body"`
+	Author       UserBasic `json:"author"`
+	CreatedAt    time.Time `json:"created_at"`
+	System       bool      `json:"system"`
+	NoteableID   int       `json:"noteable_id"`
+	NoteableIID  int       `json:"noteable_iid"`
+	NoteableType string    `json:"noteable_type"`
+	Resolvable   bool      `json:"resolvable"`
+}
+
+// Diff is the change of one file.
+type Diff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	AMode       string `json:"a_mode"`
+	BMode       string `json:"b_mode"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// Pipeline is the summary of a pipeline.
+type Pipeline struct {
+	ID        int       `json:"id"`
+	IID       int       `json:"iid"`
+	ProjectID int       `json:"project_id"`
+	SHA       string    `json:"sha"`
+	Ref       string    `json:"ref"`
+	Status    string    `json:"status"`
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	WebURL    string    `json:"web_url"`
+}
+
+// Job is one job of a pipeline.
+type Job struct {
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Stage        string    `json:"stage"`
+	Status       string    `json:"status"`
+	AllowFailure bool      `json:"allow_failure"`
+	Duration     float64   `json:"duration"`
+	CreatedAt    time.Time `json:"created_at"`
+	StartedAt    time.Time `json:"started_at"`
+	FinishedAt   time.Time `json:"finished_at"`
+	WebURL       string    `json:"web_url"`
+}
+
+// pipelinesPerRequest spaces the fake pipeline ids of each merge request.
+const pipelinesPerRequest = 10
+
+// mergeRequestDiscussions derives the discussions of the merge request.
+func mergeRequestDiscussions(mr MergeRequest) []Discussion {
+	author := currentUser
+	if mr.Author != nil {
+		author = *mr.Author
+	}
+	note := func(n int, body string, by UserBasic, system bool) Note {
+		return Note{
+			ID:           mr.ID*100 + n,
+			Body:         body,
+			Author:       by,
+			CreatedAt:    mr.CreatedAt.Add(time.Duration(n) * time.Hour),
+			System:       system,
+			NoteableID:   mr.ID,
+			NoteableIID:  mr.IID,
+			NoteableType: "MergeRequest",
+			Resolvable:   !system,
+		}
+	}
+	return []Discussion{
+		{
+			ID:             fmt.Sprintf("%040x", mr.ID*100+1),
+			IndividualNote: true,
+			Notes:          []Note{note(1, "requested review from @"+currentUser.Username, author, true)},
+		},
+		{
+			ID: fmt.Sprintf("%040x", mr.ID*100+2),
+			Notes: []Note{
+				note(2, "Could this use the existing helper?", currentUser, false),
+				note(3, "Good point, done.", author, false),
+			},
+		},
+	}
+}
+
+// mergeRequestDiffs derives the changed files of the merge request.
+func mergeRequestDiffs(mr MergeRequest) []Diff {
+	return []Diff{
+		{
+			OldPath: "README.md",
+			NewPath: "README.md",
+			AMode:   "100644",
+			BMode:   "100644",
+			Diff:    "@@ -1,3 +1,4 @@\n # project\n-old line\n+" + mr.Title + "\n+another line\n context\n",
+		},
+		{
+			OldPath: "cmd/new.go",
+			NewPath: "cmd/new.go",
+			BMode:   "100644",
+			Diff:    "@@ -0,0 +1,3 @@\n+package cmd\n+\n+// new file\n",
+			NewFile: true,
+		},
+	}
+}
+
+// mergeRequestPipelines derives the pipelines of the merge request, newest first.
+func mergeRequestPipelines(mr MergeRequest) []Pipeline {
+	pipeline := func(n int, status string) Pipeline {
+		id := mr.ID*pipelinesPerRequest + n
+		return Pipeline{
+			ID:        id,
+			IID:       n,
+			ProjectID: mr.ProjectID,
+			SHA:       mr.SHA,
+			Ref:       mr.SourceBranch,
+			Status:    status,
+			Source:    "merge_request_event",
+			CreatedAt: mr.CreatedAt.Add(time.Duration(n) * time.Minute),
+			UpdatedAt: mr.CreatedAt.Add(time.Duration(n+5) * time.Minute),
+			WebURL:    fmt.Sprintf("%s/-/pipelines/%d", mr.WebURL, id),
+		}
+	}
+	return []Pipeline{pipeline(2, "failed"), pipeline(1, "success")}
+}
+
+// pipelineJobs derives the jobs of the pipeline.
+func pipelineJobs(pipelineID int) []Job {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	job := func(n int, name, stage, status string, allowFailure bool) Job {
+		return Job{
+			ID:           pipelineID*100 + n,
+			Name:         name,
+			Stage:        stage,
+			Status:       status,
+			AllowFailure: allowFailure,
+			Duration:     float64(30 * n),
+			CreatedAt:    start,
+			StartedAt:    start,
+			FinishedAt:   start.Add(time.Duration(30*n) * time.Second),
+			WebURL:       fmt.Sprintf("https://gitlab.example.com/-/jobs/%d", pipelineID*100+n),
+		}
+	}
+	testStatus := "success"
+	if pipelineID%pipelinesPerRequest == 2 {
+		testStatus = "failed"
+	}
+	return []Job{
+		job(1, "build", "build", "success", false),
+		job(2, "test", "test", testStatus, false),
+		job(3, "lint", "test", "failed", true),
+	}
+}
+
+func (h *Handler) GetProjectMergeRequestDiscussions(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	discussions := mergeRequestDiscussions(*mr)
+	for _, note := range h.store().data.Notes[mr.ID] {
+		discussions = append(discussions, Discussion{
+			ID:             fmt.Sprintf("%040x", note.ID),
+			IndividualNote: true,
+			Notes:          []Note{note},
+		})
+	}
+	h.store().mu.Unlock()
+	writePage(h, w, r, discussions, parsePage(r))
+}
+
+func (h *Handler) CreateProjectMergeRequestNote(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	var body struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Body == "" {
+		http.Error(w, "400 (Bad request) \"body\" not given", http.StatusBadRequest)
+		return
+	}
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	defer h.store().mu.Unlock()
+
+	store := h.store()
+	// past the ids of the derived notes
+	n := len(store.data.Notes[mr.ID]) + 10
+	note := Note{
+		ID:           mr.ID*100 + n,
+		Body:         body.Body,
+		Author:       currentUser,
+		CreatedAt:    time.Now(),
+		NoteableID:   mr.ID,
+		NoteableIID:  mr.IID,
+		NoteableType: "MergeRequest",
+		Resolvable:   true,
+	}
+	store.data.Notes[mr.ID] = append(store.data.Notes[mr.ID], note)
+	mr.UpdatedAt = note.CreatedAt
+	mr.UserNotesCount++
+	store.addEvent("commented on", currentUser, mr, note.CreatedAt)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(note); err != nil {
+		h.OnServerError(w, "Failed to encode response", err)
+	}
+}
+
+func (h *Handler) GetProjectMergeRequestDiffs(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	diffs := mergeRequestDiffs(*mr)
+	h.store().mu.Unlock()
+	writePage(h, w, r, diffs, parsePage(r))
+}
+
+func (h *Handler) GetProjectMergeRequestPipelines(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	pipelines := mergeRequestPipelines(*mr)
+	h.store().mu.Unlock()
+	writePage(h, w, r, pipelines, parsePage(r))
+}
+
+// Approvals is the approval state of a merge request.
+type Approvals struct {
+	Approved          bool       `json:"approved"`
+	ApprovalsRequired int        `json:"approvals_required"`
+	ApprovalsLeft     int        `json:"approvals_left"`
+	ApprovedBy        []Approver `json:"approved_by"`
+}
+
+type Approver struct {
+	User UserBasic `json:"user"`
+}
+
+// approvalsRequired is the approvals every merge request of the fake needs.
+const approvalsRequired = 1
+
+func mergeRequestApprovals(approvers []UserBasic) Approvals {
+	a := Approvals{
+		ApprovalsRequired: approvalsRequired,
+		ApprovalsLeft:     max(0, approvalsRequired-len(approvers)),
+		ApprovedBy:        []Approver{},
+	}
+	a.Approved = a.ApprovalsLeft == 0
+	for _, u := range approvers {
+		a.ApprovedBy = append(a.ApprovedBy, Approver{User: u})
+	}
+	return a
+}
+
+func (h *Handler) GetProjectMergeRequestApprovals(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	approvals := mergeRequestApprovals(h.store().data.Approvals[mr.ID])
+	h.store().mu.Unlock()
+	h.writeJSON(w, http.StatusOK, approvals)
+}
+
+func (h *Handler) GetProjectPipelineJobs(w http.ResponseWriter, r *http.Request, id string, pipelineID string) {
+	if _, ok := h.findProject(id); !ok {
+		http.Error(w, "404 Project Not Found", http.StatusNotFound)
+		return
+	}
+	pid, err := strconv.Atoi(pipelineID)
+	if err != nil {
+		http.Error(w, "Invalid pipeline id: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	writePage(h, w, r, pipelineJobs(pid), parsePage(r))
+}
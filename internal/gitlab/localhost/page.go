@@ -0,0 +1,71 @@
+package localhost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxPerPage is the most items gitlab returns in a page.
+const maxPerPage = 100
+
+// parsePage returns the page the request asks for.
+func parsePage(r *http.Request) PageQueryParams {
+	params := PageQueryParams{Page: 1, PerPage: 20}
+	parsePageParams(r.URL.Query(), &params)
+	return params
+}
+
+// writePage encodes the page of the items the params ask for
+// with the offset paging headers gitlab sends.
+// see https://docs.gitlab.com/ee/api/rest/#pagination
+func writePage[T any](h *Handler, w http.ResponseWriter, r *http.Request, items []T, params PageQueryParams) {
+	total := len(items)
+	totalPages := max(1, (total+params.PerPage-1)/params.PerPage)
+	start := min(total, (params.Page-1)*params.PerPage)
+	end := min(total, start+params.PerPage)
+	page := items[start:end]
+	if page == nil {
+		page = []T{}
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", "application/json")
+	header.Set("X-Page", strconv.Itoa(params.Page))
+	header.Set("X-Per-Page", strconv.Itoa(params.PerPage))
+	header.Set("X-Total", strconv.Itoa(total))
+	header.Set("X-Total-Pages", strconv.Itoa(totalPages))
+	// gitlab leaves the next and prev pages empty past the ends
+	header.Set("X-Next-Page", "")
+	header.Set("X-Prev-Page", "")
+	links := []string{}
+	if params.Page < totalPages {
+		header.Set("X-Next-Page", strconv.Itoa(params.Page+1))
+		links = append(links, pageLink(r, params.Page+1, "next"))
+	}
+	if 1 < params.Page {
+		prev := min(params.Page-1, totalPages)
+		header.Set("X-Prev-Page", strconv.Itoa(prev))
+		links = append(links, pageLink(r, prev, "prev"))
+	}
+	links = append(links, pageLink(r, 1, "first"), pageLink(r, totalPages, "last"))
+	header.Set("Link", strings.Join(links, ", "))
+
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		h.OnServerError(w, "Failed to encode response", err)
+		return
+	}
+}
+
+// pageLink returns the Link header entry of the page of the request.
+func pageLink(r *http.Request, page int, rel string) string {
+	u := *r.URL
+	u.Scheme = "http"
+	u.Host = r.Host
+	q := u.Query()
+	q.Set("page", strconv.Itoa(page))
+	u.RawQuery = q.Encode()
+	return fmt.Sprintf(`<%s>; rel="%s"`, u.String(), rel)
+}
@@ -0,0 +1,479 @@
+package localhost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const projectsPrefix = "/api/v4/projects/"
+
+// splitProjectPath returns the project id and the remaining path segments of
+// /api/v4/projects/{id}/segments...
+// The id may be numeric or the url encoded path with namespace.
+func splitProjectPath(r *http.Request) (string, []string, error) {
+	rest := strings.TrimPrefix(r.URL.EscapedPath(), projectsPrefix)
+	segments := strings.Split(strings.Trim(rest, "/"), "/")
+	id, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return "", nil, err
+	}
+	return id, segments[1:], nil
+}
+
+// findProject returns a copy of the project with the numeric id or path with namespace.
+func (h *Handler) findProject(id string) (*Project, bool) {
+	store := h.store()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	p, ok := store.findProject(id)
+	if !ok {
+		return nil, false
+	}
+	project := *p
+	return &project, true
+}
+
+// RouteProjects dispatches the /api/v4/projects/ endpoints.
+func (h *Handler) RouteProjects(w http.ResponseWriter, r *http.Request) {
+	id, segments, err := splitProjectPath(r)
+	if err != nil {
+		http.Error(w, "Invalid project id: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if id == "" {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetProjects(w, r)
+		return
+	}
+	if len(segments) == 0 {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.GetProject(w, r, id)
+		return
+	}
+	if segments[0] == "events" && len(segments) == 1 && r.Method == http.MethodGet {
+		h.GetProjectEvents(w, r, id)
+		return
+	}
+	if segments[0] == "pipelines" && len(segments) == 3 && segments[2] == "jobs" && r.Method == http.MethodGet {
+		h.GetProjectPipelineJobs(w, r, id, segments[1])
+		return
+	}
+	if segments[0] != "merge_requests" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	h.routeProjectMergeRequests(w, r, id, segments[1:])
+}
+
+// routeProjectMergeRequests dispatches the /api/v4/projects/{id}/merge_requests endpoints.
+func (h *Handler) routeProjectMergeRequests(w http.ResponseWriter, r *http.Request, id string, segments []string) {
+	if len(segments) == 0 {
+		switch r.Method {
+		case http.MethodGet:
+			h.GetProjectMergeRequests(w, r, id)
+		case http.MethodPost:
+			h.CreateProjectMergeRequest(w, r, id)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+		return
+	}
+
+	iid, err := strconv.Atoi(segments[0])
+	if err != nil {
+		http.Error(w, "Invalid merge request iid: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	action := ""
+	if 1 < len(segments) {
+		action = strings.Join(segments[1:], "/")
+	}
+	switch {
+	case action == "" && r.Method == http.MethodGet:
+		h.GetProjectMergeRequest(w, r, id, iid)
+	case action == "" && r.Method == http.MethodPut:
+		h.UpdateProjectMergeRequest(w, r, id, iid)
+	case action == "approve" && r.Method == http.MethodPost:
+		h.ApproveProjectMergeRequest(w, r, id, iid)
+	case action == "unapprove" && r.Method == http.MethodPost:
+		h.UnapproveProjectMergeRequest(w, r, id, iid)
+	case action == "merge" && r.Method == http.MethodPut:
+		h.MergeProjectMergeRequest(w, r, id, iid)
+	case action == "notes" && r.Method == http.MethodPost:
+		h.CreateProjectMergeRequestNote(w, r, id, iid)
+	case action == "discussions" && r.Method == http.MethodGet:
+		h.GetProjectMergeRequestDiscussions(w, r, id, iid)
+	case action == "diffs" && r.Method == http.MethodGet:
+		h.GetProjectMergeRequestDiffs(w, r, id, iid)
+	case action == "pipelines" && r.Method == http.MethodGet:
+		h.GetProjectMergeRequestPipelines(w, r, id, iid)
+	case action == "approvals" && r.Method == http.MethodGet:
+		h.GetProjectMergeRequestApprovals(w, r, id, iid)
+	case action == "" || action == "approve" || action == "unapprove" || action == "merge":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+	}
+}
+
+func (h *Handler) GetProject(w http.ResponseWriter, r *http.Request, id string) {
+	project, ok := h.findProject(id)
+	if !ok {
+		http.Error(w, "404 Project Not Found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(project); err != nil {
+		h.OnServerError(w, "Failed to encode response", err)
+		return
+	}
+}
+
+func (h *Handler) GetProjectMergeRequests(w http.ResponseWriter, r *http.Request, id string) {
+	project, ok := h.findProject(id)
+	if !ok {
+		http.Error(w, "404 Project Not Found", http.StatusNotFound)
+		return
+	}
+	h.getMergeRequests(w, r, func(mr *MergeRequest) bool {
+		return mr.ProjectID == project.ID
+	})
+}
+
+// CreateMergeRequestBody is the subset of the create merge request attributes the fake accepts.
+type CreateMergeRequestBody struct {
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+}
+
+func (h *Handler) CreateProjectMergeRequest(w http.ResponseWriter, r *http.Request, id string) {
+	project, ok := h.findProject(id)
+	if !ok {
+		http.Error(w, "404 Project Not Found", http.StatusNotFound)
+		return
+	}
+	var body CreateMergeRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.SourceBranch == "" || body.TargetBranch == "" || body.Title == "" {
+		http.Error(w, "source_branch, target_branch and title are required", http.StatusBadRequest)
+		return
+	}
+
+	store := h.store()
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	nextID, nextIID := 1, 1
+	for _, mr := range store.data.MergeRequests {
+		if mr.ProjectID == project.ID && mr.State == "opened" && mr.SourceBranch == body.SourceBranch {
+			http.Error(w, "Another open merge request already exists for this source branch", http.StatusConflict)
+			return
+		}
+		nextID = max(nextID, mr.ID+1)
+		if mr.ProjectID == project.ID {
+			nextIID = max(nextIID, mr.IID+1)
+		}
+	}
+	now := time.Now()
+	mr := MergeRequest{
+		ID:              nextID,
+		IID:             nextIID,
+		ProjectID:       project.ID,
+		Title:           body.Title,
+		Description:     body.Description,
+		State:           "opened",
+		Draft:           isDraftTitle(body.Title),
+		WorkInProgress:  isDraftTitle(body.Title),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		SourceBranch:    body.SourceBranch,
+		TargetBranch:    body.TargetBranch,
+		Author:          &currentUser,
+		SourceProjectID: project.ID,
+		TargetProjectID: project.ID,
+		Labels:          []string{},
+		MergeStatus:     "unchecked",
+		SHA:             fmt.Sprintf("%040x", nextID),
+		References: References{
+			Short:    fmt.Sprintf("!%d", nextIID),
+			Relative: fmt.Sprintf("!%d", nextIID),
+			Full:     fmt.Sprintf("%s!%d", project.PathWithNamespace, nextIID),
+		},
+		WebURL: fmt.Sprintf("%s/-/merge_requests/%d", project.WebURL, nextIID),
+	}
+	store.data.MergeRequests = append(store.data.MergeRequests, mr)
+	store.addEvent("opened", currentUser, &mr, now)
+	h.writeMergeRequest(w, http.StatusCreated, mr)
+}
+
+// currentUser is the user the fake acts as for writes.
+var currentUser = UserBasic{
+	ID:       1,
+	Username: "root",
+	Name:     "Administrator",
+	State:    "active",
+}
+
+// writeMergeRequest encodes the merge request with status.
+func (h *Handler) writeMergeRequest(w http.ResponseWriter, status int, mr MergeRequest) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(mr); err != nil {
+		h.OnServerError(w, "Failed to encode response", err)
+		return
+	}
+}
+
+// lockMergeRequest finds the merge request of the project and holds the store lock on success.
+// The caller must call h.store().mu.Unlock() when ok.
+func (h *Handler) lockMergeRequest(w http.ResponseWriter, id string, iid int) (*MergeRequest, bool) {
+	store := h.store()
+	store.mu.Lock()
+	project, ok := store.findProject(id)
+	if !ok {
+		store.mu.Unlock()
+		http.Error(w, "404 Project Not Found", http.StatusNotFound)
+		return nil, false
+	}
+	for i := range store.data.MergeRequests {
+		if mr := &store.data.MergeRequests[i]; mr.ProjectID == project.ID && mr.IID == iid {
+			return mr, true
+		}
+	}
+	store.mu.Unlock()
+	http.Error(w, "404 Not found", http.StatusNotFound)
+	return nil, false
+}
+
+func (h *Handler) GetProjectMergeRequest(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	defer h.store().mu.Unlock()
+	h.writeMergeRequest(w, http.StatusOK, *mr)
+}
+
+// UpdateMergeRequestBody is the subset of the update merge request attributes the fake accepts.
+type UpdateMergeRequestBody struct {
+	Title        *string `json:"title"`
+	Description  *string `json:"description"`
+	Labels       *string `json:"labels"`
+	AddLabels    *string `json:"add_labels"`
+	RemoveLabels *string `json:"remove_labels"`
+	AssigneeIDs  *[]int  `json:"assignee_ids"`
+	ReviewerIDs  *[]int  `json:"reviewer_ids"`
+	TargetBranch *string `json:"target_branch"`
+	StateEvent   *string `json:"state_event"`
+}
+
+func (h *Handler) UpdateProjectMergeRequest(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	var body UpdateMergeRequestBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	defer h.store().mu.Unlock()
+
+	if body.Title != nil {
+		mr.Title = *body.Title
+		mr.Draft = isDraftTitle(mr.Title)
+		mr.WorkInProgress = mr.Draft
+	}
+	if body.Description != nil {
+		mr.Description = *body.Description
+	}
+	if body.Labels != nil {
+		mr.Labels = splitLabels(*body.Labels)
+	}
+	if body.AddLabels != nil {
+		for _, l := range splitLabels(*body.AddLabels) {
+			if !slices.Contains(mr.Labels, l) {
+				mr.Labels = append(mr.Labels, l)
+			}
+		}
+	}
+	if body.RemoveLabels != nil {
+		remove := splitLabels(*body.RemoveLabels)
+		mr.Labels = slices.DeleteFunc(mr.Labels, func(l string) bool {
+			return slices.Contains(remove, l)
+		})
+	}
+	if body.AssigneeIDs != nil {
+		mr.Assignees = h.store().users(*body.AssigneeIDs)
+		mr.Assignee = nil
+		if 0 < len(mr.Assignees) {
+			mr.Assignee = &mr.Assignees[0]
+		}
+	}
+	if body.ReviewerIDs != nil {
+		mr.Reviewers = h.store().users(*body.ReviewerIDs)
+	}
+	if body.TargetBranch != nil {
+		mr.TargetBranch = *body.TargetBranch
+	}
+	if body.StateEvent != nil {
+		switch *body.StateEvent {
+		case "close":
+			now := time.Now()
+			mr.State = "closed"
+			mr.ClosedAt = &now
+			mr.ClosedBy = &currentUser
+			h.store().addEvent("closed", currentUser, mr, now)
+		case "reopen":
+			mr.State = "opened"
+			mr.ClosedAt = nil
+			mr.ClosedBy = nil
+			h.store().addEvent("reopened", currentUser, mr, time.Now())
+		default:
+			http.Error(w, "state_event does not have a valid value", http.StatusBadRequest)
+			return
+		}
+	}
+	mr.UpdatedAt = time.Now()
+	h.writeMergeRequest(w, http.StatusOK, *mr)
+}
+
+func (h *Handler) ApproveProjectMergeRequest(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	var body struct {
+		Sha string `json:"sha"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	defer h.store().mu.Unlock()
+
+	if body.Sha != "" && body.Sha != mr.SHA {
+		http.Error(w, "SHA does not match HEAD of source branch", http.StatusConflict)
+		return
+	}
+	store := h.store()
+	if hasUser(store.data.Approvals[mr.ID], currentUser.ID) {
+		http.Error(w, "401 Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	store.data.Approvals[mr.ID] = append(store.data.Approvals[mr.ID], currentUser)
+	mr.UpdatedAt = time.Now()
+	store.addEvent("approved", currentUser, mr, mr.UpdatedAt)
+	h.writeJSON(w, http.StatusCreated, mergeRequestApprovals(store.data.Approvals[mr.ID]))
+}
+
+func (h *Handler) UnapproveProjectMergeRequest(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	defer h.store().mu.Unlock()
+
+	store := h.store()
+	if !hasUser(store.data.Approvals[mr.ID], currentUser.ID) {
+		http.Error(w, "404 Not found", http.StatusNotFound)
+		return
+	}
+	store.data.Approvals[mr.ID] = slices.DeleteFunc(store.data.Approvals[mr.ID], func(u UserBasic) bool {
+		return u.ID == currentUser.ID
+	})
+	mr.UpdatedAt = time.Now()
+	h.writeJSON(w, http.StatusCreated, mergeRequestApprovals(store.data.Approvals[mr.ID]))
+}
+
+// MergeBody is the subset of the accept merge request attributes the fake accepts.
+type MergeBody struct {
+	Squash                    bool   `json:"squash"`
+	ShouldRemoveSourceBranch  bool   `json:"should_remove_source_branch"`
+	MergeWhenPipelineSucceeds bool   `json:"merge_when_pipeline_succeeds"`
+	Sha                       string `json:"sha"`
+}
+
+func (h *Handler) MergeProjectMergeRequest(w http.ResponseWriter, r *http.Request, id string, iid int) {
+	var body MergeBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	mr, ok := h.lockMergeRequest(w, id, iid)
+	if !ok {
+		return
+	}
+	defer h.store().mu.Unlock()
+
+	if mr.State != "opened" || mr.Draft {
+		http.Error(w, "405 Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if body.Sha != "" && body.Sha != mr.SHA {
+		http.Error(w, "SHA does not match HEAD of source branch", http.StatusConflict)
+		return
+	}
+	now := time.Now()
+	mr.UpdatedAt = now
+	mr.Squash = body.Squash
+	mr.ShouldRemoveSourceBranch = body.ShouldRemoveSourceBranch
+	if body.MergeWhenPipelineSucceeds {
+		mr.MergeWhenPipelineSucceeds = true
+		mr.MergeUser = &currentUser
+	} else {
+		mr.State = "merged"
+		mr.MergedAt = &now
+		mr.MergedBy = &currentUser
+		mr.MergeUser = &currentUser
+		h.store().addEvent("accepted", currentUser, mr, now)
+	}
+	h.writeMergeRequest(w, http.StatusOK, *mr)
+}
+
+func isDraftTitle(title string) bool {
+	lower := strings.ToLower(title)
+	return strings.HasPrefix(lower, "draft:") ||
+		strings.HasPrefix(lower, "[draft]") ||
+		strings.HasPrefix(lower, "(draft)")
+}
+
+func splitLabels(labels string) []string {
+	split := []string{}
+	for _, l := range strings.Split(labels, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			split = append(split, l)
+		}
+	}
+	return split
+}
+
+// fakeUsers returns a basic user for each id.
+func fakeUsers(ids []int) []UserBasic {
+	users := make([]UserBasic, 0, len(ids))
+	for _, id := range ids {
+		users = append(users, UserBasic{
+			ID:       id,
+			Username: fmt.Sprintf("user%d", id),
+			Name:     fmt.Sprintf("User %d", id),
+			State:    "active",
+		})
+	}
+	return users
+}
@@ -0,0 +1,267 @@
+package localhost
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v7"
+)
+
+// DefaultSeed is the seed of the dataset of NewServer.
+const DefaultSeed = 42
+
+// seededMergeRequests is how many merge requests SeedDataset makes up.
+const seededMergeRequests = 75
+
+// webHost is the made up host of the web urls.
+const webHost = "https://gitlab.example.com"
+
+// SeedDataset makes up a consistent dataset of users, groups, projects,
+// merge requests and their events in the 90 days before now.
+// The same seed and now give the same dataset.
+func SeedDataset(seed uint64, now time.Time) Dataset {
+	f := gofakeit.New(seed)
+	data := Dataset{
+		Users:     seedUsers(f),
+		Groups:    seedGroups(),
+		Projects:  seedProjects(now),
+		Notes:     map[int][]Note{},
+		Approvals: map[int][]UserBasic{},
+	}
+	data.MergeRequests = seedMergeRequests(f, now, data.Users, data.Projects)
+	for i := range data.MergeRequests {
+		mr := &data.MergeRequests[i]
+		if mr.State == "merged" {
+			data.Approvals[mr.ID] = []UserBasic{*mr.MergedBy}
+		}
+	}
+	data.Events = seedEvents(data.MergeRequests)
+	return data
+}
+
+func seedUsers(f *gofakeit.Faker) []UserBasic {
+	users := []UserBasic{
+		currentUser,
+		{ID: 2, Username: "developer", Name: "Developer User", State: "active"},
+	}
+	for i := range 10 {
+		users = append(users, UserBasic{
+			ID:       100 + i,
+			Username: strings.ToLower(f.Username()),
+			Name:     f.Name(),
+			State:    "active",
+		})
+	}
+	for i := range users {
+		users[i].WebURL = webHost + "/" + users[i].Username
+	}
+	return users
+}
+
+func seedGroups() []Group {
+	return []Group{
+		{
+			ID:       123,
+			Name:     "GitLab.org",
+			Path:     "gitlab-org",
+			FullName: "GitLab.org",
+			FullPath: "gitlab-org",
+			WebURL:   webHost + "/groups/gitlab-org",
+		},
+	}
+}
+
+func seedProjects(now time.Time) []Project {
+	const day = 24 * time.Hour
+	return []Project{
+		{
+			ID:                       25,
+			Name:                     "gitlab-foss",
+			NameWithNamespace:        "GitLab.org / gitlab-foss",
+			Path:                     "gitlab-foss",
+			PathWithNamespace:        "gitlab-org/gitlab-foss",
+			Description:              "GitLab Community Edition",
+			CreatedAt:                now.Add(-365 * day),
+			UpdatedAt:                now.Add(-day),
+			LastActivityAt:           now.Add(-12 * time.Hour),
+			DefaultBranch:            "main",
+			TagList:                  []string{"ruby", "rails", "git"},
+			Topics:                   []string{"git", "version-control", "collaboration"},
+			SSHURLToRepo:             "git@gitlab.example.com:gitlab-org/gitlab-foss.git",
+			HTTPURLToRepo:            webHost + "/gitlab-org/gitlab-foss.git",
+			WebURL:                   webHost + "/gitlab-org/gitlab-foss",
+			ReadmeURL:                webHost + "/gitlab-org/gitlab-foss/-/blob/main/README.md",
+			AvatarURL:                webHost + "/uploads/project/avatar/25/gitlab_logo.png",
+			StarCount:                2345,
+			ForksCount:               589,
+			Visibility:               "public",
+			IssuesEnabled:            true,
+			MergeRequestsEnabled:     true,
+			WikiEnabled:              true,
+			JobsEnabled:              true,
+			SnippetsEnabled:          true,
+			ContainerRegistryEnabled: true,
+			Owner:                    &UserBasic{ID: 1, Username: "root", Name: "Administrator", State: "active"},
+		},
+		{
+			ID:                   30,
+			Name:                 "awesome-project",
+			NameWithNamespace:    "GitLab.org / awesome-project",
+			Path:                 "awesome-project",
+			PathWithNamespace:    "gitlab-org/awesome-project",
+			Description:          "An awesome project for demonstration",
+			CreatedAt:            now.Add(-180 * day),
+			UpdatedAt:            now.Add(-2 * day),
+			LastActivityAt:       now.Add(-day),
+			DefaultBranch:        "develop",
+			TagList:              []string{"javascript", "nodejs", "react"},
+			Topics:               []string{"frontend", "web", "javascript"},
+			SSHURLToRepo:         "git@gitlab.example.com:gitlab-org/awesome-project.git",
+			HTTPURLToRepo:        webHost + "/gitlab-org/awesome-project.git",
+			WebURL:               webHost + "/gitlab-org/awesome-project",
+			ReadmeURL:            webHost + "/gitlab-org/awesome-project/-/blob/develop/README.md",
+			StarCount:            125,
+			ForksCount:           34,
+			Visibility:           "internal",
+			IssuesEnabled:        true,
+			MergeRequestsEnabled: true,
+			JobsEnabled:          true,
+			Owner:                &UserBasic{ID: 2, Username: "developer", Name: "Developer User", State: "active"},
+		},
+		{
+			ID:                45,
+			Name:              "archived-legacy",
+			NameWithNamespace: "GitLab.org / archived-legacy",
+			Path:              "archived-legacy",
+			PathWithNamespace: "gitlab-org/archived-legacy",
+			Description:       "Legacy project that has been archived",
+			CreatedAt:         now.Add(-730 * day),
+			UpdatedAt:         now.Add(-365 * day),
+			LastActivityAt:    now.Add(-365 * day),
+			DefaultBranch:     "master",
+			TagList:           []string{"legacy", "deprecated"},
+			SSHURLToRepo:      "git@gitlab.example.com:gitlab-org/archived-legacy.git",
+			HTTPURLToRepo:     webHost + "/gitlab-org/archived-legacy.git",
+			WebURL:            webHost + "/gitlab-org/archived-legacy",
+			StarCount:         5,
+			ForksCount:        1,
+			Visibility:        "private",
+			Archived:          true,
+			Owner:             &UserBasic{ID: 1, Username: "root", Name: "Administrator", State: "active"},
+		},
+	}
+}
+
+// seedMergeRequests spreads the merge requests over the projects that take them.
+func seedMergeRequests(f *gofakeit.Faker, now time.Time, users []UserBasic, projects []Project) []MergeRequest {
+	open := []*Project{}
+	for i := range projects {
+		if projects[i].MergeRequestsEnabled && !projects[i].Archived {
+			open = append(open, &projects[i])
+		}
+	}
+	labels := []string{"bug", "feature", "refactor", "docs", "backend", "frontend"}
+	user := func() UserBasic { return users[f.Number(0, len(users)-1)] }
+
+	requests := make([]MergeRequest, 0, seededMergeRequests)
+	iids := map[int]int{}
+	for id := 1; id <= seededMergeRequests; id++ {
+		project := open[(id-1)%len(open)]
+		iids[project.ID]++
+		iid := iids[project.ID]
+		author := user()
+
+		created := now.Add(-time.Duration(f.Number(1, 90*24)) * time.Hour)
+		updated := created.Add(time.Duration(f.Number(0, int(now.Sub(created).Hours()))) * time.Hour)
+		title := fmt.Sprintf("ABC-%d %s", 100+id, strings.TrimSuffix(f.Sentence(6), "."))
+		if f.Number(1, 8) == 1 {
+			title = "Draft: " + title
+		}
+		mr := MergeRequest{
+			ID:              id,
+			IID:             iid,
+			ProjectID:       project.ID,
+			Title:           title,
+			Description:     f.Paragraph(1, 3, 10, "\n\n"),
+			State:           f.RandomString([]string{"opened", "opened", "opened", "merged", "merged", "closed"}),
+			CreatedAt:       created,
+			UpdatedAt:       updated,
+			SourceBranch:    fmt.Sprintf("%s-%d", strings.ToLower(f.Word()), iid),
+			TargetBranch:    project.DefaultBranch,
+			UserNotesCount:  2, // the notes of mergeRequestDiscussions
+			Upvotes:         f.Number(0, 5),
+			Author:          &author,
+			Assignees:       []UserBasic{author},
+			Assignee:        &author,
+			Reviewers:       []UserBasic{},
+			SourceProjectID: project.ID,
+			TargetProjectID: project.ID,
+			Labels:          []string{},
+			Draft:           isDraftTitle(title),
+			WorkInProgress:  isDraftTitle(title),
+			MergeStatus:     "can_be_merged",
+			SHA:             fmt.Sprintf("%040x", id),
+			References: References{
+				Short:    fmt.Sprintf("!%d", iid),
+				Relative: fmt.Sprintf("!%d", iid),
+				Full:     fmt.Sprintf("%s!%d", project.PathWithNamespace, iid),
+			},
+			WebURL: fmt.Sprintf("%s/-/merge_requests/%d", project.WebURL, iid),
+		}
+		for range f.Number(0, 2) {
+			if reviewer := user(); reviewer.ID != author.ID {
+				mr.Reviewers = append(mr.Reviewers, reviewer)
+			}
+		}
+		for range f.Number(0, 2) {
+			if l := f.RandomString(labels); !slices.Contains(mr.Labels, l) {
+				mr.Labels = append(mr.Labels, l)
+			}
+		}
+		switch mr.State {
+		case "opened":
+			mr.DetailedMergeStatus = f.RandomString([]string{"mergeable", "not_approved", "ci_still_running"})
+			if mr.Draft {
+				mr.DetailedMergeStatus = "draft_status"
+			}
+		case "merged":
+			merger, at := user(), mr.UpdatedAt
+			mr.Draft, mr.WorkInProgress = false, false
+			mr.Title = strings.TrimPrefix(mr.Title, "Draft: ")
+			mr.MergedAt, mr.MergedBy, mr.MergeUser = &at, &merger, &merger
+			mr.MergeCommitSHA = fmt.Sprintf("%040x", 10000+id)
+			mr.DetailedMergeStatus = "not_open"
+		case "closed":
+			closer, at := user(), mr.UpdatedAt
+			mr.ClosedAt, mr.ClosedBy = &at, &closer
+			mr.DetailedMergeStatus = "not_open"
+		}
+		requests = append(requests, mr)
+	}
+	return requests
+}
+
+// seedEvents derives the events of the merge requests, numbered in time order.
+func seedEvents(requests []MergeRequest) []Event {
+	events := []Event{}
+	for i := range requests {
+		mr := &requests[i]
+		events = append(events, mergeRequestEvent(0, "opened", *mr.Author, mr, mr.CreatedAt))
+		switch mr.State {
+		case "merged":
+			events = append(events, mergeRequestEvent(0, "accepted", *mr.MergedBy, mr, *mr.MergedAt))
+		case "closed":
+			events = append(events, mergeRequestEvent(0, "closed", *mr.ClosedBy, mr, *mr.ClosedAt))
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	for i := range events {
+		events[i].ID = i + 1
+	}
+	return events
+}
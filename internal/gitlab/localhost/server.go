@@ -5,10 +5,7 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
-	"strings"
-	"sync"
-
-	"github.com/brianvoe/gofakeit/v7"
+	"time"
 )
 
 // Server local host server for integration testing
@@ -17,17 +14,15 @@ type Server struct {
 	handler *Handler
 }
 
-var serverOnce sync.Once
-
+// NewServer serves the dataset of DefaultSeed as of now.
 func NewServer() *Server {
-	events := NewEventsRepoMem()
-	service := NewService(events)
-	handler := NewHandler(service)
+	return NewServerWithStore(NewStore(SeedDataset(DefaultSeed, time.Now())))
+}
 
-	// globally fix the fake generator seed for reproducible test data
-	serverOnce.Do(func() {
-		gofakeit.Seed(42)
-	})
+// NewServerWithStore serves the store, see LoadStore for fixtures.
+func NewServerWithStore(store *Store) *Server {
+	service := NewService(store)
+	handler := NewHandler(service)
 
 	s := &Server{
 		handler: handler,
@@ -36,6 +31,11 @@ func NewServer() *Server {
 	return s
 }
 
+// Store returns what the server holds, it has the writes of the requests so far.
+func (ts *Server) Store() *Store {
+	return ts.handler.store()
+}
+
 func (ts *Server) Start() {
 	router := SetupRouter(ts.handler)
 	ts.server = httptest.NewServer(router)
@@ -56,52 +56,54 @@ func (ts *Server) URL() string {
 // Router setup with middleware
 func SetupRouter(handler *Handler) *http.ServeMux {
 	mux := http.NewServeMux()
+	// every api route logs, then misbehaves as injected or simulated
+	api := Chain(LoggingMiddleware, handler.FaultMiddleware, handler.SimulateMiddleware)
 
 	//"/api/v4/projects:
 	//"/api/v4/projects/{id} many endpoints
-	mux.HandleFunc("/api/v4/projects/", LoggingMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			handler.GetProjects(w, r)
-		default:
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	}))
+	mux.HandleFunc("/api/v4/projects/", api(handler.RouteProjects))
 
 	// Handle the GitLab API v4 events endpoint: /api/v4/events
-	mux.HandleFunc("/api/v4/events", LoggingMiddleware(func(w http.ResponseWriter, r *http.Request) {
+	events := api(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
 			handler.GetEvents(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	}))
+	})
+	mux.HandleFunc("/api/v4/events", events)
+	mux.HandleFunc("/api/v4/events/", events)
 
-	mux.HandleFunc("/api/v4/merge_requests", LoggingMiddleware(func(w http.ResponseWriter, r *http.Request) {
+	mergeRequests := api(func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodGet:
-			handler.GetGroupsMergeRequests(w, r)
+			handler.GetMergeRequests(w, r)
 		default:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		}
-	}))
-
-	// Handle the GitLab API v4 groups endpoints: /api/v4/groups/{id}/merge_requests and /api/v4/groups/{id}/projects
-	mux.HandleFunc("/api/v4/groups/", LoggingMiddleware(func(w http.ResponseWriter, r *http.Request) {
-		switch r.Method {
-		case http.MethodGet:
-			if strings.Contains(r.URL.Path, "/merge_requests") {
-				handler.GetGroupsMergeRequests(w, r)
-			} else if strings.Contains(r.URL.Path, "/projects") {
-				handler.GetGroupsProjects(w, r)
-			} else {
-				http.Error(w, "Not found", http.StatusNotFound)
-			}
-		default:
+	})
+	mux.HandleFunc("/api/v4/merge_requests", mergeRequests)
+	mux.HandleFunc("/api/v4/merge_requests/", mergeRequests)
+
+	// Handle the GitLab API v4 groups endpoints: /api/v4/groups, /api/v4/groups/{id},
+	// /api/v4/groups/{id}/merge_requests and /api/v4/groups/{id}/projects
+	groups := api(handler.RouteGroups)
+	mux.HandleFunc("/api/v4/groups", groups)
+	mux.HandleFunc("/api/v4/groups/", groups)
+
+	users := api(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method != http.MethodGet:
 			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		case r.URL.Path == "/api/v4/user":
+			handler.GetCurrentUser(w, r)
+		default:
+			handler.GetUsers(w, r)
 		}
-	}))
+	})
+	mux.HandleFunc("/api/v4/user", users)
+	mux.HandleFunc("/api/v4/users", users)
 
 	return mux
 }
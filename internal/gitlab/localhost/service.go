@@ -1,14 +1,11 @@
 package localhost
 
 type Service struct {
-	events EventsRepo
+	store *Store
 }
 
-type EventsRepo interface {
-}
-
-func NewService(events EventsRepo) *Service {
+func NewService(store *Store) *Service {
 	return &Service{
-		events: events,
+		store: store,
 	}
 }
@@ -0,0 +1,97 @@
+package localhost
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Simulation makes the fake answer the way gitlab.com does under load or with large lists.
+// It is a preset of the faults of Server.Inject, see Middleware.
+type Simulation struct {
+	RateLimitEvery   int           `json:"rate_limit_every"`   // answer every nth GET with 429 Too Many Requests
+	RetryAfter       int           `json:"retry_after"`        // seconds in Retry-After of a 429, 0 leaves only RateLimit-Reset
+	ServerErrorEvery int           `json:"server_error_every"` // answer every nth GET that is not rate limited with 503 Service Unavailable
+	Keyset           bool          `json:"keyset"`             // omit X-Total-Pages and link the next page like keyset pagination
+	Latency          time.Duration `json:"latency"`            // wait before answering every request
+}
+
+// Middleware returns the chain of faults of the simulation, nil when it simulates nothing.
+func (s Simulation) Middleware() Middleware {
+	var mws []Middleware
+	if 0 < s.Latency {
+		mws = append(mws, Latency(s.Latency))
+	}
+	if 0 < s.RateLimitEvery {
+		mws = append(mws, OnMethod(http.MethodGet, Every(s.RateLimitEvery, RateLimit(s.RetryAfter))))
+	}
+	if 0 < s.ServerErrorEvery {
+		mws = append(mws, OnMethod(http.MethodGet, Every(s.ServerErrorEvery, ServerError(http.StatusServiceUnavailable))))
+	}
+	if s.Keyset {
+		mws = append(mws, OnMethod(http.MethodGet, KeysetPaging()))
+	}
+	if len(mws) == 0 {
+		return nil
+	}
+	return Chain(mws...)
+}
+
+type simulator struct {
+	mu   sync.Mutex
+	sim  Simulation
+	mw   Middleware
+	gets int
+}
+
+// Simulate sets how the server misbehaves from now on.
+func (ts *Server) Simulate(sim Simulation) {
+	ts.handler.sim.set(sim)
+}
+
+// Gets returns the number of GET requests the server answered.
+func (ts *Server) Gets() int {
+	return ts.handler.sim.count()
+}
+
+// set starts the simulation over, with its own counts of the requests.
+func (s *simulator) set(sim Simulation) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sim = sim
+	s.mw = sim.Middleware()
+	s.gets = 0
+}
+
+func (s *simulator) get() Simulation {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sim
+}
+
+func (s *simulator) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.gets
+}
+
+// next counts a GET and returns the middleware of the simulation.
+func (s *simulator) next(r *http.Request) Middleware {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r.Method == http.MethodGet {
+		s.gets++
+	}
+	return s.mw
+}
+
+// SimulateMiddleware answers the requests as set by Server.Simulate.
+func (h *Handler) SimulateMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if mw := h.sim.next(r); mw != nil {
+			mw(next)(w, r)
+			return
+		}
+		next(w, r)
+	}
+}
@@ -0,0 +1,208 @@
+package localhost
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// Group represents a GitLab group as per API_Entities_Group
+type Group struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	FullName string `json:"full_name"`
+	FullPath string `json:"full_path"`
+	ParentID *int   `json:"parent_id"`
+	WebURL   string `json:"web_url"`
+}
+
+// Dataset is everything the fake gitlab knows.
+// It is what a fixture file holds.
+type Dataset struct {
+	Users         []UserBasic         `json:"users"`
+	Groups        []Group             `json:"groups"`
+	Projects      []Project           `json:"projects"`
+	MergeRequests []MergeRequest      `json:"merge_requests"`
+	Events        []Event             `json:"events"`
+	Notes         map[int][]Note      `json:"notes"`     // merge request id to the notes posted to it
+	Approvals     map[int][]UserBasic `json:"approvals"` // merge request id to its approvers
+}
+
+// Store holds the dataset of the fake so requests see the writes of earlier requests.
+type Store struct {
+	mu   sync.Mutex // guards data
+	data Dataset
+}
+
+// NewStore returns a store of the dataset.
+func NewStore(data Dataset) *Store {
+	if data.Notes == nil {
+		data.Notes = map[int][]Note{}
+	}
+	if data.Approvals == nil {
+		data.Approvals = map[int][]UserBasic{}
+	}
+	return &Store{data: data}
+}
+
+// LoadStore reads a store from the json fixture at path.
+func LoadStore(path string) (*Store, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, withstack.Errorf("Could not read fixture %s: %w", path, err)
+	}
+	var data Dataset
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, withstack.Errorf("Could not parse fixture %s: %w", path, err)
+	}
+	return NewStore(data), nil
+}
+
+// Save writes the store as a json fixture to path.
+func (s *Store) Save(path string) error {
+	s.mu.Lock()
+	b, err := json.MarshalIndent(s.data, "", "  ")
+	s.mu.Unlock()
+	if err != nil {
+		return withstack.Errorf("Could not encode fixture: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return withstack.Errorf("Could not make the dir of %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		return withstack.Errorf("Could not write fixture %s: %w", path, err)
+	}
+	return nil
+}
+
+// Replace swaps what the store holds for data.
+func (s *Store) Replace(data Dataset) {
+	replaced := NewStore(data)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data = replaced.data
+}
+
+// Dataset returns a copy of what the store holds.
+func (s *Store) Dataset() Dataset {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// round trip through json for a deep copy
+	b, err := json.Marshal(s.data)
+	if err != nil {
+		panic(err)
+	}
+	var data Dataset
+	if err := json.Unmarshal(b, &data); err != nil {
+		panic(err)
+	}
+	return data
+}
+
+// findProject returns the project with the numeric id or path with namespace.
+// The caller must hold s.mu.
+func (s *Store) findProject(id string) (*Project, bool) {
+	for i := range s.data.Projects {
+		p := &s.data.Projects[i]
+		if strconv.Itoa(p.ID) == id || p.PathWithNamespace == id {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// findGroup returns the group with the numeric id or full path.
+// The caller must hold s.mu.
+func (s *Store) findGroup(id string) (*Group, bool) {
+	for i := range s.data.Groups {
+		g := &s.data.Groups[i]
+		if strconv.Itoa(g.ID) == id || g.FullPath == id {
+			return g, true
+		}
+	}
+	return nil, false
+}
+
+// findUser returns the user with the id.
+// The caller must hold s.mu.
+func (s *Store) findUser(id int) (UserBasic, bool) {
+	for _, u := range s.data.Users {
+		if u.ID == id {
+			return u, true
+		}
+	}
+	return UserBasic{}, false
+}
+
+// users returns the users with the ids, made up when they are unknown.
+// The caller must hold s.mu.
+func (s *Store) users(ids []int) []UserBasic {
+	users := fakeUsers(ids)
+	for i, id := range ids {
+		if u, ok := s.findUser(id); ok {
+			users[i] = u
+		}
+	}
+	return users
+}
+
+// inGroup is true when the project is in the group, or one of its subgroups with subgroups.
+func inGroup(p *Project, g *Group, subgroups bool) bool {
+	namespace := projectNamespace(p)
+	if namespace == g.FullPath {
+		return true
+	}
+	return subgroups && strings.HasPrefix(namespace, g.FullPath+"/")
+}
+
+// projectNamespace returns the full path of the group of the project.
+func projectNamespace(p *Project) string {
+	i := strings.LastIndex(p.PathWithNamespace, "/")
+	if i < 0 {
+		return ""
+	}
+	return p.PathWithNamespace[:i]
+}
+
+// addEvent records what the user did to the merge request.
+// The caller must hold s.mu.
+func (s *Store) addEvent(action string, by UserBasic, mr *MergeRequest, at time.Time) {
+	s.data.Events = append(s.data.Events, mergeRequestEvent(s.nextEventID(), action, by, mr, at))
+}
+
+// nextEventID returns the id past the last event.
+// The caller must hold s.mu.
+func (s *Store) nextEventID() int {
+	id := 1
+	for _, e := range s.data.Events {
+		id = max(id, e.ID+1)
+	}
+	return id
+}
+
+// mergeRequestEvent returns the event of the action on the merge request.
+func mergeRequestEvent(id int, action string, by UserBasic, mr *MergeRequest, at time.Time) Event {
+	username := by.Username
+	title := mr.Title
+	targetType := "MergeRequest"
+	return Event{
+		ID:             id,
+		ProjectID:      intPtr(mr.ProjectID),
+		ActionName:     action,
+		TargetID:       intPtr(mr.ID),
+		TargetIID:      intPtr(mr.IID),
+		TargetType:     &targetType,
+		AuthorID:       by.ID,
+		TargetTitle:    &title,
+		CreatedAt:      at,
+		AuthorUsername: &username,
+		ImportedFrom:   "none",
+	}
+}
@@ -0,0 +1,222 @@
+package localhost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// getJSON decodes the body of the GET into v and returns the response headers.
+func getJSON(t *testing.T, url string, v any) http.Header {
+	t.Helper()
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d for %s", http.StatusOK, resp.StatusCode, url)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp.Header
+}
+
+func TestSeedDatasetIsDeterministic(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	a := SeedDataset(DefaultSeed, now)
+	b := SeedDataset(DefaultSeed, now)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("Expected the same seed to make the same dataset")
+	}
+	if len(a.MergeRequests) != seededMergeRequests {
+		t.Errorf("Expected %d merge requests, got %d", seededMergeRequests, len(a.MergeRequests))
+	}
+
+	users := map[int]bool{}
+	for _, u := range a.Users {
+		users[u.ID] = true
+	}
+	projects := map[int]bool{}
+	for _, p := range a.Projects {
+		projects[p.ID] = true
+	}
+	for _, mr := range a.MergeRequests {
+		if !projects[mr.ProjectID] {
+			t.Errorf("Merge request %d is in unknown project %d", mr.ID, mr.ProjectID)
+		}
+		if mr.Author == nil || !users[mr.Author.ID] {
+			t.Errorf("Merge request %d has an unknown author", mr.ID)
+		}
+		if mr.UpdatedAt.Before(mr.CreatedAt) || mr.UpdatedAt.After(now) {
+			t.Errorf("Merge request %d was updated outside its life", mr.ID)
+		}
+	}
+	for _, e := range a.Events {
+		if !users[e.AuthorID] {
+			t.Errorf("Event %d has an unknown author", e.ID)
+		}
+	}
+}
+
+func TestPagesAreConsistent(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	seen := map[int]bool{}
+	for page := 1; ; page++ {
+		var requests []MergeRequest
+		header := getJSON(t, fmt.Sprintf("%s/api/v4/merge_requests?per_page=20&page=%d", server.URL(), page), &requests)
+		if header.Get("X-Total") != "75" || header.Get("X-Total-Pages") != "4" {
+			t.Fatalf("Expected 75 merge requests in 4 pages, got %s in %s",
+				header.Get("X-Total"), header.Get("X-Total-Pages"))
+		}
+		for _, mr := range requests {
+			if seen[mr.ID] {
+				t.Errorf("Merge request %d is on more than one page", mr.ID)
+			}
+			seen[mr.ID] = true
+		}
+		if header.Get("X-Next-Page") == "" {
+			if page != 4 || len(requests) != 15 {
+				t.Errorf("Expected the last page to be 4 with 15 merge requests, got %d with %d", page, len(requests))
+			}
+			if strings.Contains(header.Get("Link"), `rel="next"`) {
+				t.Error("Expected no next link on the last page")
+			}
+			break
+		}
+		if !strings.Contains(header.Get("Link"), `rel="next"`) {
+			t.Errorf("Expected a next link on page %d", page)
+		}
+	}
+	if len(seen) != 75 {
+		t.Errorf("Expected every merge request once, got %d", len(seen))
+	}
+}
+
+func TestFilters(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	data := server.Store().Dataset()
+	author := data.MergeRequests[0].Author.ID
+
+	var requests []MergeRequest
+	getJSON(t, fmt.Sprintf("%s/api/v4/merge_requests?state=opened&author_id=%d&per_page=100", server.URL(), author), &requests)
+	want := 0
+	for _, mr := range data.MergeRequests {
+		if mr.State == "opened" && mr.Author.ID == author {
+			want++
+		}
+	}
+	if len(requests) != want || want == 0 {
+		t.Errorf("Expected %d opened merge requests of %d, got %d", want, author, len(requests))
+	}
+
+	updatedAfter := data.MergeRequests[0].UpdatedAt
+	getJSON(t, fmt.Sprintf("%s/api/v4/merge_requests?updated_after=%s&per_page=100&order_by=updated_at&sort=asc",
+		server.URL(), updatedAfter.Format(time.RFC3339)), &requests)
+	for i, mr := range requests {
+		if mr.UpdatedAt.Before(updatedAfter) {
+			t.Errorf("Merge request %d was updated before %s", mr.ID, updatedAfter)
+		}
+		if 0 < i && mr.UpdatedAt.Before(requests[i-1].UpdatedAt) {
+			t.Error("Expected the merge requests in ascending updated_at")
+		}
+	}
+
+	var events []Event
+	before := data.Events[len(data.Events)/2].CreatedAt
+	after := before.AddDate(0, 0, -30)
+	getJSON(t, fmt.Sprintf("%s/api/v4/events?after=%s&before=%s&per_page=100",
+		server.URL(), after.Format("2006-01-02"), before.Format("2006-01-02")), &events)
+	if len(events) == 0 {
+		t.Error("Expected events in the 30 days")
+	}
+	for i, e := range events {
+		if 0 < i && e.CreatedAt.After(events[i-1].CreatedAt) {
+			t.Error("Expected the events newest first")
+		}
+	}
+}
+
+func TestWritesPersistAcrossRequests(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	body := strings.NewReader(`{"source_branch":"persist","target_branch":"main","title":"persist me"}`)
+	resp, err := http.Post(server.URL()+"/api/v4/projects/25/merge_requests", "application/json", body)
+	if err != nil {
+		t.Fatalf("Failed to make request: %v", err)
+	}
+	var created MergeRequest
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	var requests []MergeRequest
+	header := getJSON(t, server.URL()+"/api/v4/projects/25/merge_requests?search=persist", &requests)
+	if len(requests) != 1 || requests[0].ID != created.ID || header.Get("X-Total") != "1" {
+		t.Errorf("Expected the created merge request to be listed, got %d", len(requests))
+	}
+
+	var events []Event
+	getJSON(t, server.URL()+"/api/v4/projects/25/events?target_type=merge_request&per_page=1", &events)
+	if len(events) != 1 || events[0].TargetID == nil || *events[0].TargetID != created.ID {
+		t.Error("Expected the newest event of the project to be the opened merge request")
+	}
+
+	// the fixture keeps the write
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := server.Store().Save(path); err != nil {
+		t.Fatal(err)
+	}
+	store, err := LoadStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewServerWithStore(store)
+	defer loaded.Close()
+	var found MergeRequest
+	getJSON(t, fmt.Sprintf("%s/api/v4/projects/25/merge_requests/%d", loaded.URL(), created.IID), &found)
+	if found.Title != "persist me" {
+		t.Errorf("Expected the loaded fixture to have the created merge request, got %q", found.Title)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+
+	var groups []Group
+	getJSON(t, server.URL()+"/api/v4/groups?search=gitlab", &groups)
+	if len(groups) != 1 || groups[0].FullPath != "gitlab-org" {
+		t.Fatalf("Expected the gitlab-org group, got %v", groups)
+	}
+
+	var requests []MergeRequest
+	header := getJSON(t, server.URL()+"/api/v4/groups/gitlab-org/merge_requests", &requests)
+	if header.Get("X-Total") != "75" {
+		t.Errorf("Expected the group to have every merge request, got %s", header.Get("X-Total"))
+	}
+
+	resp, err := http.Get(server.URL() + "/api/v4/groups/999/projects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status %d for a missing group, got %d", http.StatusNotFound, resp.StatusCode)
+	}
+}
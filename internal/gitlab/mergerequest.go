@@ -56,7 +56,7 @@ type MergeRequestModel struct {
 	SquashOnMerge               bool                 `json:"squash_on_merge"`
 	TaskCompletionStatus        *UserBasic           `json:"task_completion_status"`
 	HasConflicts                bool                 `json:"has_conflicts"`
-	BlockingDiscussionsResolved bool                 `json:"blocking_discussions_resolved"`
+	BlockingDiscussionsResolved omitnull.Val[bool]   `json:"blocking_discussions_resolved,omitempty"`
 	ApprovalsBeforeMerge        omitnull.Val[int]    `json:"approvals_before_merge,omitempty"`
 }
 
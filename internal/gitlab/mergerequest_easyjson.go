@@ -770,7 +770,9 @@ func easyjson5af0c543DecodeGithubComStalwartgiraffeCmrInternalGitlab5(in *jlexer
 		case "has_conflicts":
 			out.HasConflicts = bool(in.Bool())
 		case "blocking_discussions_resolved":
-			out.BlockingDiscussionsResolved = bool(in.Bool())
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.BlockingDiscussionsResolved).UnmarshalJSON(data))
+			}
 		case "approvals_before_merge":
 			if data := in.Raw(); in.Ok() {
 				in.AddError((out.ApprovalsBeforeMerge).UnmarshalJSON(data))
@@ -1075,10 +1077,10 @@ func easyjson5af0c543EncodeGithubComStalwartgiraffeCmrInternalGitlab5(out *jwrit
 		out.RawString(prefix)
 		out.Bool(bool(in.HasConflicts))
 	}
-	{
+	if true {
 		const prefix string = ",\"blocking_discussions_resolved\":"
 		out.RawString(prefix)
-		out.Bool(bool(in.BlockingDiscussionsResolved))
+		out.Raw((in.BlockingDiscussionsResolved).MarshalJSON())
 	}
 	if true {
 		const prefix string = ",\"approvals_before_merge\":"
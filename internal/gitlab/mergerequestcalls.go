@@ -0,0 +1,305 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/stalwartgiraffe/cmr/kam"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+// ProjectPathID returns the url encoded path with namespace,
+// which gitlab accepts anywhere a numeric project id is expected.
+func ProjectPathID(pathWithNamespace string) string {
+	return url.PathEscape(pathWithNamespace)
+}
+
+// GetProject returns the project with the numeric or url encoded path id.
+func (c *Client) GetProject(ctx context.Context, app App, projectID string) (*ProjectModel, error) {
+	ctx, span := app.StartSpan(ctx, "GetProject")
+	defer span.End()
+
+	project, _, err := GetWithHeader[ProjectModel](ctx, app, c, "projects/"+projectID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// GetCurrentUser returns the user the token belongs to.
+func (c *Client) GetCurrentUser(ctx context.Context, app App) (*UserModel, error) {
+	ctx, span := app.StartSpan(ctx, "GetCurrentUser")
+	defer span.End()
+
+	user, _, err := GetWithHeader[UserModel](ctx, app, c, "user", nil)
+	return user, err
+}
+
+// FindOpenMergeRequest returns the open merge request of the project from the source branch.
+// A nil model is returned if there is no open merge request.
+func (c *Client) FindOpenMergeRequest(
+	ctx context.Context,
+	app App,
+	projectID int,
+	sourceBranch string,
+) (*MergeRequestModel, error) {
+	ctx, span := app.StartSpan(ctx, "FindOpenMergeRequest")
+	defer span.End()
+
+	params := kam.Map{
+		"source_branch": sourceBranch,
+		"state":         "opened",
+	}
+	path := fmt.Sprintf("projects/%d/merge_requests", projectID)
+	requests, _, err := GetWithHeader[[]MergeRequestModel](ctx, app, c, path, params)
+	if err != nil {
+		return nil, err
+	}
+	if requests == nil {
+		return nil, nil
+	}
+	for i := range *requests {
+		if mr := &(*requests)[i]; mr.SourceBranch == sourceBranch {
+			return mr, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateMergeRequestOptions is the body of a create merge request call.
+type CreateMergeRequestOptions struct {
+	SourceBranch       string `json:"source_branch"`
+	TargetBranch       string `json:"target_branch"`
+	Title              string `json:"title"`
+	Description        string `json:"description,omitempty"`
+	Labels             string `json:"labels,omitempty"`
+	AssigneeIDs        []int  `json:"assignee_ids,omitempty"`
+	ReviewerIDs        []int  `json:"reviewer_ids,omitempty"`
+	RemoveSourceBranch bool   `json:"remove_source_branch,omitempty"`
+	Squash             bool   `json:"squash,omitempty"`
+}
+
+// CreateMergeRequest opens a new merge request in the project.
+func (c *Client) CreateMergeRequest(
+	ctx context.Context,
+	app App,
+	projectID int,
+	opts *CreateMergeRequestOptions,
+) (*MergeRequestModel, error) {
+	ctx, span := app.StartSpan(ctx, "CreateMergeRequest")
+	defer span.End()
+
+	path := fmt.Sprintf("projects/%d/merge_requests", projectID)
+	return Update[CreateMergeRequestOptions, MergeRequestModel](ctx, app, c, rc.POST, path, opts)
+}
+
+// GetMergeRequest returns the merge request with the project internal id.
+func (c *Client) GetMergeRequest(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+) (*MergeRequestModel, error) {
+	ctx, span := app.StartSpan(ctx, "GetMergeRequest")
+	defer span.End()
+
+	mr, _, err := GetWithHeader[MergeRequestModel](ctx, app, c, mergeRequestPath(projectID, iid), nil)
+	return mr, err
+}
+
+// UpdateMergeRequestOptions is the body of an update merge request call.
+// Nil fields are left unchanged.
+// An empty Labels or ReviewerIDs clears them.
+type UpdateMergeRequestOptions struct {
+	Title        *string `json:"title,omitempty"`
+	Description  *string `json:"description,omitempty"`
+	Labels       *string `json:"labels,omitempty"`
+	AddLabels    *string `json:"add_labels,omitempty"`
+	RemoveLabels *string `json:"remove_labels,omitempty"`
+	AssigneeIDs  *[]int  `json:"assignee_ids,omitempty"`
+	ReviewerIDs  *[]int  `json:"reviewer_ids,omitempty"`
+	TargetBranch *string `json:"target_branch,omitempty"`
+	StateEvent   *string `json:"state_event,omitempty"` // close or reopen
+}
+
+// UpdateMergeRequest changes the attributes of the merge request.
+func (c *Client) UpdateMergeRequest(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+	opts *UpdateMergeRequestOptions,
+) (*MergeRequestModel, error) {
+	ctx, span := app.StartSpan(ctx, "UpdateMergeRequest")
+	defer span.End()
+
+	return Update[UpdateMergeRequestOptions, MergeRequestModel](
+		ctx, app, c, rc.PUT, mergeRequestPath(projectID, iid), opts)
+}
+
+// UpdateMergeRequestTitle sets the title of the merge request.
+func (c *Client) UpdateMergeRequestTitle(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+	title string,
+) (*MergeRequestModel, error) {
+	return c.UpdateMergeRequest(ctx, app, projectID, iid, &UpdateMergeRequestOptions{
+		Title: &title,
+	})
+}
+
+// UpdateMergeRequestLabels replaces the labels of the merge request.
+func (c *Client) UpdateMergeRequestLabels(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+	labels []string,
+) (*MergeRequestModel, error) {
+	joined := strings.Join(labels, ",")
+	return c.UpdateMergeRequest(ctx, app, projectID, iid, &UpdateMergeRequestOptions{
+		Labels: &joined,
+	})
+}
+
+// UpdateMergeRequestReviewers replaces the reviewers of the merge request.
+func (c *Client) UpdateMergeRequestReviewers(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+	reviewerIDs []int,
+) (*MergeRequestModel, error) {
+	if reviewerIDs == nil {
+		reviewerIDs = []int{}
+	}
+	return c.UpdateMergeRequest(ctx, app, projectID, iid, &UpdateMergeRequestOptions{
+		ReviewerIDs: &reviewerIDs,
+	})
+}
+
+// ApproveOptions is the body of an approve call.
+// A non empty Sha must match the head of the source branch.
+type ApproveOptions struct {
+	Sha string `json:"sha,omitempty"`
+}
+
+// ApproveMergeRequest approves the merge request as the current user
+// and returns its approval state.
+func (c *Client) ApproveMergeRequest(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+	opts *ApproveOptions,
+) (*ApprovalsModel, error) {
+	ctx, span := app.StartSpan(ctx, "ApproveMergeRequest")
+	defer span.End()
+
+	if opts == nil {
+		opts = &ApproveOptions{}
+	}
+	return Update[ApproveOptions, ApprovalsModel](
+		ctx, app, c, rc.POST, mergeRequestPath(projectID, iid)+"/approve", opts)
+}
+
+// UnapproveMergeRequest removes the approval of the current user
+// and returns the approval state left.
+func (c *Client) UnapproveMergeRequest(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+) (*ApprovalsModel, error) {
+	ctx, span := app.StartSpan(ctx, "UnapproveMergeRequest")
+	defer span.End()
+
+	return Update[struct{}, ApprovalsModel](
+		ctx, app, c, rc.POST, mergeRequestPath(projectID, iid)+"/unapprove", &struct{}{})
+}
+
+// MergeOptions is the body of an accept merge request call.
+type MergeOptions struct {
+	MergeCommitMessage        string `json:"merge_commit_message,omitempty"`
+	SquashCommitMessage       string `json:"squash_commit_message,omitempty"`
+	Squash                    bool   `json:"squash,omitempty"`
+	ShouldRemoveSourceBranch  bool   `json:"should_remove_source_branch,omitempty"`
+	MergeWhenPipelineSucceeds bool   `json:"merge_when_pipeline_succeeds,omitempty"`
+	Sha                       string `json:"sha,omitempty"`
+}
+
+// MergeMergeRequest accepts the merge request.
+// With MergeWhenPipelineSucceeds the merge is deferred until the pipeline passes.
+func (c *Client) MergeMergeRequest(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+	opts *MergeOptions,
+) (*MergeRequestModel, error) {
+	ctx, span := app.StartSpan(ctx, "MergeMergeRequest")
+	defer span.End()
+
+	if opts == nil {
+		opts = &MergeOptions{}
+	}
+	return Update[MergeOptions, MergeRequestModel](
+		ctx, app, c, rc.PUT, mergeRequestPath(projectID, iid)+"/merge", opts)
+}
+
+// draftPrefixes are the title prefixes gitlab reads as draft.
+var draftPrefixes = []string{"draft:", "[draft]", "(draft)"}
+
+// DraftTitle returns the title with the draft prefix added or removed.
+func DraftTitle(title string, draft bool) string {
+	trimmed := strings.TrimSpace(title)
+	lower := strings.ToLower(trimmed)
+	for _, prefix := range draftPrefixes {
+		if strings.HasPrefix(lower, prefix) {
+			trimmed = strings.TrimSpace(trimmed[len(prefix):])
+			break
+		}
+	}
+	if draft {
+		return "Draft: " + trimmed
+	}
+	return trimmed
+}
+
+// SetMergeRequestDraft marks the merge request as draft or ready through the prefix of its title.
+func (c *Client) SetMergeRequestDraft(
+	ctx context.Context,
+	app App,
+	mr *MergeRequestModel,
+	draft bool,
+) (*MergeRequestModel, error) {
+	return c.UpdateMergeRequestTitle(ctx, app, mr.ProjectID, mr.Iid, DraftTitle(mr.Title, draft))
+}
+
+// CreateNoteOptions is the body of a create note call.
+type CreateNoteOptions struct {
+	Body string `json:"body"`
+}
+
+// CreateMergeRequestNote comments on the merge request.
+func (c *Client) CreateMergeRequestNote(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+	body string,
+) (*Note, error) {
+	ctx, span := app.StartSpan(ctx, "CreateMergeRequestNote")
+	defer span.End()
+
+	return Update[CreateNoteOptions, Note](
+		ctx, app, c, rc.POST, mergeRequestPath(projectID, iid)+"/notes", &CreateNoteOptions{Body: body})
+}
+
+func mergeRequestPath(projectID int, iid int) string {
+	return fmt.Sprintf("projects/%d/merge_requests/%d", projectID, iid)
+}
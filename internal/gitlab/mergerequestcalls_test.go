@@ -0,0 +1,198 @@
+package gitlab
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appfixtures "github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	"github.com/stalwartgiraffe/cmr/kam"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+var _ = Describe("merge request writes", func() {
+	var server *localhost.Server
+	var client *Client
+	var app *appfixtures.MockApp
+	ctx := context.Background()
+	const projectPath = "gitlab-org/gitlab-foss"
+
+	BeforeEach(func() {
+		server = localhost.NewServer()
+		client = NewClient(rc.WithBaseURL(server.URL()))
+		app = appfixtures.NewApp()
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	createMR := func(branch string, title string) *MergeRequestModel {
+		project, err := client.GetProject(ctx, app, ProjectPathID(projectPath))
+		Expect(err).To(Succeed())
+		mr, err := client.CreateMergeRequest(ctx, app, project.ID, &CreateMergeRequestOptions{
+			SourceBranch: branch,
+			TargetBranch: project.DefaultBranch,
+			Title:        title,
+		})
+		Expect(err).To(Succeed())
+		return mr
+	}
+
+	It("creates and gets", func() {
+		mr := createMR("create_me", "ABC-123 create me")
+		Expect(mr.State).To(Equal("opened"))
+		Expect(mr.TargetBranch).To(Equal("main"))
+		Expect(mr.Draft).To(BeFalse())
+
+		got, err := client.GetMergeRequest(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).To(Succeed())
+		Expect(got.ID).To(Equal(mr.ID))
+		Expect(got.Title).To(Equal(mr.Title))
+
+		_, err = client.CreateMergeRequest(ctx, app, mr.ProjectID, &CreateMergeRequestOptions{
+			SourceBranch: "create_me",
+			TargetBranch: "main",
+			Title:        "again",
+		})
+		Expect(err).ToNot(Succeed())
+	})
+
+	It("updates title, labels and reviewers", func() {
+		mr := createMR("update_me", "update me")
+
+		updated, err := client.UpdateMergeRequestTitle(ctx, app, mr.ProjectID, mr.Iid, "Draft: update me")
+		Expect(err).To(Succeed())
+		Expect(updated.Title).To(Equal("Draft: update me"))
+		Expect(updated.Draft).To(BeTrue())
+
+		updated, err = client.UpdateMergeRequestLabels(ctx, app, mr.ProjectID, mr.Iid, []string{"bug", "backend"})
+		Expect(err).To(Succeed())
+		Expect(updated.Labels).To(Equal([]string{"bug", "backend"}))
+
+		updated, err = client.UpdateMergeRequestReviewers(ctx, app, mr.ProjectID, mr.Iid, []int{7, 8})
+		Expect(err).To(Succeed())
+		Expect(updated.Reviewers).To(HaveLen(2))
+		Expect(updated.Reviewers[0].ID).To(Equal(7))
+
+		updated, err = client.UpdateMergeRequestReviewers(ctx, app, mr.ProjectID, mr.Iid, nil)
+		Expect(err).To(Succeed())
+		Expect(updated.Reviewers).To(BeEmpty())
+		Expect(updated.Labels).To(Equal([]string{"bug", "backend"}))
+	})
+
+	It("approves and unapproves", func() {
+		mr := createMR("approve_me", "approve me")
+
+		_, err := client.UnapproveMergeRequest(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).ToNot(Succeed())
+
+		approvals, err := client.GetMergeRequestApprovals(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).To(Succeed())
+		Expect(approvals.Approved).To(BeFalse())
+		Expect(approvals.ApprovalsLeft).To(Equal(1))
+
+		approved, err := client.ApproveMergeRequest(ctx, app, mr.ProjectID, mr.Iid, nil)
+		Expect(err).To(Succeed())
+		Expect(approved.Approved).To(BeTrue())
+		Expect(approved.ApprovalsLeft).To(Equal(0))
+
+		me, err := client.GetCurrentUser(ctx, app)
+		Expect(err).To(Succeed())
+		approvals, err = client.GetMergeRequestApprovals(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).To(Succeed())
+		Expect(approvals.Approved).To(BeTrue())
+		Expect(approvals.ApprovalsLeft).To(Equal(0))
+		Expect(approvals.ApprovedBy).To(HaveLen(1))
+		Expect(approvals.ApprovedBy[0].User.Username).To(Equal(me.Username))
+
+		_, err = client.ApproveMergeRequest(ctx, app, mr.ProjectID, mr.Iid, nil)
+		Expect(err).ToNot(Succeed())
+
+		unapproved, err := client.UnapproveMergeRequest(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).To(Succeed())
+		Expect(unapproved.Approved).To(BeFalse())
+		Expect(unapproved.ApprovedBy).To(BeEmpty())
+
+		_, err = client.ApproveMergeRequest(ctx, app, mr.ProjectID, mr.Iid, &ApproveOptions{Sha: "not the head"})
+		Expect(err).ToNot(Succeed())
+	})
+
+	It("finds the merge requests by reviewer and assignee", func() {
+		mr := createMR("review_me", "review me")
+		updated, err := client.UpdateMergeRequestReviewers(ctx, app, mr.ProjectID, mr.Iid, []int{7})
+		Expect(err).To(Succeed())
+		reviewer := updated.Reviewers[0].Username
+
+		found, err := GetAllPages[MergeRequestModel](ctx, app, client, "merge_requests", kam.Map{
+			"scope":             "all",
+			"state":             "opened",
+			"reviewer_username": reviewer,
+		})
+		Expect(err).To(Succeed())
+		Expect(found).To(ContainElement(HaveField("ID", mr.ID)))
+		for _, m := range found {
+			Expect(m.Reviewers).To(ContainElement(HaveField("Username", reviewer)))
+		}
+
+		found, err = GetAllPages[MergeRequestModel](ctx, app, client, "merge_requests", kam.Map{
+			"assignee_username": reviewer,
+		})
+		Expect(err).To(Succeed())
+		Expect(found).ToNot(ContainElement(HaveField("ID", mr.ID)))
+	})
+
+	It("merges", func() {
+		mr := createMR("merge_me", "merge me")
+
+		auto, err := client.MergeMergeRequest(ctx, app, mr.ProjectID, mr.Iid, &MergeOptions{
+			MergeWhenPipelineSucceeds: true,
+		})
+		Expect(err).To(Succeed())
+		Expect(auto.State).To(Equal("opened"))
+		Expect(auto.MergeWhenPipelineSucceeds).To(BeTrue())
+
+		merged, err := client.MergeMergeRequest(ctx, app, mr.ProjectID, mr.Iid, nil)
+		Expect(err).To(Succeed())
+		Expect(merged.State).To(Equal("merged"))
+		Expect(merged.MergedAt.IsZero()).To(BeFalse())
+		Expect(merged.MergedBy).ToNot(BeNil())
+
+		_, err = client.MergeMergeRequest(ctx, app, mr.ProjectID, mr.Iid, nil)
+		Expect(err).ToNot(Succeed())
+	})
+
+	It("toggles draft", func() {
+		mr := createMR("draft_me", "draft me")
+
+		draft, err := client.SetMergeRequestDraft(ctx, app, mr, true)
+		Expect(err).To(Succeed())
+		Expect(draft.Title).To(Equal("Draft: draft me"))
+		Expect(draft.Draft).To(BeTrue())
+
+		ready, err := client.SetMergeRequestDraft(ctx, app, draft, false)
+		Expect(err).To(Succeed())
+		Expect(ready.Title).To(Equal("draft me"))
+		Expect(ready.Draft).To(BeFalse())
+
+		Expect(DraftTitle("[Draft] x", true)).To(Equal("Draft: x"))
+		Expect(DraftTitle("(draft) x", false)).To(Equal("x"))
+	})
+
+	It("comments", func() {
+		mr := createMR("comment_me", "comment me")
+
+		note, err := client.CreateMergeRequestNote(ctx, app, mr.ProjectID, mr.Iid, "looks good")
+		Expect(err).To(Succeed())
+		Expect(note.Body).To(Equal("looks good"))
+
+		discussions, err := client.GetMergeRequestDiscussions(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).To(Succeed())
+		last := discussions[len(discussions)-1]
+		Expect(last.Notes[0].Body).To(Equal("looks good"))
+
+		_, err = client.CreateMergeRequestNote(ctx, app, mr.ProjectID, mr.Iid, "")
+		Expect(err).ToNot(Succeed())
+	})
+})
@@ -0,0 +1,204 @@
+package gitlab
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stalwartgiraffe/cmr/kam"
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// Discussion is a thread of notes on a merge request.
+type Discussion struct {
+	ID             string `json:"id"`
+	IndividualNote bool   `json:"individual_note"`
+	Notes          []Note `json:"notes"`
+}
+
+// MergeRequestDiff is the unified diff of one changed file.
+type MergeRequestDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	AMode       string `json:"a_mode"`
+	BMode       string `json:"b_mode"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	RenamedFile bool   `json:"renamed_file"`
+	DeletedFile bool   `json:"deleted_file"`
+}
+
+// PipelineModel is the summary of a pipeline.
+type PipelineModel struct {
+	ID        int    `json:"id"`
+	Iid       int    `json:"iid"`
+	ProjectID int    `json:"project_id"`
+	Sha       string `json:"sha"`
+	Ref       string `json:"ref"`
+	Status    string `json:"status"`
+	Source    string `json:"source"`
+	CreatedAt Time   `json:"created_at"`
+	UpdatedAt Time   `json:"updated_at"`
+	WebURL    string `json:"web_url"`
+}
+
+// JobModel is one job of a pipeline.
+type JobModel struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	Stage        string  `json:"stage"`
+	Status       string  `json:"status"`
+	AllowFailure bool    `json:"allow_failure"`
+	Duration     float64 `json:"duration"`
+	CreatedAt    Time    `json:"created_at"`
+	StartedAt    Time    `json:"started_at"`
+	FinishedAt   Time    `json:"finished_at"`
+	WebURL       string  `json:"web_url"`
+}
+
+// maxDetailPages bounds the pages of a detail list.
+const maxDetailPages = 100
+
+// GetAllPages returns the items of every page of the list in order.
+func GetAllPages[T any](
+	ctx context.Context,
+	app App,
+	c *Client,
+	path string,
+	params kam.Map,
+) ([]T, error) {
+	q := UrlQuery{Path: path, Params: kam.Map{"per_page": 100}}
+	for k, v := range params {
+		q.Params[k] = v
+	}
+	all := []T{}
+	for range maxDetailPages {
+		page, header, err := GetWithHeader[[]T](ctx, app, c, q.Path, q.Params)
+		if err != nil {
+			return nil, err
+		}
+		if page != nil {
+			all = append(all, *page...)
+		}
+		cursor, err := parsePageCursor(header)
+		if err != nil {
+			return nil, withstack.Errorf("%s: %w", path, err)
+		}
+		next, ok, err := cursor.nextQuery(c, q)
+		if err != nil || !ok {
+			return all, err
+		}
+		q = next
+	}
+	return nil, withstack.Errorf("%s has more than %d pages", path, maxDetailPages)
+}
+
+// GetMergeRequestDiscussions returns the discussion threads of the merge request.
+func (c *Client) GetMergeRequestDiscussions(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+) ([]Discussion, error) {
+	ctx, span := app.StartSpan(ctx, "GetMergeRequestDiscussions")
+	defer span.End()
+
+	return GetAllPages[Discussion](ctx, app, c, mergeRequestPath(projectID, iid)+"/discussions", nil)
+}
+
+// GetMergeRequestDiffs returns the diff of each changed file of the merge request.
+func (c *Client) GetMergeRequestDiffs(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+) ([]MergeRequestDiff, error) {
+	ctx, span := app.StartSpan(ctx, "GetMergeRequestDiffs")
+	defer span.End()
+
+	return GetAllPages[MergeRequestDiff](ctx, app, c, mergeRequestPath(projectID, iid)+"/diffs", nil)
+}
+
+// GetMergeRequestPipelines returns the pipelines of the merge request, newest first.
+func (c *Client) GetMergeRequestPipelines(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+) ([]PipelineModel, error) {
+	ctx, span := app.StartSpan(ctx, "GetMergeRequestPipelines")
+	defer span.End()
+
+	return GetAllPages[PipelineModel](ctx, app, c, mergeRequestPath(projectID, iid)+"/pipelines", nil)
+}
+
+// GetPipelineJobs returns the jobs of the pipeline.
+func (c *Client) GetPipelineJobs(
+	ctx context.Context,
+	app App,
+	projectID int,
+	pipelineID int,
+) ([]JobModel, error) {
+	ctx, span := app.StartSpan(ctx, "GetPipelineJobs")
+	defer span.End()
+
+	path := fmt.Sprintf("projects/%d/pipelines/%d/jobs", projectID, pipelineID)
+	return GetAllPages[JobModel](ctx, app, c, path, nil)
+}
+
+// GetLatestPipeline returns the newest pipeline of the merge request and its jobs.
+// A nil pipeline is returned when the merge request has none.
+func (c *Client) GetLatestPipeline(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+) (*PipelineModel, []JobModel, error) {
+	pipelines, err := c.GetMergeRequestPipelines(ctx, app, projectID, iid)
+	if err != nil || len(pipelines) == 0 {
+		return nil, nil, err
+	}
+	latest := LatestPipeline(pipelines)
+	jobs, err := c.GetPipelineJobs(ctx, app, projectID, latest.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return latest, jobs, nil
+}
+
+// LatestPipeline returns the newest of the pipelines, nil when there are none.
+func LatestPipeline(pipelines []PipelineModel) *PipelineModel {
+	var latest *PipelineModel
+	for i := range pipelines {
+		if latest == nil || latest.ID < pipelines[i].ID {
+			latest = &pipelines[i]
+		}
+	}
+	return latest
+}
+
+// ApprovalsModel is the approval state of a merge request.
+type ApprovalsModel struct {
+	Approved          bool       `json:"approved"`
+	ApprovalsRequired int        `json:"approvals_required"`
+	ApprovalsLeft     int        `json:"approvals_left"`
+	ApprovedBy        []Approver `json:"approved_by"`
+}
+
+// Approver is a user who approved a merge request.
+type Approver struct {
+	User UserModel `json:"user"`
+}
+
+// GetMergeRequestApprovals returns who approved the merge request and how many approvals are left.
+func (c *Client) GetMergeRequestApprovals(
+	ctx context.Context,
+	app App,
+	projectID int,
+	iid int,
+) (*ApprovalsModel, error) {
+	ctx, span := app.StartSpan(ctx, "GetMergeRequestApprovals")
+	defer span.End()
+
+	approvals, _, err := GetWithHeader[ApprovalsModel](ctx, app, c, mergeRequestPath(projectID, iid)+"/approvals", nil)
+	return approvals, err
+}
@@ -0,0 +1,70 @@
+package gitlab
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appfixtures "github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+var _ = Describe("merge request details", func() {
+	var server *localhost.Server
+	var client *Client
+	var app *appfixtures.MockApp
+	var mr *MergeRequestModel
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		server = localhost.NewServer()
+		client = NewClient(rc.WithBaseURL(server.URL()))
+		app = appfixtures.NewApp()
+
+		project, err := client.GetProject(ctx, app, ProjectPathID("gitlab-org/gitlab-foss"))
+		Expect(err).To(Succeed())
+		mr, err = client.CreateMergeRequest(ctx, app, project.ID, &CreateMergeRequestOptions{
+			SourceBranch: "details",
+			TargetBranch: project.DefaultBranch,
+			Title:        "ABC-1 show details",
+		})
+		Expect(err).To(Succeed())
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("gets the discussions", func() {
+		discussions, err := client.GetMergeRequestDiscussions(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).To(Succeed())
+		Expect(discussions).To(HaveLen(2))
+		Expect(discussions[0].IndividualNote).To(BeTrue())
+		Expect(discussions[0].Notes[0].System).To(BeTrue())
+		Expect(discussions[1].Notes).To(HaveLen(2))
+		Expect(discussions[1].Notes[1].Author.Username).To(Equal(mr.Author.Username))
+	})
+
+	It("gets the diffs", func() {
+		diffs, err := client.GetMergeRequestDiffs(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).To(Succeed())
+		Expect(diffs).To(HaveLen(2))
+		Expect(diffs[0].Diff).To(ContainSubstring("+" + mr.Title))
+		Expect(diffs[1].NewFile).To(BeTrue())
+	})
+
+	It("gets the latest pipeline and its jobs", func() {
+		pipeline, jobs, err := client.GetLatestPipeline(ctx, app, mr.ProjectID, mr.Iid)
+		Expect(err).To(Succeed())
+		Expect(pipeline.Status).To(Equal("failed"))
+		Expect(jobs).To(HaveLen(3))
+		Expect(jobs[1].Name).To(Equal("test"))
+		Expect(jobs[1].Status).To(Equal("failed"))
+	})
+
+	It("fails for a missing merge request", func() {
+		_, err := client.GetMergeRequestDiffs(ctx, app, mr.ProjectID, mr.Iid+1000)
+		Expect(err).ToNot(Succeed())
+	})
+})
@@ -3,6 +3,7 @@ package gitlab
 import (
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/stalwartgiraffe/cmr/withstack"
 )
@@ -14,6 +15,7 @@ type pageCursor struct {
 	perPage    *int
 	prevPage   *int
 	totalItems *int
+	nextLink   string // url of Link rel="next"
 }
 
 func (c *pageCursor) remainingPageIndex() []int {
@@ -29,11 +31,10 @@ func (c *pageCursor) remainingPageIndex() []int {
 	return idx
 }
 
+// parseHeaderInts returns no values when the key is missing.
+// gitlab omits X-Total and X-Total-Pages above 10,000 rows.
 func parseHeaderInts(h http.Header, key string) ([]int, error) {
-	strVals, ok := h[key]
-	if !ok {
-		return nil, withstack.Errorf("Header key %s not found", key)
-	}
+	strVals := h.Values(key)
 
 	vals := []int{}
 	for _, s := range strVals {
@@ -87,5 +88,51 @@ func parsePageCursor(h http.Header) (pageCursor, error) {
 	err = parseOneHeaderInt(err, h, totalPages, &p.totalPages)
 	err = parseOneHeaderInt(err, h, perPage, &p.perPage)
 	err = parseOneHeaderInt(err, h, total, &p.totalItems)
+	p.nextLink = parseNextLink(h)
 	return p, err
 }
+
+// parseNextLink returns the url of the next page in the Link header or empty.
+//
+//	Link: <https://gitlab.example.com/api/v4/projects?id_after=42&pagination=keyset>; rel="next"
+func parseNextLink(h http.Header) string {
+	for _, link := range h.Values("Link") {
+		for _, part := range strings.Split(link, ",") {
+			target, params, ok := strings.Cut(part, ";")
+			if !ok {
+				continue
+			}
+			target = strings.TrimSpace(target)
+			if !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			for _, param := range strings.Split(params, ";") {
+				k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if k == "rel" && strings.Trim(v, `"`) == "next" {
+					return target[1 : len(target)-1]
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// hasTotalPages is true when every remaining page can be requested at once.
+func (c *pageCursor) hasTotalPages() bool {
+	return c.page != nil && c.totalPages != nil
+}
+
+// nextQuery returns the query of the page after q, following the next link
+// or else X-Next-Page. It is false on the last page.
+func (c *pageCursor) nextQuery(client *Client, q UrlQuery) (UrlQuery, bool, error) {
+	if c.nextLink != "" {
+		next, err := client.linkQuery(c.nextLink)
+		return next, err == nil, err
+	}
+	if c.nextPage == nil || (c.page != nil && *c.nextPage <= *c.page) {
+		return UrlQuery{}, false, nil
+	}
+	next := *q.Clone()
+	next.Params["page"] = *c.nextPage
+	return next, true, nil
+}
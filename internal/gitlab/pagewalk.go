@@ -0,0 +1,132 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+)
+
+// pageFetch gets one page, delivers its call and returns the header.
+// It is false when the page failed and the error was delivered.
+type pageFetch func(q UrlQuery) (http.Header, bool)
+
+// walkPages gets the first page of the query and sends the query of each remaining page
+// to the tail workers. When gitlab omits X-Total-Pages, above 10,000 rows or with keyset
+// pagination, the next page links are followed one page at a time instead.
+// Pages already recorded in the checkpoint are skipped.
+func walkPages(
+	ctx context.Context,
+	client *Client,
+	first UrlQuery,
+	totalPagesLimit int, // 0 means no limit
+	checkpoint *Checkpoint,
+	fetch pageFetch,
+	onError func(UrlQuery, error),
+	queries chan<- UrlQuery,
+) {
+	progress := checkpoint.progress(first)
+	switch {
+	case progress.Complete:
+		return
+	case progress.Next != nil:
+		next := *progress.Next
+		if h, ok := fetch(next); ok {
+			followPages(ctx, client, first, next, h, 1, totalPagesLimit, checkpoint, fetch, onError)
+		}
+		return
+	case 0 < progress.TotalPages:
+		queuePages(ctx, first, firstPage(first), progress, queries)
+		return
+	}
+
+	h, ok := fetch(first)
+	if !ok {
+		return
+	}
+	cursor, err := parsePageCursor(h)
+	if err != nil {
+		onError(first, err)
+		return
+	}
+	if !cursor.hasTotalPages() {
+		followPages(ctx, client, first, first, h, 1, totalPagesLimit, checkpoint, fetch, onError)
+		return
+	}
+	n := *cursor.totalPages
+	if totalPagesLimit > 0 && n > totalPagesLimit {
+		n = totalPagesLimit
+	}
+	checkpoint.setTotalPages(first, n)
+	checkpoint.pageDone(first, *cursor.page)
+	progress = checkpoint.progress(first)
+	progress.TotalPages = n // without a checkpoint the progress is empty
+	queuePages(ctx, first, *cursor.page+1, progress, queries)
+}
+
+// queuePages sends the query of each offset page that is not done.
+func queuePages(
+	ctx context.Context,
+	first UrlQuery,
+	startPage int,
+	progress QueryProgress,
+	queries chan<- UrlQuery,
+) {
+	for p := startPage; p <= progress.TotalPages; p++ {
+		if progress.isPageDone(p) {
+			continue
+		}
+		next := *first.Clone()
+		next.Params["page"] = p
+		select {
+		case queries <- next:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// followPages gets the pages after q one at a time until there is no next page.
+func followPages(
+	ctx context.Context,
+	client *Client,
+	first UrlQuery,
+	q UrlQuery,
+	h http.Header,
+	pages int,
+	totalPagesLimit int,
+	checkpoint *Checkpoint,
+	fetch pageFetch,
+	onError func(UrlQuery, error),
+) {
+	for {
+		cursor, err := parsePageCursor(h)
+		if err != nil {
+			onError(q, err)
+			return
+		}
+		next, ok, err := cursor.nextQuery(client, q)
+		if err != nil {
+			onError(q, err)
+			return
+		}
+		if !ok || (totalPagesLimit > 0 && pages >= totalPagesLimit) {
+			checkpoint.complete(first)
+			return
+		}
+		checkpoint.setNext(first, &next)
+		if ctx.Err() != nil {
+			return
+		}
+		if h, ok = fetch(next); !ok {
+			return
+		}
+		q = next
+		pages++
+	}
+}
+
+func firstPage(q UrlQuery) int {
+	if p, ok := queryPage(q); ok {
+		return p
+	}
+	return 1
+}
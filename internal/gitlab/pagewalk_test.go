@@ -0,0 +1,215 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appfixtures "github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	"github.com/stalwartgiraffe/cmr/kam"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+var _ = Describe("paging through the fake gitlab", func() {
+	var server *localhost.Server
+	var client *Client
+	var app *appfixtures.MockApp
+	ctx := context.Background()
+
+	// the fake has 75 merge requests, 3 pages of 25
+	const totalPages = 3
+	firstQuery := func() UrlQuery {
+		return UrlQuery{
+			Path: "merge_requests",
+			Params: kam.Map{
+				"state":    "all",
+				"page":     1,
+				"per_page": 25,
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		server = localhost.NewServer()
+		client = NewClient(rc.WithBaseURL(server.URL()))
+		client.SetRetryPolicy(RetryPolicy{
+			MaxAttempts: 4,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    10 * time.Millisecond,
+		})
+		app = appfixtures.NewApp()
+	})
+	AfterEach(func() {
+		server.Close()
+	})
+
+	gather := func(totalPagesLimit int, checkpoint *Checkpoint) ([]CallNoError[kam.JSONValue], error) {
+		firstQueries := make(chan UrlQuery, 1)
+		firstQueries <- firstQuery()
+		close(firstQueries)
+		calls, errs := GatherPageCallsDualCheckpoint[kam.JSONValue](
+			ctx, app, client, firstQueries, totalPagesLimit, checkpoint)
+
+		done := make(chan error)
+		go func() {
+			var all error
+			for err := range errs {
+				all = errors.Join(all, err)
+			}
+			done <- all
+		}()
+		got := []CallNoError[kam.JSONValue]{}
+		for c := range calls {
+			got = append(got, c)
+		}
+		return got, <-done
+	}
+
+	It("gets every offset page", func() {
+		calls, err := gather(0, nil)
+		Expect(err).To(Succeed())
+		Expect(calls).To(HaveLen(totalPages))
+		Expect(server.Gets()).To(Equal(totalPages))
+	})
+
+	It("honours Retry-After on 429", func() {
+		server.Simulate(localhost.Simulation{RateLimitEvery: 2, RetryAfter: 1})
+		start := time.Now()
+		calls, err := gather(0, nil)
+		Expect(err).To(Succeed())
+		Expect(calls).To(HaveLen(totalPages))
+		Expect(server.Gets()).To(BeNumerically(">", totalPages))
+		Expect(time.Since(start)).To(BeNumerically(">=", time.Second))
+	})
+
+	It("backs off on 5xx", func() {
+		server.Simulate(localhost.Simulation{ServerErrorEvery: 2})
+		calls, err := gather(0, nil)
+		Expect(err).To(Succeed())
+		Expect(calls).To(HaveLen(totalPages))
+	})
+
+	It("gives up when the retries are used up", func() {
+		server.Simulate(localhost.Simulation{ServerErrorEvery: 1})
+		calls, err := gather(0, nil)
+		Expect(err).ToNot(Succeed())
+		Expect(calls).To(BeEmpty())
+		Expect(server.Gets()).To(Equal(4))
+	})
+
+	It("follows next links without total pages", func() {
+		server.Simulate(localhost.Simulation{Keyset: true})
+		calls, err := gather(0, nil)
+		Expect(err).To(Succeed())
+		Expect(calls).To(HaveLen(totalPages))
+		Expect(calls[0].Header.Get("X-Total-Pages")).To(BeEmpty())
+		Expect(calls[0].Header.Get("Link")).To(ContainSubstring(`rel="next"`))
+		Expect(calls[2].Query.Params["page"]).To(Equal("3"))
+
+		calls, err = gather(2, nil)
+		Expect(err).To(Succeed())
+		Expect(calls).To(HaveLen(2))
+	})
+
+	It("resumes the offset pages left in the checkpoint", func() {
+		checkpoint := NewCheckpoint()
+		_, err := gather(0, checkpoint)
+		Expect(err).To(Succeed())
+		progress := checkpoint.progress(firstQuery())
+		Expect(progress.TotalPages).To(Equal(totalPages))
+		Expect(progress.DonePages).To(Equal([]int{1, 2, 3}))
+
+		checkpoint = NewCheckpoint()
+		checkpoint.setTotalPages(firstQuery(), totalPages)
+		checkpoint.pageDone(firstQuery(), 1)
+		checkpoint.pageDone(firstQuery(), 2)
+		calls, err := gather(0, checkpoint)
+		Expect(err).To(Succeed())
+		Expect(calls).To(HaveLen(1))
+		Expect(calls[0].Query.Params["page"]).To(Equal(3))
+	})
+
+	It("resumes keyset pages from the saved checkpoint", func() {
+		client.SetRetryPolicy(RetryPolicy{MaxAttempts: 1})
+		server.Simulate(localhost.Simulation{Keyset: true, ServerErrorEvery: 3})
+		checkpoint := NewCheckpoint()
+		calls, err := gather(0, checkpoint)
+		Expect(err).ToNot(Succeed())
+		Expect(calls).To(HaveLen(2))
+
+		path := filepath.Join(GinkgoT().TempDir(), "checkpoint.yaml")
+		Expect(checkpoint.Save(path)).To(Succeed())
+		checkpoint, err = LoadCheckpoint(path)
+		Expect(err).To(Succeed())
+		Expect(checkpoint.progress(firstQuery()).Next).ToNot(BeNil())
+
+		server.Simulate(localhost.Simulation{Keyset: true})
+		calls, err = gather(0, checkpoint)
+		Expect(err).To(Succeed())
+		Expect(calls).To(HaveLen(1))
+		Expect(checkpoint.progress(firstQuery()).Complete).To(BeTrue())
+
+		calls, err = gather(0, checkpoint)
+		Expect(err).To(Succeed())
+		Expect(calls).To(BeEmpty())
+	})
+})
+
+var _ = Describe("retry policy", func() {
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	policy := RetryPolicy{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: 4 * time.Second}
+	failure := func(code int, header http.Header) error {
+		return &rc.FailureResponse{StatusCode: code, Header: header}
+	}
+
+	DescribeTable("delay",
+		func(attempt int, err error, expected time.Duration, expectedOK bool) {
+			d, ok := policy.delay(attempt, err, now)
+			Expect(ok).To(Equal(expectedOK))
+			if 0 < expected {
+				Expect(d).To(Equal(expected))
+			}
+		},
+		Entry("retry after seconds", 1,
+			failure(http.StatusTooManyRequests, http.Header{"Retry-After": {"7"}}), 7*time.Second, true),
+		Entry("retry after date", 1,
+			failure(http.StatusTooManyRequests, http.Header{"Retry-After": {now.Add(time.Minute).Format(http.TimeFormat)}}), time.Minute, true),
+		Entry("rate limit reset", 1,
+			failure(http.StatusTooManyRequests, http.Header{
+				"Ratelimit-Remaining": {"0"},
+				"Ratelimit-Reset":     {strconv.FormatInt(now.Add(9*time.Second).Unix(), 10)},
+			}), 9*time.Second, true),
+		Entry("server error backs off", 1, failure(http.StatusBadGateway, nil), time.Duration(0), true),
+		Entry("not found is final", 1, failure(http.StatusNotFound, nil), time.Duration(0), false),
+		Entry("attempts used up", 3, failure(http.StatusBadGateway, nil), time.Duration(0), false),
+		Entry("canceled is final", 1, context.Canceled, time.Duration(0), false),
+	)
+
+	It("backs off with jitter below the cap", func() {
+		for attempt := 1; attempt < 6; attempt++ {
+			d := policy.backoff(attempt)
+			Expect(d).To(BeNumerically("<=", policy.MaxDelay))
+			Expect(d).To(BeNumerically(">=", min(policy.BaseDelay<<(attempt-1), policy.MaxDelay)/2))
+		}
+	})
+})
+
+var _ = Describe("next link", func() {
+	DescribeTable("parse",
+		func(link string, expected string) {
+			Expect(parseNextLink(http.Header{"Link": {link}})).To(Equal(expected))
+		},
+		Entry("next", `<https://gitlab.example.com/api/v4/projects?id_after=42>; rel="next"`,
+			"https://gitlab.example.com/api/v4/projects?id_after=42"),
+		Entry("first and next", `<https://h/api/v4/p?page=1>; rel="first", <https://h/api/v4/p?page=2>; rel="next"`,
+			"https://h/api/v4/p?page=2"),
+		Entry("no next", `<https://h/api/v4/p?page=1>; rel="first"`, ""),
+	)
+})
@@ -1,6 +1,7 @@
 package gitlab
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -82,14 +83,29 @@ func ReadProjectsSlice(filepath string) ([]ProjectModel, error) {
 	return projects, nil
 }
 
+// ReadProjects reads the projects from the cache.
 func ReadProjects() (map[int]ProjectModel, error) {
-	projectsSlice, err := ReadProjectsSlice("ignore/projects.yaml")
+	ctx := context.Background()
+	cache, err := OpenCache(ctx, CacheDir)
+	if err != nil {
+		return nil, err
+	}
+	projectsSlice, err := cache.Projects(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return MakeProjectMap(projectsSlice), nil
 }
 
+// SaveProjects writes the projects to the cache.
+func SaveProjects(ctx context.Context, projects []ProjectModel) error {
+	cache, err := OpenCache(ctx, CacheDir)
+	if err != nil {
+		return err
+	}
+	return cache.PutProjects(ctx, projects)
+}
+
 func MakeProjectMap(projectsSlice []ProjectModel) map[int]ProjectModel {
 	projects := make(map[int]ProjectModel)
 	for _, p := range projectsSlice {
@@ -1,9 +1,8 @@
 package gitlab
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"os"
 	"sort"
 
 	"github.com/TwiN/go-color"
@@ -13,16 +12,24 @@ import (
 
 type MergeRequestMap map[int]MergeRequestModel
 
+// NewMergeRequestMapFromYaml reads the cached merge requests that were kept in the yaml file.
 func NewMergeRequestMapFromYaml(filepath string) (MergeRequestMap, error) {
-	var requests []MergeRequestModel
-	if err := utils.ReadFromYamlFile(filepath, &requests); err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			fmt.Println(color.Ize(color.Red, err.Error()))
-			return nil, err
-		}
+	ctx := context.Background()
+	cache, scope, err := openCacheOf(ctx, filepath)
+	if err != nil {
+		fmt.Println(color.Ize(color.Red, err.Error()))
+		return nil, err
 	}
+	return cache.MergeRequests(ctx, scope)
+}
 
-	return NewMergeRequestMapFromSlice(requests), nil
+// Save writes the merge requests to the cache of the yaml file.
+func (m MergeRequestMap) Save(ctx context.Context, filepath string) error {
+	cache, scope, err := openCacheOf(ctx, filepath)
+	if err != nil {
+		return err
+	}
+	return cache.PutMergeRequests(ctx, scope, maps.Values(m))
 }
 
 func NewMergeRequestMapFromSlice(requests []MergeRequestModel) MergeRequestMap {
@@ -0,0 +1,137 @@
+package gitlab
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+// RetryPolicy is how a get backs off when gitlab is rate limiting or failing.
+type RetryPolicy struct {
+	MaxAttempts int           // 1 or less never retries
+	BaseDelay   time.Duration // first backoff, doubled on each attempt
+	MaxDelay    time.Duration // cap of the backoff, Retry-After is always honoured
+}
+
+// DefaultRetryPolicy retries 429 and 5xx for about a minute.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 6,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+}
+
+// delay returns how long to wait before the next attempt or false if the error is final.
+func (p RetryPolicy) delay(attempt int, err error, now time.Time) (time.Duration, bool) {
+	if p.MaxAttempts <= attempt {
+		return 0, false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+	var failure *rc.FailureResponse
+	if !errors.As(err, &failure) {
+		if isNetworkError(err) {
+			return p.backoff(attempt), true
+		}
+		return 0, false
+	}
+	if !isRetryStatus(failure.StatusCode) {
+		return 0, false
+	}
+	if d, ok := retryAfter(failure.Header, now); ok {
+		return d, true
+	}
+	return p.backoff(attempt), true
+}
+
+// backoff is exponential with jitter so parallel workers do not retry in step.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BaseDelay << (attempt - 1)
+	if d <= 0 || p.MaxDelay < d {
+		d = p.MaxDelay
+	}
+	if d <= 0 {
+		return 0
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// isNetworkError is true when the request did not get a whole response,
+// such as a dropped connection.
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET)
+}
+
+func isRetryStatus(code int) bool {
+	return code == http.StatusTooManyRequests ||
+		(http.StatusInternalServerError <= code && code < 600 && code != http.StatusNotImplemented)
+}
+
+// retryAfter reads Retry-After in seconds or as a date,
+// then RateLimit-Reset when the rate limit is used up.
+// see https://docs.gitlab.com/ee/user/gitlab_com/index.html#gitlabcom-specific-rate-limits
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	if h == nil {
+		return 0, false
+	}
+	if s := h.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil && 0 <= secs {
+			return time.Duration(secs) * time.Second, true
+		}
+		if at, err := http.ParseTime(s); err == nil {
+			return max(0, at.Sub(now)), true
+		}
+	}
+	if h.Get("RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(h.Get("RateLimit-Reset"), 10, 64); err == nil {
+			return max(0, time.Unix(reset, 0).Sub(now)), true
+		}
+	}
+	return 0, false
+}
+
+// withRetry calls get until it succeeds, the error is final or the context is done.
+func withRetry[RespT any](
+	ctx context.Context,
+	policy RetryPolicy,
+	get func() (*RespT, http.Header, error),
+) (*RespT, http.Header, error) {
+	for attempt := 1; ; attempt++ {
+		v, h, err := get()
+		if err == nil {
+			return v, h, nil
+		}
+		d, ok := policy.delay(attempt, err, time.Now())
+		if !ok {
+			return nil, nil, err
+		}
+		trace.SpanFromContext(ctx).AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.String("delay", d.String()),
+		))
+		timer := time.NewTimer(d)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, nil, errors.Join(ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package gitutil
+
+import (
+	"github.com/go-git/go-git/v5"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+)
+
+// CheckoutCreatePolicyBranch creates the branch that the policy names at the head and checks it out.
+// It returns the name of the branch.
+func CheckoutCreatePolicyBranch(repo *git.Repository, branches *config.Branches, branch config.Branch) (string, error) {
+	name, err := branches.Format(branch)
+	if err != nil {
+		return "", err
+	}
+	return name, CheckoutCreateBranch(repo, name)
+}
+
+// ReadBranch returns what the name of the current branch holds by the policy, see config.Branches.Read.
+func ReadBranch(repo *git.Repository, branches *config.Branches) (config.Branch, error) {
+	name, err := BranchShortName(repo)
+	if err != nil {
+		return config.Branch{}, err
+	}
+	return branches.Read(name), nil
+}
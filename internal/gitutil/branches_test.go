@@ -0,0 +1,45 @@
+package gitutil
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+)
+
+var _ = Describe("policy branches", func() {
+	var repo *git.Repository
+	branches := config.DefaultBranches()
+
+	BeforeEach(func() {
+		var err error
+		repo, err = MakeEmptyRepoWithBranchCommitTag(memfs.New(), "main", "Initial commit.", "v1.0.0", "Annie Mouse")
+		Expect(err).To(Succeed())
+	})
+
+	It("creates and reads the branch", func() {
+		name, err := CheckoutCreatePolicyBranch(repo, branches, config.Branch{Issue: "DEALS-1234", Description: "Big deal"})
+		Expect(err).To(Succeed())
+		Expect(name).To(Equal("DEALS-1234_big_deal"))
+
+		headRef, err := repo.Head()
+		Expect(err).To(Succeed())
+		Expect(headRef.Name().Short()).To(Equal(name))
+
+		branch, err := ReadBranch(repo, branches)
+		Expect(err).To(Succeed())
+		Expect(branch).To(Equal(config.Branch{Issue: "DEALS-1234", Description: "big deal"}))
+	})
+
+	It("does not create a branch that breaks the policy", func() {
+		_, err := CheckoutCreatePolicyBranch(repo, branches, config.Branch{Issue: "deals-1234", Description: "big deal"})
+		Expect(err).ToNot(Succeed())
+
+		headRef, err := repo.Head()
+		Expect(err).To(Succeed())
+		Expect(headRef.Name().Short()).To(Equal("main"))
+	})
+})
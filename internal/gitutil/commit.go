@@ -0,0 +1,208 @@
+package gitutil
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// Signer signs the encoded commit and returns the armored signature.
+type Signer interface {
+	Sign(message []byte) ([]byte, error)
+}
+
+// Signer returns the signer of the commits when commit.gpgsign is set, nil when it is not.
+// gpg.format chooses openpgp, ssh or x509, gpg.program or gpg.<format>.program the program
+// and user.signingkey the key. The openpgp and x509 key default to the committer.
+func (c *Config) Signer(committer *object.Signature) (Signer, error) {
+	if !c.Bool("commit.gpgsign") {
+		return nil, nil
+	}
+	key := c.Get("user.signingkey")
+	switch format := firstOf(c.Get("gpg.format"), "openpgp"); format {
+	case "openpgp":
+		program := firstOf(c.Get("gpg.openpgp.program"), c.Get("gpg.program"), "gpg")
+		return gpgSigner(program, firstOf(key, committer.String())), nil
+	case "x509":
+		program := firstOf(c.Get("gpg.x509.program"), "gpgsm")
+		return gpgSigner(program, firstOf(key, committer.String())), nil
+	case "ssh":
+		if key == "" {
+			return nil, withstack.Errorf("user.signingkey is needed to sign with ssh")
+		}
+		if rest, ok := strings.CutPrefix(key, "~/"); ok {
+			key = path.Join(c.env.Home, rest)
+		}
+		return &sshSigner{program: firstOf(c.Get("gpg.ssh.program"), "ssh-keygen"), key: key}, nil
+	default:
+		return nil, withstack.Errorf("unknown gpg.format %s", format)
+	}
+}
+
+// commandSigner runs the program with the message on stdin and reads the signature from stdout.
+type commandSigner struct {
+	program string
+	args    []string
+}
+
+func gpgSigner(program string, key string) *commandSigner {
+	return &commandSigner{program: program, args: []string{"--status-fd=2", "-bsau", key}}
+}
+
+func (s *commandSigner) Sign(message []byte) ([]byte, error) {
+	cmd := exec.Command(s.program, s.args...)
+	cmd.Stdin = bytes.NewReader(message)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	signature, err := cmd.Output()
+	if err != nil {
+		return nil, withstack.Errorf("%s failed to sign: %w %s", s.program, err, strings.TrimSpace(stderr.String()))
+	}
+	return signature, nil
+}
+
+// sshSigner signs with ssh-keygen, the key is the file of the key or key:: and the public key in the agent.
+type sshSigner struct {
+	program string
+	key     string
+}
+
+func (s *sshSigner) Sign(message []byte) ([]byte, error) {
+	args := []string{"-Y", "sign", "-n", "git", "-f", s.key}
+	if literal, ok := strings.CutPrefix(s.key, "key::"); ok {
+		f, err := os.CreateTemp("", "cmr-signingkey-*.pub")
+		if err != nil {
+			return nil, withstack.Errorf("%w", err)
+		}
+		defer os.Remove(f.Name())
+		_, err = f.WriteString(literal + "\n")
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, withstack.Errorf("%w", err)
+		}
+		args = []string{"-Y", "sign", "-n", "git", "-f", f.Name(), "-U"}
+	}
+	return (&commandSigner{program: s.program, args: args}).Sign(message)
+}
+
+// Commit commits the staging index as the author and committer of the git config,
+// signed when commit.gpgsign is set. Amend replaces the head commit and keeps its author,
+// an empty message then keeps its message.
+func Commit(repo *git.Repository, cfg *Config, msg string, amend bool) (plumbing.Hash, error) {
+	committer, err := cfg.Committer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	signer, err := cfg.Signer(committer)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, withstack.Errorf("%w", err)
+	}
+
+	var head *object.Commit
+	var author *object.Signature
+	if amend {
+		ref, err := repo.Head()
+		if err != nil {
+			return plumbing.ZeroHash, withstack.Errorf("nothing to amend: %w", err)
+		}
+		if head, err = repo.CommitObject(ref.Hash()); err != nil {
+			return plumbing.ZeroHash, withstack.Errorf("%w", err)
+		}
+		author = &head.Author
+		msg = firstOf(msg, head.Message)
+	} else if author, err = cfg.Author(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// go-git amends on top of the head with the tree of the head,
+	// so the commit of the index is rewritten onto the parents of the head
+	hash, err := worktree.Commit(msg, &git.CommitOptions{
+		Author:            author,
+		Committer:         committer,
+		AllowEmptyCommits: amend,
+	})
+	if err != nil {
+		return plumbing.ZeroHash, withstack.Errorf("%w", err)
+	}
+	if head == nil && signer == nil {
+		return hash, nil
+	}
+
+	commit, err := repo.CommitObject(hash)
+	if err != nil {
+		return plumbing.ZeroHash, withstack.Errorf("%w", err)
+	}
+	if head != nil {
+		commit.ParentHashes = head.ParentHashes
+	}
+	if hash, err = storeCommit(repo, commit, signer); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return hash, setHead(repo, hash)
+}
+
+// storeCommit stores the commit, signed by the signer when it is not nil.
+func storeCommit(repo *git.Repository, commit *object.Commit, signer Signer) (plumbing.Hash, error) {
+	commit.PGPSignature = ""
+	if signer != nil {
+		unsigned := repo.Storer.NewEncodedObject()
+		if err := commit.EncodeWithoutSignature(unsigned); err != nil {
+			return plumbing.ZeroHash, withstack.Errorf("%w", err)
+		}
+		r, err := unsigned.Reader()
+		if err != nil {
+			return plumbing.ZeroHash, withstack.Errorf("%w", err)
+		}
+		defer r.Close()
+		message, err := io.ReadAll(r)
+		if err != nil {
+			return plumbing.ZeroHash, withstack.Errorf("%w", err)
+		}
+		signature, err := signer.Sign(message)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		commit.PGPSignature = string(signature)
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return plumbing.ZeroHash, withstack.Errorf("%w", err)
+	}
+	hash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, withstack.Errorf("%w", err)
+	}
+	return hash, nil
+}
+
+// setHead points the branch of the head, or the detached head, at the commit.
+func setHead(repo *git.Repository, hash plumbing.Hash) error {
+	head, err := repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	name := plumbing.HEAD
+	if head.Type() != plumbing.HashReference {
+		name = head.Target()
+	}
+	if err := repo.Storer.SetReference(plumbing.NewHashReference(name, hash)); err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package gitutil
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var _ = Describe("git config and commit", func() {
+	var rootFS, homeFS billy.Filesystem
+	var repo *git.Repository
+	var env ConfigEnv
+	var vars map[string]string
+	now := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	// the config files are outside of the worktree, a checkout would remove them
+	writeFile := func(name, content string) {
+		Expect(util.WriteFile(homeFS, name, []byte(content), 0o644)).To(Succeed())
+	}
+	load := func() *Config {
+		cfg, err := LoadConfig(repo, env)
+		Expect(err).To(Succeed())
+		return cfg
+	}
+
+	BeforeEach(func() {
+		var err error
+		rootFS = memfs.New()
+		homeFS = memfs.New()
+		repo, err = MakeEmptyRepo(rootFS)
+		Expect(err).To(Succeed())
+		vars = map[string]string{"GIT_CONFIG_NOSYSTEM": "1"}
+		env = ConfigEnv{
+			FS:     homeFS,
+			Home:   "/home/annie",
+			Getenv: func(key string) string { return vars[key] },
+			Now:    func() time.Time { return now },
+		}
+		writeFile("/home/annie/.gitconfig", `[user]
+	name = Annie Mouse
+	email = annie@home.org
+[includeIf "gitdir:~/elsewhere/"]
+	path = elsewhere.inc
+[includeIf "gitdir/i:/.GIT"]
+	path = work.inc
+[includeIf "onbranch:release/"]
+	path = /etc/release.inc
+`)
+		writeFile("/home/annie/work.inc", "[user]\n\temail = annie@work.org\n[include]\n\tpath = ./signing.inc\n")
+		writeFile("/home/annie/elsewhere.inc", "[user]\n\temail = annie@elsewhere.org\n")
+		writeFile("/home/annie/signing.inc", "[user]\n\tsigningKey = ABCD1234\n")
+		writeFile("/etc/release.inc", "[user]\n\temail = annie@release.org\n")
+	})
+
+	It("resolves the identity as git does", func() {
+		cfg := load()
+		Expect(cfg.Get("user.signingkey")).To(Equal("ABCD1234"))
+		author, err := cfg.Author()
+		Expect(err).To(Succeed())
+		Expect(author.Name).To(Equal("Annie Mouse"))
+		Expect(author.Email).To(Equal("annie@work.org"))
+		Expect(author.When).To(Equal(now))
+
+		Expect(CheckoutCreateBranch(repo, "release/v2")).To(Succeed())
+		author, err = load().Author()
+		Expect(err).To(Succeed())
+		Expect(author.Email).To(Equal("annie@release.org"))
+
+		// the repo config wins over the global config, the env over both
+		repoConfig, err := repo.Config()
+		Expect(err).To(Succeed())
+		repoConfig.Raw.Section("committer").SetOption("email", "annie@repo.org")
+		Expect(repo.SetConfig(repoConfig)).To(Succeed())
+		vars["GIT_AUTHOR_NAME"] = "A. Mouse"
+		vars["GIT_AUTHOR_DATE"] = "@1700000000 +0100"
+		cfg = load()
+		author, err = cfg.Author()
+		Expect(err).To(Succeed())
+		Expect(author.Name).To(Equal("A. Mouse"))
+		Expect(author.Email).To(Equal("annie@release.org"))
+		Expect(author.When.Unix()).To(Equal(int64(1700000000)))
+		committer, err := cfg.Committer()
+		Expect(err).To(Succeed())
+		Expect(committer.Name).To(Equal("Annie Mouse"))
+		Expect(committer.Email).To(Equal("annie@repo.org"))
+
+		vars["GIT_CONFIG_GLOBAL"] = "/home/annie/missing"
+		_, err = load().Author()
+		Expect(err).To(MatchError(ErrNoIdentity))
+	})
+
+	It("commits and amends", func() {
+		worktree, err := repo.Worktree()
+		Expect(err).To(Succeed())
+		first, err := repo.Head()
+		Expect(err).To(Succeed())
+
+		Expect(util.WriteFile(rootFS, "/README.md", []byte("hello\n"), 0o644)).To(Succeed())
+		_, err = worktree.Add("README.md")
+		Expect(err).To(Succeed())
+		hash, err := Commit(repo, load(), "docs: add the readme", false)
+		Expect(err).To(Succeed())
+		commit, err := repo.CommitObject(hash)
+		Expect(err).To(Succeed())
+		Expect(commit.Author.Email).To(Equal("annie@work.org"))
+		Expect(commit.Committer.Email).To(Equal("annie@work.org"))
+		Expect(commit.PGPSignature).To(BeEmpty())
+
+		Expect(util.WriteFile(rootFS, "/README.md", []byte("hello world\n"), 0o644)).To(Succeed())
+		_, err = worktree.Add("README.md")
+		Expect(err).To(Succeed())
+		vars["GIT_COMMITTER_NAME"] = "Bob"
+		amended, err := Commit(repo, load(), "", true)
+		Expect(err).To(Succeed())
+		Expect(amended).ToNot(Equal(hash))
+
+		head, err := repo.Head()
+		Expect(err).To(Succeed())
+		Expect(head.Name()).To(Equal(plumbing.NewBranchReferenceName("main")))
+		Expect(head.Hash()).To(Equal(amended))
+		commit, err = repo.CommitObject(amended)
+		Expect(err).To(Succeed())
+		Expect(commit.Message).To(Equal("docs: add the readme"))
+		Expect(commit.ParentHashes).To(Equal([]plumbing.Hash{first.Hash()}))
+		Expect(commit.Author.Name).To(Equal("Annie Mouse"))
+		Expect(commit.Committer.Name).To(Equal("Bob"))
+		file, err := commit.File("README.md")
+		Expect(err).To(Succeed())
+		Expect(file.Contents()).To(Equal("hello world\n"))
+	})
+
+	It("signs when commit.gpgsign is set", func() {
+		dir := GinkgoT().TempDir()
+		program := filepath.Join(dir, "fakegpg")
+		Expect(os.WriteFile(program, []byte(`#!/bin/sh
+cat > "$(dirname "$0")/message"
+echo "-----BEGIN PGP SIGNATURE-----"
+echo "$@"
+echo "-----END PGP SIGNATURE-----"
+`), 0o755)).To(Succeed())
+		writeFile("/home/annie/.config/git/config", "[commit]\n\tgpgSign = true\n[gpg]\n\tprogram = "+program+"\n")
+
+		hash, err := Commit(repo, load(), "chore: sign me", true)
+		Expect(err).To(Succeed())
+		commit, err := repo.CommitObject(hash)
+		Expect(err).To(Succeed())
+		Expect(commit.PGPSignature).To(Equal("-----BEGIN PGP SIGNATURE-----\n--status-fd=2 -bsau ABCD1234\n-----END PGP SIGNATURE-----\n"))
+		Expect(commit.Message).To(Equal("chore: sign me"))
+
+		// the signature is of the commit without the signature
+		message, err := os.ReadFile(filepath.Join(dir, "message"))
+		Expect(err).To(Succeed())
+		Expect(string(message)).To(ContainSubstring("chore: sign me"))
+		Expect(string(message)).ToNot(ContainSubstring("gpgsig"))
+
+		writeFile("/home/annie/.config/git/config", "[commit]\n\tgpgSign = true\n[gpg]\n\tformat = ssh\n")
+		writeFile("/home/annie/signing.inc", "")
+		_, err = load().Signer(&commit.Committer)
+		Expect(err).ToNot(Succeed())
+	})
+
+	It("lists the recent authors once each", func() {
+		worktree, err := repo.Worktree()
+		Expect(err).To(Succeed())
+		when := now
+		for _, email := range []string{"bob@home.org", "annie@home.org", "BOB@home.org"} {
+			when = when.Add(time.Minute)
+			_, err := worktree.Commit("chore: empty", &git.CommitOptions{
+				AllowEmptyCommits: true,
+				Author:            &object.Signature{Name: email, Email: email, When: when},
+			})
+			Expect(err).To(Succeed())
+		}
+
+		authors, err := RecentAuthors(repo, 5)
+		Expect(err).To(Succeed())
+		var emails []string
+		for _, a := range authors {
+			emails = append(emails, a.Email)
+		}
+		Expect(emails).To(Equal([]string{"BOB@home.org", "annie@home.org"}))
+
+		authors, err = RecentAuthors(repo, 1)
+		Expect(err).To(Succeed())
+		Expect(authors).To(HaveLen(1))
+	})
+
+	It("installs hooks in the .git dir or core.hooksPath", func() {
+		file, err := InstallHook(repo, load(), "commit-msg", "exit 0\n", false)
+		Expect(err).To(Succeed())
+		Expect(file).To(Equal("/.git/hooks/commit-msg"))
+		hook, err := util.ReadFile(rootFS, ".git/hooks/commit-msg")
+		Expect(err).To(Succeed())
+		Expect(string(hook)).To(Equal("#!/bin/sh\n# installed by cmr hooks install\nexit 0\n"))
+		fi, err := rootFS.Stat(".git/hooks/commit-msg")
+		Expect(err).To(Succeed())
+		Expect(fi.Mode().Perm()).To(Equal(os.FileMode(0o755)))
+
+		// a hook of cmr is replaced, any other hook only by force
+		_, err = InstallHook(repo, load(), "commit-msg", "exit 1\n", false)
+		Expect(err).To(Succeed())
+		Expect(util.WriteFile(rootFS, ".git/hooks/commit-msg", []byte("#!/bin/sh\nlint\n"), 0o755)).To(Succeed())
+		_, err = InstallHook(repo, load(), "commit-msg", "exit 0\n", false)
+		Expect(err).To(MatchError(ErrHookExists))
+		_, err = InstallHook(repo, load(), "commit-msg", "exit 0\n", true)
+		Expect(err).To(Succeed())
+
+		writeFile("/home/annie/.config/git/config", "[core]\n\thooksPath = ~/hooks\n")
+		_, err = InstallHook(repo, load(), "commit-msg", "exit 0\n", false)
+		Expect(err).To(Succeed())
+		Expect(util.ReadFile(homeFS, "/home/annie/hooks/commit-msg")).To(ContainSubstring("exit 0"))
+	})
+})
@@ -0,0 +1,273 @@
+package gitutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	format "github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// maxIncludeDepth bounds the nesting of include and includeIf, as git does.
+const maxIncludeDepth = 10
+
+// ConfigEnv is where LoadConfig reads the config files and the environment of git.
+type ConfigEnv struct {
+	FS     billy.Filesystem // the system and global config files and the included files by absolute path
+	Home   string
+	Getenv func(string) string
+	Now    func() time.Time
+}
+
+// NewConfigEnv returns the env of the os.
+func NewConfigEnv() (ConfigEnv, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ConfigEnv{}, withstack.Errorf("%w", err)
+	}
+	return ConfigEnv{
+		FS:     osfs.New("/"),
+		Home:   home,
+		Getenv: os.Getenv,
+		Now:    time.Now,
+	}, nil
+}
+
+// Config is the git config of a repo, merged from the system, global and repo config files.
+type Config struct {
+	env    ConfigEnv
+	values map[string][]string
+}
+
+// LoadConfig reads the git config of the repo the way git does:
+// the system, then the global and then the repo config, a later value wins.
+// GIT_CONFIG_NOSYSTEM, GIT_CONFIG_SYSTEM and GIT_CONFIG_GLOBAL choose the files.
+// The files of include.path are read where they are included, those of includeIf.<condition>.path
+// when the gitdir:, gitdir/i: or onbranch: condition holds.
+func LoadConfig(repo *git.Repository, env ConfigEnv) (*Config, error) {
+	l := &configLoader{
+		Config: &Config{env: env, values: map[string][]string{}},
+		gitDir: repoGitDir(repo),
+	}
+	if branch, err := BranchShortName(repo); err == nil {
+		l.branch = branch
+	}
+
+	var files []string
+	if env.Getenv("GIT_CONFIG_NOSYSTEM") == "" {
+		files = append(files, firstOf(env.Getenv("GIT_CONFIG_SYSTEM"), "/etc/gitconfig"))
+	}
+	if global := env.Getenv("GIT_CONFIG_GLOBAL"); global != "" {
+		files = append(files, global)
+	} else {
+		xdg := firstOf(env.Getenv("XDG_CONFIG_HOME"), path.Join(env.Home, ".config"))
+		files = append(files, path.Join(xdg, "git", "config"), path.Join(env.Home, ".gitconfig"))
+	}
+	for _, file := range files {
+		if err := l.readFile(l.expand(file, ""), 0); err != nil {
+			return nil, err
+		}
+	}
+
+	repoConfig, err := repo.Config()
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	if err := l.merge(repoConfig.Raw, l.gitDir, 0); err != nil {
+		return nil, err
+	}
+	return l.Config, nil
+}
+
+// Get returns the last value of the key such as user.name or gpg.ssh.program, empty when it is not set.
+func (c *Config) Get(key string) string {
+	values := c.values[configKey(key)]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// Bool returns whether the key is set to true, yes, on or 1.
+func (c *Config) Bool(key string) bool {
+	switch strings.ToLower(c.Get(key)) {
+	case "true", "yes", "on", "1":
+		return true
+	}
+	return false
+}
+
+func (c *Config) add(section, subsection, name, value string) {
+	key := section + "." + name
+	if subsection != "" {
+		key = section + "." + subsection + "." + name
+	}
+	key = configKey(key)
+	c.values[key] = append(c.values[key], value)
+}
+
+// configKey lowers the section and the name of the key, the subsection keeps its case.
+func configKey(key string) string {
+	first := strings.Index(key, ".")
+	last := strings.LastIndex(key, ".")
+	if first < 0 {
+		return strings.ToLower(key)
+	}
+	return strings.ToLower(key[:first]) + key[first:last] + strings.ToLower(key[last:])
+}
+
+type configLoader struct {
+	*Config
+	gitDir string
+	branch string
+}
+
+// readFile merges the config file, a missing file is skipped as git does.
+func (l *configLoader) readFile(file string, depth int) error {
+	if maxIncludeDepth < depth {
+		return withstack.Errorf("%s: more than %d nested includes", file, maxIncludeDepth)
+	}
+	f, err := l.env.FS.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	defer f.Close()
+
+	raw := format.New()
+	if err := format.NewDecoder(f).Decode(raw); err != nil && !errors.Is(err, io.EOF) {
+		return withstack.Errorf("%s: %w", file, err)
+	}
+	return l.merge(raw, path.Dir(file), depth)
+}
+
+// merge adds the values of the config in order, dir is where its relative includes are.
+func (l *configLoader) merge(raw *format.Config, dir string, depth int) error {
+	for _, section := range raw.Sections {
+		name := strings.ToLower(section.Name)
+		for _, o := range section.Options {
+			l.add(name, "", o.Key, o.Value)
+			if name == "include" && strings.EqualFold(o.Key, "path") {
+				if err := l.include(o.Value, dir, depth); err != nil {
+					return err
+				}
+			}
+		}
+		for _, sub := range section.Subsections {
+			for _, o := range sub.Options {
+				l.add(name, sub.Name, o.Key, o.Value)
+				if name == "includeif" && strings.EqualFold(o.Key, "path") && l.holds(sub.Name, dir) {
+					if err := l.include(o.Value, dir, depth); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// holds is whether the condition of includeIf holds, unknown conditions do not.
+func (l *configLoader) holds(condition string, dir string) bool {
+	if pattern, ok := strings.CutPrefix(condition, "gitdir:"); ok {
+		return l.gitDir != "" && l.globOf(pattern, dir, false).MatchString(l.gitDir)
+	}
+	if pattern, ok := strings.CutPrefix(condition, "gitdir/i:"); ok {
+		return l.gitDir != "" && l.globOf(pattern, dir, true).MatchString(l.gitDir)
+	}
+	if pattern, ok := strings.CutPrefix(condition, "onbranch:"); ok {
+		if strings.HasSuffix(pattern, "/") {
+			pattern += "**"
+		}
+		return l.branch != "" && globRegexp(pattern, false).MatchString(l.branch)
+	}
+	return false
+}
+
+// globOf is the gitdir pattern: ~/ is the home, ./ the dir of the config file,
+// a relative pattern matches at any depth and a trailing / matches everything below.
+func (l *configLoader) globOf(pattern string, dir string, fold bool) *regexp.Regexp {
+	pattern = l.expand(pattern, dir)
+	if !strings.HasPrefix(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	if strings.HasSuffix(pattern, "/") {
+		pattern += "**"
+	}
+	return globRegexp(pattern, fold)
+}
+
+// expand replaces a leading ~/ with the home and a leading ./ with the dir.
+func (l *configLoader) expand(p string, dir string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		return path.Join(l.env.Home, rest)
+	}
+	if rest, ok := strings.CutPrefix(p, "./"); ok {
+		return path.Join(dir, rest)
+	}
+	return p
+}
+
+// include reads the included file, a relative path is relative to the dir of the including file.
+func (l *configLoader) include(p string, dir string, depth int) error {
+	p = l.expand(p, dir)
+	if !path.IsAbs(p) {
+		p = path.Join(dir, p)
+	}
+	return l.readFile(p, depth+1)
+}
+
+// globRegexp matches the whole path against the glob, ** matches across slashes.
+func globRegexp(glob string, fold bool) *regexp.Regexp {
+	var b strings.Builder
+	if fold {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case glob[i] == '*':
+			b.WriteString("[^/]*")
+		case glob[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
+
+// repoGitDir is the path of the .git dir of the repo, empty when it is not stored in a filesystem.
+func repoGitDir(repo *git.Repository) string {
+	if s, ok := repo.Storer.(*filesystem.Storage); ok {
+		return s.Filesystem().Root()
+	}
+	return ""
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -1,17 +1,22 @@
 package gitutil
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"regexp"
 	"strings"
 	"time"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/cache"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/storage/filesystem"
 
@@ -56,59 +61,117 @@ func NewEmptyCommitOptions(authorName string) *git.CommitOptions {
 		}}
 }
 
-func Clone(directory, url, token string, progress io.Writer) error {
-	_, err := git.PlainClone(directory, false, &git.CloneOptions{
+// ErrDirty is returned by Pull when the worktree has changes the pull could overwrite.
+var ErrDirty = errors.New("worktree has uncommitted changes")
+
+// tokenAuth returns the basic auth of the token, nil for an empty token.
+func tokenAuth(token string) *http.BasicAuth {
+	if token == "" {
+		return nil
+	}
+	return &http.BasicAuth{
+		Username: "auto", // yes, this can be anything except an empty string
+		Password: token,
+	}
+}
+
+// urlAuth returns the basic auth of the token for an http(s) url.
+// It returns nil for an empty token or any other url, such as ssh, so its transport
+// uses its own credentials, for ssh the agent or the keys.
+func urlAuth(remoteURL, token string) transport.AuthMethod {
+	if token == "" {
+		return nil
+	}
+	endpoint, err := transport.NewEndpoint(remoteURL)
+	if err != nil || (endpoint.Protocol != "http" && endpoint.Protocol != "https") {
+		return nil
+	}
+	return tokenAuth(token)
+}
+
+// remoteAuth returns the urlAuth of the url of the remote.
+func remoteAuth(repo *git.Repository, remoteName, token string) (transport.AuthMethod, error) {
+	if token == "" {
+		return nil, nil
+	}
+	remoteURL, err := RemoteURL(repo, remoteName)
+	if err != nil {
+		return nil, err
+	}
+	return urlAuth(remoteURL, token), nil
+}
+
+// Clone clones the url into the directory, which is removed again if the clone created it and failed.
+// The token authenticates an http(s) url, an empty token or an ssh url clones without it.
+func Clone(ctx context.Context, directory, url, token string, progress io.Writer) error {
+	opts := &git.CloneOptions{
 		URL:               url,
+		Auth:              urlAuth(url, token),
 		RecurseSubmodules: git.DefaultSubmoduleRecursionDepth,
-		Auth: &http.BasicAuth{
-			Username: "auto", // yes, this can be anything except an empty string
-			Password: token,
-		},
-		Progress: progress,
-	})
-	return err
+		Progress:          progress,
+	}
+	_, statErr := os.Stat(directory)
+	created := os.IsNotExist(statErr)
+	if _, err := git.PlainCloneContext(ctx, directory, false, opts); err != nil {
+		// a partial clone has a .git dir and would pass for a clone on the next run
+		if created {
+			_ = os.RemoveAll(directory)
+		}
+		return withstack.Errorf("Could not clone %s: %w", url, err)
+	}
+	return nil
 }
 
-func Pull(directory, url, token string, progress io.Writer) error {
-	// We instantiate a new repository targeting the given path (the .git folder)
+// Pull pulls the current branch of the repo in the directory from origin.
+// It returns true if commits were pulled, false if the branch was up to date.
+// ErrDirty is returned without pulling if tracked files have changes.
+// The token authenticates an http(s) origin, an empty token or an ssh origin pulls without it.
+func Pull(ctx context.Context, directory, token string, progress io.Writer) (bool, error) {
 	r, err := git.PlainOpen(directory)
 	if err != nil {
-		return err
+		return false, withstack.Errorf("%w", err)
 	}
-
-	// Get the working directory for the repository
 	w, err := r.Worktree()
 	if err != nil {
-		return err
+		return false, withstack.Errorf("%w", err)
 	}
-
-	// Pull the latest changes from the origin remote and merge into the current branch
-	fmt.Println("git pull origin")
-	err = w.Pull(
-		&git.PullOptions{
-			RemoteName: "origin",
-			Auth: &http.BasicAuth{
-				Username: "auto", // yes, this can be anything except an empty string
-				Password: token,
-			},
-			Progress: progress,
-		})
+	status, err := w.Status()
 	if err != nil {
-		return err
+		return false, withstack.Errorf("%w", err)
+	}
+	if isDirty(status) {
+		return false, ErrDirty
 	}
 
-	// Print the latest commit that was just pulled
-	ref, err := r.Head()
+	auth, err := remoteAuth(r, "origin", token)
 	if err != nil {
-		return err
+		return false, err
+	}
+	err = w.PullContext(ctx, &git.PullOptions{
+		RemoteName: "origin",
+		Auth:       auth,
+		Progress:   progress,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return false, nil
 	}
-	commit, err := r.CommitObject(ref.Hash())
 	if err != nil {
-		return err
+		return false, withstack.Errorf("Could not pull %s: %w", directory, err)
 	}
+	return true, nil
+}
 
-	fmt.Println(commit)
-	return nil
+// isDirty returns true if a tracked file is changed, untracked files do not block a pull.
+func isDirty(status git.Status) bool {
+	for _, fs := range status {
+		if fs.Worktree == git.Untracked && fs.Staging == git.Untracked {
+			continue
+		}
+		if fs.Worktree != git.Unmodified || fs.Staging != git.Unmodified {
+			return true
+		}
+	}
+	return false
 }
 
 func MakeEmptyRepoWithBranchCommitTag(
@@ -174,30 +237,6 @@ func MakeEmptyRepoWithBranchCommitTag(
 	return repo, closeErr
 }
 
-var oldJiraRE = regexp.MustCompile(`^JIRA\d{4}$`)
-
-// isJiraLabel return true on JIRA1234
-func isJiraLabel(s string) bool {
-	return oldJiraRE.MatchString(s)
-}
-
-var branchLabelRE = regexp.MustCompile(`^[a-zA-Z0-9]+(_[a-zA-Z0-9]+)*$`)
-
-// isBranchLabel return true on alpha numeric words separated by underscore: snake_case_Labels01
-func isBranchLabel(s string) bool {
-	return branchLabelRE.MatchString(s)
-}
-
-func CheckoutCreateBranchJiraLabel(repo *git.Repository, jiraIssue, branchLabel string) error {
-	if isJiraLabel(jiraIssue) {
-		return fmt.Errorf("not in expected jira format:%s", jiraIssue)
-	}
-	if !isBranchLabel(branchLabel) {
-		return fmt.Errorf("not in expected branch label format:%s", branchLabel)
-	}
-	return CheckoutCreateBranch(repo, fmt.Sprintf("%s_%s", jiraIssue, branchLabel))
-}
-
 // CheckoutCreateBranch
 // Info("git branch my-branch")
 func CheckoutCreateBranch(repo *git.Repository, branchShortName string) error {
@@ -245,6 +284,134 @@ func Push(fs billy.Filesystem) error {
 	return repo.Push(&git.PushOptions{})
 }
 
+// PushBranch pushes the local branch to the same name on the remote
+// and sets the remote branch as the upstream of the local branch.
+// The token authenticates an http(s) remote, an empty token or an ssh remote pushes without it.
+func PushBranch(repo *git.Repository, remoteName, branchShortName, token string, progress io.Writer) error {
+	auth, err := remoteAuth(repo, remoteName, token)
+	if err != nil {
+		return err
+	}
+	refName := plumbing.NewBranchReferenceName(branchShortName)
+	opts := &git.PushOptions{
+		RemoteName: remoteName,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("%s:%s", refName, refName)),
+		},
+		Auth:     auth,
+		Progress: progress,
+	}
+	if err := repo.Push(opts); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return withstack.Errorf("Could not push %s to %s: %w", branchShortName, remoteName, err)
+	}
+	return SetUpstream(repo, remoteName, branchShortName)
+}
+
+// CheckoutRemoteBranch fetches the branch from the remote and checks it out.
+// A new local branch starts at the remote branch and tracks it,
+// an existing local branch is checked out as is.
+// The token authenticates an http(s) remote, an empty token or an ssh remote fetches without it.
+func CheckoutRemoteBranch(repo *git.Repository, remoteName, branchShortName, token string, progress io.Writer) error {
+	auth, err := remoteAuth(repo, remoteName, token)
+	if err != nil {
+		return err
+	}
+	refName := plumbing.NewBranchReferenceName(branchShortName)
+	remoteRefName := plumbing.NewRemoteReferenceName(remoteName, branchShortName)
+	opts := &git.FetchOptions{
+		RemoteName: remoteName,
+		RefSpecs: []config.RefSpec{
+			config.RefSpec(fmt.Sprintf("+%s:%s", refName, remoteRefName)),
+		},
+		Auth:     auth,
+		Progress: progress,
+	}
+	if err := repo.Fetch(opts); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return withstack.Errorf("Could not fetch %s from %s: %w", branchShortName, remoteName, err)
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	if _, err := repo.Reference(refName, false); err == nil {
+		if err := worktree.Checkout(&git.CheckoutOptions{Branch: refName}); err != nil {
+			return withstack.Errorf("Could not checkout %s: %w", branchShortName, err)
+		}
+		return nil
+	}
+
+	remoteRef, err := repo.Reference(remoteRefName, true)
+	if err != nil {
+		return withstack.Errorf("Could not find %s: %w", remoteRefName, err)
+	}
+	err = worktree.Checkout(&git.CheckoutOptions{
+		Branch: refName,
+		Hash:   remoteRef.Hash(),
+		Create: true,
+	})
+	if err != nil {
+		return withstack.Errorf("Could not checkout %s: %w", branchShortName, err)
+	}
+	return SetUpstream(repo, remoteName, branchShortName)
+}
+
+// SetUpstream sets the tracking branch of the local branch
+// git branch --set-upstream-to=remote/branch branch
+func SetUpstream(repo *git.Repository, remoteName, branchShortName string) error {
+	cfg, err := repo.Config()
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	cfg.Branches[branchShortName] = &config.Branch{
+		Name:   branchShortName,
+		Remote: remoteName,
+		Merge:  plumbing.NewBranchReferenceName(branchShortName),
+	}
+	if err := repo.SetConfig(cfg); err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	return nil
+}
+
+// RemoteURL returns the first url of the named remote.
+func RemoteURL(repo *git.Repository, remoteName string) (string, error) {
+	remote, err := repo.Remote(remoteName)
+	if err != nil {
+		return "", withstack.Errorf("Could not get remote %s: %w", remoteName, err)
+	}
+	urls := remote.Config().URLs
+	if len(urls) < 1 {
+		return "", fmt.Errorf("remote %s has no url", remoteName)
+	}
+	return urls[0], nil
+}
+
+var scpLikeURLRE = regexp.MustCompile(`^(?:[^@/]+@)?[^:/]+:(.+)$`)
+
+// ParseRemotePath returns the path with namespace of a remote url:
+// git@gitlab.example.com:group/project.git and
+// https://gitlab.example.com/group/project.git both return group/project
+func ParseRemotePath(remoteURL string) (string, error) {
+	var path string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil {
+			return "", withstack.Errorf("Could not parse remote url: %w", err)
+		}
+		path = u.Path
+	} else if m := scpLikeURLRE.FindStringSubmatch(remoteURL); m != nil {
+		path = m[1]
+	} else {
+		return "", fmt.Errorf("unexpected remote url format:%s", remoteURL)
+	}
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	if path == "" {
+		return "", fmt.Errorf("remote url has no path:%s", remoteURL)
+	}
+	return path, nil
+}
+
 func OpenRepo(fs billy.Filesystem) (*git.Repository, error) {
 	if _, err := fs.Stat(git.GitDirName); err != nil {
 		return nil, withstack.Errorf("%w", err)
@@ -325,19 +492,6 @@ func FileStatusString(fs git.FileStatus) string {
 	)
 }
 
-var jiraRE = regexp.MustCompile(`[A-Z]{3,}-[0-9]{2,4}`)
-
-func ParseBranchJiraTitle(branchName string) (string, string) {
-	issue := jiraRE.FindString(branchName)
-	if 0 < len(issue) {
-		branchName = strings.Replace(branchName, issue, "", 1)
-	}
-	branchName = strings.Replace(branchName, "_", " ", -1)
-	words := strings.Fields(branchName)
-	description := strings.Join(words, " ")
-	return issue, description
-}
-
 // AddAll adds all the files or directory to the staging index.
 func AddAll(worktree *git.Worktree, filePaths []string) error {
 	// FIXME? on error use git reset to rollback?
@@ -349,3 +503,45 @@ func AddAll(worktree *git.Worktree, filePaths []string) error {
 	}
 	return nil
 }
+
+// CommitsBetween returns the commits reachable from the to revision and not from the from revision,
+// as git log from..to lists them.
+func CommitsBetween(repo *git.Repository, from, to string) ([]*object.Commit, error) {
+	fromHash, err := repo.ResolveRevision(plumbing.Revision(from))
+	if err != nil {
+		return nil, withstack.Errorf("Could not resolve %s: %w", from, err)
+	}
+	toHash, err := repo.ResolveRevision(plumbing.Revision(to))
+	if err != nil {
+		return nil, withstack.Errorf("Could not resolve %s: %w", to, err)
+	}
+
+	excluded := map[plumbing.Hash]bool{}
+	fromLog, err := repo.Log(&git.LogOptions{From: *fromHash})
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	err = fromLog.ForEach(func(c *object.Commit) error {
+		excluded[c.Hash] = true
+		return nil
+	})
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+
+	toLog, err := repo.Log(&git.LogOptions{From: *toHash})
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	var commits []*object.Commit
+	err = toLog.ForEach(func(c *object.Commit) error {
+		if !excluded[c.Hash] {
+			commits = append(commits, c)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	return commits, nil
+}
@@ -1,6 +1,9 @@
 package gitutil
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -9,6 +12,9 @@ import (
 	"github.com/go-git/go-billy/v5"
 	"github.com/go-git/go-billy/v5/memfs"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 func TestBranch(t *testing.T) {
@@ -16,55 +22,6 @@ func TestBranch(t *testing.T) {
 	RunSpecs(t, "git_test")
 }
 
-var _ = Describe("branch labels", func() {
-
-	DescribeTable("snake_case_labels",
-		func(label string, isValid bool) {
-			Expect(isBranchLabel(label)).To(Equal(isValid))
-		},
-		Entry(nil, "x", true),
-		Entry(nil, "xxx", true),
-		Entry(nil, "x_x", true),
-		Entry(nil, "0", true),
-		Entry(nil, "0_9", true),
-		Entry(nil, "mylabel", true),
-		Entry(nil, "my_label", true),
-		Entry(nil, "My_Label", true),
-		Entry(nil, "my_label_0123456789", true),
-
-		Entry(nil, "", false),
-		Entry(nil, "my label", false),
-		Entry(nil, " my_label", false),
-		Entry(nil, "my_label ", false),
-		Entry(nil, "_", false),
-		Entry(nil, "_x", false),
-		Entry(nil, "x_", false),
-	)
-
-	DescribeTable("JIRA1234",
-		func(label string, isValid bool) {
-			Expect(isJiraLabel(label)).To(Equal(isValid))
-		},
-		Entry(nil, "JIRA1234", true),
-		Entry(nil, "JIRA0000", true),
-		Entry(nil, "JIRA9999", true),
-
-		Entry(nil, "", false),
-		Entry(nil, " JIRA1234", false),
-		Entry(nil, "JIRA1234 ", false),
-		Entry(nil, "JIRA1234 ", false),
-		Entry(nil, "JIRA123", false),
-		Entry(nil, "JIRA123x", false),
-		Entry(nil, "JIRA123_", false),
-		Entry(nil, "JIRA1234x", false),
-		Entry(nil, "DORA1234", false),
-		Entry(nil, "_", false),
-		Entry(nil, "_x", false),
-		Entry(nil, "x_", false),
-	)
-
-})
-
 var _ = Describe("Create an empty repo", func() {
 	Context("in memory fs", func() {
 		var rootFS billy.Filesystem
@@ -111,24 +68,178 @@ var _ = Describe("CheckoutCreateBranch", func() {
 	})
 })
 
-var _ = Describe("parse branch jira title", func() {
+var _ = Describe("parse remote path", func() {
+	DescribeTable("remote urls",
+		func(remoteURL string, path string, isValid bool) {
+			havePath, err := ParseRemotePath(remoteURL)
+			if isValid {
+				Expect(err).To(Succeed())
+				Expect(havePath).To(Equal(path))
+			} else {
+				Expect(err).ToNot(Succeed())
+			}
+		},
+		Entry(nil, "git@gitlab.example.com:group/project.git", "group/project", true),
+		Entry(nil, "gitlab.example.com:group/sub/project", "group/sub/project", true),
+		Entry(nil, "https://gitlab.example.com/group/project.git", "group/project", true),
+		Entry(nil, "https://gitlab.example.com/group/sub/project/", "group/sub/project", true),
+		Entry(nil, "ssh://git@gitlab.example.com:2222/group/project.git", "group/project", true),
+
+		Entry(nil, "", "", false),
+		Entry(nil, "https://gitlab.example.com/", "", false),
+		Entry(nil, "/local/path/project.git", "", false),
+	)
+})
+
+var _ = Describe("PushBranch", func() {
+	It("pushes to a bare remote and tracks it", func() {
+		remoteDir := GinkgoT().TempDir()
+		_, err := git.PlainInit(remoteDir, true)
+		Expect(err).To(Succeed())
 
-	DescribeTable("branch namesk",
-		func(label string, issue string, description string) {
-			haveIssue, haveDescription := ParseBranchJiraTitle(label)
-			Expect(haveIssue).To(Equal(issue))
-			Expect(haveDescription).To(Equal(description))
+		repo, err := git.PlainInit(GinkgoT().TempDir(), false)
+		Expect(err).To(Succeed())
+		worktree, err := repo.Worktree()
+		Expect(err).To(Succeed())
+		hash, err := worktree.Commit("Initial commit.", NewEmptyCommitOptions("Annie Mouse"))
+		Expect(err).To(Succeed())
+		const shortName = "ABC-1234_my_label"
+		Expect(CheckoutCreateBranch(repo, shortName)).To(Succeed())
+		_, err = repo.CreateRemote(&config.RemoteConfig{
+			Name: "origin",
+			URLs: []string{remoteDir},
+		})
+		Expect(err).To(Succeed())
+
+		Expect(PushBranch(repo, "origin", shortName, "", nil)).To(Succeed())
+		// pushing again is not an error
+		Expect(PushBranch(repo, "origin", shortName, "", nil)).To(Succeed())
+
+		remote, err := git.PlainOpen(remoteDir)
+		Expect(err).To(Succeed())
+		ref, err := remote.Reference(plumbing.NewBranchReferenceName(shortName), true)
+		Expect(err).To(Succeed())
+		Expect(ref.Hash()).To(Equal(hash))
+
+		cfg, err := repo.Config()
+		Expect(err).To(Succeed())
+		Expect(cfg.Branches).To(HaveKey(shortName))
+		Expect(cfg.Branches[shortName].Remote).To(Equal("origin"))
+		Expect(cfg.Branches[shortName].Merge).To(Equal(plumbing.NewBranchReferenceName(shortName)))
+
+		url, err := RemoteURL(repo, "origin")
+		Expect(err).To(Succeed())
+		Expect(url).To(Equal(remoteDir))
+	})
+})
+
+var _ = Describe("remote auth", func() {
+	DescribeTable("sends the token only over http",
+		func(remoteURL string, isBasic bool) {
+			repo, err := git.Init(memory.NewStorage(), memfs.New())
+			Expect(err).To(Succeed())
+			_, err = repo.CreateRemote(&config.RemoteConfig{
+				Name: "origin",
+				URLs: []string{remoteURL},
+			})
+			Expect(err).To(Succeed())
+
+			auth, err := remoteAuth(repo, "origin", "secret")
+			Expect(err).To(Succeed())
+			if isBasic {
+				Expect(auth).To(Equal(tokenAuth("secret")))
+			} else {
+				Expect(auth).To(BeNil())
+			}
 		},
-		Entry(nil, "x", "", "x"),
-		Entry(nil, "xy", "", "xy"),
-		Entry(nil, "x_y", "", "x y"),
-		Entry(nil, "JIRA-1234_x_y", "JIRA-1234", "x y"),
-		Entry(nil, "JIRA-1234__x1?x_y_", "JIRA-1234", "x1?x y"),
-		Entry(nil, "ABC-12__xx_y_", "ABC-12", "xx y"),
-		Entry(nil, "AB-12__xx_y_", "", "AB-12 xx y"),
-		Entry(nil, "xx_y_ABC-12", "ABC-12", "xx y"),
-		Entry(nil, "xx_y_ABC-123", "ABC-123", "xx y"),
-		Entry(nil, "xx_y_ABC-1234", "ABC-1234", "xx y"),
-		Entry(nil, "xx_y_ABC-123456y", "ABC-1234", "xx y 56y"),
+		Entry(nil, "https://gitlab.example.com/group/project.git", true),
+		Entry(nil, "http://localhost:8089/group/project.git", true),
+		Entry(nil, "git@gitlab.example.com:group/project.git", false),
+		Entry(nil, "ssh://git@gitlab.example.com/group/project.git", false),
+		Entry(nil, "/srv/git/project.git", false),
 	)
 })
+
+var _ = Describe("CheckoutRemoteBranch", func() {
+	It("checks out a branch of the remote and tracks it", func() {
+		remoteDir := GinkgoT().TempDir()
+		remote, err := git.PlainInit(remoteDir, false)
+		Expect(err).To(Succeed())
+		remoteTree, err := remote.Worktree()
+		Expect(err).To(Succeed())
+		_, err = remoteTree.Commit("Initial commit.", NewEmptyCommitOptions("Annie Mouse"))
+		Expect(err).To(Succeed())
+		const shortName = "ABC-1234_review_me"
+		Expect(CheckoutCreateBranch(remote, shortName)).To(Succeed())
+		hash, err := remoteTree.Commit("Review me.", NewEmptyCommitOptions("Annie Mouse"))
+		Expect(err).To(Succeed())
+
+		repo, err := git.PlainInit(GinkgoT().TempDir(), false)
+		Expect(err).To(Succeed())
+		worktree, err := repo.Worktree()
+		Expect(err).To(Succeed())
+		_, err = worktree.Commit("Local commit.", NewEmptyCommitOptions("Annie Mouse"))
+		Expect(err).To(Succeed())
+		_, err = repo.CreateRemote(&config.RemoteConfig{
+			Name: "origin",
+			URLs: []string{remoteDir},
+		})
+		Expect(err).To(Succeed())
+
+		Expect(CheckoutRemoteBranch(repo, "origin", shortName, "secret", nil)).To(Succeed())
+		head, err := repo.Head()
+		Expect(err).To(Succeed())
+		Expect(head.Name()).To(Equal(plumbing.NewBranchReferenceName(shortName)))
+		Expect(head.Hash()).To(Equal(hash))
+
+		cfg, err := repo.Config()
+		Expect(err).To(Succeed())
+		Expect(cfg.Branches).To(HaveKey(shortName))
+
+		// checking out again keeps the local branch
+		Expect(CheckoutRemoteBranch(repo, "origin", shortName, "", nil)).To(Succeed())
+
+		Expect(CheckoutRemoteBranch(repo, "origin", "no_such_branch", "", nil)).ToNot(Succeed())
+	})
+})
+
+var _ = Describe("Clone and Pull", func() {
+	It("clones, pulls and refuses a dirty worktree", func() {
+		ctx := context.Background()
+		remoteDir := GinkgoT().TempDir()
+		remote, err := git.PlainInit(remoteDir, false)
+		Expect(err).To(Succeed())
+		remoteTree, err := remote.Worktree()
+		Expect(err).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(remoteDir, "README.md"), []byte("hello\n"), 0o644)).To(Succeed())
+		_, err = remoteTree.Add("README.md")
+		Expect(err).To(Succeed())
+		_, err = remoteTree.Commit("Initial commit.", NewEmptyCommitOptions("Annie Mouse"))
+		Expect(err).To(Succeed())
+
+		dir := filepath.Join(GinkgoT().TempDir(), "clone")
+		Expect(Clone(ctx, dir, remoteDir, "", nil)).To(Succeed())
+
+		pulled, err := Pull(ctx, dir, "", nil)
+		Expect(err).To(Succeed())
+		Expect(pulled).To(BeFalse())
+
+		_, err = remoteTree.Commit("Second commit.", NewEmptyCommitOptions("Annie Mouse"))
+		Expect(err).To(Succeed())
+		// the token is only for an http(s) origin
+		pulled, err = Pull(ctx, dir, "secret", nil)
+		Expect(err).To(Succeed())
+		Expect(pulled).To(BeTrue())
+
+		// untracked files do not block the pull
+		Expect(os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("mine\n"), 0o644)).To(Succeed())
+		_, err = Pull(ctx, dir, "", nil)
+		Expect(err).To(Succeed())
+
+		Expect(os.WriteFile(filepath.Join(dir, "README.md"), []byte("changed\n"), 0o644)).To(Succeed())
+		_, err = Pull(ctx, dir, "", nil)
+		Expect(err).To(MatchError(ErrDirty))
+
+		Expect(Clone(ctx, dir, remoteDir, "", nil)).ToNot(Succeed())
+	})
+})
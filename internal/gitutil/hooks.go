@@ -0,0 +1,73 @@
+package gitutil
+
+import (
+	"errors"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// ErrHookExists is returned by InstallHook when a hook that cmr did not install is in the way.
+var ErrHookExists = errors.New("a hook that cmr did not install is in the way, use --force to replace it")
+
+// hookMarker tells the hooks that cmr installed.
+const hookMarker = "# installed by cmr hooks install"
+
+// InstallHook writes the sh script as the named hook of the repo, in core.hooksPath when it is set.
+// A hook that cmr did not install is only replaced when force is set. It returns the path of the hook.
+func InstallHook(repo *git.Repository, cfg *Config, name string, script string, force bool) (string, error) {
+	fs, dir, err := hooksDir(repo, cfg)
+	if err != nil {
+		return "", err
+	}
+	file := path.Join(dir, name)
+	existing, err := util.ReadFile(fs, file)
+	if err != nil && !os.IsNotExist(err) {
+		return "", withstack.Errorf("%w", err)
+	}
+	if err == nil && !force && !strings.Contains(string(existing), hookMarker) {
+		return "", withstack.Errorf("%s: %w", file, ErrHookExists)
+	}
+
+	content := "#!/bin/sh\n" + hookMarker + "\n" + script
+	if err := util.WriteFile(fs, file, []byte(content), 0o755); err != nil {
+		return "", withstack.Errorf("%w", err)
+	}
+	// a replaced hook keeps its mode
+	if change, ok := fs.(billy.Change); ok {
+		if err := change.Chmod(file, 0o755); err != nil {
+			return "", withstack.Errorf("%w", err)
+		}
+	}
+	return path.Join(fs.Root(), file), nil
+}
+
+// hooksDir is the dir of the hooks: core.hooksPath, relative to the worktree or absolute,
+// or else the hooks dir of the .git dir.
+func hooksDir(repo *git.Repository, cfg *Config) (billy.Filesystem, string, error) {
+	if hooksPath := cfg.Get("core.hooksPath"); hooksPath != "" {
+		if rest, ok := strings.CutPrefix(hooksPath, "~/"); ok {
+			hooksPath = path.Join(cfg.env.Home, rest)
+		}
+		if path.IsAbs(hooksPath) {
+			return cfg.env.FS, hooksPath, nil
+		}
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return nil, "", withstack.Errorf("%w", err)
+		}
+		return worktree.Filesystem, hooksPath, nil
+	}
+	s, ok := repo.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, "", withstack.Errorf("the repo has no .git dir for its hooks")
+	}
+	return s.Filesystem(), "hooks", nil
+}
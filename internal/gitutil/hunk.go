@@ -0,0 +1,174 @@
+package gitutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/go-git/go-git/v5/utils/diff"
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// DiffContext is the number of unchanged lines around the changes of a hunk, as in git diff.
+const DiffContext = 3
+
+// HunkLine is a line of a hunk, Op is ' ' for context, '-' for removed and '+' for added.
+// Text keeps its line ending, the last line of a file may have none.
+type HunkLine struct {
+	Op   byte
+	Text string
+}
+
+// Hunk is a run of changes with their context lines, as in a unified diff.
+// OldStart and NewStart are the 0-based index of its first line in the old and new content.
+type Hunk struct {
+	OldStart int
+	OldLines int
+	NewStart int
+	NewLines int
+	Lines    []HunkLine
+}
+
+// Header is the @@ line of the hunk, with the 1-based line numbers of git diff.
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return fmt.Sprint(start + 1)
+	}
+	if lines == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	return fmt.Sprintf("%d,%d", start+1, lines)
+}
+
+// Apply returns the old content with the change of the hunk, the hunk must be of DiffHunks of old.
+func (h Hunk) Apply(old string) string {
+	return splice(old, h.OldStart, h.OldLines, h.side('-'))
+}
+
+// Revert returns the new content without the change of the hunk, the hunk must be of DiffHunks into new.
+func (h Hunk) Revert(new string) string {
+	return splice(new, h.NewStart, h.NewLines, h.side('+'))
+}
+
+// side returns the lines of the hunk without the lines of the op, the other side of the diff.
+func (h Hunk) side(skip byte) []string {
+	var lines []string
+	for _, l := range h.Lines {
+		if l.Op != skip {
+			lines = append(lines, l.Text)
+		}
+	}
+	return lines
+}
+
+// splice replaces count lines of the content from start with the lines.
+func splice(content string, start, count int, lines []string) string {
+	all := splitLines(content)
+	var b strings.Builder
+	for _, l := range all[:start] {
+		b.WriteString(l)
+	}
+	for _, l := range lines {
+		b.WriteString(l)
+	}
+	for _, l := range all[start+count:] {
+		b.WriteString(l)
+	}
+	return b.String()
+}
+
+// String formats the hunk as in a unified diff.
+func (h Hunk) String() string {
+	var b strings.Builder
+	b.WriteString(h.Header())
+	b.WriteString("\n")
+	for _, l := range h.Lines {
+		b.WriteByte(l.Op)
+		b.WriteString(strings.TrimSuffix(l.Text, "\n"))
+		b.WriteString("\n")
+		if !strings.HasSuffix(l.Text, "\n") {
+			b.WriteString("\\ No newline at end of file\n")
+		}
+	}
+	return b.String()
+}
+
+// IsBinary is whether the content is binary as git guesses it, by a NUL byte in its first 8000 bytes.
+func IsBinary(content string) bool {
+	return bytes.IndexByte([]byte(content[:min(len(content), 8000)]), 0) != -1
+}
+
+// DiffHunks returns the hunks that turn old into new with DiffContext lines around the changes,
+// nil when they are equal or either is binary.
+func DiffHunks(old, new string) []Hunk {
+	if old == new || IsBinary(old) || IsBinary(new) {
+		return nil
+	}
+	var lines []HunkLine
+	for _, d := range diff.Do(old, new) {
+		op := byte(' ')
+		switch d.Type {
+		case diffmatchpatch.DiffDelete:
+			op = '-'
+		case diffmatchpatch.DiffInsert:
+			op = '+'
+		}
+		for _, text := range splitLines(d.Text) {
+			lines = append(lines, HunkLine{Op: op, Text: text})
+		}
+	}
+
+	// the index of each line in the old and the new content
+	oldAt := make([]int, len(lines)+1)
+	newAt := make([]int, len(lines)+1)
+	for i, l := range lines {
+		oldAt[i+1], newAt[i+1] = oldAt[i], newAt[i]
+		if l.Op != '+' {
+			oldAt[i+1]++
+		}
+		if l.Op != '-' {
+			newAt[i+1]++
+		}
+	}
+
+	var hunks []Hunk
+	for first := 0; first < len(lines); first++ {
+		if lines[first].Op == ' ' {
+			continue
+		}
+		// the changes closer than twice the context share a hunk
+		last := first
+		for next := first + 1; next < len(lines) && next-last <= 2*DiffContext+1; next++ {
+			if lines[next].Op != ' ' {
+				last = next
+			}
+		}
+		from := max(first-DiffContext, 0)
+		to := min(last+DiffContext+1, len(lines))
+		hunks = append(hunks, Hunk{
+			OldStart: oldAt[from],
+			OldLines: oldAt[to] - oldAt[from],
+			NewStart: newAt[from],
+			NewLines: newAt[to] - newAt[from],
+			Lines:    lines[from:to],
+		})
+		first = last
+	}
+	return hunks
+}
+
+// splitLines splits the content after each newline, the last line may have none.
+func splitLines(content string) []string {
+	if content == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
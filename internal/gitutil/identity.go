@@ -0,0 +1,103 @@
+package gitutil
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// ErrNoIdentity is returned when the name or the email of an author or committer is not set.
+var ErrNoIdentity = errors.New("no git identity, set user.name and user.email in the git config")
+
+// Author returns the author of a new commit. GIT_AUTHOR_NAME, GIT_AUTHOR_EMAIL and GIT_AUTHOR_DATE
+// win over author.name and author.email, which win over user.name and user.email.
+func (c *Config) Author() (*object.Signature, error) {
+	return c.identity("author")
+}
+
+// Committer returns the committer of a new commit, see Author.
+func (c *Config) Committer() (*object.Signature, error) {
+	return c.identity("committer")
+}
+
+func (c *Config) identity(role string) (*object.Signature, error) {
+	env := "GIT_" + strings.ToUpper(role)
+	name := firstOf(c.env.Getenv(env+"_NAME"), c.Get(role+".name"), c.Get("user.name"))
+	email := firstOf(c.env.Getenv(env+"_EMAIL"), c.Get(role+".email"), c.Get("user.email"), c.env.Getenv("EMAIL"))
+	if name == "" || email == "" {
+		return nil, withstack.Errorf("%s: %w", role, ErrNoIdentity)
+	}
+	when := c.env.Now()
+	if date := c.env.Getenv(env + "_DATE"); date != "" {
+		var err error
+		if when, err = parseGitDate(date); err != nil {
+			return nil, withstack.Errorf("%s_DATE: %w", env, err)
+		}
+	}
+	return &object.Signature{Name: name, Email: email, When: when}, nil
+}
+
+// parseGitDate parses the date formats of GIT_AUTHOR_DATE:
+// the internal @1700000000 +0100, RFC 3339 and RFC 2822.
+func parseGitDate(date string) (time.Time, error) {
+	if seconds, zone, ok := strings.Cut(strings.TrimPrefix(date, "@"), " "); ok {
+		if unix, err := strconv.ParseInt(seconds, 10, 64); err == nil {
+			if z, err := time.Parse("-0700", zone); err == nil {
+				return time.Unix(unix, 0).In(z.Location()), nil
+			}
+		}
+	}
+	for _, layout := range []string{time.RFC3339, time.RFC1123Z} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, withstack.Errorf("unknown date format %q", date)
+}
+
+// recentCommits bounds the log that RecentAuthors reads.
+const recentCommits = 500
+
+// RecentAuthors returns up to limit authors of the recent commits of the head, the latest first,
+// one per email. Authors without an email and a repo without commits have none.
+func RecentAuthors(repo *git.Repository, limit int) ([]object.Signature, error) {
+	ref, err := repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	commits, err := repo.Log(&git.LogOptions{From: ref.Hash(), Order: git.LogOrderCommitterTime})
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	defer commits.Close()
+
+	var authors []object.Signature
+	seen := map[string]bool{}
+	for range recentCommits {
+		if len(authors) == limit {
+			break
+		}
+		commit, err := commits.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, withstack.Errorf("%w", err)
+		}
+		email := strings.ToLower(commit.Author.Email)
+		if email != "" && !seen[email] {
+			seen[email] = true
+			authors = append(authors, commit.Author)
+		}
+	}
+	return authors, nil
+}
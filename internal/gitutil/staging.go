@@ -0,0 +1,311 @@
+package gitutil
+
+import (
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// FileChange is a changed file of the worktree or of the staging index.
+type FileChange struct {
+	Path   string
+	Status git.StatusCode
+}
+
+// Changes returns the files of the worktree that differ from the index, untracked included,
+// and the files of the index that differ from the head, both sorted by path.
+func Changes(worktree *git.Worktree) (unstaged []FileChange, staged []FileChange, err error) {
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, nil, withstack.Errorf("%w", err)
+	}
+	for _, path := range slices.Sorted(maps.Keys(status)) {
+		s := status[path]
+		if s.Worktree != git.Unmodified {
+			unstaged = append(unstaged, FileChange{Path: path, Status: s.Worktree})
+		}
+		if s.Staging != git.Unmodified && s.Staging != git.Untracked {
+			staged = append(staged, FileChange{Path: path, Status: s.Staging})
+		}
+	}
+	return unstaged, staged, nil
+}
+
+// Staging reads and moves the changes of the files of the repo between its worktree and index.
+type Staging struct {
+	repo     *git.Repository
+	worktree *git.Worktree
+}
+
+// NewStaging returns the staging of the repo.
+func NewStaging(repo *git.Repository) (*Staging, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	return &Staging{repo: repo, worktree: worktree}, nil
+}
+
+// Changes returns the unstaged and the staged files, see Changes.
+func (s *Staging) Changes() (unstaged []FileChange, staged []FileChange, err error) {
+	return Changes(s.worktree)
+}
+
+// UnstagedDiff returns the index and the worktree content of the file, empty when it is not there.
+func (s *Staging) UnstagedDiff(path string) (from string, to string, err error) {
+	if from, _, err = s.indexContent(path); err != nil {
+		return "", "", err
+	}
+	to, err = s.worktreeContent(path)
+	return from, to, err
+}
+
+// StagedDiff returns the head and the index content of the file, empty when it is not there.
+func (s *Staging) StagedDiff(path string) (from string, to string, err error) {
+	if from, err = s.headContent(path); err != nil {
+		return "", "", err
+	}
+	to, _, err = s.indexContent(path)
+	return from, to, err
+}
+
+// StageFile stages the worktree file, or its deletion.
+func (s *Staging) StageFile(path string) error {
+	if _, err := s.worktree.Add(path); err != nil {
+		return withstack.Errorf("Could not add %s to staging index: %w", path, err)
+	}
+	return nil
+}
+
+// UnstageFile resets the index entry of the file to the head, the worktree keeps its changes.
+func (s *Staging) UnstageFile(path string) error {
+	tree, err := s.headTree()
+	if err != nil {
+		return err
+	}
+	var file *object.File
+	if tree != nil {
+		if file, err = tree.File(path); err != nil && !errors.Is(err, object.ErrFileNotFound) {
+			return withstack.Errorf("%w", err)
+		}
+	}
+	if file == nil {
+		return s.updateIndex(func(idx *index.Index) error {
+			_, err := idx.Remove(path)
+			if errors.Is(err, index.ErrEntryNotFound) {
+				return nil
+			}
+			return err
+		})
+	}
+	return s.setIndexEntry(path, file.Hash, file.Mode, file.Size)
+}
+
+// RevertFile discards the unstaged changes of the file, an untracked file is removed.
+func (s *Staging) RevertFile(path string) error {
+	content, mode, err := s.indexContent(path)
+	if err != nil {
+		return err
+	}
+	if mode == filemode.Empty {
+		if err := s.worktree.Filesystem.Remove(path); err != nil && !os.IsNotExist(err) {
+			return withstack.Errorf("%w", err)
+		}
+		return nil
+	}
+	return s.writeWorktree(path, content, mode)
+}
+
+// StageHunk stages the hunk of the unstaged diff of the file.
+func (s *Staging) StageHunk(path string, hunk Hunk) error {
+	content, mode, err := s.indexContent(path)
+	if err != nil {
+		return err
+	}
+	if mode == filemode.Empty {
+		mode = filemode.Regular
+		if fi, err := s.worktree.Filesystem.Lstat(path); err == nil {
+			if m, err := filemode.NewFromOSFileMode(fi.Mode()); err == nil {
+				mode = m
+			}
+		}
+	}
+	return s.setIndexContent(path, hunk.Apply(content), mode)
+}
+
+// UnstageHunk unstages the hunk of the staged diff of the file.
+func (s *Staging) UnstageHunk(path string, hunk Hunk) error {
+	content, mode, err := s.indexContent(path)
+	if err != nil {
+		return err
+	}
+	if mode == filemode.Empty {
+		return withstack.Errorf("%s is not in the staging index", path)
+	}
+	return s.setIndexContent(path, hunk.Revert(content), mode)
+}
+
+// RevertHunk discards the hunk of the unstaged diff of the file from the worktree.
+func (s *Staging) RevertHunk(path string, hunk Hunk) error {
+	content, err := s.worktreeContent(path)
+	if err != nil {
+		return err
+	}
+	fi, err := s.worktree.Filesystem.Lstat(path)
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	mode, err := filemode.NewFromOSFileMode(fi.Mode())
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	return s.writeWorktree(path, hunk.Revert(content), mode)
+}
+
+func (s *Staging) headTree() (*object.Tree, error) {
+	ref, err := s.repo.Head()
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	commit, err := s.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, withstack.Errorf("%w", err)
+	}
+	return tree, nil
+}
+
+func (s *Staging) headContent(path string) (string, error) {
+	tree, err := s.headTree()
+	if err != nil || tree == nil {
+		return "", err
+	}
+	file, err := tree.File(path)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return "", nil
+	} else if err != nil {
+		return "", withstack.Errorf("%w", err)
+	}
+	content, err := file.Contents()
+	if err != nil {
+		return "", withstack.Errorf("%w", err)
+	}
+	return content, nil
+}
+
+// indexContent returns the content and the mode of the index entry, the empty mode when there is none.
+func (s *Staging) indexContent(path string) (string, filemode.FileMode, error) {
+	idx, err := s.repo.Storer.Index()
+	if err != nil {
+		return "", filemode.Empty, withstack.Errorf("%w", err)
+	}
+	entry, err := idx.Entry(path)
+	if errors.Is(err, index.ErrEntryNotFound) {
+		return "", filemode.Empty, nil
+	} else if err != nil {
+		return "", filemode.Empty, withstack.Errorf("%w", err)
+	}
+	blob, err := s.repo.BlobObject(entry.Hash)
+	if err != nil {
+		return "", filemode.Empty, withstack.Errorf("%w", err)
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return "", filemode.Empty, withstack.Errorf("%w", err)
+	}
+	defer r.Close()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return "", filemode.Empty, withstack.Errorf("%w", err)
+	}
+	return string(content), entry.Mode, nil
+}
+
+func (s *Staging) worktreeContent(path string) (string, error) {
+	content, err := util.ReadFile(s.worktree.Filesystem, path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", withstack.Errorf("%w", err)
+	}
+	return string(content), nil
+}
+
+func (s *Staging) writeWorktree(path string, content string, mode filemode.FileMode) error {
+	perm := os.FileMode(0o644)
+	if mode == filemode.Executable {
+		perm = 0o755
+	}
+	if err := util.WriteFile(s.worktree.Filesystem, path, []byte(content), perm); err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	return nil
+}
+
+// setIndexContent stores the content as a blob and points the index entry of the file at it.
+func (s *Staging) setIndexContent(path string, content string, mode filemode.FileMode) error {
+	obj := s.repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	_, err = io.Copy(w, strings.NewReader(content))
+	if closeErr := w.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	hash, err := s.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	return s.setIndexEntry(path, hash, mode, int64(len(content)))
+}
+
+func (s *Staging) setIndexEntry(path string, hash plumbing.Hash, mode filemode.FileMode, size int64) error {
+	return s.updateIndex(func(idx *index.Index) error {
+		entry, err := idx.Entry(path)
+		if errors.Is(err, index.ErrEntryNotFound) {
+			entry = idx.Add(path)
+		} else if err != nil {
+			return err
+		}
+		entry.Hash = hash
+		entry.Mode = mode
+		entry.Size = uint32(size)
+		return nil
+	})
+}
+
+func (s *Staging) updateIndex(update func(idx *index.Index) error) error {
+	idx, err := s.repo.Storer.Index()
+	if err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	if err := update(idx); err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	if err := s.repo.Storer.SetIndex(idx); err != nil {
+		return withstack.Errorf("%w", err)
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package gitutil
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+)
+
+// numbered returns the lines from first to last, one number per line.
+func numbered(first, last int) []string {
+	var lines []string
+	for i := first; i <= last; i++ {
+		lines = append(lines, strings.Repeat("x", i%3+1)+" line\n")
+	}
+	return lines
+}
+
+var _ = Describe("diff hunks", func() {
+	old := strings.Join(numbered(1, 20), "")
+
+	It("splits the changes that are far apart", func() {
+		lines := numbered(1, 20)
+		lines[1] = "changed two\n"
+		lines[17] = "changed eighteen\n"
+		new := strings.Join(lines, "")
+
+		hunks := DiffHunks(old, new)
+		Expect(hunks).To(HaveLen(2))
+		Expect(hunks[0].Header()).To(Equal("@@ -1,5 +1,5 @@"))
+		Expect(hunks[1].Header()).To(Equal("@@ -15,6 +15,6 @@"))
+		Expect(hunks[0].String()).To(ContainSubstring("-xxx line\n+changed two\n"))
+
+		// each hunk applies on its own, reverting both is the old content
+		once := hunks[1].Apply(old)
+		Expect(DiffHunks(once, new)).To(HaveLen(1))
+		Expect(DiffHunks(once, new)[0].Apply(once)).To(Equal(new))
+		Expect(hunks[0].Revert(hunks[1].Revert(new))).To(Equal(old))
+		Expect(DiffHunks(old, hunks[0].Revert(new))).To(HaveLen(1))
+	})
+
+	It("joins the changes that are close", func() {
+		lines := numbered(1, 20)
+		lines[5] = "six\n"
+		lines[11] = "twelve\n"
+		Expect(DiffHunks(old, strings.Join(lines, ""))).To(HaveLen(1))
+	})
+
+	It("adds to an empty file and keeps a missing newline", func() {
+		hunks := DiffHunks("", "one\ntwo")
+		Expect(hunks).To(HaveLen(1))
+		Expect(hunks[0].Header()).To(Equal("@@ -0,0 +1,2 @@"))
+		Expect(hunks[0].String()).To(HaveSuffix("+two\n\\ No newline at end of file\n"))
+		Expect(hunks[0].Apply("")).To(Equal("one\ntwo"))
+		Expect(hunks[0].Revert("one\ntwo")).To(Equal(""))
+	})
+
+	It("has no hunks for binary or equal content", func() {
+		Expect(DiffHunks(old, old)).To(BeEmpty())
+		Expect(DiffHunks("a\x00b", "a\x00c")).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Staging", func() {
+	var rootFS billy.Filesystem
+	var repo *git.Repository
+	var staging *Staging
+	old := strings.Join(numbered(1, 20), "")
+
+	writeFile := func(name, content string) {
+		Expect(util.WriteFile(rootFS, name, []byte(content), 0o644)).To(Succeed())
+	}
+	changes := func() ([]FileChange, []FileChange) {
+		unstaged, staged, err := staging.Changes()
+		Expect(err).To(Succeed())
+		return unstaged, staged
+	}
+
+	BeforeEach(func() {
+		var err error
+		rootFS = memfs.New()
+		repo, err = MakeEmptyRepo(rootFS)
+		Expect(err).To(Succeed())
+		writeFile("/notes.txt", old)
+		staging, err = NewStaging(repo)
+		Expect(err).To(Succeed())
+		Expect(staging.StageFile("notes.txt")).To(Succeed())
+		_, err = staging.worktree.Commit("Add the notes.", NewEmptyCommitOptions("Annie Mouse"))
+		Expect(err).To(Succeed())
+	})
+
+	It("moves the files between the worktree and the index", func() {
+		writeFile("/new.txt", "new\n")
+		Expect(rootFS.Remove("/notes.txt")).To(Succeed())
+		unstaged, staged := changes()
+		Expect(unstaged).To(Equal([]FileChange{{"new.txt", git.Untracked}, {"notes.txt", git.Deleted}}))
+		Expect(staged).To(BeEmpty())
+
+		Expect(staging.StageFile("new.txt")).To(Succeed())
+		Expect(staging.StageFile("notes.txt")).To(Succeed())
+		unstaged, staged = changes()
+		Expect(unstaged).To(BeEmpty())
+		Expect(staged).To(Equal([]FileChange{{"new.txt", git.Added}, {"notes.txt", git.Deleted}}))
+
+		Expect(staging.UnstageFile("new.txt")).To(Succeed())
+		Expect(staging.UnstageFile("notes.txt")).To(Succeed())
+		unstaged, staged = changes()
+		Expect(unstaged).To(Equal([]FileChange{{"new.txt", git.Untracked}, {"notes.txt", git.Deleted}}))
+		Expect(staged).To(BeEmpty())
+
+		Expect(staging.RevertFile("new.txt")).To(Succeed())
+		Expect(staging.RevertFile("notes.txt")).To(Succeed())
+		unstaged, _ = changes()
+		Expect(unstaged).To(BeEmpty())
+		content, err := util.ReadFile(rootFS, "/notes.txt")
+		Expect(err).To(Succeed())
+		Expect(string(content)).To(Equal(old))
+	})
+
+	It("moves the hunks between the worktree and the index", func() {
+		lines := numbered(1, 20)
+		lines[1] = "changed two\n"
+		lines[17] = "changed eighteen\n"
+		changed := strings.Join(lines, "")
+		writeFile("/notes.txt", changed)
+
+		from, to, err := staging.UnstagedDiff("notes.txt")
+		Expect(err).To(Succeed())
+		hunks := DiffHunks(from, to)
+		Expect(hunks).To(HaveLen(2))
+		Expect(staging.StageHunk("notes.txt", hunks[1])).To(Succeed())
+
+		unstaged, staged := changes()
+		Expect(unstaged).To(Equal([]FileChange{{"notes.txt", git.Modified}}))
+		Expect(staged).To(Equal([]FileChange{{"notes.txt", git.Modified}}))
+		from, to, err = staging.StagedDiff("notes.txt")
+		Expect(err).To(Succeed())
+		Expect(DiffHunks(from, to)).To(HaveLen(1))
+		Expect(DiffHunks(from, to)[0].String()).To(ContainSubstring("+changed eighteen"))
+
+		from, to, err = staging.UnstagedDiff("notes.txt")
+		Expect(err).To(Succeed())
+		hunks = DiffHunks(from, to)
+		Expect(hunks).To(HaveLen(1))
+		Expect(staging.RevertHunk("notes.txt", hunks[0])).To(Succeed())
+		unstaged, _ = changes()
+		Expect(unstaged).To(BeEmpty())
+
+		from, to, err = staging.StagedDiff("notes.txt")
+		Expect(err).To(Succeed())
+		Expect(staging.UnstageHunk("notes.txt", DiffHunks(from, to)[0])).To(Succeed())
+		unstaged, staged = changes()
+		Expect(unstaged).To(Equal([]FileChange{{"notes.txt", git.Modified}}))
+		Expect(staged).To(BeEmpty())
+	})
+})
@@ -1,11 +1,13 @@
 package prompts
 
 import (
-	"fmt"
-	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/internal/commitlint"
 )
 
 // To step through the debugger in a TUI,
@@ -38,42 +40,59 @@ import (
 // log.Println(spew.Sdump(okButton))
 // and then tail the log file in another terminal
 
-// these operations are typical of conventional commits.
-var operations []string = []string{
-	"chore", "build", "ci", "docs", "feat", "fix", "perf", "refactor", "revert", "style", "test"}
-
+// CommitToLocal asks for the conventional commit message of the staged files, filled in from the draft.
+// The scope is suggested from the top level directories of the files and the co-authors are picked
+// from the authors, Name <email>. It returns no files and no message when the user cancels.
 func CommitToLocal(
 	statuses git.Status,
-	issue string,
-	description string) (
-	[]string, string, string, string, error) {
+	draft CommitMessage,
+	authors []string) (
+	[]string, *CommitMessage, error) {
 	app := tview.NewApplication()
 	var statusField *tview.TextArea
 	var okButton *tview.Button
+	var preview *tview.TextView
 
 	// FIXME implement custom primitives to do validation warning highlights.
 	// FIXME implement custom buttons to customize the disabled state - can not persistently set style
 	// The default Form over rides items styles.
 	// https://github.com/rivo/tview/issues/931
 	// we would need a custom form with its own drawing to implement more customized item styles
-	form := tview.NewForm().
-		AddDropDown(operationLabel, operations, 0, nil)
+	form := tview.NewForm()
+	onChanged := func() {
+		onValidate(form, authors, statusField, okButton, preview)
+	}
+	onTextChanged := func(string) { onChanged() }
+
+	form.AddDropDown(operationLabel, commitlint.Types, max(slices.Index(commitlint.Types, draft.Type), 0),
+		func(string, int) { onChanged() })
 
 	filePaths, lines := toFileLines(statuses, ToStagingStatus)
+	scopes := SuggestScopes(filePaths)
+	if draft.Scope == "" && len(scopes) == 1 {
+		draft.Scope = scopes[0]
+	}
+	form.AddInputField(scopeLabel, draft.Scope, 20, nil, onTextChanged)
+	form.GetFormItemByLabel(scopeLabel).(*tview.InputField).
+		SetAutocompleteFunc(func(txt string) []string {
+			var matches []string
+			for _, scope := range scopes {
+				if strings.HasPrefix(scope, txt) {
+					matches = append(matches, scope)
+				}
+			}
+			return matches
+		})
+
 	addLinesText(form, "Files", lines)
-	form.AddInputField(issueLabel, issue, 20,
-		func(textToCheck string, lastChar rune) bool {
-			onValidate(form, statusField, okButton)
-			return true
-		},
-		nil)
-
-	form.AddInputField(descriptionLabel, description, 60,
-		func(txt string, lastChar rune) bool {
-			onValidate(form, statusField, okButton)
-			return true
-		},
-		nil)
+	form.AddInputField(issueLabel, draft.Issue, 20, nil, onTextChanged)
+	form.AddInputField(descriptionLabel, draft.Description, 60, nil, onTextChanged)
+	form.AddTextArea(bodyLabel, draft.Body, 60, 5, 0, onTextChanged)
+	form.AddInputField(breakingLabel, draft.Breaking, 60, nil, onTextChanged)
+	form.AddInputField(refsLabel, draft.Refs, 40, nil, onTextChanged)
+	for _, author := range authors {
+		form.AddCheckbox(author, slices.Contains(draft.CoAuthors, author), func(bool) { onChanged() })
+	}
 
 	isOk := false
 	form.
@@ -87,29 +106,47 @@ func CommitToLocal(
 		})
 	okButton = form.GetButton(form.GetButtonIndex(okLabel))
 
-	form.AddTextArea(statusLabel, okStatus, 60, 20, 500, nil)
+	form.AddTextArea(statusLabel, okStatus, 60, 3, 500, nil)
 	statusField = form.GetFormItemByLabel(statusLabel).(*tview.TextArea)
 
-	form.SetBorder(true).SetTitle("Enter some data").SetTitleAlign(tview.AlignLeft)
-	if err := app.SetRoot(form, true).EnableMouse(true).Run(); err != nil { // block
-		return nil, "", "", "", err
+	form.SetBorder(true).SetTitle("Commit").SetTitleAlign(tview.AlignLeft)
+	preview = tview.NewTextView()
+	preview.SetBorder(true).SetTitle("Message").SetTitleAlign(tview.AlignLeft)
+	onChanged()
+
+	layout := tview.NewFlex().
+		AddItem(form, 0, 1, true).
+		AddItem(preview, 0, 1, false)
+	if err := app.SetRoot(layout, true).EnableMouse(true).Run(); err != nil { // block
+		return nil, nil, err
 	}
 	if !isOk {
-		return nil, "", "", "", nil
+		return nil, nil, nil
 	}
 
-	opTxt, descriptionTxt, issueTxt, err := validateFields(form)
-	return filePaths, opTxt, descriptionTxt, issueTxt, err
+	msg := readMessage(form, authors)
+	if err := msg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return filePaths, &msg, nil
 }
 
-// onValidate will write validation state to form .
-func onValidate(form *tview.Form, statusField *tview.TextArea, okButton *tview.Button) {
+// onValidate will write validation state and the message to form .
+func onValidate(
+	form *tview.Form,
+	authors []string,
+	statusField *tview.TextArea,
+	okButton *tview.Button,
+	preview *tview.TextView) {
 	if form == nil ||
 		statusField == nil ||
-		okButton == nil {
+		okButton == nil ||
+		preview == nil {
 		return
 	}
-	if _, _, _, err := validateFields(form); err != nil {
+	msg := readMessage(form, authors)
+	preview.SetText(msg.String())
+	if err := msg.Validate(); err != nil {
 		okButton.SetDisabled(true)
 		statusField.SetText(err.Error(), true)
 	} else {
@@ -119,8 +156,12 @@ func onValidate(form *tview.Form, statusField *tview.TextArea, okButton *tview.B
 }
 
 const operationLabel = "Operation"
+const scopeLabel = "Scope"
 const issueLabel = "Issue"
 const descriptionLabel = "Description"
+const bodyLabel = "Body"
+const breakingLabel = "Breaking change"
+const refsLabel = "Refs"
 const statusLabel = "Status"
 const okLabel = "Ok"
 const cancelLabel = "Cancel"
@@ -129,68 +170,25 @@ const badIssueStatus = "Issue does not match jira naming rules."
 const badDescriptionStatus = "Description does not match conventional commit rules."
 const okStatus = "-----------"
 
-func validateFields(form *tview.Form) (string, string, string, error) {
+// readMessage reads the message of the form, the checked authors are the co-authors.
+func readMessage(form *tview.Form, authors []string) CommitMessage {
 	_, opTxt := form.GetFormItemByLabel(operationLabel).(*tview.DropDown).GetCurrentOption()
-	if len(opTxt) < 1 {
-		return "", "", "", fmt.Errorf(badOperationStatus)
+	inputText := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
 	}
-
-	issueTxt := form.GetFormItemByLabel(issueLabel).(*tview.InputField).GetText()
-	if !jiraIssueRE.Match([]byte(issueTxt)) {
-		return "", "", "", fmt.Errorf(badIssueStatus)
+	msg := CommitMessage{
+		Type:        opTxt,
+		Scope:       inputText(scopeLabel),
+		Description: inputText(descriptionLabel),
+		Issue:       inputText(issueLabel),
+		Body:        form.GetFormItemByLabel(bodyLabel).(*tview.TextArea).GetText(),
+		Breaking:    inputText(breakingLabel),
+		Refs:        inputText(refsLabel),
 	}
-	descriptionTxt := form.GetFormItemByLabel(descriptionLabel).(*tview.InputField).GetText()
-	if !isConventionalCommitDescription(descriptionTxt) {
-		return "", "", "", fmt.Errorf(badDescriptionStatus)
+	for _, author := range authors {
+		if form.GetFormItemByLabel(author).(*tview.Checkbox).IsChecked() {
+			msg.CoAuthors = append(msg.CoAuthors, author)
+		}
 	}
-	return opTxt, descriptionTxt, issueTxt, nil
-}
-
-// var partialIssueRE = regexp.MustCompile(`^([A-Z]{1,5})?(-)?([0-9]{1,5})?$`)
-var jiraIssueRE = regexp.MustCompile(`^([A-Z]{1,5})(-)([0-9]{1,5})$`)
-
-/*
-// these are the default case rules for conventional commitlint
-'sentence-case', // Sentence case
-  'start-case'.    // Start Case
-  'pascal-case',   // PascalCase
-  'upper-case',    // UPPERCASE
-*/
-
-// Common punctuation: Periods, commas, semicolons, colons, hyphens, question marks, exclamation marks, parentheses, brackets
-// Some special characters: Underscores, ampersands, at signs, plus signs, equal signs, asterisks, percent signs, slashes (forward and backward)
-// var specialCh = `\.\,\;\:\-\?\!\(\)\_\&\@\+\=\*\%\\\/`
-var specialCh = `\:`
-var digitSpecialCh = `0-9` + specialCh
-var lowerCh = `a-z` + digitSpecialCh
-var upperCh = `A-Z` + digitSpecialCh
-
-// 'sentence-case', // Sentence case
-var sentenceCaseTxt = `^([A-Z]?[` + lowerCh + `]*)(\s+[` + lowerCh + `]+)*$`
-var sentenceCaseRE = regexp.MustCompile(sentenceCaseTxt)
-
-var startWord = `[A-Z][` + lowerCh + `]*`
-var digitSpecialWord = `[` + digitSpecialCh + `]+`
-
-var startOrDigitSpecialWord = startWord + `|` + digitSpecialWord
-
-// 'start-case'.    // Start Case
-var startTxt = `^(` + startOrDigitSpecialWord + `)(\s+(` + startOrDigitSpecialWord + `))*$`
-var startRe = regexp.MustCompile(startTxt)
-
-// 'pascal-case',   // PascalCase
-// are space allowed?
-var pascalWord = `[A-Z][` + lowerCh + `]+`
-var pascalTxt = `^(` + pascalWord + `)+$`
-var pascalRe = regexp.MustCompile(pascalTxt)
-
-var upperCaseTxt = `^([` + upperCh + `]+)(\s+[` + upperCh + `]+)*$`
-var upperRe = regexp.MustCompile(upperCaseTxt)
-
-func isConventionalCommitDescription(txt string) bool {
-	b := []byte(txt)
-	return sentenceCaseRE.Match(b) ||
-		startRe.Match(b) ||
-		pascalRe.Match(b) ||
-		upperRe.Match(b)
+	return msg
 }
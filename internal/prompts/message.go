@@ -0,0 +1,130 @@
+package prompts
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/stalwartgiraffe/cmr/internal/commitlint"
+)
+
+// CommitMessage is a conventional commit message.
+//
+//	type(scope)!: description [ISSUE]
+//
+//	body
+//
+//	BREAKING CHANGE: breaking
+//	Refs: refs
+//	Co-authored-by: Name <email>
+type CommitMessage struct {
+	Type        string
+	Scope       string
+	Description string
+	Issue       string
+	Body        string
+	Breaking    string // what breaks, empty when nothing does
+	Refs        string
+	CoAuthors   []string // Name <email>
+}
+
+// Header is the first line of the message.
+func (m CommitMessage) Header() string {
+	var b strings.Builder
+	b.WriteString(m.Type)
+	if m.Scope != "" {
+		b.WriteString("(" + m.Scope + ")")
+	}
+	if m.Breaking != "" {
+		b.WriteString("!")
+	}
+	fmt.Fprintf(&b, ": %s [%s]", m.Description, m.Issue)
+	return b.String()
+}
+
+// Footers are the trailer lines of the message.
+func (m CommitMessage) Footers() []string {
+	var footers []string
+	if m.Breaking != "" {
+		footers = append(footers, "BREAKING CHANGE: "+m.Breaking)
+	}
+	if m.Refs != "" {
+		footers = append(footers, "Refs: "+m.Refs)
+	}
+	for _, a := range m.CoAuthors {
+		footers = append(footers, "Co-authored-by: "+a)
+	}
+	return footers
+}
+
+// String formats the message with a blank line between the header, the body and the footers.
+func (m CommitMessage) String() string {
+	parts := []string{m.Header()}
+	if body := strings.TrimSpace(m.Body); body != "" {
+		parts = append(parts, body)
+	}
+	if footers := m.Footers(); 0 < len(footers) {
+		parts = append(parts, strings.Join(footers, "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+const badScopeStatus = "Scope must be lower case."
+const badBreakingStatus = "Breaking change must be one line."
+const badRefsStatus = "Refs must be one line."
+const badCoAuthorStatus = "Co-author must be Name <email>."
+
+// lower case as the scope-case rule of commitlint
+var scopeRE = regexp.MustCompile(`^[a-z0-9][a-z0-9._/-]*$`)
+
+var coAuthorRE = regexp.MustCompile(`^[^<>\n]+ <[^<>\s]+@[^<>\s]+>$`)
+
+// Validate checks the message against the conventional commit rules, those of the fields
+// of the form first and then the default rules of commitlint.
+func (m CommitMessage) Validate() error {
+	if !slices.Contains(commitlint.Types, m.Type) {
+		return fmt.Errorf(badOperationStatus)
+	}
+	if m.Scope != "" && !scopeRE.MatchString(m.Scope) {
+		return fmt.Errorf(badScopeStatus)
+	}
+	if !commitlint.IsIssue(m.Issue) {
+		return fmt.Errorf(badIssueStatus)
+	}
+	if !commitlint.IsSubject(m.Description) {
+		return fmt.Errorf(badDescriptionStatus)
+	}
+	if strings.Contains(m.Breaking, "\n") {
+		return fmt.Errorf(badBreakingStatus)
+	}
+	if strings.Contains(m.Refs, "\n") {
+		return fmt.Errorf(badRefsStatus)
+	}
+	for _, a := range m.CoAuthors {
+		if !coAuthorRE.MatchString(a) {
+			return fmt.Errorf(badCoAuthorStatus)
+		}
+	}
+	for _, p := range commitlint.DefaultConfig().Lint(m.String()) {
+		if p.Level == commitlint.Error {
+			return fmt.Errorf("%s", p.Message)
+		}
+	}
+	return nil
+}
+
+// SuggestScopes returns the top level directories of the paths, sorted.
+// The files at the top level suggest none.
+func SuggestScopes(filePaths []string) []string {
+	var scopes []string
+	for _, fp := range filePaths {
+		dir, _, ok := strings.Cut(path.Clean(fp), "/")
+		if ok && !slices.Contains(scopes, dir) {
+			scopes = append(scopes, dir)
+		}
+	}
+	slices.Sort(scopes)
+	return scopes
+}
@@ -0,0 +1,57 @@
+package prompts
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("commit message", func() {
+	It("formats the header alone", func() {
+		msg := CommitMessage{Type: "fix", Description: "Handle empty pages", Issue: "DEALS-1234"}
+		Expect(msg.String()).To(Equal("fix: Handle empty pages [DEALS-1234]"))
+		Expect(msg.Validate()).To(Succeed())
+	})
+
+	It("formats the scope, body and footers", func() {
+		msg := CommitMessage{
+			Type:        "feat",
+			Scope:       "cmd",
+			Description: "Add the mrs command",
+			Issue:       "DEALS-1234",
+			Body:        "\nList the merge requests\nby review state.\n\n",
+			Breaking:    "The mr command is gone.",
+			Refs:        "DEALS-1200",
+			CoAuthors:   []string{"Annie Mouse <annie@home.org>"},
+		}
+		Expect(msg.String()).To(Equal(`feat(cmd)!: Add the mrs command [DEALS-1234]
+
+List the merge requests
+by review state.
+
+BREAKING CHANGE: The mr command is gone.
+Refs: DEALS-1200
+Co-authored-by: Annie Mouse <annie@home.org>`))
+		Expect(msg.Validate()).To(Succeed())
+	})
+
+	DescribeTable("rejects what breaks the rules",
+		func(change func(*CommitMessage), status string) {
+			msg := CommitMessage{Type: "fix", Description: "Handle empty pages", Issue: "DEALS-1234"}
+			change(&msg)
+			Expect(msg.Validate()).To(MatchError(status))
+		},
+		Entry("type", func(m *CommitMessage) { m.Type = "fixed" }, badOperationStatus),
+		Entry("scope", func(m *CommitMessage) { m.Scope = "Cmd" }, badScopeStatus),
+		Entry("issue", func(m *CommitMessage) { m.Issue = "" }, badIssueStatus),
+		Entry("description", func(m *CommitMessage) { m.Description = "handle Empty pages" }, badDescriptionStatus),
+		Entry("breaking", func(m *CommitMessage) { m.Breaking = "one\ntwo" }, badBreakingStatus),
+		Entry("refs", func(m *CommitMessage) { m.Refs = "A-1\nB-2" }, badRefsStatus),
+		Entry("co-author", func(m *CommitMessage) { m.CoAuthors = []string{"annie@home.org"} }, badCoAuthorStatus),
+	)
+
+	It("suggests the top level directories as scopes", func() {
+		Expect(SuggestScopes([]string{"go.mod", "cmd/gac.go", "internal/prompts/commit.go", "cmd/root.go"})).
+			To(Equal([]string{"cmd", "internal"}))
+		Expect(SuggestScopes([]string{"README.md"})).To(BeEmpty())
+	})
+})
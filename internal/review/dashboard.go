@@ -0,0 +1,164 @@
+package review
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+)
+
+// Section is the part of the dashboard a merge request is in.
+type Section string
+
+const (
+	Authored  Section = "authored"
+	Reviewing Section = "reviewing" // assigned to me or I am a reviewer
+	Mergeable Section = "approved"  // approved but not merged yet
+	Other     Section = ""
+)
+
+// Input is a merge request with what is known of its approvals and latest pipeline.
+type Input struct {
+	MergeRequest gitlab.MergeRequestModel
+	Approvals    *gitlab.ApprovalsModel
+	Pipeline     *gitlab.PipelineModel
+}
+
+// Item is a merge request on the dashboard.
+type Item struct {
+	Rank          int       `json:"rank"`
+	Section       Section   `json:"section"`
+	State         State     `json:"state"`
+	MyTurn        bool      `json:"my_turn"`
+	Waited        string    `json:"waited"`
+	WaitingSince  time.Time `json:"waiting_since"`
+	ID            int       `json:"id"`
+	Iid           int       `json:"iid"`
+	ProjectID     int       `json:"project_id"`
+	Ref           string    `json:"ref"`
+	Title         string    `json:"title"`
+	Author        string    `json:"author"`
+	Pipeline      string    `json:"pipeline,omitempty"`
+	ApprovalsLeft int       `json:"approvals_left"`
+	WebURL        string    `json:"web_url"`
+}
+
+// Build returns the open merge requests of the user me on the dashboard, most urgent first.
+func Build(me string, inputs []Input, now time.Time) []Item {
+	var items []Item
+	for _, in := range inputs {
+		item := NewItem(in, me, now)
+		if item.State == Closed || item.Section == Other {
+			continue
+		}
+		items = append(items, item)
+	}
+	Sort(items)
+	return items
+}
+
+// NewItem puts the merge request on the dashboard of the user me.
+// A merge request waits since it was last updated.
+// When me is not known every merge request is taken as mine, as in the cache of my merge requests.
+func NewItem(in Input, me string, now time.Time) Item {
+	mr := &in.MergeRequest
+	state := StateOf(mr, in.Approvals, in.Pipeline)
+	isAuthor := me == "" || mr.Author != nil && mr.Author.Username == me
+
+	item := Item{
+		State:        state,
+		WaitingSince: mr.UpdatedAt.Time,
+		Waited:       FormatWait(now.Sub(mr.UpdatedAt.Time)),
+		ID:           mr.ID,
+		Iid:          mr.Iid,
+		ProjectID:    mr.ProjectID,
+		Title:        mr.Title,
+		WebURL:       mr.WebURL,
+	}
+	if mr.References != nil {
+		item.Ref = mr.References.Full
+	}
+	if mr.Author != nil {
+		item.Author = mr.Author.Username
+	}
+	if in.Pipeline != nil {
+		item.Pipeline = in.Pipeline.Status
+	}
+	if in.Approvals != nil {
+		item.ApprovalsLeft = in.Approvals.ApprovalsLeft
+	}
+
+	switch {
+	case !isAuthor && !isReviewing(mr, me):
+		item.Section = Other
+	case state == Approved:
+		item.Section = Mergeable
+		item.MyTurn = isAuthor
+	case isAuthor:
+		item.Section = Authored
+		item.MyTurn = authorsTurn(state)
+	default:
+		item.Section = Reviewing
+		item.MyTurn = state == NeedsApproval && !approvedBy(in.Approvals, me)
+	}
+	return item
+}
+
+func isReviewing(mr *gitlab.MergeRequestModel, me string) bool {
+	isMe := func(u gitlab.UserModel) bool { return u.Username == me }
+	return slices.ContainsFunc(mr.Reviewers, isMe) || slices.ContainsFunc(mr.Assignees, isMe) ||
+		mr.Assignee != nil && isMe(*mr.Assignee)
+}
+
+// authorsTurn is true for the states only the author can move on from.
+func authorsTurn(state State) bool {
+	switch state {
+	case Conflicts, NeedsRebase, PipelineFailed, Discussions, ChangesRequested:
+		return true
+	}
+	return false
+}
+
+func approvedBy(approvals *gitlab.ApprovalsModel, me string) bool {
+	return approvals != nil && slices.ContainsFunc(approvals.ApprovedBy, func(a gitlab.Approver) bool {
+		return a.User.Username == me
+	})
+}
+
+// Sort orders the items by urgency and numbers their ranks from 1:
+// the items that wait on me first, then the ones that waited longest.
+func Sort(items []Item) {
+	slices.SortStableFunc(items, func(a, b Item) int {
+		if a.MyTurn != b.MyTurn {
+			if a.MyTurn {
+				return -1
+			}
+			return 1
+		}
+		if c := a.WaitingSince.Compare(b.WaitingSince); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	for i := range items {
+		items[i].Rank = i + 1
+	}
+}
+
+// FormatWait formats how long a merge request waited, such as 45m, 3h or 2d4h.
+func FormatWait(d time.Duration) string {
+	const day = 24 * time.Hour
+	switch {
+	case d < time.Minute:
+		return "now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	case d < day:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%day < time.Hour:
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return fmt.Sprintf("%dd%dh", d/day, d%day/time.Hour)
+}
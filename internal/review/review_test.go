@@ -0,0 +1,141 @@
+package review
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/aarondl/opt/omitnull"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+)
+
+func TestStateOf(t *testing.T) {
+	open := func(status string) *gitlab.MergeRequestModel {
+		return &gitlab.MergeRequestModel{State: "opened", DetailedMergeStatus: status, BlockingDiscussionsResolved: omitnull.From(true)}
+	}
+	approved := &gitlab.ApprovalsModel{Approved: true}
+	waiting := &gitlab.ApprovalsModel{ApprovalsRequired: 1, ApprovalsLeft: 1}
+	passed := &gitlab.PipelineModel{Status: "success"}
+
+	tests := []struct {
+		name      string
+		mr        *gitlab.MergeRequestModel
+		approvals *gitlab.ApprovalsModel
+		pipeline  *gitlab.PipelineModel
+		expected  State
+	}{
+		{name: "merged", mr: &gitlab.MergeRequestModel{State: "merged"}, expected: Closed},
+		{name: "draft", mr: &gitlab.MergeRequestModel{State: "opened", Draft: true, HasConflicts: true}, expected: Draft},
+		{name: "conflicts", mr: &gitlab.MergeRequestModel{State: "opened", HasConflicts: true}, expected: Conflicts},
+		{name: "rebase", mr: open("need_rebase"), expected: NeedsRebase},
+		{name: "failed pipeline", mr: open("mergeable"), approvals: approved, pipeline: &gitlab.PipelineModel{Status: "failed"}, expected: PipelineFailed},
+		{name: "no pipeline", mr: open("ci_must_pass"), expected: PipelineRunning},
+		{name: "ci must pass of a failed pipeline", mr: open("ci_must_pass"), pipeline: &gitlab.PipelineModel{Status: "failed"}, expected: PipelineFailed},
+		{name: "running pipeline", mr: open("ci_still_running"), pipeline: &gitlab.PipelineModel{Status: "running"}, expected: PipelineRunning},
+		{name: "discussions", mr: &gitlab.MergeRequestModel{State: "opened", BlockingDiscussionsResolved: omitnull.From(false)}, pipeline: passed, expected: Discussions},
+		{name: "discussions status", mr: open("discussions_not_resolved"), expected: Discussions},
+		{name: "discussions unknown", mr: &gitlab.MergeRequestModel{State: "opened", DetailedMergeStatus: "mergeable"}, expected: Approved},
+		{name: "changes requested", mr: open("requested_changes"), expected: ChangesRequested},
+		{name: "approvals left", mr: open("mergeable"), approvals: waiting, pipeline: passed, expected: NeedsApproval},
+		{name: "not approved", mr: open("not_approved"), expected: NeedsApproval},
+		{name: "approved", mr: open("blocked_status"), approvals: approved, pipeline: passed, expected: Approved},
+		{name: "mergeable", mr: open("mergeable"), expected: Approved},
+		{name: "blocked", mr: open("blocked_status"), expected: Blocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, StateOf(tt.mr, tt.approvals, tt.pipeline))
+		})
+	}
+}
+
+func TestStateOfCached(t *testing.T) {
+	// the cache and some gitlab versions leave out blocking_discussions_resolved
+	var mr gitlab.MergeRequestModel
+	require.NoError(t, json.Unmarshal([]byte(`{"state":"opened","detailed_merge_status":"mergeable"}`), &mr))
+	require.True(t, mr.BlockingDiscussionsResolved.IsUnset())
+	require.Equal(t, Approved, StateOf(&mr, nil, nil))
+
+	require.NoError(t, json.Unmarshal([]byte(`{"state":"opened","blocking_discussions_resolved":false}`), &mr))
+	require.Equal(t, Discussions, StateOf(&mr, nil, nil))
+
+	data, err := json.Marshal(mr)
+	require.NoError(t, err)
+	var cached gitlab.MergeRequestModel
+	require.NoError(t, json.Unmarshal(data, &cached))
+	require.Equal(t, omitnull.From(false), cached.BlockingDiscussionsResolved)
+}
+
+func TestBuild(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	annie := &gitlab.UserModel{Username: "annie"}
+	bob := &gitlab.UserModel{Username: "bob"}
+	mr := func(id int, author *gitlab.UserModel, status string, age time.Duration) gitlab.MergeRequestModel {
+		return gitlab.MergeRequestModel{
+			ID:                          id,
+			State:                       "opened",
+			Author:                      author,
+			Reviewers:                   []gitlab.UserModel{{Username: "carol"}},
+			DetailedMergeStatus:         status,
+			BlockingDiscussionsResolved: omitnull.From(true),
+			UpdatedAt:                   gitlab.Time{Time: now.Add(-age)},
+		}
+	}
+	waiting := &gitlab.ApprovalsModel{ApprovalsLeft: 1}
+	approvedByCarol := &gitlab.ApprovalsModel{ApprovalsLeft: 1, ApprovedBy: []gitlab.Approver{{User: gitlab.UserModel{Username: "carol"}}}}
+
+	inputs := []Input{
+		{MergeRequest: mr(1, annie, "not_approved", time.Hour), Approvals: waiting},
+		{MergeRequest: mr(2, annie, "conflict", 2*time.Hour)},
+		{MergeRequest: mr(3, bob, "not_approved", 30*time.Minute), Approvals: waiting},
+		{MergeRequest: mr(4, bob, "mergeable", 50*time.Hour)},
+		{MergeRequest: mr(5, annie, "mergeable", 3*time.Hour)},
+		{MergeRequest: mr(6, bob, "not_approved", 5*time.Hour), Approvals: approvedByCarol},
+		{MergeRequest: gitlab.MergeRequestModel{ID: 7, State: "merged", Author: annie}},
+	}
+	inputs[3].MergeRequest.Reviewers = nil
+	inputs[4].MergeRequest.Assignees = []gitlab.UserModel{{Username: "carol"}}
+
+	type row struct {
+		ID      int
+		Section Section
+		MyTurn  bool
+		Waited  string
+	}
+	rows := func(items []Item) []row {
+		var r []row
+		for i, item := range items {
+			require.Equal(t, i+1, item.Rank)
+			r = append(r, row{item.ID, item.Section, item.MyTurn, item.Waited})
+		}
+		return r
+	}
+
+	require.Equal(t, []row{
+		{5, Mergeable, true, "3h"},
+		{2, Authored, true, "2h"},
+		{1, Authored, false, "1h"},
+	}, rows(Build("annie", inputs, now)))
+
+	require.Equal(t, []row{
+		{1, Reviewing, true, "1h"},
+		{3, Reviewing, true, "30m"},
+		{6, Reviewing, false, "5h"},
+		{5, Mergeable, false, "3h"},
+		{2, Reviewing, false, "2h"},
+	}, rows(Build("carol", inputs, now)))
+
+	require.Len(t, Build("", inputs, now), 6)
+	require.Empty(t, Build("dave", inputs, now))
+}
+
+func TestFormatWait(t *testing.T) {
+	require.Equal(t, "now", FormatWait(30*time.Second))
+	require.Equal(t, "45m", FormatWait(45*time.Minute))
+	require.Equal(t, "23h", FormatWait(23*time.Hour+59*time.Minute))
+	require.Equal(t, "2d", FormatWait(48*time.Hour+30*time.Minute))
+	require.Equal(t, "2d4h", FormatWait(52*time.Hour))
+}
@@ -0,0 +1,86 @@
+// Package review derives where a merge request stands in its review
+// and lays out the dashboard of the merge requests that wait on me.
+package review
+
+import (
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+)
+
+// State is where a merge request stands in its review, see StateOf.
+type State string
+
+const (
+	Draft            State = "draft"
+	Conflicts        State = "conflicts"
+	NeedsRebase      State = "needs rebase"
+	PipelineFailed   State = "pipeline failed"
+	PipelineRunning  State = "pipeline running"
+	Discussions      State = "unresolved discussions"
+	ChangesRequested State = "changes requested"
+	NeedsApproval    State = "needs approval"
+	Approved         State = "approved"
+	Blocked          State = "blocked"
+	Closed           State = "closed"
+)
+
+// the detailed_merge_status values that StateOf tells apart, see
+// https://docs.gitlab.com/api/merge_requests/#merge-status
+const (
+	statusMergeable        = "mergeable"
+	statusDraft            = "draft_status"
+	statusConflict         = "conflict"
+	statusNeedRebase       = "need_rebase"
+	statusCIMustPass       = "ci_must_pass"
+	statusCIStillRunning   = "ci_still_running"
+	statusDiscussions      = "discussions_not_resolved"
+	statusRequestedChanges = "requested_changes"
+	statusNotApproved      = "not_approved"
+)
+
+// StateOf derives the state of the merge request from its merge status,
+// its conflicts and discussions, its approvals and its latest pipeline.
+// The approvals and the pipeline are nil when they are not known,
+// as are the discussions when blocking_discussions_resolved is missing, such as in the cache.
+// The first state that holds wins, in the order the author has to work through them.
+func StateOf(mr *gitlab.MergeRequestModel, approvals *gitlab.ApprovalsModel, pipeline *gitlab.PipelineModel) State {
+	status := mr.DetailedMergeStatus
+	switch {
+	case mr.State != "opened":
+		return Closed
+	case mr.Draft || status == statusDraft:
+		return Draft
+	case mr.HasConflicts || status == statusConflict:
+		return Conflicts
+	case status == statusNeedRebase:
+		return NeedsRebase
+	case pipelineFailed(pipeline):
+		return PipelineFailed
+	// without a known pipeline ci_must_pass waits for one to pass, it has not failed
+	case pipelineRunning(pipeline) || status == statusCIStillRunning || pipeline == nil && status == statusCIMustPass:
+		return PipelineRunning
+	case !mr.BlockingDiscussionsResolved.GetOr(true) || status == statusDiscussions:
+		return Discussions
+	case status == statusRequestedChanges:
+		return ChangesRequested
+	case approvals != nil && 0 < approvals.ApprovalsLeft || status == statusNotApproved:
+		return NeedsApproval
+	case approvals != nil && approvals.Approved || status == statusMergeable:
+		return Approved
+	}
+	return Blocked
+}
+
+func pipelineFailed(pipeline *gitlab.PipelineModel) bool {
+	return pipeline != nil && (pipeline.Status == "failed" || pipeline.Status == "canceled")
+}
+
+func pipelineRunning(pipeline *gitlab.PipelineModel) bool {
+	if pipeline == nil {
+		return false
+	}
+	switch pipeline.Status {
+	case "created", "waiting_for_resource", "preparing", "pending", "running", "scheduled":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+// Package store is the local cache of gitlab entities in an embedded sqlite database.
+//
+// Each record is the json of one entity keyed by kind, scope and id.
+// The scope separates lists of the same kind, such as the events of each project.
+// The latest updated_at of a scope is its watermark for incremental sync.
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite" // pure go sqlite driver
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// Kind is the type of entity in a record.
+type Kind string
+
+const (
+	Projects      Kind = "project"
+	Events        Kind = "event"
+	MergeRequests Kind = "merge_request"
+)
+
+// Record is the json of one entity.
+type Record struct {
+	ID        int
+	ProjectID int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	Data      []byte
+}
+
+// Store is safe for concurrent use, including by several processes.
+type Store struct {
+	db *sql.DB
+}
+
+const schemaVersion = 1
+
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS records (
+		kind       TEXT    NOT NULL,
+		scope      TEXT    NOT NULL,
+		id         INTEGER NOT NULL,
+		project_id INTEGER NOT NULL DEFAULT 0,
+		created_at TEXT    NOT NULL DEFAULT '',
+		updated_at TEXT    NOT NULL DEFAULT '',
+		data       BLOB    NOT NULL,
+		PRIMARY KEY (kind, scope, id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS records_updated_at ON records (kind, scope, updated_at)`,
+	`CREATE INDEX IF NOT EXISTS records_project_id ON records (kind, project_id)`,
+	`CREATE TABLE IF NOT EXISTS imports (
+		source      TEXT PRIMARY KEY,
+		imported_at TEXT NOT NULL
+	)`,
+}
+
+// Open opens or creates the database file.
+// ":memory:" opens a private in memory database.
+func Open(ctx context.Context, path string) (*Store, error) {
+	dsn := "file:" + path + "?_pragma=busy_timeout(10000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, withstack.Errorf("open store %s: %w", path, err)
+	}
+	if path == ":memory:" {
+		// each connection would be a different database
+		db.SetMaxOpenConns(1)
+	}
+	s := &Store{db: db}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, withstack.Errorf("migrate store %s: %w", path, err)
+	}
+	return s, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	var version int
+	if err := s.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version == schemaVersion {
+		return nil
+	}
+	if schemaVersion < version {
+		return fmt.Errorf("store schema %d is newer than %d", version, schemaVersion)
+	}
+	return s.inTx(ctx, func(tx *sql.Tx) error {
+		for _, stmt := range schema {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		_, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", schemaVersion))
+		return err
+	})
+}
+
+func (s *Store) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return errors.Join(err, tx.Rollback())
+	}
+	return tx.Commit()
+}
+
+// Put inserts or replaces the records of the scope in one transaction.
+func (s *Store) Put(ctx context.Context, kind Kind, scope string, records []Record) error {
+	err := s.inTx(ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, `INSERT INTO records
+			(kind, scope, id, project_id, created_at, updated_at, data)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (kind, scope, id) DO UPDATE SET
+				project_id = excluded.project_id,
+				created_at = excluded.created_at,
+				updated_at = excluded.updated_at,
+				data       = excluded.data`)
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+		for _, r := range records {
+			if _, err := stmt.ExecContext(ctx,
+				string(kind), scope, r.ID, r.ProjectID,
+				formatTime(r.CreatedAt), formatTime(r.UpdatedAt), r.Data,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return withstack.Errorf("put %s %s: %w", kind, scope, err)
+	}
+	return nil
+}
+
+// All returns the records of the scope ordered by id.
+func (s *Store) All(ctx context.Context, kind Kind, scope string) ([]Record, error) {
+	return s.query(ctx, `SELECT id, project_id, created_at, updated_at, data FROM records
+		WHERE kind = ? AND scope = ? ORDER BY id`, string(kind), scope)
+}
+
+// UpdatedAfter returns the records of the scope updated after the time, oldest first.
+func (s *Store) UpdatedAfter(ctx context.Context, kind Kind, scope string, after time.Time) ([]Record, error) {
+	return s.query(ctx, `SELECT id, project_id, created_at, updated_at, data FROM records
+		WHERE kind = ? AND scope = ? AND updated_at > ? ORDER BY updated_at, id`,
+		string(kind), scope, formatTime(after))
+}
+
+// Count returns the number of records of the scope.
+func (s *Store) Count(ctx context.Context, kind Kind, scope string) (int, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM records WHERE kind = ? AND scope = ?`,
+		string(kind), scope).Scan(&n)
+	if err != nil {
+		return 0, withstack.Errorf("count %s %s: %w", kind, scope, err)
+	}
+	return n, nil
+}
+
+// Watermark returns the latest updated_at of the scope or the zero time when it is empty.
+func (s *Store) Watermark(ctx context.Context, kind Kind, scope string) (time.Time, error) {
+	var last sql.NullString
+	err := s.db.QueryRowContext(ctx, `SELECT max(updated_at) FROM records WHERE kind = ? AND scope = ?`,
+		string(kind), scope).Scan(&last)
+	if err != nil {
+		return time.Time{}, withstack.Errorf("watermark %s %s: %w", kind, scope, err)
+	}
+	if !last.Valid {
+		return time.Time{}, nil
+	}
+	return parseTime(last.String)
+}
+
+// Imported is true if the source was imported.
+func (s *Store) Imported(ctx context.Context, source string) (bool, error) {
+	var n int
+	err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM imports WHERE source = ?`, source).Scan(&n)
+	if err != nil {
+		return false, withstack.Errorf("imported %s: %w", source, err)
+	}
+	return 0 < n, nil
+}
+
+// ImportOnce puts the records of the source unless it was imported before.
+func (s *Store) ImportOnce(
+	ctx context.Context,
+	source string,
+	kind Kind,
+	scope string,
+	read func() ([]Record, error),
+) (bool, error) {
+	if done, err := s.Imported(ctx, source); err != nil || done {
+		return false, err
+	}
+	records, err := read()
+	if err != nil {
+		return false, err
+	}
+	if err := s.Put(ctx, kind, scope, records); err != nil {
+		return false, err
+	}
+	_, err = s.db.ExecContext(ctx, `INSERT OR IGNORE INTO imports (source, imported_at) VALUES (?, ?)`,
+		source, formatTime(time.Now()))
+	if err != nil {
+		return false, withstack.Errorf("import %s: %w", source, err)
+	}
+	return true, nil
+}
+
+func (s *Store) query(ctx context.Context, query string, args ...any) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, withstack.Errorf("query records: %w", err)
+	}
+	defer rows.Close()
+
+	records := []Record{}
+	for rows.Next() {
+		var r Record
+		var createdAt, updatedAt string
+		if err := rows.Scan(&r.ID, &r.ProjectID, &createdAt, &updatedAt, &r.Data); err != nil {
+			return nil, withstack.Errorf("scan record: %w", err)
+		}
+		if r.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		if r.UpdatedAt, err = parseTime(updatedAt); err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+	}
+	return records, rows.Err()
+}
+
+// timeLayout sorts as text in time order.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(timeLayout)
+}
+
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(timeLayout, s)
+	if err != nil {
+		return time.Time{}, withstack.Errorf("store time %s: %w", s, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestStore(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "store_test")
+}
+
+var _ = Describe("Store", func() {
+	var (
+		ctx context.Context
+		s   *Store
+	)
+	day := func(d int) time.Time {
+		return time.Date(2024, 3, d, 10, 0, 0, 0, time.UTC)
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		var err error
+		s, err = Open(ctx, filepath.Join(GinkgoT().TempDir(), "cmr.db"))
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(s.Close)
+	})
+
+	It("replaces records by id", func() {
+		Expect(s.Put(ctx, MergeRequests, "mine", []Record{
+			{ID: 2, UpdatedAt: day(2), Data: []byte(`{"v":1}`)},
+			{ID: 1, UpdatedAt: day(1), Data: []byte(`{"v":1}`)},
+		})).To(Succeed())
+		Expect(s.Put(ctx, MergeRequests, "mine", []Record{
+			{ID: 2, ProjectID: 7, UpdatedAt: day(3), Data: []byte(`{"v":2}`)},
+		})).To(Succeed())
+
+		records, err := s.All(ctx, MergeRequests, "mine")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].ID).To(Equal(1))
+		Expect(records[1]).To(Equal(Record{ID: 2, ProjectID: 7, UpdatedAt: day(3), Data: []byte(`{"v":2}`)}))
+	})
+
+	It("keeps the scopes and kinds apart", func() {
+		Expect(s.Put(ctx, Events, "project_1_events", []Record{{ID: 1, Data: []byte(`{}`)}})).To(Succeed())
+		Expect(s.Put(ctx, Events, "project_2_events", []Record{{ID: 1, Data: []byte(`{}`)}})).To(Succeed())
+
+		for _, scope := range []string{"project_1_events", "project_2_events"} {
+			n, err := s.Count(ctx, Events, scope)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(1))
+		}
+		n, err := s.Count(ctx, MergeRequests, "project_1_events")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(BeZero())
+	})
+
+	It("tracks the updated_at watermark", func() {
+		watermark, err := s.Watermark(ctx, Projects, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watermark.IsZero()).To(BeTrue())
+
+		Expect(s.Put(ctx, Projects, "", []Record{
+			{ID: 1, UpdatedAt: day(5), Data: []byte(`{}`)},
+			{ID: 2, UpdatedAt: day(9), Data: []byte(`{}`)},
+			{ID: 3, UpdatedAt: day(7), Data: []byte(`{}`)},
+		})).To(Succeed())
+		watermark, err = s.Watermark(ctx, Projects, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(watermark).To(Equal(day(9)))
+
+		records, err := s.UpdatedAfter(ctx, Projects, "", day(5))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+		Expect(records[0].ID).To(Equal(3))
+		Expect(records[1].ID).To(Equal(2))
+	})
+
+	It("imports a source once", func() {
+		reads := 0
+		read := func() ([]Record, error) {
+			reads++
+			return []Record{{ID: reads, Data: []byte(`{}`)}}, nil
+		}
+		imported, err := s.ImportOnce(ctx, "projects.yaml", Projects, "", read)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(BeTrue())
+		imported, err = s.ImportOnce(ctx, "projects.yaml", Projects, "", read)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(BeFalse())
+		Expect(reads).To(Equal(1))
+	})
+
+	It("does not mark a failed import", func() {
+		_, err := s.ImportOnce(ctx, "bad.yaml", Projects, "", func() ([]Record, error) {
+			return nil, errors.New("bad yaml")
+		})
+		Expect(err).To(MatchError("bad yaml"))
+		imported, err := s.Imported(ctx, "bad.yaml")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(imported).To(BeFalse())
+	})
+
+	It("takes concurrent writers", func() {
+		const writers = 20
+		var wg sync.WaitGroup
+		errs := make(chan error, writers)
+		for w := range writers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- s.Put(ctx, Events, "mine", []Record{{ID: w, UpdatedAt: day(1), Data: []byte(`{}`)}})
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			Expect(err).NotTo(HaveOccurred())
+		}
+		n, err := s.Count(ctx, Events, "mine")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(writers))
+	})
+
+	It("reopens the file", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "again.db")
+		first, err := Open(ctx, path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.Put(ctx, Projects, "", []Record{{ID: 4, Data: []byte(`{}`)}})).To(Succeed())
+		Expect(first.Close()).To(Succeed())
+
+		second, err := Open(ctx, path)
+		Expect(err).NotTo(HaveOccurred())
+		defer second.Close()
+		n, err := second.Count(ctx, Projects, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+	})
+})
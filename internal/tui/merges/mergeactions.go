@@ -0,0 +1,193 @@
+package merges
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// MergeAction is something the user does to the selected merge request.
+type MergeAction int
+
+const (
+	ApproveAction MergeAction = iota
+	CommentAction
+	ToggleDraftAction
+	AutoMergeAction
+	OpenAction
+	CheckoutAction
+)
+
+func (a MergeAction) String() string {
+	switch a {
+	case ApproveAction:
+		return "approve"
+	case CommentAction:
+		return "comment"
+	case ToggleDraftAction:
+		return "toggle draft"
+	case AutoMergeAction:
+		return "auto merge"
+	case OpenAction:
+		return "open"
+	case CheckoutAction:
+		return "checkout"
+	default:
+		return "unknown"
+	}
+}
+
+// ActionRequest is an action the user confirmed.
+type ActionRequest struct {
+	Action MergeAction
+	MR     *gitlab.MergeRequestModel
+	Text   string // body of a comment
+}
+
+// MergeActions carries out the actions.
+// The calls that change the merge request return it as it is afterwards.
+type MergeActions interface {
+	Approve(ctx context.Context, mr *gitlab.MergeRequestModel) (*gitlab.MergeRequestModel, error)
+	Comment(ctx context.Context, mr *gitlab.MergeRequestModel, body string) (*gitlab.MergeRequestModel, error)
+	SetDraft(ctx context.Context, mr *gitlab.MergeRequestModel, draft bool) (*gitlab.MergeRequestModel, error)
+	AutoMerge(ctx context.Context, mr *gitlab.MergeRequestModel) (*gitlab.MergeRequestModel, error)
+	Open(ctx context.Context, mr *gitlab.MergeRequestModel) error
+	Checkout(ctx context.Context, mr *gitlab.MergeRequestModel) (string, error)
+}
+
+var _ MergeActions = (*ClientMergeActions)(nil)
+
+// ClientMergeActions carries out the actions with gitlab and the local clones.
+type ClientMergeActions struct {
+	app    gitlab.App
+	client *gitlab.Client
+
+	home      string
+	reposRoot string
+	// token loads the gitlab token when a fetch first needs it
+	token func(ctx context.Context) (string, error)
+
+	// openURL opens the url in the browser
+	openURL func(url string) error
+}
+
+func NewClientMergeActions(
+	app gitlab.App,
+	client *gitlab.Client,
+	home string,
+	reposRoot string,
+	token func(ctx context.Context) (string, error),
+) *ClientMergeActions {
+	return &ClientMergeActions{
+		app:       app,
+		client:    client,
+		home:      home,
+		reposRoot: reposRoot,
+		token:     token,
+		openURL:   openBrowser,
+	}
+}
+
+func (a *ClientMergeActions) Approve(ctx context.Context, mr *gitlab.MergeRequestModel) (*gitlab.MergeRequestModel, error) {
+	if _, err := a.client.ApproveMergeRequest(ctx, a.app, mr.ProjectID, mr.Iid, nil); err != nil {
+		return nil, err
+	}
+	// approve returns the approval state, not the merge request
+	return a.client.GetMergeRequest(ctx, a.app, mr.ProjectID, mr.Iid)
+}
+
+func (a *ClientMergeActions) Comment(ctx context.Context, mr *gitlab.MergeRequestModel, body string) (*gitlab.MergeRequestModel, error) {
+	if _, err := a.client.CreateMergeRequestNote(ctx, a.app, mr.ProjectID, mr.Iid, body); err != nil {
+		return nil, err
+	}
+	// the note count and update time of the merge request changed
+	return a.client.GetMergeRequest(ctx, a.app, mr.ProjectID, mr.Iid)
+}
+
+func (a *ClientMergeActions) SetDraft(ctx context.Context, mr *gitlab.MergeRequestModel, draft bool) (*gitlab.MergeRequestModel, error) {
+	return a.client.SetMergeRequestDraft(ctx, a.app, mr, draft)
+}
+
+func (a *ClientMergeActions) AutoMerge(ctx context.Context, mr *gitlab.MergeRequestModel) (*gitlab.MergeRequestModel, error) {
+	return a.client.MergeMergeRequest(ctx, a.app, mr.ProjectID, mr.Iid, &gitlab.MergeOptions{
+		MergeWhenPipelineSucceeds: true,
+		Sha:                       mr.Sha,
+	})
+}
+
+func (a *ClientMergeActions) Open(ctx context.Context, mr *gitlab.MergeRequestModel) error {
+	if mr.WebURL == "" {
+		return withstack.Errorf("!%d has no web url", mr.Iid)
+	}
+	return a.openURL(mr.WebURL)
+}
+
+// Checkout checks out the source branch in the local clone of the project
+// and returns the directory of the clone.
+func (a *ClientMergeActions) Checkout(ctx context.Context, mr *gitlab.MergeRequestModel) (string, error) {
+	project, err := a.client.GetProject(ctx, a.app, strconv.Itoa(mr.ProjectID))
+	if err != nil {
+		return "", err
+	}
+	dir := gitlab.RepoFilePath(a.home, a.reposRoot, *project)
+	repo, err := gitutil.PlainOpen(dir)
+	if err != nil {
+		return dir, withstack.Errorf("No clone of %s in %s: %w", project.PathWithNamespace, dir, err)
+	}
+	token, err := a.token(ctx)
+	if err != nil {
+		return dir, err
+	}
+	return dir, gitutil.CheckoutRemoteBranch(repo, "origin", mr.SourceBranch, token, nil)
+}
+
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return withstack.Errorf("Could not open %s: %w", url, err)
+	}
+	// reap the opener, it does not tell us whether the browser succeeded
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
+
+// describeAction returns what the action did to the merge request.
+func describeAction(req ActionRequest, updated *gitlab.MergeRequestModel, dir string) string {
+	ref := fmt.Sprintf("!%d", req.MR.Iid)
+	switch req.Action {
+	case ApproveAction:
+		return "approved " + ref
+	case CommentAction:
+		return "commented on " + ref
+	case ToggleDraftAction:
+		if updated != nil && updated.Draft {
+			return "marked " + ref + " as draft"
+		}
+		return "marked " + ref + " as ready"
+	case AutoMergeAction:
+		if updated != nil && updated.State == "merged" {
+			return "merged " + ref
+		}
+		return ref + " merges when the pipeline succeeds"
+	case OpenAction:
+		return "opened " + req.MR.WebURL
+	case CheckoutAction:
+		return "checked out " + req.MR.SourceBranch + " in " + dir
+	default:
+		return req.Action.String() + " " + ref
+	}
+}
@@ -0,0 +1,130 @@
+package merges
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	appfixtures "github.com/stalwartgiraffe/cmr/internal/app/fixtures"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab/localhost"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
+	rc "github.com/stalwartgiraffe/cmr/restclient"
+)
+
+func TestClientMergeActions(t *testing.T) {
+	server := localhost.NewServer()
+	defer server.Close()
+	ctx := context.Background()
+	app := appfixtures.NewApp()
+	client := gitlab.NewClient(rc.WithBaseURL(server.URL()))
+
+	project, err := client.GetProject(ctx, app, gitlab.ProjectPathID("gitlab-org/gitlab-foss"))
+	require.NoError(t, err)
+	mr, err := client.CreateMergeRequest(ctx, app, project.ID, &gitlab.CreateMergeRequestOptions{
+		SourceBranch: "act_on_me",
+		TargetBranch: project.DefaultBranch,
+		Title:        "act on me",
+	})
+	require.NoError(t, err)
+
+	noToken := func(context.Context) (string, error) { return "", nil }
+	actions := NewClientMergeActions(app, client, t.TempDir(), "", noToken)
+	opened := ""
+	actions.openURL = func(url string) error {
+		opened = url
+		return nil
+	}
+
+	approved, err := actions.Approve(ctx, mr)
+	require.NoError(t, err)
+	require.Equal(t, mr.ID, approved.ID)
+	require.Equal(t, mr.Title, approved.Title)
+
+	commented, err := actions.Comment(ctx, mr, "looks good")
+	require.NoError(t, err)
+	require.Equal(t, mr.UserNotesCount+1, commented.UserNotesCount)
+
+	draft, err := actions.SetDraft(ctx, mr, true)
+	require.NoError(t, err)
+	require.True(t, draft.Draft)
+	ready, err := actions.SetDraft(ctx, draft, false)
+	require.NoError(t, err)
+	require.False(t, ready.Draft)
+
+	auto, err := actions.AutoMerge(ctx, ready)
+	require.NoError(t, err)
+	require.True(t, auto.MergeWhenPipelineSucceeds)
+
+	require.NoError(t, actions.Open(ctx, mr))
+	require.Equal(t, mr.WebURL, opened)
+
+	// there is no clone in the empty home
+	_, err = actions.Checkout(ctx, mr)
+	require.ErrorContains(t, err, "No clone of gitlab-org/gitlab-foss")
+}
+
+type fakeRenderer struct {
+	req     ActionRequest
+	updated *gitlab.MergeRequestModel
+	message string
+	err     error
+}
+
+func (r *fakeRenderer) Run() error                               { return nil }
+func (r *fakeRenderer) OnActionSubscribe(fn func(ActionRequest)) {}
+func (r *fakeRenderer) QueueUpdate(f func())                     { f() }
+func (r *fakeRenderer) ShowActionResult(req ActionRequest, updated *gitlab.MergeRequestModel, message string, err error) {
+	r.req, r.updated, r.message, r.err = req, updated, message, err
+}
+
+type fakeActions struct {
+	MergeActions
+	err error
+}
+
+func (a *fakeActions) SetDraft(ctx context.Context, mr *gitlab.MergeRequestModel, draft bool) (*gitlab.MergeRequestModel, error) {
+	if a.err != nil {
+		return nil, a.err
+	}
+	updated := *mr
+	updated.Draft = draft
+	updated.Title = gitlab.DraftTitle(mr.Title, draft)
+	return &updated, nil
+}
+
+func TestMergesControllerRefreshesRow(t *testing.T) {
+	mergesMap := gitlab.MergeRequestMap{
+		1: {ID: 1, Iid: 11, Title: "one"},
+		2: {ID: 2, Iid: 12, Title: "two"},
+	}
+	repo := NewInMemoryMergesRepository(nil)
+	var err error
+	repo.RecordTable, err = records.NewRecordTable(
+		records.MergeRequestSpec(records.Lookups{}), nil, slices.Collect(maps.Values(mergesMap)))
+	require.NoError(t, err)
+	changes := 0
+	repo.OnChanged(func(EmptyT) { changes++ })
+
+	render := &fakeRenderer{}
+	actions := &fakeActions{}
+	m := NewMergesController(repo, render, actions)
+
+	mr := repo.GetRowRecord(1).(*gitlab.MergeRequestModel)
+	require.Equal(t, "one", mr.Title)
+	m.do(context.Background(), ActionRequest{Action: ToggleDraftAction, MR: mr})
+
+	require.NoError(t, render.err)
+	require.Equal(t, "marked !11 as draft", render.message)
+	require.Equal(t, 1, changes)
+	require.Equal(t, "Draft: one", repo.GetCell(1, 3))
+
+	actions.err = errors.New("boom")
+	m.do(context.Background(), ActionRequest{Action: ToggleDraftAction, MR: mr})
+	require.EqualError(t, render.err, "boom")
+	require.Equal(t, 1, changes)
+}
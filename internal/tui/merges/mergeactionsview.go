@@ -0,0 +1,163 @@
+package merges
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+)
+
+const actionHelp = "[gray]Enter[white] details  [gray]a[white] approve  [gray]c[white] comment  " +
+	"[gray]d[white] draft  [gray]m[white] auto merge  [gray]o[white] open  [gray]b[white] checkout branch"
+
+// actionKeys binds the keys to the actions on the selected merge request.
+var actionKeys = map[rune]MergeAction{
+	'a': ApproveAction,
+	'c': CommentAction,
+	'd': ToggleDraftAction,
+	'm': AutoMergeAction,
+	'o': OpenAction,
+	'b': CheckoutAction,
+}
+
+func (r *TuiMergesRenderer) setupActions() {
+	r.pages.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// leave the keys to the filter and the modals
+		if event.Key() != tcell.KeyRune || r.tablePage.FilterHasFocus() || r.pages.HasPage(modalPageName) {
+			return event
+		}
+		action, ok := actionKeys[event.Rune()]
+		if !ok {
+			return event
+		}
+		mr := r.selected()
+		if mr == nil {
+			return event
+		}
+		r.confirmAction(action, mr)
+		return nil
+	})
+}
+
+// selected returns the merge request of the detail page or of the selected row.
+func (r *TuiMergesRenderer) selected() *gitlab.MergeRequestModel {
+	if name, _ := r.pages.GetFrontPage(); name == detailPageName {
+		return r.detailPage.Current()
+	}
+	mr, _ := r.tablePage.Selected().(*gitlab.MergeRequestModel)
+	return mr
+}
+
+func (r *TuiMergesRenderer) OnActionSubscribe(fn func(ActionRequest)) {
+	r.onAction.Subscribe(fn)
+}
+
+func (r *TuiMergesRenderer) QueueUpdate(f func()) {
+	r.tviewApp.QueueUpdateDraw(f)
+}
+
+// ShowActionResult reports the outcome in the status bar
+// and refreshes the detail page of the merge request.
+func (r *TuiMergesRenderer) ShowActionResult(req ActionRequest, updated *gitlab.MergeRequestModel, message string, err error) {
+	if err != nil {
+		// the stack of the error does not fit the status bar
+		first, _, _ := strings.Cut(err.Error(), "\n")
+		r.statusBar.SetText(fmt.Sprintf("[red]%s !%d failed: %s[white]", req.Action, req.MR.Iid, tview.Escape(first)))
+		return
+	}
+	r.statusBar.SetText("[green]" + tview.Escape(message) + "[white]")
+	if updated == nil && req.Action != CommentAction {
+		return
+	}
+	r.detailPage.Forget(req.MR.ID)
+	if current := r.detailPage.Current(); current != nil && current.ID == req.MR.ID {
+		if updated == nil {
+			updated = current
+		}
+		r.detailPage.Show(r.ctx, updated)
+	}
+}
+
+// confirmAction asks before the action is carried out.
+func (r *TuiMergesRenderer) confirmAction(action MergeAction, mr *gitlab.MergeRequestModel) {
+	if action == CommentAction {
+		r.showCommentForm(mr)
+		return
+	}
+	modal := tview.NewModal().
+		SetText(confirmText(action, mr)).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(_ int, label string) {
+			r.closeModal()
+			if label == "Yes" {
+				r.onAction.Notify(ActionRequest{Action: action, MR: mr})
+			}
+		})
+	r.showModal(modal)
+}
+
+func confirmText(action MergeAction, mr *gitlab.MergeRequestModel) string {
+	ref := fmt.Sprintf("!%d %s", mr.Iid, mr.Title)
+	switch action {
+	case ApproveAction:
+		return "Approve " + ref + "?"
+	case ToggleDraftAction:
+		if mr.Draft {
+			return "Mark " + ref + " as ready?"
+		}
+		return "Mark " + ref + " as draft?"
+	case AutoMergeAction:
+		return "Merge " + ref + " when the pipeline succeeds?"
+	case OpenAction:
+		return "Open " + mr.WebURL + " in the browser?"
+	case CheckoutAction:
+		return "Check out " + mr.SourceBranch + " in the local clone?"
+	default:
+		return action.String() + " " + ref + "?"
+	}
+}
+
+// showCommentForm asks for the body of the comment, posting it confirms the action.
+func (r *TuiMergesRenderer) showCommentForm(mr *gitlab.MergeRequestModel) {
+	form := tview.NewForm()
+	form.AddTextArea("Comment", "", 0, 6, 0, nil)
+	form.AddButton("Post", func() {
+		body := strings.TrimSpace(form.GetFormItem(0).(*tview.TextArea).GetText())
+		r.closeModal()
+		if body != "" {
+			r.onAction.Notify(ActionRequest{Action: CommentAction, MR: mr, Text: body})
+		}
+	})
+	form.AddButton("Cancel", r.closeModal)
+	form.SetCancelFunc(r.closeModal)
+	form.SetBorder(true).SetTitle(fmt.Sprintf(" Comment on !%d ", mr.Iid))
+	r.showModal(centered(form, 72, 12))
+}
+
+func (r *TuiMergesRenderer) showModal(p tview.Primitive) {
+	r.modalReturn = r.tviewApp.GetFocus()
+	r.pages.AddPage(modalPageName, p, true, true)
+	r.tviewApp.SetFocus(p)
+}
+
+func (r *TuiMergesRenderer) closeModal() {
+	r.pages.RemovePage(modalPageName)
+	if r.modalReturn != nil {
+		r.tviewApp.SetFocus(r.modalReturn)
+		r.modalReturn = nil
+	}
+}
+
+// centered places p in the middle of the screen.
+func centered(p tview.Primitive, width, height int) tview.Primitive {
+	return tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(p, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+}
@@ -0,0 +1,199 @@
+package merges
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+)
+
+// DetailSource fetches the parts of a merge request that are not in the cache.
+type DetailSource interface {
+	Discussions(ctx context.Context, mr *gitlab.MergeRequestModel) ([]gitlab.Discussion, error)
+	Diffs(ctx context.Context, mr *gitlab.MergeRequestModel) ([]gitlab.MergeRequestDiff, error)
+	Pipeline(ctx context.Context, mr *gitlab.MergeRequestModel) (*gitlab.PipelineModel, []gitlab.JobModel, error)
+}
+
+var _ DetailSource = (*ClientDetailSource)(nil)
+
+// ClientDetailSource fetches the details from gitlab.
+type ClientDetailSource struct {
+	app    gitlab.App
+	client *gitlab.Client
+}
+
+func NewClientDetailSource(app gitlab.App, client *gitlab.Client) *ClientDetailSource {
+	return &ClientDetailSource{
+		app:    app,
+		client: client,
+	}
+}
+
+func (s *ClientDetailSource) Discussions(ctx context.Context, mr *gitlab.MergeRequestModel) ([]gitlab.Discussion, error) {
+	return s.client.GetMergeRequestDiscussions(ctx, s.app, mr.ProjectID, mr.Iid)
+}
+
+func (s *ClientDetailSource) Diffs(ctx context.Context, mr *gitlab.MergeRequestModel) ([]gitlab.MergeRequestDiff, error) {
+	return s.client.GetMergeRequestDiffs(ctx, s.app, mr.ProjectID, mr.Iid)
+}
+
+func (s *ClientDetailSource) Pipeline(ctx context.Context, mr *gitlab.MergeRequestModel) (*gitlab.PipelineModel, []gitlab.JobModel, error) {
+	return s.client.GetLatestPipeline(ctx, s.app, mr.ProjectID, mr.Iid)
+}
+
+// formatDescription renders the header and the description of the merge request.
+func formatDescription(mr *gitlab.MergeRequestModel) string {
+	var sb strings.Builder
+	ref := fmt.Sprintf("!%d", mr.Iid)
+	if mr.References != nil && mr.References.Full != "" {
+		ref = mr.References.Full
+	}
+	fmt.Fprintf(&sb, "[yellow]%s[white] %s\n", tview.Escape(ref), tview.Escape(mr.Title))
+	fmt.Fprintf(&sb, "[blue]State:[white] %s", mr.State)
+	if mr.Draft {
+		sb.WriteString(" (draft)")
+	}
+	fmt.Fprintf(&sb, "  [blue]Author:[white] %s\n", tview.Escape(getUserName(mr)))
+	fmt.Fprintf(&sb, "[blue]Branch:[white] %s -> %s\n",
+		tview.Escape(mr.SourceBranch), tview.Escape(mr.TargetBranch))
+	if 0 < len(mr.Labels) {
+		fmt.Fprintf(&sb, "[blue]Labels:[white] %s\n", tview.Escape(strings.Join(mr.Labels, ", ")))
+	}
+	if mr.WebURL != "" {
+		fmt.Fprintf(&sb, "[blue]URL:[white] %s\n", tview.Escape(mr.WebURL))
+	}
+	sb.WriteString("\n")
+	description := strings.TrimSpace(mr.Description)
+	if description == "" {
+		description = "No description."
+	}
+	sb.WriteString(tview.Escape(description))
+	return sb.String()
+}
+
+// formatDiscussions renders the threads, replies are indented under the first note.
+// System notes such as "requested review" are dimmed.
+func formatDiscussions(discussions []gitlab.Discussion) string {
+	var sb strings.Builder
+	count := 0
+	for _, d := range discussions {
+		for i, note := range d.Notes {
+			indent := ""
+			if 0 < i {
+				indent = "    "
+			}
+			author := ""
+			if note.Author != nil {
+				author = note.Author.Username
+			}
+			when := formatWhen(note.CreatedAt)
+			if note.System {
+				fmt.Fprintf(&sb, "%s[gray]%s %s %s[white]\n", indent,
+					tview.Escape(author), tview.Escape(note.Body), when)
+				continue
+			}
+			count++
+			fmt.Fprintf(&sb, "%s[green]%s[white] [gray]%s[white]\n", indent, tview.Escape(author), when)
+			for _, line := range strings.Split(strings.TrimRight(note.Body, "\n"), "\n") {
+				fmt.Fprintf(&sb, "%s  %s\n", indent, tview.Escape(line))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	if count == 0 {
+		return "No comments.\n" + sb.String()
+	}
+	return sb.String()
+}
+
+// formatFileName labels the changed file for the files list.
+func formatFileName(d *gitlab.MergeRequestDiff) string {
+	switch {
+	case d.NewFile:
+		return "A " + d.NewPath
+	case d.DeletedFile:
+		return "D " + d.OldPath
+	case d.RenamedFile:
+		return "R " + d.OldPath + " -> " + d.NewPath
+	default:
+		return "M " + d.NewPath
+	}
+}
+
+// formatDiff colors the unified diff of the file.
+func formatDiff(d *gitlab.MergeRequestDiff) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[::b]--- a/%s\n+++ b/%s[::-]\n", tview.Escape(d.OldPath), tview.Escape(d.NewPath))
+	if d.Diff == "" {
+		sb.WriteString("[gray]no textual changes[white]\n")
+		return sb.String()
+	}
+	for _, line := range strings.Split(strings.TrimRight(d.Diff, "\n"), "\n") {
+		escaped := tview.Escape(line)
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			fmt.Fprintf(&sb, "[aqua]%s[white]\n", escaped)
+		case strings.HasPrefix(line, "+"):
+			fmt.Fprintf(&sb, "[green]%s[white]\n", escaped)
+		case strings.HasPrefix(line, "-"):
+			fmt.Fprintf(&sb, "[red]%s[white]\n", escaped)
+		default:
+			fmt.Fprintf(&sb, "%s\n", escaped)
+		}
+	}
+	return sb.String()
+}
+
+// formatPipeline renders the status of the pipeline and each of its jobs by stage.
+func formatPipeline(pipeline *gitlab.PipelineModel, jobs []gitlab.JobModel) string {
+	if pipeline == nil {
+		return "No pipeline."
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[blue]Pipeline[white] #%d %s %s\n", pipeline.ID,
+		statusColor(pipeline.Status, false), tview.Escape(pipeline.Ref))
+	if pipeline.WebURL != "" {
+		fmt.Fprintf(&sb, "%s\n", tview.Escape(pipeline.WebURL))
+	}
+	stage := ""
+	for _, job := range jobs {
+		if job.Stage != stage {
+			stage = job.Stage
+			fmt.Fprintf(&sb, "\n[yellow]%s[white]\n", tview.Escape(stage))
+		}
+		fmt.Fprintf(&sb, "  %-24s %s %s\n", tview.Escape(job.Name),
+			statusColor(job.Status, job.AllowFailure),
+			(time.Duration(job.Duration) * time.Second).String())
+	}
+	return sb.String()
+}
+
+func statusColor(status string, allowFailure bool) string {
+	color := "white"
+	switch status {
+	case "success":
+		color = "green"
+	case "failed":
+		color = "red"
+		if allowFailure {
+			color = "orange"
+			status = "failed (allowed)"
+		}
+	case "running", "pending", "created":
+		color = "aqua"
+	case "canceled", "skipped", "manual":
+		color = "gray"
+	}
+	return "[" + color + "]" + status + "[white]"
+}
+
+func formatWhen(t gitlab.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(time.DateTime)
+}
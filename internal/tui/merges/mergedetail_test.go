@@ -0,0 +1,156 @@
+package merges
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+)
+
+func TestFormatDiff(t *testing.T) {
+	d := &gitlab.MergeRequestDiff{
+		OldPath: "a.go",
+		NewPath: "a.go",
+		Diff:    "@@ -1,2 +1,2 @@\n keep [x]\n-old\n+new\n",
+	}
+	text := formatDiff(d)
+	require.Contains(t, text, "[aqua]@@ -1,2 +1,2 @@[white]")
+	require.Contains(t, text, "[red]-old[white]")
+	require.Contains(t, text, "[green]+new[white]")
+	require.Contains(t, text, "keep [x[]")
+	require.Equal(t, "M a.go", formatFileName(d))
+}
+
+func TestFormatDiscussions(t *testing.T) {
+	require.Contains(t, formatDiscussions(nil), "No comments.")
+
+	discussions := []gitlab.Discussion{
+		{
+			Notes: []gitlab.Note{
+				{Body: "why?", Author: &gitlab.UserModel{Username: "ann"}},
+				{Body: "because", Author: &gitlab.UserModel{Username: "bob"}},
+			},
+		},
+	}
+	text := formatDiscussions(discussions)
+	require.Contains(t, text, "[green]ann[white]")
+	require.Contains(t, text, "    [green]bob[white]")
+	require.Contains(t, text, "      because")
+}
+
+func TestFormatPipeline(t *testing.T) {
+	require.Equal(t, "No pipeline.", formatPipeline(nil, nil))
+
+	pipeline := &gitlab.PipelineModel{ID: 7, Status: "failed", Ref: "feature"}
+	jobs := []gitlab.JobModel{
+		{Name: "build", Stage: "build", Status: "success"},
+		{Name: "lint", Stage: "test", Status: "failed", AllowFailure: true},
+	}
+	text := formatPipeline(pipeline, jobs)
+	require.Contains(t, text, "#7 [red]failed[white] feature")
+	require.Contains(t, text, "[green]success[white]")
+	require.Contains(t, text, "[orange]failed (allowed)[white]")
+}
+
+type fakeDetailSource struct {
+	mu    sync.Mutex
+	calls map[string]int
+	err   error
+	// ctx is the context of the last call
+	ctx context.Context
+}
+
+func (s *fakeDetailSource) called(ctx context.Context, name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[name]++
+	s.ctx = ctx
+}
+
+func (s *fakeDetailSource) Discussions(ctx context.Context, mr *gitlab.MergeRequestModel) ([]gitlab.Discussion, error) {
+	s.called(ctx, "discussions")
+	return []gitlab.Discussion{{Notes: []gitlab.Note{{Body: "looks good"}}}}, s.err
+}
+
+func (s *fakeDetailSource) Diffs(ctx context.Context, mr *gitlab.MergeRequestModel) ([]gitlab.MergeRequestDiff, error) {
+	s.called(ctx, "diffs")
+	return []gitlab.MergeRequestDiff{
+		{OldPath: "a.go", NewPath: "a.go", Diff: "+a\n"},
+		{OldPath: "b.go", NewPath: "b.go", Diff: "+b\n", NewFile: true},
+	}, s.err
+}
+
+func (s *fakeDetailSource) Pipeline(ctx context.Context, mr *gitlab.MergeRequestModel) (*gitlab.PipelineModel, []gitlab.JobModel, error) {
+	s.called(ctx, "pipeline")
+	return &gitlab.PipelineModel{ID: 1, Status: "success"}, nil, s.err
+}
+
+// newTestDetailPage returns a page whose ui updates are run by the returned func.
+func newTestDetailPage(source DetailSource) (*MergeDetailPage, func(n int)) {
+	p := NewMergeDetailPage(tview.NewApplication(), source, tw.NewStyle())
+	updates := make(chan func(), 8)
+	p.update = func(f func()) {
+		updates <- f
+	}
+	drain := func(n int) {
+		for range n {
+			(<-updates)()
+		}
+	}
+	return p, drain
+}
+
+func TestMergeDetailPageShow(t *testing.T) {
+	source := &fakeDetailSource{calls: map[string]int{}}
+	p, drain := newTestDetailPage(source)
+	mr := &gitlab.MergeRequestModel{ID: 1, Iid: 2, Title: "add detail"}
+
+	p.Show(context.Background(), mr)
+	require.Contains(t, p.description.GetText(true), "add detail")
+	require.Equal(t, "loading...", p.pipeline.GetText(true))
+	drain(3)
+
+	require.Contains(t, p.discussions.GetText(true), "looks good")
+	require.Contains(t, p.pipeline.GetText(true), "success")
+	require.Equal(t, 2, p.files.GetItemCount())
+	require.Contains(t, p.diff.GetText(true), "+a")
+
+	p.files.SetCurrentItem(1)
+	require.Contains(t, p.diff.GetText(true), "+b")
+
+	// the details are fetched once
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "shown")
+	p.Show(ctx, mr)
+	require.Equal(t, map[string]int{"discussions": 1, "diffs": 1, "pipeline": 1}, source.calls)
+	require.Contains(t, p.discussions.GetText(true), "looks good")
+
+	// a reload fetches with the context the page was shown with
+	p.Reload()
+	drain(3)
+	require.Equal(t, map[string]int{"discussions": 2, "diffs": 2, "pipeline": 2}, source.calls)
+	require.Equal(t, "shown", source.ctx.Value(ctxKey{}))
+}
+
+func TestMergeDetailPageStaleFetch(t *testing.T) {
+	source := &fakeDetailSource{calls: map[string]int{}, err: errors.New("boom")}
+	p, drain := newTestDetailPage(source)
+	closed := 0
+	p.OnCloseSubscribe(func(EmptyT) { closed++ })
+
+	p.Show(context.Background(), &gitlab.MergeRequestModel{ID: 1})
+	p.Close()
+	drain(3)
+	require.Equal(t, 1, closed)
+	require.Equal(t, "loading...", p.pipeline.GetText(true))
+
+	p.Show(context.Background(), &gitlab.MergeRequestModel{ID: 2})
+	drain(3)
+	require.Contains(t, p.pipeline.GetText(true), "boom")
+}
@@ -0,0 +1,296 @@
+package merges
+
+import (
+	"context"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/events"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+)
+
+const (
+	loadingText = "[gray]loading...[white]"
+	detailHelp  = "[gray]Tab[white] next panel  [gray]Esc[white] back  [gray]r[white] reload"
+)
+
+// MergeDetailPage drills down into one merge request.
+// The discussions, diffs and pipeline are fetched the first time the merge request is shown.
+type MergeDetailPage struct {
+	*tview.Flex
+	source DetailSource
+
+	// update runs f on the ui goroutine
+	update func(f func())
+
+	description *tw.TextDetailsPanel
+	discussions *tw.TextDetailsPanel
+	files       *tw.ListPanel
+	diff        *tw.TextDetailsPanel
+	pipeline    *tw.TextDetailsPanel
+	focusRing   *tw.FocusRing
+
+	mr *gitlab.MergeRequestModel
+	// ctx is the context the page was shown with, a reload fetches with it
+	ctx     context.Context
+	cancel  context.CancelFunc
+	details map[int]*mergeDetail // by merge request id
+
+	onClose events.Event[EmptyT]
+}
+
+// mergeDetail holds what was fetched for one merge request.
+type mergeDetail struct {
+	discussions    []gitlab.Discussion
+	hasDiscussions bool
+	diffs          []gitlab.MergeRequestDiff
+	hasDiffs       bool
+	pipeline       *gitlab.PipelineModel
+	jobs           []gitlab.JobModel
+	hasPipeline    bool
+}
+
+func NewMergeDetailPage(tviewApp *tview.Application, source DetailSource, style *tw.Style) *MergeDetailPage {
+	p := &MergeDetailPage{
+		Flex:   tview.NewFlex(),
+		source: source,
+		update: func(f func()) {
+			tviewApp.QueueUpdateDraw(f)
+		},
+		description: newDetailPanel("Description", style),
+		discussions: newDetailPanel("Discussions", style),
+		files:       tw.NewListPanel("Files", style),
+		diff:        newDetailPanel("Diff", style),
+		pipeline:    newDetailPanel("Pipeline", style),
+		details:     map[int]*mergeDetail{},
+	}
+	p.focusRing = tw.NewFocusRing(tviewApp,
+		p.description, p.discussions, p.files, p.diff, p.pipeline)
+	p.setupLayout()
+	p.setupKeyHandlers()
+	p.files.SetChangedFunc(func(index int, _ string, _ string, _ rune) {
+		p.showDiff(index)
+	})
+	return p
+}
+
+func newDetailPanel(title string, style *tw.Style) *tw.TextDetailsPanel {
+	p := tw.NewTextDetailsPanel(style)
+	p.SetTitle(title)
+	return p
+}
+
+// setupLayout puts the text on the left and the code on the right
+func (p *MergeDetailPage) setupLayout() {
+	left := tview.NewFlex().SetDirection(tview.FlexRow)
+	left.AddItem(p.description, 0, 1, true)
+	left.AddItem(p.discussions, 0, 2, false)
+
+	right := tview.NewFlex().SetDirection(tview.FlexRow)
+	right.AddItem(p.files, 0, 1, false)
+	right.AddItem(p.diff, 0, 3, false)
+	right.AddItem(p.pipeline, 0, 1, false)
+
+	body := tview.NewFlex().SetDirection(tview.FlexColumn)
+	body.AddItem(left, 0, 1, true)
+	body.AddItem(right, 0, 1, false)
+
+	help := tview.NewTextView().SetDynamicColors(true).SetText(detailHelp)
+
+	p.SetDirection(tview.FlexRow)
+	p.AddItem(body, 0, 1, true)
+	p.AddItem(help, 1, 0, false)
+}
+
+func (p *MergeDetailPage) setupKeyHandlers() {
+	p.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			p.Close()
+			return nil
+		case tcell.KeyTab:
+			p.focusRing.Cycle(tw.NextDir)
+			return nil
+		case tcell.KeyBacktab:
+			p.focusRing.Cycle(tw.PrevDir)
+			return nil
+		case tcell.KeyRune:
+			if event.Rune() == 'r' {
+				p.Reload()
+				return nil
+			}
+		}
+		return event
+	})
+}
+
+// OnCloseSubscribe is notified when the user leaves the page.
+func (p *MergeDetailPage) OnCloseSubscribe(fn EmptyFn) {
+	p.onClose.Subscribe(fn)
+}
+
+// Show renders the merge request and fetches the details not yet fetched.
+func (p *MergeDetailPage) Show(ctx context.Context, mr *gitlab.MergeRequestModel) {
+	p.stopFetching()
+	p.ctx = ctx
+	ctx, p.cancel = context.WithCancel(ctx)
+	p.mr = mr
+
+	d, ok := p.details[mr.ID]
+	if !ok {
+		d = &mergeDetail{}
+		p.details[mr.ID] = d
+	}
+
+	p.description.SetText(formatDescription(mr))
+	p.description.ScrollToBeginning()
+	p.loadDiscussions(ctx, mr, d)
+	p.loadDiffs(ctx, mr, d)
+	p.loadPipeline(ctx, mr, d)
+	p.focusRing.Focus(0)
+}
+
+// Current returns the merge request on the page, nil when the page is closed.
+func (p *MergeDetailPage) Current() *gitlab.MergeRequestModel {
+	return p.mr
+}
+
+// Forget drops what was fetched for the merge request, it is fetched again when shown.
+func (p *MergeDetailPage) Forget(id int) {
+	delete(p.details, id)
+}
+
+// Reload fetches the details of the merge request again.
+func (p *MergeDetailPage) Reload() {
+	if p.mr == nil {
+		return
+	}
+	p.Forget(p.mr.ID)
+	p.Show(p.ctx, p.mr)
+}
+
+// Close stops the fetches in flight and notifies the subscribers.
+func (p *MergeDetailPage) Close() {
+	p.stopFetching()
+	p.mr = nil
+	p.onClose.Notify(EmptyT{})
+}
+
+func (p *MergeDetailPage) stopFetching() {
+	if p.cancel != nil {
+		p.cancel()
+		p.cancel = nil
+	}
+}
+
+// fetch runs get off the ui goroutine then apply on it, unless the page moved on.
+func (p *MergeDetailPage) fetch(
+	ctx context.Context,
+	mr *gitlab.MergeRequestModel,
+	get func(ctx context.Context) func(),
+) {
+	go func() {
+		apply := get(ctx)
+		p.update(func() {
+			if ctx.Err() != nil || p.mr != mr {
+				return
+			}
+			apply()
+		})
+	}()
+}
+
+func (p *MergeDetailPage) loadDiscussions(ctx context.Context, mr *gitlab.MergeRequestModel, d *mergeDetail) {
+	if d.hasDiscussions {
+		p.showDiscussions(d)
+		return
+	}
+	p.discussions.SetText(loadingText)
+	p.fetch(ctx, mr, func(ctx context.Context) func() {
+		discussions, err := p.source.Discussions(ctx, mr)
+		return func() {
+			if err != nil {
+				p.discussions.SetText(errorText(err))
+				return
+			}
+			d.discussions, d.hasDiscussions = discussions, true
+			p.showDiscussions(d)
+		}
+	})
+}
+
+func (p *MergeDetailPage) showDiscussions(d *mergeDetail) {
+	p.discussions.SetText(formatDiscussions(d.discussions))
+	p.discussions.ScrollToBeginning()
+}
+
+func (p *MergeDetailPage) loadDiffs(ctx context.Context, mr *gitlab.MergeRequestModel, d *mergeDetail) {
+	p.files.Clear()
+	if d.hasDiffs {
+		p.showFiles(d)
+		return
+	}
+	p.diff.SetText(loadingText)
+	p.fetch(ctx, mr, func(ctx context.Context) func() {
+		diffs, err := p.source.Diffs(ctx, mr)
+		return func() {
+			if err != nil {
+				p.diff.SetText(errorText(err))
+				return
+			}
+			d.diffs, d.hasDiffs = diffs, true
+			p.showFiles(d)
+		}
+	})
+}
+
+func (p *MergeDetailPage) showFiles(d *mergeDetail) {
+	p.files.Clear()
+	if len(d.diffs) == 0 {
+		p.diff.SetText("No changes.")
+		return
+	}
+	for i := range d.diffs {
+		p.files.AddItem(tview.Escape(formatFileName(&d.diffs[i])), "", 0, nil)
+	}
+	p.files.SetCurrentItem(0)
+	p.showDiff(0)
+}
+
+// showDiff renders the diff of the file at index of the files list.
+func (p *MergeDetailPage) showDiff(index int) {
+	if p.mr == nil {
+		return
+	}
+	d := p.details[p.mr.ID]
+	if d == nil || index < 0 || len(d.diffs) <= index {
+		return
+	}
+	p.diff.SetText(formatDiff(&d.diffs[index]))
+	p.diff.ScrollToBeginning()
+}
+
+func (p *MergeDetailPage) loadPipeline(ctx context.Context, mr *gitlab.MergeRequestModel, d *mergeDetail) {
+	if d.hasPipeline {
+		p.pipeline.SetText(formatPipeline(d.pipeline, d.jobs))
+		return
+	}
+	p.pipeline.SetText(loadingText)
+	p.fetch(ctx, mr, func(ctx context.Context) func() {
+		pipeline, jobs, err := p.source.Pipeline(ctx, mr)
+		return func() {
+			if err != nil {
+				p.pipeline.SetText(errorText(err))
+				return
+			}
+			d.pipeline, d.jobs, d.hasPipeline = pipeline, jobs, true
+			p.pipeline.SetText(formatPipeline(pipeline, jobs))
+		}
+	})
+}
+
+func errorText(err error) string {
+	return "[red]" + tview.Escape(err.Error()) + "[white]"
+}
@@ -1,21 +1,71 @@
 // Package merges renders the merge request collection
 package merges
 
+import (
+	"context"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+)
+
 type MergesController struct {
-	repo   MergesRepository
-	render MergesRenderer
+	repo    MergesRepository
+	render  MergesRenderer
+	actions MergeActions
 }
 
 func NewMergesController(
 	repo MergesRepository,
 	render MergesRenderer,
+	actions MergeActions,
 ) *MergesController {
 	return &MergesController{
-		repo:   repo,
-		render: render,
+		repo:    repo,
+		render:  render,
+		actions: actions,
 	}
 }
 
-func (m *MergesController) Run() error {
+func (m *MergesController) Run(ctx context.Context) error {
+	m.Listen(ctx)
 	return m.render.Run()
 }
+
+// Listen carries out the actions the user confirms, for a renderer that is run by another view.
+func (m *MergesController) Listen(ctx context.Context) {
+	m.render.OnActionSubscribe(func(req ActionRequest) {
+		go m.do(ctx, req)
+	})
+}
+
+// do carries out the action off the ui goroutine
+// then refreshes the row of the merge request on it.
+func (m *MergesController) do(ctx context.Context, req ActionRequest) {
+	var updated *gitlab.MergeRequestModel
+	var dir string
+	var err error
+	switch req.Action {
+	case ApproveAction:
+		updated, err = m.actions.Approve(ctx, req.MR)
+	case CommentAction:
+		updated, err = m.actions.Comment(ctx, req.MR, req.Text)
+	case ToggleDraftAction:
+		updated, err = m.actions.SetDraft(ctx, req.MR, !req.MR.Draft)
+	case AutoMergeAction:
+		updated, err = m.actions.AutoMerge(ctx, req.MR)
+	case OpenAction:
+		err = m.actions.Open(ctx, req.MR)
+	case CheckoutAction:
+		dir, err = m.actions.Checkout(ctx, req.MR)
+	}
+
+	message := ""
+	if err == nil {
+		message = describeAction(req, updated, dir)
+	}
+	m.render.QueueUpdate(func() {
+		if updated != nil {
+			m.repo.Update(updated)
+		}
+		m.render.ShowActionResult(req, updated, message, err)
+	})
+}
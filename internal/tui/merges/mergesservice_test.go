@@ -2,12 +2,14 @@ package merges
 
 import (
 	//"path/filepath"
+	"maps"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
-	"github.com/stalwartgiraffe/cmr/internal/find"
 	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
 )
 
 func TestUpdateFind(t *testing.T) {
@@ -37,11 +39,11 @@ func TestUpdateFind(t *testing.T) {
 			projects := gitlab.MakeProjectMap(projectsSlice)
 
 			require.NoError(t, err)
-			contents := NewMergeRequestContents()
-			table := NewRecordTable(contents, mergesMap, projects)
-			view := find.NewTableView(table)
-			require.NotNil(t, view)
-			view.UpdateFind(tt.pattern)
+			spec := records.MergeRequestSpec(records.Lookups{Projects: projects})
+			table, err := records.NewRecordTable(spec, nil, slices.Collect(maps.Values(mergesMap)))
+			require.NoError(t, err)
+			require.NotNil(t, table)
+			table.Filter(tt.pattern)
 		})
 	}
 }
@@ -3,85 +3,101 @@ package merges
 import (
 	"context"
 
-	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
+	"github.com/stalwartgiraffe/cmr/events"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
 	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
 )
 
 type MergesRenderer interface {
 	Run() error
+
+	// OnActionSubscribe is notified when the user confirms an action.
+	OnActionSubscribe(fn func(ActionRequest))
+	// QueueUpdate runs f on the ui goroutine.
+	QueueUpdate(f func())
+	ShowActionResult(req ActionRequest, updated *gitlab.MergeRequestModel, message string, err error)
 }
 
 var _ MergesRenderer = (*TuiMergesRenderer)(nil)
 
 type TuiMergesRenderer struct {
+	*tview.Flex // the pages over the status bar
+
 	tviewApp *tview.Application
 	stop     StopFn
 
-	tablePage *tview.Flex
+	ctx context.Context
 
-	filterPanel  *tw.BasicFilterPanel
-	tablePanel   *tw.TablePanel
-	detailsPanel *tw.TextDetailsPanel
-
-	focusRing FocusRing
-}
+	pages      *tview.Pages
+	tablePage  *records.Screen
+	detailPage *MergeDetailPage
+	statusBar  *tview.TextView
 
-type FocusRing interface {
-	Cycle(direction tw.RingDirection)
+	onAction    events.Event[ActionRequest]
+	modalReturn tview.Primitive // has the focus back when the modal closes
 }
 
 type StopFn func()
 
 type MergesRepository interface {
-	TableContents
+	// the table of merge requests with its filter and layout
+	records.Source
 
 	Load() error
 
-	Filter(string)
-
-	RecordsTable
-}
-
-type RecordsTable interface {
-	GetRowRecord(int) any
+	Update(*gitlab.MergeRequestModel)
 }
 
-type TableContents interface {
-	GetRowCount() int
-	GetColumnCount() int
-	GetCell(row, col int) string
-}
+const (
+	tablePageName  = "table"
+	detailPageName = "detail"
+	modalPageName  = "modal"
+)
 
-func NewTuiMergesRenderer(ctx context.Context, repo MergesRepository) *TuiMergesRenderer {
+func NewTuiMergesRenderer(ctx context.Context, repo MergesRepository, source DetailSource) *TuiMergesRenderer {
 	tviewApp := tview.NewApplication()
 	stop := tviewApp.Stop
 	style := tw.NewStyle()
-	tablePanel := tw.NewTablePanel(
-		tw.NewTwoBandTableContent(repo),
-		stop,
-		style,
-	)
+	tablePage := records.NewScreen(tviewApp, repo, style, tw.NewFocusRing(tviewApp), stop)
+	r := NewTuiMergesPage(ctx, tviewApp, tablePage, source, style)
+	r.statusBar.SetText(actionHelp + "  " + records.ScreenHelp)
+
+	go blockOnCtxDone(ctx, stop)
+
+	return r
+}
+
+// NewTuiMergesPage drills down from the screen of the merge requests
+// of an app that is run by another view, such as a tab of the shell.
+func NewTuiMergesPage(
+	ctx context.Context,
+	tviewApp *tview.Application,
+	tablePage *records.Screen,
+	source DetailSource,
+	style *tw.Style,
+) *TuiMergesRenderer {
 	r := &TuiMergesRenderer{
-		tviewApp:     tviewApp,
-		tablePage:    tview.NewFlex(),
-		filterPanel:  tw.NewBasicFilterPanel("", style),
-		tablePanel:   tablePanel,
-		detailsPanel: tw.NewTextDetailsPanel(style),
-		stop:         stop,
+		Flex:       tview.NewFlex(),
+		tviewApp:   tviewApp,
+		ctx:        ctx,
+		pages:      tview.NewPages(),
+		statusBar:  tview.NewTextView().SetDynamicColors(true).SetText(actionHelp),
+		tablePage:  tablePage,
+		detailPage: NewMergeDetailPage(tviewApp, source, style),
+		stop:       tviewApp.Stop,
 	}
 
-	r.focusRing = tw.NewFocusRing(tviewApp, r.filterPanel, r.tablePanel, r.detailsPanel)
-
 	// tui colors cheat sheet
 	// https://betterterminal.com/terminal-colors
-	r.setupTablePage()
-	r.setupKeyHandlers()
-	r.setupEvents(repo)
-
-	go blockOnCtxDone(ctx, stop)
-
+	r.setupEvents(ctx)
+	r.pages.AddPage(tablePageName, r.tablePage, true, true)
+	r.pages.AddPage(detailPageName, r.detailPage, true, false)
+	r.SetDirection(tview.FlexRow)
+	r.AddItem(r.pages, 0, 1, true)
+	r.AddItem(r.statusBar, 1, 0, false)
 	return r
 }
 
@@ -91,47 +107,31 @@ func blockOnCtxDone(ctx context.Context, stop StopFn) {
 }
 
 func (r *TuiMergesRenderer) Run() error {
-	return r.tviewApp.SetRoot(r.tablePage, true).SetFocus(r.tablePage).Run()
+	return r.tviewApp.SetRoot(r, true).SetFocus(r.tablePage).EnableMouse(true).Run()
 }
 
-// setupTablePage laysout the panels in the page
-func (r *TuiMergesRenderer) setupTablePage() {
-	r.tablePage.SetDirection(tview.FlexRow)
-
-	// row 1
-	r.tablePage.AddItem(r.filterPanel, 3, 0, false)
-
-	// row 2
-	tableRow := tview.NewFlex().SetDirection(tview.FlexColumn)
-	tableRow.AddItem(r.tablePanel, 0, 2, true)                   // Table takes 2/3
-	tableRow.AddItem(r.detailsPanel.GetPrimitive(), 0, 1, false) // Details takes 1/3
-	r.tablePage.AddItem(tableRow, 0, 1, true)
+// Activate gives the focus back to the page in front when the view shows the renderer again.
+func (r *TuiMergesRenderer) Activate() {
+	r.tablePage.Activate()
+	if name, _ := r.pages.GetFrontPage(); name != tablePageName {
+		r.tviewApp.SetFocus(r.pages)
+	}
 }
 
-// setupKeyHandlers configures keyboard navigation
-func (r *TuiMergesRenderer) setupKeyHandlers() {
-	r.tablePage.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEscape:
-			r.stop()
-			return nil
-		case tcell.KeyTab:
-			r.focusRing.Cycle(tw.NextDir)
-			return nil
-		case tcell.KeyBacktab:
-			r.focusRing.Cycle(tw.PrevDir)
-			return nil
+func (r *TuiMergesRenderer) setupEvents(ctx context.Context) {
+	// enter drills down into the merge request, escape comes back to the table
+	r.tablePage.OnSelectedSubscribe(func(record any) {
+		mr, ok := record.(*gitlab.MergeRequestModel)
+		if !ok {
+			return
 		}
-		return event
+		r.pages.SwitchToPage(detailPageName)
+		r.detailPage.Show(ctx, mr)
 	})
-}
-
-func (r *TuiMergesRenderer) setupEvents(repo MergesRepository) {
-	r.tablePanel.OnCellSelectedSubscribe(func(cell tw.CellParams) {
-		r.detailsPanel.ShowDetails(repo.GetRowRecord(cell.Row))
+	r.detailPage.OnCloseSubscribe(func(EmptyT) {
+		r.pages.SwitchToPage(tablePageName)
+		r.tablePage.FocusTable()
 	})
 
-	r.filterPanel.OnChangeSubscribe(func(filterText string) {
-		repo.Filter(filterText)
-	})
+	r.setupActions()
 }
@@ -0,0 +1,37 @@
+package merges
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+)
+
+func TestTuiMergesPageActivate(t *testing.T) {
+	tviewApp := tview.NewApplication()
+	style := tw.NewStyle()
+	repo := NewInMemoryMergesRepository(nil)
+	var err error
+	repo.RecordTable, err = records.NewRecordTable(records.MergeRequestSpec(records.Lookups{}), nil,
+		[]gitlab.MergeRequestModel{{ID: 1, Iid: 11, Title: "one"}})
+	require.NoError(t, err)
+	screen := records.NewScreen(tviewApp, repo, style, tw.NewFocusRing(tviewApp), tviewApp.Stop)
+	r := NewTuiMergesPage(context.Background(), tviewApp, screen, &fakeDetailSource{calls: map[string]int{}}, style)
+
+	r.Activate()
+	require.True(t, screen.HasFocus())
+
+	// the detail page keeps the focus when the page is shown again
+	r.pages.SwitchToPage(detailPageName)
+	r.detailPage.Show(context.Background(), repo.Record(0))
+	screen.Activate()
+	require.False(t, r.detailPage.HasFocus())
+	r.Activate()
+	require.True(t, r.detailPage.HasFocus())
+	require.Equal(t, 1, r.selected().ID)
+}
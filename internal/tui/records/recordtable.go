@@ -0,0 +1,171 @@
+// Package records shows tables of gitlab records in the tui.
+// A RecordTable lays out the records as a Spec and the config say,
+// filters them with a find query and notifies when they change.
+package records
+
+import (
+	"slices"
+
+	"github.com/stalwartgiraffe/cmr/events"
+	"github.com/stalwartgiraffe/cmr/internal/columns"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/find"
+)
+
+type EmptyT = struct{}
+
+// Spec is how a table of T is laid out.
+type Spec[T any] struct {
+	// Table names the layout in config.Tables.
+	Table string
+	// Default is the layout until the table is configured.
+	Default config.TableLayout
+	// Joins are the columns that look up a value by an id of the record, see Join.
+	Joins map[string]columns.ValueFunc[T]
+	// ID identifies a record, see RecordTable.Update.
+	ID func(*T) int
+}
+
+// RecordTable is the records of T laid out in columns and filtered by a find query.
+type RecordTable[T any] struct {
+	spec    Spec[T]
+	table   *columns.Table[T]
+	view    *find.TableView
+	changes events.Event[EmptyT]
+}
+
+// NewRecordTable lays out the records as the tables configure the spec.
+func NewRecordTable[T any](spec Spec[T], tables config.Tables, records []T) (*RecordTable[T], error) {
+	layout, err := columns.NewLayout(tables.Get(spec.Table, spec.Default), spec.Joins)
+	if err != nil {
+		return nil, err
+	}
+	t := &RecordTable[T]{
+		spec:  spec,
+		table: columns.NewTable(layout, records),
+	}
+	t.view = find.NewTableView(t.table)
+	return t, nil
+}
+
+// Filter shows the records that match the find query, see find.Query.
+// A malformed query keeps the records shown and returns the error.
+func (t *RecordTable[T]) Filter(search string) error {
+	if err := t.view.UpdateFind(search); err != nil {
+		return err
+	}
+	t.changed()
+	return nil
+}
+
+// SetRanked shows the best matches of the filter first.
+func (t *RecordTable[T]) SetRanked(ranked bool) {
+	t.view.SetRanked(ranked)
+	t.changed()
+}
+
+func (t *RecordTable[T]) GetRowCount() int {
+	return t.view.GetRowCount()
+}
+
+func (t *RecordTable[T]) GetColumnCount() int {
+	return t.view.GetColumnCount()
+}
+
+func (t *RecordTable[T]) GetColumn(col int) string {
+	return t.view.GetColumn(col)
+}
+
+func (t *RecordTable[T]) GetCell(row int, col int) string {
+	return t.view.GetCell(row, col)
+}
+
+// GetHeader returns the title of the column with how the rows sort by it.
+func (t *RecordTable[T]) GetHeader(col int) string {
+	return t.table.GetHeader(col)
+}
+
+func (t *RecordTable[T]) GetColumnWidth(col int) int {
+	return t.table.GetColumnWidth(col)
+}
+
+// GetMatchSpans returns where the filter matched the cell.
+func (t *RecordTable[T]) GetMatchSpans(row int, col int) []find.Span {
+	return t.view.GetMatchSpans(row, col)
+}
+
+// Record returns the record of the row, nil when there is no such row.
+func (t *RecordTable[T]) Record(row int) *T {
+	if row < 0 || t.view.GetRowCount() <= row {
+		return nil
+	}
+	return &t.table.Records()[t.view.GetRowIndex(row)]
+}
+
+// GetRowRecord returns the record of the row as a *T, or nil.
+func (t *RecordTable[T]) GetRowRecord(row int) any {
+	if r := t.Record(row); r != nil {
+		return r
+	}
+	return nil
+}
+
+// Update replaces the record with the same id, filters it again and notifies the change.
+func (t *RecordTable[T]) Update(record T) bool {
+	records := t.table.Records()
+	i := slices.IndexFunc(records, func(r T) bool { return t.spec.ID(&r) == t.spec.ID(&record) })
+	if i < 0 {
+		return false
+	}
+	records[i] = record
+	t.refresh()
+	return true
+}
+
+// SetRecords replaces the records, such as when they are loaded after the table is shown,
+// filters them again and notifies the change.
+func (t *RecordTable[T]) SetRecords(records []T) {
+	t.table.SetRecords(records)
+	t.refresh()
+}
+
+func (t *RecordTable[T]) SortBy(col int, then bool) {
+	t.table.SortBy(col, then)
+	t.refresh()
+}
+
+func (t *RecordTable[T]) Resize(col int, delta int) {
+	t.table.Resize(col, delta)
+	t.changed()
+}
+
+func (t *RecordTable[T]) Hide(col int) {
+	t.table.Hide(col)
+	t.refresh()
+}
+
+func (t *RecordTable[T]) ShowAll() {
+	t.table.ShowAll()
+	t.refresh()
+}
+
+// Layout returns the layout of the table as the user left it.
+func (t *RecordTable[T]) Layout() config.TableLayout {
+	return t.table.Layout()
+}
+
+// OnChanged is notified when the records shown or their layout change.
+func (t *RecordTable[T]) OnChanged(callback func(EmptyT)) {
+	t.changes.Subscribe(callback)
+}
+
+// refresh finds the filter again in the rows of the new layout.
+func (t *RecordTable[T]) refresh() {
+	// a filter of a hidden column is cleared and all the rows show
+	_ = t.view.Refresh()
+	t.changed()
+}
+
+func (t *RecordTable[T]) changed() {
+	t.changes.Notify(EmptyT{})
+}
@@ -0,0 +1,170 @@
+package records
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/find"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/review"
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+)
+
+func mergeRequests() []gitlab.MergeRequestModel {
+	return []gitlab.MergeRequestModel{
+		{ID: 1, ProjectID: 10, Title: "fix the bug", Author: &gitlab.UserModel{Username: "annie"}},
+		{ID: 2, ProjectID: 20, Title: "add a feature", Author: &gitlab.UserModel{Username: "bob"}},
+	}
+}
+
+func newMergesTable(t *testing.T) *RecordTable[gitlab.MergeRequestModel] {
+	projects := map[int]gitlab.ProjectModel{10: {ID: 10, Name: "cmr"}}
+	table, err := NewRecordTable(MergeRequestSpec(Lookups{Projects: projects}), nil, mergeRequests())
+	require.NoError(t, err)
+	return table
+}
+
+func TestRecordTableJoins(t *testing.T) {
+	table := newMergesTable(t)
+
+	// sorted by -id, an unknown project shows its id
+	require.Equal(t, 2, table.GetRowCount())
+	require.Equal(t, "20", table.GetCell(0, 1))
+	require.Equal(t, "cmr", table.GetCell(1, 1))
+	require.Equal(t, "annie", table.GetCell(1, 2))
+}
+
+func TestRecordTableFilter(t *testing.T) {
+	table := newMergesTable(t)
+	changes := 0
+	table.OnChanged(func(EmptyT) { changes++ })
+
+	require.NoError(t, table.Filter("?title:/feat/"))
+	require.Equal(t, 1, changes)
+	require.Equal(t, 1, table.GetRowCount())
+	require.Equal(t, 2, table.Record(0).ID)
+	require.Nil(t, table.Record(1))
+	require.Nil(t, table.GetRowRecord(1))
+
+	require.Error(t, table.Filter("?title:/(/"))
+	require.Equal(t, 1, changes)
+	require.Equal(t, 1, table.GetRowCount())
+}
+
+func TestRecordTableKeepsHeader(t *testing.T) {
+	table := newMergesTable(t)
+	require.NoError(t, table.Filter("?title:/feat/"))
+	header := tw.NewHeaderTable(table)
+
+	// the header keeps its row above the rows found, the sorted column is marked
+	require.Equal(t, 2, header.GetRowCount())
+	require.Equal(t, 4, header.GetColumnCount())
+	for col, title := range []string{"ID ▼", "ProjectID", "AuthorUsername", "Title"} {
+		require.Equal(t, title, header.GetCell(0, col))
+		require.Nil(t, header.GetMatchSpans(0, col))
+	}
+	require.Equal(t, "2", header.GetCell(1, 0))
+	require.Equal(t, "bob", header.GetCell(1, 2))
+	require.Nil(t, header.GetMatchSpans(1, 2))
+	require.Equal(t, []find.Span{{Start: 6, End: 10}}, header.GetMatchSpans(1, 3))
+
+	content := tw.NewTwoBandTableContent(header)
+	require.Equal(t, "Title", content.GetCell(0, 3).Text)
+	require.Equal(t, "add a [yellow::b]feat[-::-]ure", content.GetCell(1, 3).Text)
+	require.Equal(t, "bob", content.GetCell(1, 2).Text)
+}
+
+func TestRecordTableUpdate(t *testing.T) {
+	table := newMergesTable(t)
+	require.NoError(t, table.Filter("bug"))
+	changes := 0
+	table.OnChanged(func(EmptyT) { changes++ })
+
+	require.False(t, table.Update(gitlab.MergeRequestModel{ID: 3, Title: "bug"}))
+	require.Equal(t, 0, changes)
+
+	require.True(t, table.Update(gitlab.MergeRequestModel{ID: 2, ProjectID: 20, Title: "fix another bug"}))
+	require.Equal(t, 1, changes)
+	require.Equal(t, 2, table.GetRowCount())
+	require.Equal(t, "fix another bug", table.GetCell(0, 3))
+}
+
+func TestRecordTableSetRecords(t *testing.T) {
+	table, err := NewRecordTable(MergeRequestSpec(Lookups{}), nil, []gitlab.MergeRequestModel(nil))
+	require.NoError(t, err)
+	require.NoError(t, table.Filter("?title:bug"))
+	require.Equal(t, 0, table.GetRowCount())
+	changes := 0
+	table.OnChanged(func(EmptyT) { changes++ })
+
+	// the records loaded later are sorted and filtered
+	table.SetRecords(append(mergeRequests(), gitlab.MergeRequestModel{ID: 3, Title: "another bug"}))
+	require.Equal(t, 1, changes)
+	require.Equal(t, 2, table.GetRowCount())
+	require.Equal(t, 3, table.Record(0).ID)
+	require.Equal(t, 1, table.Record(1).ID)
+}
+
+func TestRecordTableClearsFilterOfHiddenColumn(t *testing.T) {
+	table := newMergesTable(t)
+	require.NoError(t, table.Filter("?title:bug"))
+	require.Equal(t, 1, table.GetRowCount())
+
+	table.SortBy(0, false)
+	require.Equal(t, 1, table.Record(0).ID)
+
+	// the filter of the title can not find in the rows without it
+	table.Hide(3)
+	require.Equal(t, 2, table.GetRowCount())
+	require.Equal(t, 1, table.Record(0).ID)
+	require.Equal(t, 2, table.Record(1).ID)
+	require.Nil(t, table.GetMatchSpans(0, 0))
+}
+
+func TestRecordTableLayout(t *testing.T) {
+	table := newMergesTable(t)
+	loaded := table.Layout()
+	changes := 0
+	table.OnChanged(func(EmptyT) { changes++ })
+
+	table.SortBy(0, false)
+	require.Equal(t, 1, changes)
+	require.Equal(t, 1, table.Record(0).ID)
+	require.Equal(t, []string{"id"}, table.Layout().Sort)
+
+	table.Hide(1)
+	require.Equal(t, 3, table.GetColumnCount())
+	table.ShowAll()
+	require.Equal(t, 4, table.GetColumnCount())
+	require.Equal(t, 3, changes)
+	require.NotEqual(t, loaded, table.Layout())
+}
+
+func TestJoin(t *testing.T) {
+	users := map[int]gitlab.UserModel{7: {ID: 7, Username: "annie"}}
+	creator := UserJoin(users, func(p *gitlab.ProjectModel) int { return p.CreatorID })
+
+	require.Equal(t, "annie", creator(&gitlab.ProjectModel{CreatorID: 7}))
+	require.Equal(t, "8", creator(&gitlab.ProjectModel{CreatorID: 8}))
+
+	noUsers := UserJoin[gitlab.ProjectModel](nil, func(p *gitlab.ProjectModel) int { return p.CreatorID })
+	require.Equal(t, "7", noUsers(&gitlab.ProjectModel{CreatorID: 7}))
+}
+
+func TestDashboardSpec(t *testing.T) {
+	projects := map[int]gitlab.ProjectModel{10: {ID: 10, Name: "cmr"}}
+	items := []review.Item{
+		{Rank: 2, ID: 1, ProjectID: 10, Section: review.Authored, Title: "fix the bug"},
+		{Rank: 1, ID: 2, ProjectID: 20, Section: review.Reviewing, Title: "add a feature", MyTurn: true},
+	}
+	table, err := NewRecordTable(DashboardSpec(Lookups{Projects: projects}, config.DashboardTable), nil, items)
+	require.NoError(t, err)
+
+	// sorted by rank, the ref and url are hidden
+	require.Equal(t, 8, table.GetColumnCount())
+	require.Equal(t, "reviewing", table.GetCell(0, 0))
+	require.Equal(t, "20", table.GetCell(0, 4))
+	require.Equal(t, "cmr", table.GetCell(1, 4))
+}
@@ -0,0 +1,196 @@
+package records
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/events"
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+)
+
+// Source is the table of records a screen shows, such as a RecordTable.
+type Source interface {
+	tw.ColumnTable
+	tw.LayoutTable
+
+	// Filter shows the records that match the find query, see find.Query.
+	Filter(string) error
+	// SetRanked shows the best matches of the filter first.
+	SetRanked(bool)
+
+	GetRowRecord(row int) any
+	OnChanged(func(EmptyT))
+}
+
+// ScreenHelp describes the keys of a screen for a status bar.
+const ScreenHelp = "[gray]Tab[white] next panel  [gray]Ctrl-R[white] rank  " + tw.LayoutHelp
+
+// Screen is the filter, the table and the details of the selected record of a source.
+//
+//	| filter          |         |
+//	| table 2/3       | details |
+type Screen struct {
+	*tview.Flex
+
+	tviewApp *tview.Application
+	source   Source
+
+	filterPanel  *tw.BasicFilterPanel
+	tablePanel   *tw.TablePanel
+	detailsPanel *tw.TextDetailsPanel
+	focusRing    *tw.FocusRing
+	focused      int // the panel focused when the screen was last active
+	ranked       bool
+
+	onSelected events.Event[any]
+}
+
+// NewScreen shows the source, escape calls stop.
+// The screens of an app share the style and the focus ring, the new screen is active.
+func NewScreen(
+	tviewApp *tview.Application,
+	source Source,
+	style *tw.Style,
+	focusRing *tw.FocusRing,
+	stop tw.StopFunc,
+) *Screen {
+	s := &Screen{
+		Flex:     tview.NewFlex(),
+		tviewApp: tviewApp,
+		source:   source,
+		tablePanel: tw.NewTablePanel(
+			tw.NewTwoBandTableContent(tw.NewHeaderTable(source)),
+			stop,
+			style,
+		),
+		filterPanel:  tw.NewBasicFilterPanel("", style),
+		detailsPanel: tw.NewTextDetailsPanel(style),
+		focusRing:    focusRing,
+		focused:      tablePanelIndex,
+	}
+	s.setupLayout()
+	s.setupKeyHandlers(stop)
+	s.setupEvents()
+	s.Activate()
+	return s
+}
+
+// the panels of the focus ring
+const tablePanelIndex = 1
+
+// Activate puts the panels of the screen in the focus ring and focuses the one that was.
+func (s *Screen) Activate() {
+	s.focusRing.SetPanels(s.focused, s.filterPanel, s.tablePanel, s.detailsPanel)
+}
+
+// Deactivate remembers the focused panel for when the screen is active again.
+func (s *Screen) Deactivate() {
+	s.focused = s.focusRing.Focused()
+}
+
+func (s *Screen) setupLayout() {
+	s.SetDirection(tview.FlexRow)
+
+	// row 1
+	s.AddItem(s.filterPanel, 3, 0, false)
+
+	// row 2
+	tableRow := tview.NewFlex().SetDirection(tview.FlexColumn)
+	tableRow.AddItem(s.tablePanel, 0, 2, true)                   // Table takes 2/3
+	tableRow.AddItem(s.detailsPanel.GetPrimitive(), 0, 1, false) // Details takes 1/3
+	s.AddItem(tableRow, 0, 1, true)
+}
+
+func (s *Screen) setupKeyHandlers(stop tw.StopFunc) {
+	s.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyCtrlR:
+			s.ranked = !s.ranked
+			s.source.SetRanked(s.ranked)
+			return nil
+		case tcell.KeyEscape:
+			stop()
+			return nil
+		case tcell.KeyTab:
+			s.focusRing.Cycle(tw.NextDir)
+			return nil
+		case tcell.KeyBacktab:
+			s.focusRing.Cycle(tw.PrevDir)
+			return nil
+		}
+		return event
+	})
+}
+
+func (s *Screen) setupEvents() {
+	s.tablePanel.OnCellChangedSubscribe(func(cell tw.CellParams) {
+		s.detailsPanel.ShowDetails(s.rowRecord(cell.Row))
+	})
+
+	// enter on a record selects it, enter on the header sorts by its column
+	s.tablePanel.OnCellSelectedSubscribe(func(cell tw.CellParams) {
+		if cell.Row == 0 {
+			s.source.SortBy(cell.Col, false)
+			return
+		}
+		if record := s.rowRecord(cell.Row); record != nil {
+			s.onSelected.Notify(record)
+		}
+	})
+
+	s.filterPanel.OnChangeSubscribe(func(filterText string) {
+		s.filterPanel.SetError(s.source.Filter(filterText))
+	})
+
+	// the record of the selected row may have changed under the selection
+	s.source.OnChanged(func(EmptyT) {
+		s.detailsPanel.ShowDetails(s.Selected())
+	})
+}
+
+// rowRecord returns the record of the row of the table panel, its first row is the header.
+func (s *Screen) rowRecord(row int) any {
+	return s.source.GetRowRecord(row - 1)
+}
+
+// Selected returns the record of the selected row, or nil.
+func (s *Screen) Selected() any {
+	row, _ := s.tablePanel.GetSelection()
+	return s.rowRecord(row)
+}
+
+// OnSelectedSubscribe is notified with the record the user presses enter on.
+func (s *Screen) OnSelectedSubscribe(fn func(any)) {
+	s.onSelected.Subscribe(fn)
+}
+
+// FilterHasFocus is true while the user types a filter.
+func (s *Screen) FilterHasFocus() bool {
+	return s.filterPanel.HasFocus()
+}
+
+// SetFilter types the find query into the filter, which filters the source.
+func (s *Screen) SetFilter(text string) {
+	s.filterPanel.SetFilter(text)
+}
+
+// SetRanked shows the best matches of the filter first, Ctrl-R toggles it.
+func (s *Screen) SetRanked(ranked bool) {
+	s.ranked = ranked
+	s.source.SetRanked(ranked)
+}
+
+// FocusTable gives the focus to the table.
+func (s *Screen) FocusTable() {
+	s.focusRing.Focus(tablePanelIndex)
+}
+
+// Filter returns the find query typed into the filter.
+func (s *Screen) Filter() string {
+	return s.filterPanel.GetFilter()
+}
+
+// Run shows the screen as the root of the app until the user escapes.
+func (s *Screen) Run() error {
+	return s.tviewApp.SetRoot(s, true).SetFocus(s).EnableMouse(true).Run()
+}
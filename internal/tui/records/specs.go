@@ -0,0 +1,202 @@
+package records
+
+import (
+	"fmt"
+
+	"github.com/stalwartgiraffe/cmr/internal/columns"
+	"github.com/stalwartgiraffe/cmr/internal/config"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/review"
+)
+
+// Lookups are the records the joins look up by id, a nil map shows the ids.
+type Lookups struct {
+	Projects map[int]gitlab.ProjectModel
+	Users    map[int]gitlab.UserModel
+}
+
+// Join is a column of a value of the record V that the key of T looks up,
+// or of the key when it is not found.
+func Join[T any, K comparable, V any](lookup map[K]V, key func(*T) K, value func(*V) any) columns.ValueFunc[T] {
+	return func(record *T) any {
+		k := key(record)
+		if v, ok := lookup[k]; ok {
+			return value(&v)
+		}
+		return fmt.Sprint(k)
+	}
+}
+
+// ProjectJoin is the name of the project of the id.
+func ProjectJoin[T any](projects map[int]gitlab.ProjectModel, id func(*T) int) columns.ValueFunc[T] {
+	return Join(projects, id, func(p *gitlab.ProjectModel) any { return p.Name })
+}
+
+// UserJoin is the username of the user of the id.
+func UserJoin[T any](users map[int]gitlab.UserModel, id func(*T) int) columns.ValueFunc[T] {
+	return Join(users, id, func(u *gitlab.UserModel) any { return u.Username })
+}
+
+// MergeRequestSpec lays out merge requests, the project column joins the project.
+func MergeRequestSpec(lookups Lookups) Spec[gitlab.MergeRequestModel] {
+	return Spec[gitlab.MergeRequestModel]{
+		Table: config.MergeRequestsTable,
+		Default: config.TableLayout{
+			Columns: []config.Column{
+				{Field: "id", Title: "ID"},
+				{Field: "project", Title: "ProjectID"},
+				{Field: "author.username", Title: "AuthorUsername"},
+				{Field: "title", Title: "Title"},
+			},
+			Sort: []string{"-id"},
+		},
+		Joins: map[string]columns.ValueFunc[gitlab.MergeRequestModel]{
+			"project": ProjectJoin(lookups.Projects, func(m *gitlab.MergeRequestModel) int { return m.ProjectID }),
+		},
+		ID: func(m *gitlab.MergeRequestModel) int { return m.ID },
+	}
+}
+
+// EventSpec lays out events, the project and author columns join the project and user.
+func EventSpec(lookups Lookups) Spec[gitlab.EventModel] {
+	return Spec[gitlab.EventModel]{
+		Table: config.EventsTable,
+		Default: config.TableLayout{
+			Columns: []config.Column{
+				{Field: "id", Title: "ID"},
+				{Field: "project", Title: "ProjectID"},
+				{Field: "author_username", Title: "AuthorUsername"},
+				{Field: "title", Title: "Title"},
+				{Field: "action_name", Title: "ActionName"},
+				{Field: "target_type", Title: "TargetType"},
+				{Field: "target_title", Title: "TargetTitle"},
+				{Field: "created_at", Title: "CreatedAt"},
+				{Field: "data", Title: "Data"},
+				{Field: "imported", Title: "Imported"},
+				{Field: "imported_from", Title: "ImportedFrom"},
+				{Field: "push_data", Title: "PushData"},
+				{Field: "note", Title: "Note"},
+			},
+			Sort: []string{"-id"},
+		},
+		Joins: map[string]columns.ValueFunc[gitlab.EventModel]{
+			"project": ProjectJoin(lookups.Projects, func(e *gitlab.EventModel) int { return e.ProjectID }),
+			"author":  UserJoin(lookups.Users, func(e *gitlab.EventModel) int { return e.AuthorID }),
+		},
+		ID: func(e *gitlab.EventModel) int { return e.ID },
+	}
+}
+
+// ProjectSpec lays out projects, the creator column joins the user.
+func ProjectSpec(lookups Lookups) Spec[gitlab.ProjectModel] {
+	return Spec[gitlab.ProjectModel]{
+		Table: config.ProjectsTable,
+		Default: config.TableLayout{
+			Columns: []config.Column{
+				{Field: "id", Title: "ID"},
+				{Field: "path_with_namespace", Title: "Path"},
+				{Field: "default_branch", Title: "Branch"},
+				{Field: "topics", Title: "Topics"},
+				{Field: "last_activity_at", Title: "Active"},
+				{Field: "archived", Title: "Archived", Hidden: true},
+				{Field: "creator", Title: "Creator", Hidden: true},
+			},
+			Sort: []string{"path_with_namespace"},
+		},
+		Joins: map[string]columns.ValueFunc[gitlab.ProjectModel]{
+			"creator": UserJoin(lookups.Users, func(p *gitlab.ProjectModel) int { return p.CreatorID }),
+		},
+		ID: func(p *gitlab.ProjectModel) int { return p.ID },
+	}
+}
+
+// GroupSpec lays out groups.
+func GroupSpec() Spec[gitlab.GroupModel] {
+	return Spec[gitlab.GroupModel]{
+		Table: config.GroupsTable,
+		Default: config.TableLayout{
+			Columns: []config.Column{
+				{Field: "id", Title: "ID"},
+				{Field: "full_path", Title: "Path"},
+				{Field: "name", Title: "Name"},
+				{Field: "visibility", Title: "Visibility"},
+				{Field: "description", Title: "Description"},
+			},
+			Sort: []string{"full_path"},
+		},
+		ID: func(g *gitlab.GroupModel) int { return g.ID },
+	}
+}
+
+// PipelineSpec lays out pipelines, the project column joins the project.
+func PipelineSpec(lookups Lookups) Spec[gitlab.PipelineModel] {
+	return Spec[gitlab.PipelineModel]{
+		Table: config.PipelinesTable,
+		Default: config.TableLayout{
+			Columns: []config.Column{
+				{Field: "id", Title: "ID"},
+				{Field: "project", Title: "Project"},
+				{Field: "ref", Title: "Ref"},
+				{Field: "status", Title: "Status"},
+				{Field: "source", Title: "Source"},
+				{Field: "updated_at", Title: "Updated"},
+			},
+			Sort: []string{"-updated_at"},
+		},
+		Joins: map[string]columns.ValueFunc[gitlab.PipelineModel]{
+			"project": ProjectJoin(lookups.Projects, func(p *gitlab.PipelineModel) int { return p.ProjectID }),
+		},
+		ID: func(p *gitlab.PipelineModel) int { return p.ID },
+	}
+}
+
+// DashboardSpec lays out the dashboard of the merge requests most urgent first,
+// the project column joins the project.
+func DashboardSpec(lookups Lookups, table string) Spec[review.Item] {
+	return Spec[review.Item]{
+		Table: table,
+		Default: config.TableLayout{
+			Columns: []config.Column{
+				{Field: "section", Title: "Section"},
+				{Field: "state", Title: "State"},
+				{Field: "my_turn", Title: "MyTurn"},
+				{Field: "waited", Title: "Waited"},
+				{Field: "project", Title: "Project"},
+				{Field: "title", Title: "Title"},
+				{Field: "author", Title: "Author"},
+				{Field: "pipeline", Title: "Pipeline"},
+				{Field: "ref", Title: "Ref", Hidden: true},
+				{Field: "web_url", Title: "URL", Hidden: true},
+			},
+			Sort: []string{"rank"},
+		},
+		Joins: map[string]columns.ValueFunc[review.Item]{
+			"project": ProjectJoin(lookups.Projects, func(i *review.Item) int { return i.ProjectID }),
+		},
+		ID: func(i *review.Item) int { return i.ID },
+	}
+}
+
+// LocalRepo is a project cloned on this machine.
+type LocalRepo struct {
+	ID     int    `json:"id"`
+	Path   string `json:"path"`
+	Dir    string `json:"dir"`
+	Branch string `json:"branch"`
+}
+
+// LocalRepoSpec lays out the local repos.
+func LocalRepoSpec() Spec[LocalRepo] {
+	return Spec[LocalRepo]{
+		Table: config.LocalReposTable,
+		Default: config.TableLayout{
+			Columns: []config.Column{
+				{Field: "path", Title: "Path"},
+				{Field: "branch", Title: "Branch"},
+				{Field: "dir", Title: "Dir"},
+			},
+			Sort: []string{"path"},
+		},
+		ID: func(r *LocalRepo) int { return r.ID },
+	}
+}
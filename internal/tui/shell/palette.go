@@ -0,0 +1,135 @@
+package shell
+
+import (
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+)
+
+// Command is an entry of the palette.
+type Command struct {
+	Name string
+	Run  func()
+}
+
+// Palette finds a command by the words typed into it.
+// Enter runs the selected command, escape closes the palette.
+type Palette struct {
+	*tview.Flex
+
+	input *tview.InputField
+	list  *tview.List
+
+	commands []Command
+	shown    []Command
+
+	onClose func(*Command) // the command to run, nil when the palette was escaped
+}
+
+const paletteWidth = 50
+
+// NewPalette returns the palette centered over the screen, onClose is called
+// with the command the user chose.
+func NewPalette(style *tw.Style, onClose func(*Command)) *Palette {
+	p := &Palette{
+		Flex:    tview.NewFlex(),
+		input:   tview.NewInputField().SetLabel(": "),
+		list:    tview.NewList().ShowSecondaryText(false).SetHighlightFullLine(true),
+		onClose: onClose,
+	}
+	p.input.SetFieldBackgroundColor(style.FocusBackground)
+
+	box := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(p.input, 1, 0, true).
+		AddItem(p.list, 0, 1, false)
+	box.SetBorder(true).SetTitle("Commands")
+
+	// center the box
+	p.AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(box, 12, 0, true).
+			AddItem(nil, 0, 1, false), paletteWidth, 0, true).
+		AddItem(nil, 0, 1, false)
+
+	p.input.SetChangedFunc(p.show)
+	p.input.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyUp, tcell.KeyDown:
+			// the list moves the selection while the input keeps the focus
+			p.list.InputHandler()(event, nil)
+			return nil
+		case tcell.KeyEnter:
+			p.close(p.Selected())
+			return nil
+		case tcell.KeyEscape:
+			p.close(nil)
+			return nil
+		}
+		return event
+	})
+	p.list.SetSelectedFunc(func(i int, _ string, _ string, _ rune) {
+		p.close(p.Selected())
+	})
+	return p
+}
+
+// Add adds the command to the palette.
+func (p *Palette) Add(command Command) {
+	p.commands = append(p.commands, command)
+	p.show(p.input.GetText())
+}
+
+// Open clears what was typed and shows all the commands.
+func (p *Palette) Open(tviewApp *tview.Application) {
+	p.input.SetText("")
+	tviewApp.SetFocus(p.input)
+}
+
+// Match returns the commands whose name contains each of the words of text, ignoring case.
+func (p *Palette) Match(text string) []Command {
+	words := strings.Fields(strings.ToLower(text))
+	var matched []Command
+	for _, c := range p.commands {
+		name := strings.ToLower(c.Name)
+		if allContained(name, words) {
+			matched = append(matched, c)
+		}
+	}
+	return matched
+}
+
+func allContained(name string, words []string) bool {
+	for _, w := range words {
+		if !strings.Contains(name, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// Selected returns the selected command, or nil when none matched.
+func (p *Palette) Selected() *Command {
+	i := p.list.GetCurrentItem()
+	if i < 0 || len(p.shown) <= i {
+		return nil
+	}
+	return &p.shown[i]
+}
+
+func (p *Palette) show(text string) {
+	p.shown = p.Match(text)
+	p.list.Clear()
+	for _, c := range p.shown {
+		p.list.AddItem(c.Name, "", 0, nil)
+	}
+}
+
+func (p *Palette) close(command *Command) {
+	if p.onClose != nil {
+		p.onClose(command)
+	}
+}
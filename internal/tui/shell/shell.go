@@ -0,0 +1,257 @@
+// Package shell shows the screens of the tui as tabs of one app.
+// The screens share the style and the focus ring, and keep their filter
+// and layout while the user is on another tab.
+//
+//	| 1 my MRs  2 to review  3 activity ... |
+//	| the screen of the selected tab        |
+//	| help                       sync state |
+package shell
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+)
+
+// Help describes the keys of the shell for the status bar.
+const Help = "[gray]1-9[white] tab  [gray]:[white] commands  " + records.ScreenHelp
+
+const (
+	shellPageName   = "shell"
+	palettePageName = "palette"
+)
+
+// Tab is a named screen of the shell.
+type Tab struct {
+	Name   string
+	Screen *records.Screen
+	Page   Page // the screen, or a page around it such as a drill down from it
+}
+
+// Page is what a tab shows, see NewPage.
+type Page interface {
+	tview.Primitive
+
+	// Activate gives the focus back to the page when its tab is selected.
+	Activate()
+}
+
+type Shell struct {
+	*tview.Pages // the shell with the palette over it
+
+	tviewApp  *tview.Application
+	style     *tw.Style
+	focusRing *tw.FocusRing
+	stop      tw.StopFunc
+
+	tabBar    *tview.TextView
+	screens   *tview.Pages
+	help      *tview.TextView
+	syncState *tview.TextView
+	palette   *Palette
+
+	tabs    []Tab
+	current int
+}
+
+// NewShell returns a shell without tabs, escape stops the app.
+func NewShell(tviewApp *tview.Application) *Shell {
+	s := &Shell{
+		Pages:     tview.NewPages(),
+		tviewApp:  tviewApp,
+		style:     tw.NewStyle(),
+		focusRing: tw.NewFocusRing(tviewApp),
+		stop:      tviewApp.Stop,
+		tabBar:    tview.NewTextView().SetDynamicColors(true).SetRegions(true).SetWrap(false),
+		screens:   tview.NewPages(),
+		help:      tview.NewTextView().SetDynamicColors(true).SetText(Help),
+		syncState: tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignRight),
+	}
+	s.palette = NewPalette(s.style, s.closePalette)
+	s.setupLayout()
+	s.setupKeyHandlers()
+
+	// a click on a tab selects it
+	s.tabBar.SetHighlightedFunc(func(added, removed, remaining []string) {
+		if len(added) == 0 {
+			return
+		}
+		if i, err := strconv.Atoi(added[0]); err == nil && i != s.current {
+			s.Select(i)
+		}
+	})
+	return s
+}
+
+func (s *Shell) setupLayout() {
+	statusBar := tview.NewFlex().
+		AddItem(s.help, 0, 2, false).
+		AddItem(s.syncState, 0, 1, false)
+
+	main := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(s.tabBar, 1, 0, false).
+		AddItem(s.screens, 0, 1, true).
+		AddItem(statusBar, 1, 0, false)
+
+	s.AddPage(shellPageName, main, true, true)
+	s.AddPage(palettePageName, s.palette, true, false)
+}
+
+func (s *Shell) setupKeyHandlers() {
+	s.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if s.paletteIsOpen() {
+			return event
+		}
+		if event.Key() == tcell.KeyCtrlP {
+			s.OpenPalette()
+			return nil
+		}
+		// the keys type into the filter or a text field while it has the focus
+		if event.Key() != tcell.KeyRune || s.typing() {
+			return event
+		}
+		switch r := event.Rune(); {
+		case r == ':':
+			s.OpenPalette()
+			return nil
+		case '1' <= r && r <= '9':
+			s.Select(int(r - '1'))
+			return nil
+		}
+		return event
+	})
+}
+
+// Style is shared by the screens of the shell.
+func (s *Shell) Style() *tw.Style {
+	return s.style
+}
+
+// NewScreen adds a tab that shows the source, and a palette command to go to it.
+func (s *Shell) NewScreen(name string, source records.Source) *records.Screen {
+	return s.NewPage(name, source, func(screen *records.Screen) Page { return screen })
+}
+
+// NewPage adds a tab that shows the page that page puts around the screen of the source,
+// and a palette command to go to it.
+func (s *Shell) NewPage(name string, source records.Source, page func(*records.Screen) Page) *records.Screen {
+	if current := s.Screen(); current != nil {
+		current.Deactivate()
+	}
+	// the new screen takes the focus ring until the selected tab has it back
+	screen := records.NewScreen(s.tviewApp, source, s.style, s.focusRing, s.stop)
+	screen.Deactivate()
+
+	i := len(s.tabs)
+	tab := Tab{Name: name, Screen: screen, Page: page(screen)}
+	s.tabs = append(s.tabs, tab)
+	s.screens.AddPage(name, tab.Page, true, false)
+	s.AddCommand(Command{Name: "go to " + name, Run: func() { s.Select(i) }})
+	s.tabBar.SetText(s.tabText())
+	s.Select(s.current)
+	return screen
+}
+
+func (s *Shell) tabText() string {
+	var b strings.Builder
+	for i, tab := range s.tabs {
+		fmt.Fprintf(&b, `["%d"] [::b]%d[::-] %s [""] `, i, i+1, tview.Escape(tab.Name))
+	}
+	return b.String()
+}
+
+// AddCommand adds the command to the palette.
+func (s *Shell) AddCommand(command Command) {
+	s.palette.Add(command)
+}
+
+// Tabs returns the tabs in the order they were added.
+func (s *Shell) Tabs() []Tab {
+	return s.tabs
+}
+
+// Current returns the index of the selected tab.
+func (s *Shell) Current() int {
+	return s.current
+}
+
+// Screen returns the screen of the selected tab, or nil before a tab is added.
+func (s *Shell) Screen() *records.Screen {
+	if len(s.tabs) == 0 {
+		return nil
+	}
+	return s.tabs[s.current].Screen
+}
+
+// Select shows the tab at index i, the tab left keeps its focused panel and filter.
+func (s *Shell) Select(i int) {
+	if i < 0 || len(s.tabs) <= i {
+		return
+	}
+	if i != s.current {
+		s.tabs[s.current].Screen.Deactivate()
+	}
+	s.current = i
+	tab := s.tabs[i]
+	s.screens.SwitchToPage(tab.Name)
+	tab.Page.Activate()
+	s.tabBar.Highlight(strconv.Itoa(i))
+}
+
+// SetSyncState shows the state of the sync in the status bar, see SyncState.
+func (s *Shell) SetSyncState(text string) {
+	s.syncState.SetText(text)
+}
+
+// OpenPalette shows the palette over the screen.
+func (s *Shell) OpenPalette() {
+	if screen := s.Screen(); screen != nil {
+		screen.Deactivate()
+	}
+	s.ShowPage(palettePageName)
+	s.palette.Open(s.tviewApp)
+}
+
+func (s *Shell) closePalette(command *Command) {
+	s.HidePage(palettePageName)
+	s.activate()
+	if command != nil {
+		command.Run()
+	}
+}
+
+func (s *Shell) paletteIsOpen() bool {
+	name, _ := s.GetFrontPage()
+	return name == palettePageName
+}
+
+// typing is true while the filter or a text field of a page has the focus.
+func (s *Shell) typing() bool {
+	switch s.tviewApp.GetFocus().(type) {
+	case *tview.InputField, *tview.TextArea:
+		return true
+	}
+	screen := s.Screen()
+	return screen != nil && screen.FilterHasFocus()
+}
+
+// activate gives the focus back to the page of the selected tab.
+func (s *Shell) activate() {
+	if len(s.tabs) != 0 {
+		s.tabs[s.current].Page.Activate()
+	}
+}
+
+// Run shows the shell until the user escapes or stop is called.
+func (s *Shell) Run() error {
+	root := s.tviewApp.SetRoot(s, true)
+	// the root took the focus
+	s.activate()
+	return root.EnableMouse(true).Run()
+}
@@ -0,0 +1,190 @@
+package shell
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/daemon"
+	"github.com/stalwartgiraffe/cmr/internal/gitlab"
+	"github.com/stalwartgiraffe/cmr/internal/tui/records"
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+func newShell(t *testing.T) (*tview.Application, *Shell) {
+	app := tview.NewApplication()
+	s := NewShell(app)
+	for _, name := range []string{"projects", "local repos"} {
+		table, err := records.NewRecordTable(records.ProjectSpec(records.Lookups{}), nil, []gitlab.ProjectModel{
+			{ID: 1, PathWithNamespace: "tools/cmr"},
+			{ID: 2, PathWithNamespace: "tools/lint"},
+		})
+		require.NoError(t, err)
+		s.NewScreen(name, table)
+	}
+	return app, s
+}
+
+func press(app *tview.Application, s *Shell, event *tcell.EventKey) {
+	s.InputHandler()(event, func(p tview.Primitive) { app.SetFocus(p) })
+}
+
+func TestShellTabs(t *testing.T) {
+	app, s := newShell(t)
+	require.Len(t, s.Tabs(), 2)
+	require.Equal(t, 0, s.Current())
+	require.Equal(t, s.Tabs()[0].Screen, s.Screen())
+
+	s.Screen().SetFilter("lint")
+	press(app, s, tcell.NewEventKey(tcell.KeyRune, '2', tcell.ModNone))
+	require.Equal(t, 1, s.Current())
+	require.Equal(t, "", s.Screen().Filter())
+
+	// the tab keeps its filter
+	s.Select(0)
+	require.Equal(t, "lint", s.Screen().Filter())
+
+	// out of range is ignored
+	press(app, s, tcell.NewEventKey(tcell.KeyRune, '9', tcell.ModNone))
+	require.Equal(t, 0, s.Current())
+}
+
+// formPage is a screen with a text field over it, such as a comment form.
+type formPage struct {
+	*tview.Flex
+	screen    *records.Screen
+	activated int
+}
+
+func (p *formPage) Activate() {
+	p.activated++
+	p.screen.Activate()
+}
+
+func TestShellPage(t *testing.T) {
+	app, s := newShell(t)
+	table, err := records.NewRecordTable(records.ProjectSpec(records.Lookups{}), nil, []gitlab.ProjectModel(nil))
+	require.NoError(t, err)
+	form := tview.NewTextArea()
+	var page *formPage
+	screen := s.NewPage("form", table, func(screen *records.Screen) Page {
+		page = &formPage{Flex: tview.NewFlex().AddItem(form, 1, 0, false).AddItem(screen, 0, 1, true), screen: screen}
+		return page
+	})
+	require.Equal(t, screen, s.Tabs()[2].Screen)
+	require.Equal(t, Page(page), s.Tabs()[2].Page)
+
+	press(app, s, tcell.NewEventKey(tcell.KeyRune, '3', tcell.ModNone))
+	require.Equal(t, 2, s.Current())
+	require.Equal(t, 1, page.activated)
+
+	// the digits type into the text field
+	app.SetFocus(form)
+	press(app, s, tcell.NewEventKey(tcell.KeyRune, '1', tcell.ModNone))
+	require.Equal(t, 2, s.Current())
+	require.Equal(t, "1", form.GetText())
+}
+
+func TestShellPalette(t *testing.T) {
+	app, s := newShell(t)
+	ran := 0
+	s.AddCommand(Command{Name: "quit", Run: func() { ran++ }})
+
+	press(app, s, tcell.NewEventKey(tcell.KeyCtrlP, 0, tcell.ModCtrl))
+	require.True(t, s.paletteIsOpen())
+
+	for _, r := range "local" {
+		press(app, s, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	press(app, s, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	require.False(t, s.paletteIsOpen())
+	require.Equal(t, 1, s.Current())
+
+	press(app, s, tcell.NewEventKey(tcell.KeyRune, ':', tcell.ModNone))
+	require.True(t, s.paletteIsOpen())
+
+	// the digits type into the palette
+	for _, r := range "go 1 " {
+		press(app, s, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+	}
+	require.Equal(t, 1, s.Current())
+	require.Empty(t, s.palette.shown)
+
+	press(app, s, tcell.NewEventKey(tcell.KeyEscape, 0, tcell.ModNone))
+	require.False(t, s.paletteIsOpen())
+	require.Equal(t, 1, s.Current())
+	require.Equal(t, 0, ran)
+}
+
+func TestPaletteMatch(t *testing.T) {
+	p := NewPalette(NewShell(tview.NewApplication()).Style(), nil)
+	for _, name := range []string{"go to my MRs", "go to projects", "quit"} {
+		p.Add(Command{Name: name})
+	}
+
+	names := func(commands []Command) []string {
+		var n []string
+		for _, c := range commands {
+			n = append(n, c.Name)
+		}
+		return n
+	}
+	require.Equal(t, []string{"go to my MRs", "go to projects", "quit"}, names(p.Match("")))
+	require.Equal(t, []string{"go to my MRs"}, names(p.Match("mrs GO")))
+	require.Equal(t, []string{"go to projects"}, names(p.Match("to pro")))
+	require.Empty(t, p.Match("go quit"))
+	require.Equal(t, "go to my MRs", p.Selected().Name)
+}
+
+func TestSyncState(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2026, 10, 18, hour, 30, 0, 0, time.UTC) }
+	clockAt := func(hour int) string { return at(hour).Local().Format(time.TimeOnly) }
+
+	tests := []struct {
+		name     string
+		status   *daemon.Status
+		err      error
+		expected string
+	}{
+		{
+			name:     "never ran",
+			err:      withstack.Errorf("%w", fs.ErrNotExist),
+			expected: "sync never ran",
+		},
+		{
+			name:     "unreadable",
+			err:      errors.New("bad [yaml]"),
+			expected: "[red]sync status: bad [yaml[][-]",
+		},
+		{
+			name:     "running",
+			status:   &daemon.Status{State: daemon.Running, LastStart: at(9)},
+			expected: "syncing since " + clockAt(9),
+		},
+		{
+			name:     "waiting",
+			status:   &daemon.Status{State: daemon.Waiting, LastEnd: at(9), NextRun: at(10)},
+			expected: "synced " + clockAt(9) + ", next " + clockAt(10),
+		},
+		{
+			name: "failed jobs",
+			status: &daemon.Status{State: daemon.Stopped, Jobs: map[string]*daemon.JobStatus{
+				"projects": {LastError: "timeout"},
+				"events":   {LastError: "401"},
+				"merges":   {},
+			}},
+			expected: "sync stopped, last never [red]failed events, projects[-]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, SyncState(tt.status, tt.err))
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package shell
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/internal/daemon"
+)
+
+// SyncState describes the last sync for the status bar,
+// err is why the status could not be read.
+func SyncState(status *daemon.Status, err error) string {
+	if errors.Is(err, fs.ErrNotExist) {
+		return "sync never ran"
+	}
+	if err != nil {
+		return fmt.Sprintf("[red]sync status: %s[-]", tview.Escape(err.Error()))
+	}
+
+	var state string
+	switch status.State {
+	case daemon.Running:
+		state = "syncing since " + clock(status.LastStart)
+	case daemon.Waiting:
+		state = "synced " + clock(status.LastEnd) + ", next " + clock(status.NextRun)
+	default:
+		state = fmt.Sprintf("sync %s, last %s", status.State, clock(status.LastEnd))
+	}
+	if failed := failedJobs(status); 0 < len(failed) {
+		return fmt.Sprintf("%s [red]failed %s[-]", state, strings.Join(failed, ", "))
+	}
+	return state
+}
+
+func failedJobs(status *daemon.Status) []string {
+	var failed []string
+	for _, name := range slices.Sorted(maps.Keys(status.Jobs)) {
+		if status.Jobs[name].LastError != "" {
+			failed = append(failed, name)
+		}
+	}
+	return failed
+}
+
+func clock(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return t.Local().Format(time.TimeOnly)
+}
+
+// WatchSync shows the sync state that read returns in the status bar every interval until ctx is done.
+func (s *Shell) WatchSync(ctx context.Context, read func() (*daemon.Status, error), every time.Duration) {
+	s.SetSyncState(SyncState(read()))
+	go func() {
+		ticker := time.NewTicker(every)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				state := SyncState(read())
+				s.tviewApp.QueueUpdateDraw(func() { s.SetSyncState(state) })
+			}
+		}
+	}()
+}
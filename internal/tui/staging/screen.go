@@ -0,0 +1,355 @@
+// Package staging shows the changes of a repo in two panes and moves
+// whole files or single hunks between the worktree and the staging index.
+//
+//	| unstaged              | staged              |
+//	| the diff of the focused file, by hunk       |
+//	| help                                status  |
+package staging
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+	tw "github.com/stalwartgiraffe/cmr/internal/tviewwrapper"
+)
+
+// Help describes the keys of the screen for the status bar.
+const Help = "[gray]Tab[white] pane  [gray]Space[white] move file  [gray]n/p[white] hunk  " +
+	"[gray]h[white] move hunk  [gray]u[white] unstage all  [gray]r[white] revert file  [gray]d[white] revert hunk  " +
+	"[gray]Enter[white] commit  [gray]Esc[white] cancel"
+
+const (
+	screenPageName = "staging"
+	modalPageName  = "modal"
+)
+
+// the panes of the focus ring
+const (
+	unstagedPane = iota
+	stagedPane
+)
+
+// Screen is the staging screen of a repo.
+type Screen struct {
+	*tview.Pages // the screen with the confirmations over it
+
+	tviewApp  *tview.Application
+	staging   *gitutil.Staging
+	focusRing *tw.FocusRing
+
+	unstaged *tw.ListPanel
+	staged   *tw.ListPanel
+	diff     *tw.TextDetailsPanel
+	status   *tview.TextView
+
+	unstagedFiles []gitutil.FileChange
+	stagedFiles   []gitutil.FileChange
+	hunks         []gitutil.Hunk
+	hunk          int
+
+	modalReturn tview.Primitive
+	filling     bool
+	done        bool
+}
+
+// NewScreen returns the staging screen of the changes of the repo.
+func NewScreen(tviewApp *tview.Application, staging *gitutil.Staging) *Screen {
+	style := tw.NewStyle()
+	s := &Screen{
+		Pages:    tview.NewPages(),
+		tviewApp: tviewApp,
+		staging:  staging,
+		unstaged: tw.NewListPanel("Unstaged", style),
+		staged:   tw.NewListPanel("Staged", style),
+		diff:     tw.NewTextDetailsPanel(style),
+		status:   tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignRight),
+	}
+	s.diff.SetTitle("Diff")
+	s.diff.SetWordWrap(false)
+	s.diff.SetRegions(true)
+	s.focusRing = tw.NewFocusRing(tviewApp, s.unstaged, s.staged)
+
+	panes := tview.NewFlex().
+		AddItem(s.unstaged, 0, 1, true).
+		AddItem(s.staged, 0, 1, false)
+	footer := tview.NewFlex().
+		AddItem(tview.NewTextView().SetDynamicColors(true).SetText(Help), 0, 3, false).
+		AddItem(s.status, 0, 1, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(panes, 0, 1, true).
+		AddItem(s.diff, 0, 2, false).
+		AddItem(footer, 1, 0, false)
+	s.AddPage(screenPageName, layout, true, true)
+
+	// the list calls back before its current item changes
+	onChanged := func(pane int) func(int, string, string, rune) {
+		return func(i int, _ string, _ string, _ rune) {
+			if !s.filling && s.focusRing.Focused() == pane {
+				s.showFileDiff(pane, i)
+			}
+		}
+	}
+	s.unstaged.SetChangedFunc(onChanged(unstagedPane))
+	s.staged.SetChangedFunc(onChanged(stagedPane))
+	s.setupKeys()
+	s.focusRing.Focus(unstagedPane)
+	s.refresh()
+	return s
+}
+
+// Run shows the screen until the user commits or cancels, it returns whether the user commits.
+func (s *Screen) Run() (bool, error) {
+	if err := s.tviewApp.SetRoot(s, true).EnableMouse(true).Run(); err != nil {
+		return false, err
+	}
+	return s.done, nil
+}
+
+// Files returns the unstaged and the staged files as shown.
+func (s *Screen) Files() (unstaged []gitutil.FileChange, staged []gitutil.FileChange) {
+	return s.unstagedFiles, s.stagedFiles
+}
+
+func (s *Screen) setupKeys() {
+	s.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if s.HasPage(modalPageName) {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyTab:
+			s.focusRing.Cycle(tw.NextDir)
+			s.showDiff()
+		case tcell.KeyBacktab:
+			s.focusRing.Cycle(tw.PrevDir)
+			s.showDiff()
+		case tcell.KeyEnter:
+			s.done = true
+			s.tviewApp.Stop()
+		case tcell.KeyEscape:
+			s.tviewApp.Stop()
+		case tcell.KeyRune:
+			return s.onRune(event)
+		default:
+			return event
+		}
+		return nil
+	})
+}
+
+func (s *Screen) onRune(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Rune() {
+	case ' ':
+		s.moveFile()
+	case 'n':
+		s.selectHunk(s.hunk + 1)
+	case 'p':
+		s.selectHunk(s.hunk - 1)
+	case 'h':
+		s.moveHunk()
+	case 'u':
+		if 0 < len(s.stagedFiles) {
+			s.confirm(fmt.Sprintf("Unstage all %d staged files?", len(s.stagedFiles)), s.unstageAll)
+		}
+	case 'r':
+		if path, ok := s.focusedFile(unstagedPane); ok {
+			s.confirm("Discard the changes to "+path+"?", func() error { return s.staging.RevertFile(path) })
+		}
+	case 'd':
+		if path, ok := s.focusedFile(unstagedPane); ok && s.hunk < len(s.hunks) {
+			hunk := s.hunks[s.hunk]
+			s.confirm("Discard the hunk "+hunk.Header()+" of "+path+"?", func() error { return s.staging.RevertHunk(path, hunk) })
+		}
+	case 'q':
+		s.tviewApp.Stop()
+	default:
+		return event
+	}
+	return nil
+}
+
+// focusedFile returns the selected file of the pane when the pane has focus.
+func (s *Screen) focusedFile(pane int) (string, bool) {
+	if s.focusRing.Focused() != pane {
+		return "", false
+	}
+	if pane == stagedPane {
+		return fileAt(s.stagedFiles, s.staged.GetCurrentItem())
+	}
+	return fileAt(s.unstagedFiles, s.unstaged.GetCurrentItem())
+}
+
+func fileAt(files []gitutil.FileChange, i int) (string, bool) {
+	if i < 0 || len(files) <= i {
+		return "", false
+	}
+	return files[i].Path, true
+}
+
+// moveFile stages the focused unstaged file or unstages the focused staged file.
+func (s *Screen) moveFile() {
+	if path, ok := s.focusedFile(unstagedPane); ok {
+		s.do(func() error { return s.staging.StageFile(path) })
+	} else if path, ok := s.focusedFile(stagedPane); ok {
+		s.do(func() error { return s.staging.UnstageFile(path) })
+	}
+}
+
+// moveHunk stages or unstages the selected hunk of the focused file.
+func (s *Screen) moveHunk() {
+	if len(s.hunks) <= s.hunk {
+		return
+	}
+	hunk := s.hunks[s.hunk]
+	if path, ok := s.focusedFile(unstagedPane); ok {
+		s.do(func() error { return s.staging.StageHunk(path, hunk) })
+	} else if path, ok := s.focusedFile(stagedPane); ok {
+		s.do(func() error { return s.staging.UnstageHunk(path, hunk) })
+	}
+}
+
+func (s *Screen) unstageAll() error {
+	for _, f := range s.stagedFiles {
+		if err := s.staging.UnstageFile(f.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do carries out the change and shows the files as they are after it.
+func (s *Screen) do(change func() error) {
+	if err := change(); err != nil {
+		// the stack of the error does not fit the status bar
+		first, _, _ := strings.Cut(err.Error(), "\n")
+		s.status.SetText("[red]" + tview.Escape(first) + "[-]")
+	} else {
+		s.status.SetText("")
+	}
+	s.refresh()
+}
+
+// confirm asks before the change is carried out.
+func (s *Screen) confirm(text string, change func() error) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(_ int, label string) {
+			s.closeModal()
+			if label == "Yes" {
+				s.do(change)
+			}
+		})
+	s.modalReturn = s.tviewApp.GetFocus()
+	s.AddPage(modalPageName, modal, true, true)
+	s.tviewApp.SetFocus(modal)
+}
+
+func (s *Screen) closeModal() {
+	s.RemovePage(modalPageName)
+	if s.modalReturn != nil {
+		s.tviewApp.SetFocus(s.modalReturn)
+		s.modalReturn = nil
+	}
+}
+
+// refresh reads the changes again and keeps the selection of each pane where it was.
+func (s *Screen) refresh() {
+	unstaged, staged, err := s.staging.Changes()
+	if err != nil {
+		s.status.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	s.unstagedFiles, s.stagedFiles = unstaged, staged
+	s.fill(s.unstaged, unstaged)
+	s.fill(s.staged, staged)
+	s.showDiff()
+}
+
+// fill lists the files, the diff is shown once both panes are filled.
+func (s *Screen) fill(list *tw.ListPanel, files []gitutil.FileChange) {
+	s.filling = true
+	defer func() { s.filling = false }()
+	current := list.GetCurrentItem()
+	list.Clear()
+	for _, f := range files {
+		list.AddItem(fmt.Sprintf("%c %s", f.Status, tview.Escape(f.Path)), "", 0, nil)
+	}
+	list.SetCurrentItem(min(current, max(len(files)-1, 0)))
+}
+
+// showDiff shows the diff of the focused file.
+func (s *Screen) showDiff() {
+	if pane := s.focusRing.Focused(); pane == stagedPane {
+		s.showFileDiff(pane, s.staged.GetCurrentItem())
+	} else {
+		s.showFileDiff(pane, s.unstaged.GetCurrentItem())
+	}
+}
+
+// showFileDiff shows the diff of the file i of the pane and selects its first hunk.
+func (s *Screen) showFileDiff(pane int, i int) {
+	s.hunks, s.hunk = nil, 0
+	var from, to string
+	var err error
+	if pane == stagedPane {
+		path, ok := fileAt(s.stagedFiles, i)
+		if !ok {
+			s.diff.SetText("")
+			return
+		}
+		from, to, err = s.staging.StagedDiff(path)
+	} else {
+		path, ok := fileAt(s.unstagedFiles, i)
+		if !ok {
+			s.diff.SetText("")
+			return
+		}
+		from, to, err = s.staging.UnstagedDiff(path)
+	}
+	if err != nil {
+		s.diff.SetText("[red]" + tview.Escape(err.Error()) + "[-]")
+		return
+	}
+	if gitutil.IsBinary(from) || gitutil.IsBinary(to) {
+		s.diff.SetText("binary file, move it whole")
+		return
+	}
+	s.hunks = gitutil.DiffHunks(from, to)
+	s.diff.SetText(formatHunks(s.hunks))
+	s.selectHunk(0)
+}
+
+// selectHunk highlights the hunk of the diff and scrolls to it.
+func (s *Screen) selectHunk(i int) {
+	if len(s.hunks) == 0 {
+		return
+	}
+	s.hunk = min(max(i, 0), len(s.hunks)-1)
+	s.diff.Highlight(fmt.Sprint(s.hunk)).ScrollToHighlight()
+}
+
+// formatHunks colors the hunks, each in the region of its index.
+func formatHunks(hunks []gitutil.Hunk) string {
+	var b strings.Builder
+	for i, h := range hunks {
+		fmt.Fprintf(&b, `["%d"]`, i)
+		for _, line := range strings.SplitAfter(strings.TrimSuffix(h.String(), "\n"), "\n") {
+			color := "white"
+			switch {
+			case strings.HasPrefix(line, "@@"):
+				color = "aqua"
+			case strings.HasPrefix(line, "+"):
+				color = "green"
+			case strings.HasPrefix(line, "-"):
+				color = "red"
+			}
+			fmt.Fprintf(&b, "[%s]%s[-]", color, tview.Escape(line))
+		}
+		b.WriteString(`[""]` + "\n")
+	}
+	return b.String()
+}
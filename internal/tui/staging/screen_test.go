@@ -0,0 +1,132 @@
+package staging
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/rivo/tview"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/gitutil"
+)
+
+func lines(first, last int) string {
+	var b strings.Builder
+	for i := first; i <= last; i++ {
+		b.WriteString(strings.Repeat("x", i) + "\n")
+	}
+	return b.String()
+}
+
+func newScreen(t *testing.T) (*tview.Application, *Screen, billy.Filesystem) {
+	rootFS := memfs.New()
+	repo, err := gitutil.MakeEmptyRepo(rootFS)
+	require.NoError(t, err)
+	require.NoError(t, util.WriteFile(rootFS, "notes.txt", []byte(lines(1, 20)), 0o644))
+	staging, err := gitutil.NewStaging(repo)
+	require.NoError(t, err)
+	require.NoError(t, staging.StageFile("notes.txt"))
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Commit("Add the notes.", gitutil.NewEmptyCommitOptions("Annie Mouse"))
+	require.NoError(t, err)
+
+	changed := strings.Replace(lines(1, 20), "xx\n", "two\n", 1)
+	changed = strings.Replace(changed, strings.Repeat("x", 18)+"\n", "eighteen\n", 1)
+	require.NoError(t, util.WriteFile(rootFS, "notes.txt", []byte(changed), 0o644))
+	require.NoError(t, util.WriteFile(rootFS, "new.txt", []byte("new\n"), 0o644))
+
+	app := tview.NewApplication()
+	return app, NewScreen(app, staging), rootFS
+}
+
+func press(app *tview.Application, s *Screen, event *tcell.EventKey) {
+	s.InputHandler()(event, func(p tview.Primitive) { app.SetFocus(p) })
+}
+
+func key(r rune) *tcell.EventKey {
+	return tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone)
+}
+
+func paths(files []gitutil.FileChange) []string {
+	var p []string
+	for _, f := range files {
+		p = append(p, string(f.Status)+" "+f.Path)
+	}
+	return p
+}
+
+func TestScreenMovesFilesAndHunks(t *testing.T) {
+	app, s, _ := newScreen(t)
+	unstaged, staged := s.Files()
+	require.Equal(t, []string{"? new.txt", "M notes.txt"}, paths(unstaged))
+	require.Empty(t, staged)
+
+	// the new file moves whole
+	press(app, s, key(' '))
+	unstaged, staged = s.Files()
+	require.Equal(t, []string{"M notes.txt"}, paths(unstaged))
+	require.Equal(t, []string{"A new.txt"}, paths(staged))
+
+	// the second hunk of the notes moves alone
+	require.Len(t, s.hunks, 2)
+	require.Contains(t, s.diff.GetText(true), "+two")
+	press(app, s, key('n'))
+	require.Equal(t, 1, s.hunk)
+	press(app, s, key('h'))
+	unstaged, staged = s.Files()
+	require.Equal(t, []string{"M notes.txt"}, paths(unstaged))
+	require.Equal(t, []string{"A new.txt", "M notes.txt"}, paths(staged))
+	require.Len(t, s.hunks, 1)
+
+	// the staged pane shows the staged hunk and moves it back
+	press(app, s, tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	s.staged.SetCurrentItem(1)
+	require.Len(t, s.hunks, 1)
+	require.Contains(t, s.diff.GetText(true), "+eighteen")
+	press(app, s, key('h'))
+	_, staged = s.Files()
+	require.Equal(t, []string{"A new.txt"}, paths(staged))
+
+	press(app, s, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	require.True(t, s.done)
+}
+
+func TestScreenConfirmsReverts(t *testing.T) {
+	app, s, rootFS := newScreen(t)
+	s.unstaged.SetCurrentItem(1)
+
+	// no keeps the changes
+	press(app, s, key('r'))
+	require.True(t, s.HasPage(modalPageName))
+	press(app, s, tcell.NewEventKey(tcell.KeyTab, 0, tcell.ModNone))
+	press(app, s, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	require.False(t, s.HasPage(modalPageName))
+	unstaged, _ := s.Files()
+	require.Len(t, unstaged, 2)
+
+	press(app, s, key('d'))
+	press(app, s, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	require.False(t, s.HasPage(modalPageName))
+	content, err := util.ReadFile(rootFS, "notes.txt")
+	require.NoError(t, err)
+	require.NotContains(t, string(content), "two")
+	require.Contains(t, string(content), "eighteen")
+
+	press(app, s, key('r'))
+	press(app, s, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	unstaged, _ = s.Files()
+	require.Equal(t, []string{"? new.txt"}, paths(unstaged))
+
+	press(app, s, key(' '))
+	press(app, s, key('u'))
+	press(app, s, tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone))
+	unstaged, staged := s.Files()
+	require.Equal(t, []string{"? new.txt"}, paths(unstaged))
+	require.Empty(t, staged)
+	require.False(t, s.done)
+}
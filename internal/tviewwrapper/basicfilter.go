@@ -1,6 +1,8 @@
 package tviewwrapper
 
 import (
+	"fmt"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
 
@@ -16,10 +18,12 @@ type BasicFilterPanel struct {
 	OnChange events.Event[string]
 }
 
+const filterLabel = "Filter: "
+
 // NewBasicFilterPanel creates a basic filter input
 func NewBasicFilterPanel(placeholder string, style *Style) *BasicFilterPanel {
 	input := tview.NewInputField()
-	input.SetLabel("Filter: ")
+	input.SetLabel(filterLabel)
 	input.SetPlaceholder(placeholder)
 	input.SetFieldWidth(0) // Use available width
 
@@ -55,6 +59,17 @@ func (p *BasicFilterPanel) SetFilter(text string) {
 	p.SetText(text)
 }
 
+// SetError shows why the filter could not be applied in the label, nil clears it.
+func (p *BasicFilterPanel) SetError(err error) {
+	if err == nil {
+		p.SetLabel(filterLabel)
+		p.SetFieldTextColor(tview.Styles.PrimaryTextColor)
+		return
+	}
+	p.SetLabel(fmt.Sprintf("Filter [red](%s)[-]: ", tview.Escape(err.Error())))
+	p.SetFieldTextColor(tcell.ColorRed)
+}
+
 func (p *BasicFilterPanel) SetBlurred() {
 	p.SetBackgroundColor(p.style.BlurBackground)
 }
@@ -24,5 +24,6 @@ func MakeContentTable(ptc tview.TableContent, stop StopFunc) *tview.Table {
 		table.GetCell(row, column).SetTextColor(tcell.ColorRed)
 		table.SetSelectable(true, true)
 	})
+	bindLayoutKeys(table, ptc)
 	return table
 }
@@ -58,6 +58,9 @@ const (
 // Cycle changes the focus in the specified direction
 func (r *FocusRing) Cycle(direction RingDirection) {
 	N := len(r.panels)
+	if N == 0 {
+		return
+	}
 
 	r.onPanelBlurred.Notify(FocusParams{
 		r.tviewApp,
@@ -69,3 +72,38 @@ func (r *FocusRing) Cycle(direction RingDirection) {
 		r.panels[r.focusedPanel],
 	})
 }
+
+// Focus moves the focus to the panel at index i
+func (r *FocusRing) Focus(i int) {
+	if i < 0 || len(r.panels) <= i {
+		return
+	}
+	r.onPanelBlurred.Notify(FocusParams{
+		r.tviewApp,
+		r.panels[r.focusedPanel],
+	})
+	r.focusedPanel = i
+	r.onPanelFocused.Notify(FocusParams{
+		r.tviewApp,
+		r.panels[r.focusedPanel],
+	})
+}
+
+// Focused returns the index of the focused panel.
+func (r *FocusRing) Focused() int {
+	return r.focusedPanel
+}
+
+// SetPanels replaces the panels of the ring, as when the screens that share it switch,
+// and focuses the panel at index focused.
+func (r *FocusRing) SetPanels(focused int, panels ...Panel) {
+	if r.focusedPanel < len(r.panels) {
+		r.onPanelBlurred.Notify(FocusParams{
+			r.tviewApp,
+			r.panels[r.focusedPanel],
+		})
+	}
+	r.panels = panels
+	r.focusedPanel = 0
+	r.Focus(focused)
+}
@@ -151,6 +151,30 @@ func TestFocusRing_Cycle(t *testing.T) {
 	}
 }
 
+func TestFocusRing_SetPanels(t *testing.T) {
+	app := tview.NewApplication()
+	style := NewStyle()
+	panel1 := NewBasicFilterPanel("1", style)
+	panel2 := NewBasicFilterPanel("2", style)
+	panel3 := NewBasicFilterPanel("3", style)
+
+	ring := NewFocusRing(app)
+	ring.Cycle(NextDir)
+	require.Equal(t, 0, ring.Focused())
+
+	ring.SetPanels(1, panel1, panel2)
+	require.Equal(t, 1, ring.Focused())
+	require.Equal(t, panel2, app.GetFocus())
+
+	ring.SetPanels(0, panel3)
+	require.Equal(t, 0, ring.Focused())
+	require.Equal(t, []Panel{panel3}, ring.panels)
+	require.Equal(t, panel3, app.GetFocus())
+
+	ring.Cycle(NextDir)
+	require.Equal(t, 0, ring.Focused())
+}
+
 func TestRingDirection_Constants(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -0,0 +1,87 @@
+package tviewwrapper
+
+import (
+	"github.com/stalwartgiraffe/cmr/internal/find"
+)
+
+// ColumnTable is a table whose columns have titles.
+type ColumnTable interface {
+	TextTable
+	GetColumn(col int) string
+}
+
+// HeaderedTable is a table whose header shows more than the titles, such as how the rows sort.
+type HeaderedTable interface {
+	GetHeader(col int) string
+}
+
+// HeaderTable shows the titles of the columns of a table as its first row.
+// It passes the widths, match spans and layout changes of the table through.
+type HeaderTable struct {
+	table ColumnTable
+}
+
+var _ TextTable = (*HeaderTable)(nil)
+var _ MatchTable = (*HeaderTable)(nil)
+var _ WidthTable = (*HeaderTable)(nil)
+var _ LayoutTable = (*HeaderTable)(nil)
+
+func NewHeaderTable(table ColumnTable) *HeaderTable {
+	return &HeaderTable{table: table}
+}
+
+func (t *HeaderTable) GetRowCount() int {
+	return t.table.GetRowCount() + 1
+}
+
+func (t *HeaderTable) GetColumnCount() int {
+	return t.table.GetColumnCount()
+}
+
+func (t *HeaderTable) GetCell(row int, col int) string {
+	if row != 0 {
+		return t.table.GetCell(row-1, col)
+	}
+	if h, ok := t.table.(HeaderedTable); ok {
+		return h.GetHeader(col)
+	}
+	return t.table.GetColumn(col)
+}
+
+func (t *HeaderTable) GetMatchSpans(row int, col int) []find.Span {
+	if m, ok := t.table.(MatchTable); ok && row != 0 {
+		return m.GetMatchSpans(row-1, col)
+	}
+	return nil
+}
+
+func (t *HeaderTable) GetColumnWidth(col int) int {
+	if w, ok := t.table.(WidthTable); ok {
+		return w.GetColumnWidth(col)
+	}
+	return 0
+}
+
+func (t *HeaderTable) SortBy(col int, then bool) {
+	if l, ok := t.table.(LayoutTable); ok {
+		l.SortBy(col, then)
+	}
+}
+
+func (t *HeaderTable) Resize(col int, delta int) {
+	if l, ok := t.table.(LayoutTable); ok {
+		l.Resize(col, delta)
+	}
+}
+
+func (t *HeaderTable) Hide(col int) {
+	if l, ok := t.table.(LayoutTable); ok {
+		l.Hide(col)
+	}
+}
+
+func (t *HeaderTable) ShowAll() {
+	if l, ok := t.table.(LayoutTable); ok {
+		l.ShowAll()
+	}
+}
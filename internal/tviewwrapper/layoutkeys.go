@@ -0,0 +1,49 @@
+package tviewwrapper
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// LayoutHelp describes the layout keys for a status bar.
+const LayoutHelp = "[gray]s[white]/[gray]S[white] sort/then sort  [gray]<[white]/[gray]>[white] width  [gray]z[white]/[gray]Z[white] hide/show all"
+
+// layoutKeys binds the keys that change the layout of the column of the selection.
+var layoutKeys = map[rune]func(l LayoutTable, col int){
+	's': func(l LayoutTable, col int) { l.SortBy(col, false) },
+	'S': func(l LayoutTable, col int) { l.SortBy(col, true) },
+	'<': func(l LayoutTable, col int) { l.Resize(col, -2) },
+	'>': func(l LayoutTable, col int) { l.Resize(col, 2) },
+	'z': func(l LayoutTable, col int) { l.Hide(col) },
+	'Z': func(l LayoutTable, col int) { l.ShowAll() },
+}
+
+// bindLayoutKeys lets the user change the layout of the content of the table with the layout keys.
+func bindLayoutKeys(table *tview.Table, ptc tview.TableContent) {
+	var l LayoutTable
+	switch c := ptc.(type) {
+	case *TwoBandTableContent:
+		var ok bool
+		if l, ok = c.layoutTable(); !ok {
+			return
+		}
+	case LayoutTable:
+		l = c
+	default:
+		return
+	}
+	capture := table.GetInputCapture()
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyRune {
+			if change, ok := layoutKeys[event.Rune()]; ok {
+				_, col := table.GetSelection()
+				change(l, min(col, max(0, ptc.GetColumnCount()-1)))
+				return nil
+			}
+		}
+		if capture != nil {
+			return capture(event)
+		}
+		return event
+	})
+}
@@ -0,0 +1,35 @@
+package tviewwrapper
+
+import (
+	"github.com/rivo/tview"
+)
+
+// ListPanel is a bordered list that implements Panel
+type ListPanel struct {
+	*tview.List
+	style *Style
+}
+
+// NewListPanel creates a list panel with a title
+func NewListPanel(title string, style *Style) *ListPanel {
+	list := tview.NewList()
+	list.ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetBorder(true)
+	list.SetTitle(title)
+	p := &ListPanel{
+		List:  list,
+		style: style,
+	}
+	p.SetBlurred()
+	return p
+}
+
+func (p *ListPanel) SetBlurred() {
+	p.SetBackgroundColor(p.style.BlurBackground)
+}
+
+func (p *ListPanel) SetFocus(tviewApp *tview.Application) {
+	p.SetBackgroundColor(p.style.FocusBackground)
+	tviewApp.SetFocus(p)
+}
@@ -12,6 +12,7 @@ type TablePanel struct {
 	style *Style
 
 	onCellSelected events.Event[CellParams]
+	onCellChanged  events.Event[CellParams]
 }
 
 func NewTablePanel(ptc tview.TableContent, stop StopFunc, style *Style) *TablePanel {
@@ -22,6 +23,7 @@ func NewTablePanel(ptc tview.TableContent, stop StopFunc, style *Style) *TablePa
 	p.Table.SetContent(ptc)
 	p.setupTableLayout(stop)
 	p.setupEvents()
+	bindLayoutKeys(p.Table, ptc)
     p.SetBlurred()
 	return p
 }
@@ -46,6 +48,9 @@ func (p *TablePanel) setupEvents() {
 	p.SetSelectedFunc(func(row, col int) {
 		p.onCellSelected.Notify(CellParams{row, col})
 	})
+	p.SetSelectionChangedFunc(func(row, col int) {
+		p.onCellChanged.Notify(CellParams{row, col})
+	})
 }
 
 type CellParams struct {
@@ -55,6 +60,12 @@ type CellParams struct {
 func (p *TablePanel) OnCellSelectedSubscribe(fn func(CellParams)) {
 	p.onCellSelected.Subscribe(fn)
 }
+
+// OnCellChangedSubscribe is notified when the selection moves to another cell.
+func (p *TablePanel) OnCellChangedSubscribe(fn func(CellParams)) {
+	p.onCellChanged.Subscribe(fn)
+}
+
 func (p *TablePanel) OnCellSelectedNotify(c CellParams) {
 	p.onCellSelected.Notify(c)
 }
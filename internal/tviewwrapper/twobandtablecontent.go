@@ -1,8 +1,12 @@
 package tviewwrapper
 
 import (
+	"strings"
+
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+
+	"github.com/stalwartgiraffe/cmr/internal/find"
 )
 
 type TextTable interface {
@@ -11,6 +15,30 @@ type TextTable interface {
 	GetCell(row, col int) string
 }
 
+// MatchTable is a table that knows where a find matched its cells.
+type MatchTable interface {
+	GetMatchSpans(row, col int) []find.Span
+}
+
+// LayoutTable is a table whose columns the user sorts, resizes and hides.
+type LayoutTable interface {
+	SortBy(col int, then bool)
+	Resize(col int, delta int)
+	Hide(col int)
+	ShowAll()
+}
+
+// WidthTable is a table whose columns have a width, 0 fits the contents.
+type WidthTable interface {
+	GetColumnWidth(col int) int
+}
+
+// matchTag and matchEndTag color the matched characters of a cell.
+const (
+	matchTag    = "[yellow::b]"
+	matchEndTag = "[-::-]"
+)
+
 type TwoBandTableContent struct {
 	tview.TableContentReadOnly
 	rowColors []tcell.Color
@@ -42,15 +70,54 @@ func (c *TwoBandTableContent) GetColumnCount() int {
 
 // GetCell returns the contents of a table cell.
 func (c *TwoBandTableContent) GetCell(row, col int) *tview.TableCell {
+	text := c.table.GetCell(row, col)
+	if m, ok := c.table.(MatchTable); ok {
+		text = highlight(text, m.GetMatchSpans(row, col))
+	}
 	cell := &tview.TableCell{
 		Align:           tview.AlignLeft,
 		Color:           tview.Styles.PrimaryTextColor,
 		Transparent:     false, // must for false for BackgroundColor to be drawn
-		Text:            c.table.GetCell(row, col),
+		Text:            text,
 		BackgroundColor: c.bandBackground(row),
 	}
+	if w, ok := c.table.(WidthTable); ok {
+		cell.MaxWidth = w.GetColumnWidth(col)
+	}
+	// clicking a header sorts by its column
+	if l, ok := c.table.(LayoutTable); ok && row == 0 {
+		cell.Clicked = func() bool {
+			l.SortBy(col, false)
+			return false
+		}
+	}
 	return cell
 }
+
+// layoutTable returns the table of the content when the user can change its layout.
+func (c *TwoBandTableContent) layoutTable() (LayoutTable, bool) {
+	l, ok := c.table.(LayoutTable)
+	return l, ok
+}
+
+// highlight tags the spans of text, the text between the tags is escaped
+// so it shows the same with or without a match.
+func highlight(text string, spans []find.Span) string {
+	if len(spans) == 0 {
+		return tview.Escape(text)
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(tview.Escape(text[last:s.Start]))
+		b.WriteString(matchTag)
+		b.WriteString(tview.Escape(text[s.Start:s.End]))
+		b.WriteString(matchEndTag)
+		last = s.End
+	}
+	b.WriteString(tview.Escape(text[last:]))
+	return b.String()
+}
 func (c *TwoBandTableContent) bandBackground(row int) tcell.Color {
 	if row == 0 {
 		return c.rowColors[0]
@@ -0,0 +1,30 @@
+package tviewwrapper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/stalwartgiraffe/cmr/internal/find"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		spans    []find.Span
+		expected string
+	}{
+		{name: "no spans", text: "fix bug", spans: nil, expected: "fix bug"},
+		{name: "no spans is escaped", text: "fix [b] [red]", spans: nil, expected: "fix [b[] [red[]"},
+		{name: "span", text: "a fix", spans: []find.Span{{Start: 2, End: 5}}, expected: "a [yellow::b]fix[-::-]"},
+		{name: "spans", text: "abcd", spans: []find.Span{{Start: 0, End: 1}, {Start: 2, End: 3}}, expected: "[yellow::b]a[-::-]b[yellow::b]c[-::-]d"},
+		{name: "text is escaped", text: "[b] fix", spans: []find.Span{{Start: 4, End: 7}}, expected: "[b[] [yellow::b]fix[-::-]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, highlight(tt.text, tt.spans))
+		})
+	}
+}
@@ -3,6 +3,7 @@ package kam
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"sort"
 	"strings"
 
@@ -55,7 +56,8 @@ func (m Map) ToQueryParameters() string {
 
 	sb := strings.Builder{}
 	for i, k := range keys {
-		sb.WriteString(fmt.Sprintf("%s=%v", k, m[k]))
+		// escape so a keyset cursor from a next link survives the round trip
+		sb.WriteString(url.QueryEscape(k) + "=" + url.QueryEscape(fmt.Sprint(m[k])))
 		if i+1 < len(m) {
 			sb.WriteString("&")
 		}
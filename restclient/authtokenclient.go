@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"strings"
+	"sync"
 
 	"github.com/TwiN/go-color"
 	"github.com/go-resty/resty/v2"
@@ -25,9 +26,13 @@ type AuthTokenClient struct {
 	userAgent string
 	api       string
 	authToken string // the manually managed bearer token.
+	// loadToken loads the bearer token on the first request that needs it
+	loadToken func(ctx context.Context) (string, error)
+	tokenMu   sync.Mutex
 	isVerbose bool
 	isDebug   bool
 	headers   map[string]string
+	transport http.RoundTripper // of the default client, such as a Cassette
 }
 
 type Option func(*AuthTokenClient)
@@ -50,6 +55,14 @@ func WithAuthToken(authToken string) Option {
 	}
 }
 
+// WithAuthTokenFunc loads the bearer token on the first request rather than up front,
+// so a client that never sends a request needs no token. WithAuthToken takes precedence.
+func WithAuthTokenFunc(loadToken func(ctx context.Context) (string, error)) Option {
+	return func(c *AuthTokenClient) {
+		c.loadToken = loadToken
+	}
+}
+
 func WithIsVerbose(isVerbose bool) Option {
 	return func(c *AuthTokenClient) {
 		c.isVerbose = isVerbose
@@ -74,6 +87,14 @@ func WithHeader(k, v string) Option {
 	}
 }
 
+// WithTransport sends the requests of the default client with the transport,
+// such as a Cassette that records or replays them. It has no effect with WithClient.
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *AuthTokenClient) {
+		c.transport = transport
+	}
+}
+
 func WithClient(client Client) Option {
 	return func(c *AuthTokenClient) {
 		if client == nil {
@@ -97,6 +118,31 @@ func WithClient(client Client) Option {
 	}
 }
 
+// token returns the bearer token, loading it with the func of WithAuthTokenFunc once it loads.
+func (c *AuthTokenClient) token(ctx context.Context) (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	if c.authToken == "" && c.loadToken != nil {
+		authToken, err := c.loadToken(ctx)
+		if err != nil {
+			return "", withstack.Errorf("Could not load the auth token: %w", err)
+		}
+		c.authToken = authToken
+		c.loadToken = nil
+	}
+	return c.authToken, nil
+}
+
+// BaseURL is the url of the server.
+func (c *AuthTokenClient) BaseURL() string {
+	return c.baseURL
+}
+
+// API is the path of the api below the base url.
+func (c *AuthTokenClient) API() string {
+	return c.api
+}
+
 func ConnectClient(opts ...Option) *AuthTokenClient {
 	c := &AuthTokenClient{}
 	for _, opt := range opts {
@@ -104,7 +150,7 @@ func ConnectClient(opts ...Option) *AuthTokenClient {
 	}
 
 	if c.client == nil {
-		WithClient(newClientAdapter())(c)
+		WithClient(newClientAdapter(c.transport))(c)
 	}
 	return c
 }
@@ -154,11 +200,15 @@ func getResponse(
 	}
 	span.SetAttributes(attributes...)
 
+	authToken, err := tokenClient.token(ctx)
+	if err != nil {
+		return nil, err
+	}
 	r := tokenClient.client.Request()
 	r.SetContext(ctx).
 		SetHeader("Accept", accept)
-	if tokenClient.authToken != "" {
-		r = r.SetAuthToken(tokenClient.authToken)
+	if authToken != "" {
+		r = r.SetAuthToken(authToken)
 	}
 	if queries != "" {
 		r = r.SetQueryString(queries)
@@ -294,16 +344,19 @@ const (
 )
 
 func Update[BodyT any, RespT any](ctx context.Context, op int, tokenClient *AuthTokenClient, path string, b *BodyT) (*RespT, error) {
+	authToken, err := tokenClient.token(ctx)
+	if err != nil {
+		return nil, err
+	}
 	r := tokenClient.client.Request().
 		SetContext(ctx).
 		SetHeader("Accept", "application/json")
-	if tokenClient.authToken != "" {
-		r = r.SetAuthToken(tokenClient.authToken)
+	if authToken != "" {
+		r = r.SetAuthToken(authToken)
 	}
 
 	rb := r.SetBody(b)
 	p := tokenClient.api + path
-	var err error
 	var resp *resty.Response
 	switch op {
 	case HEAD:
@@ -337,6 +390,40 @@ func Update[BodyT any, RespT any](ctx context.Context, op int, tokenClient *Auth
 	return Unmarshal[RespT](resp)
 }
 
+var opNames = []string{
+	HEAD:    http.MethodHead,
+	POST:    http.MethodPost,
+	PUT:     http.MethodPut,
+	DELETE:  http.MethodDelete,
+	OPTIONS: http.MethodOptions,
+	PATCH:   http.MethodPatch,
+}
+
+// UpdateWithApp is Update traced within an app span.
+func UpdateWithApp[BodyT any, RespT any](
+	ctx context.Context,
+	app App,
+	op int,
+	tokenClient *AuthTokenClient,
+	path string,
+	b *BodyT) (
+	*RespT,
+	error,
+) {
+	ctx, span := app.StartSpan(ctx, "UpdateWithApp")
+	defer span.End()
+
+	attributes := []attribute.KeyValue{
+		attribute.String("path", path),
+	}
+	if 0 <= op && op < len(opNames) {
+		attributes = append(attributes, attribute.String("method", opNames[op]))
+	}
+	span.SetAttributes(attributes...)
+
+	return Update[BodyT, RespT](ctx, op, tokenClient, path, b)
+}
+
 func Head[BodyT any, RespT any](ctx context.Context, tokenClient *AuthTokenClient, path string, b *BodyT) (*RespT, error) {
 	return Update[BodyT, RespT](ctx, HEAD, tokenClient, path, b)
 }
@@ -358,11 +445,15 @@ func Patch[BodyT any, RespT any](ctx context.Context, tokenClient *AuthTokenClie
 }
 
 func PostReturnCookies[BodyT any, RespT any](ctx context.Context, tokenClient *AuthTokenClient, path string, b *BodyT) (*RespT, []*http.Cookie, error) {
+	authToken, err := tokenClient.token(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
 	r := tokenClient.client.Request().
 		SetContext(ctx).
 		SetHeader("Accept", "application/json")
-	if tokenClient.authToken != "" {
-		r = r.SetAuthToken(tokenClient.authToken)
+	if authToken != "" {
+		r = r.SetAuthToken(authToken)
 	}
 
 	resp, err := r.
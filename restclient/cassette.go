@@ -0,0 +1,263 @@
+package restclient
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stalwartgiraffe/cmr/withstack"
+)
+
+// Scrubbed replaces the secrets in a recorded cassette.
+const Scrubbed = "[scrubbed]"
+
+// secretHeaders are the request and response headers a cassette never keeps.
+var secretHeaders = []string{"Authorization", "Private-Token", "Job-Token", "Cookie", "Set-Cookie"}
+
+// secretParams are the query params a cassette never keeps.
+var secretParams = []string{"private_token", "access_token", "job_token", "token"}
+
+// secretFields are the fields of a JSON body a cassette never keeps, at any depth.
+// Only JSON bodies are scrubbed, a secret in any other body is recorded as is.
+var secretFields = []string{
+	"token", "private_token", "access_token", "refresh_token", "job_token",
+	"runners_token", "runner_token", "password", "secret", "secret_token", "client_secret",
+}
+
+// Interaction is one recorded request and the response to it.
+type Interaction struct {
+	Request  RecordedRequest  `yaml:"request"`
+	Response RecordedResponse `yaml:"response"`
+}
+
+// RecordedRequest is a request with its secrets scrubbed.
+type RecordedRequest struct {
+	Method string      `yaml:"method"`
+	URL    string      `yaml:"url"`
+	Header http.Header `yaml:"header,omitempty"`
+	Body   string      `yaml:"body,omitempty"`
+}
+
+// RecordedResponse is a response with its secrets scrubbed.
+type RecordedResponse struct {
+	StatusCode int         `yaml:"status_code"`
+	Header     http.Header `yaml:"header,omitempty"`
+	Body       string      `yaml:"body"`
+}
+
+// Cassette is a transport that records the traffic of a client to a file,
+// or replays a recorded file without a server.
+// see WithTransport
+type Cassette struct {
+	Interactions []Interaction `yaml:"interactions"`
+
+	path   string
+	next   http.RoundTripper // nil replays
+	mu     sync.Mutex
+	played []bool
+}
+
+// NewRecorder returns a cassette that sends requests with next, nil for the default
+// transport, and saves every interaction to path as it happens.
+func NewRecorder(path string, next http.RoundTripper) *Cassette {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &Cassette{path: path, next: next}
+}
+
+// LoadCassette returns a cassette that replays the interactions recorded at path.
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, withstack.Errorf("Could not read cassette %s: %w", path, err)
+	}
+	c := &Cassette{path: path}
+	if err := yaml.Unmarshal(b, c); err != nil {
+		return nil, withstack.Errorf("Could not parse cassette %s: %w", path, err)
+	}
+	c.played = make([]bool, len(c.Interactions))
+	return c, nil
+}
+
+// RoundTrip records or replays the request.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.next == nil {
+		return c.replay(req)
+	}
+	return c.record(req)
+}
+
+func (c *Cassette) record(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+	resp, err := c.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			URL:    scrubURL(req.URL),
+			Header: scrubHeader(req.Header),
+			Body:   scrubBody(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       scrubBody(respBody),
+		},
+	})
+	if err := c.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save writes the cassette to its path.
+// The caller must hold c.mu.
+func (c *Cassette) save() error {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return withstack.Errorf("Could not encode cassette: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return withstack.Errorf("Could not make the dir of %s: %w", c.path, err)
+	}
+	if err := os.WriteFile(c.path, b, 0644); err != nil {
+		return withstack.Errorf("Could not write cassette %s: %w", c.path, err)
+	}
+	return nil
+}
+
+// replay answers with the first unplayed interaction of the same method and url,
+// or the last one played when they have all been played, as when a get is retried.
+func (c *Cassette) replay(req *http.Request) (*http.Response, error) {
+	u := scrubURL(req.URL)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	last := -1
+	for i, in := range c.Interactions {
+		if in.Request.Method != req.Method || in.Request.URL != u {
+			continue
+		}
+		last = i
+		if !c.played[i] {
+			break
+		}
+	}
+	if last < 0 {
+		return nil, withstack.Errorf("Cassette %s has no %s %s", c.path, req.Method, u)
+	}
+	c.played[last] = true
+	recorded := c.Interactions[last].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// scrubHeader returns a copy of the header with the secrets replaced.
+func scrubHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	scrubbed := h.Clone()
+	for _, k := range secretHeaders {
+		if scrubbed.Get(k) != "" {
+			scrubbed.Set(k, Scrubbed)
+		}
+	}
+	return scrubbed
+}
+
+// scrubURL returns the url with the secret params replaced and the params sorted.
+func scrubURL(u *url.URL) string {
+	scrubbed := *u
+	q := scrubbed.Query()
+	for _, k := range secretParams {
+		if q.Has(k) {
+			q.Set(k, Scrubbed)
+		}
+	}
+	scrubbed.RawQuery = q.Encode()
+	scrubbed.User = nil
+	return scrubbed.String()
+}
+
+// scrubBody returns the body with the values of the secret fields replaced.
+// A body that is not JSON, or has no secret, is returned as is.
+func scrubBody(body []byte) string {
+	if !json.Valid(body) {
+		return string(body)
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil || !scrubValue(v) {
+		return string(body)
+	}
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return string(body)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// scrubValue replaces the secret fields of the objects in v, it returns true if it replaced any.
+func scrubValue(v any) bool {
+	scrubbed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, field := range v {
+			if slices.Contains(secretFields, strings.ToLower(k)) {
+				if s, ok := field.(string); ok && s != "" {
+					v[k] = Scrubbed
+					scrubbed = true
+				}
+				continue
+			}
+			scrubbed = scrubValue(field) || scrubbed
+		}
+	case []any:
+		for _, item := range v {
+			scrubbed = scrubValue(item) || scrubbed
+		}
+	}
+	return scrubbed
+}
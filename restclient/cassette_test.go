@@ -0,0 +1,57 @@
+package restclient
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestCassetteScrubsBodies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.yaml")
+	const respBody = `{"id":1,"name":"ci","token":"glpat-response","owner":{"password":"hunter2"}}`
+	recorder := NewRecorder(path, roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusCreated,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(respBody)),
+		}, nil
+	}))
+
+	req, err := http.NewRequest(http.MethodPost, "https://gitlab.example.com/api/v4/user/personal_access_tokens",
+		strings.NewReader(`{"name":"ci","scopes":["api"],"secret":"s3cr3t"}`))
+	require.NoError(t, err)
+	resp, err := recorder.RoundTrip(req)
+	require.NoError(t, err)
+	// the client still gets the secrets
+	b, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, respBody, string(b))
+
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	for _, secret := range []string{"glpat-response", "hunter2", "s3cr3t"} {
+		require.NotContains(t, string(b), secret)
+	}
+
+	cassette, err := LoadCassette(path)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"ci","scopes":["api"],"secret":"[scrubbed]"}`, cassette.Interactions[0].Request.Body)
+	require.JSONEq(t, `{"id":1,"name":"ci","token":"[scrubbed]","owner":{"password":"[scrubbed]"}}`, cassette.Interactions[0].Response.Body)
+}
+
+func TestScrubBodyKeepsOtherBodies(t *testing.T) {
+	for _, body := range []string{"", "token=abc", `{"id": 1,  "title": "a <b>"}`, `[{"id":12345678901234567890}]`} {
+		require.Equal(t, body, scrubBody([]byte(body)))
+	}
+}
@@ -1,6 +1,10 @@
 package restclient
 
-import "github.com/go-resty/resty/v2"
+import (
+	"net/http"
+
+	"github.com/go-resty/resty/v2"
+)
 
 type Client interface {
 	GetBaseURL() string
@@ -14,9 +18,15 @@ type clientAdapter struct {
 	client *resty.Client
 }
 
-func newClientAdapter() *clientAdapter {
+// newClientAdapter returns a resty client that sends requests with the transport,
+// nil for the default transport.
+func newClientAdapter(transport http.RoundTripper) *clientAdapter {
+	client := resty.New()
+	if transport != nil {
+		client.SetTransport(transport)
+	}
 	return &clientAdapter{
-		client: resty.New(),
+		client: client,
 	}
 }
 
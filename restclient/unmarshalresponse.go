@@ -2,6 +2,7 @@ package restclient
 
 import (
 	"encoding/json"
+	"net/http"
 
 	"github.com/go-resty/resty/v2"
 	"gopkg.in/yaml.v3"
@@ -31,9 +32,11 @@ func Unmarshal[T any](resp *resty.Response) (*T, error) {
 
 // Define a custom error type
 type FailureResponse struct {
-	Msg     string         `json:"msg"`
-	Status  string         `json:"status"`
-	Request map[string]any `json:"request"`
+	Msg        string         `json:"msg"`
+	Status     string         `json:"status"`
+	StatusCode int            `json:"status_code" yaml:"status_code"`
+	Request    map[string]any `json:"request"`
+	Header     http.Header    `json:"-" yaml:"-"` // response header, for Retry-After and RateLimit-*
 }
 
 // NewFailureResponse returns a custom response error.
@@ -54,8 +57,10 @@ func NewFailureResponse(msg string, resp *resty.Response) *FailureResponse {
 		}
 	}
 	return &FailureResponse{
-		Msg:    withstack.StackTrace() + msg,
-		Status: resp.Status(),
+		Msg:        withstack.StackTrace() + msg,
+		Status:     resp.Status(),
+		StatusCode: resp.StatusCode(),
+		Header:     resp.Header(),
 		Request: map[string]any{
 			"method": resp.Request.Method,
 			"url":    resp.Request.URL,
@@ -53,7 +53,26 @@ func newCmdCtxRunner(
 	c.Env = env       // process environment to the child process
 	c.Stdout = stdOut // writer for standard out
 	c.Stderr = stdErr // writer for standard err
-	return c          // ful
+	return &cmdRunner{c}
+}
+
+// InputRunner is a Runner that can read standard input.
+type InputRunner interface {
+	Runner
+	SetStdin(stdIn io.Reader)
+}
+
+type cmdRunner struct {
+	*exec.Cmd
+}
+
+func (r *cmdRunner) SetStdin(stdIn io.Reader) {
+	r.Stdin = stdIn
+}
+
+// NewFuncs returns the default dependencies of os and exec.
+func NewFuncs() Funcs {
+	return newFuncs()
 }
 
 // newFuncs returns default dependencies
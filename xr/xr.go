@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"os/exec"
 )
 
@@ -27,6 +28,26 @@ func Run(
 	}
 }
 
+// RunWithInput will run program name at cwd with args, reading stdIn.
+// Will return the output written fully into a string or error.
+func RunWithInput(
+	ctx context.Context,
+	name string,
+	allowedStatus int,
+	fn Funcs,
+	stdIn io.Reader,
+	args ...string,
+) (string, error) {
+	if fn == nil {
+		fn = newFuncs()
+	}
+	if dir, err := fn.Getwd(); err != nil {
+		return "", err
+	} else {
+		return runAt(ctx, dir, allowedStatus, name, fn, stdIn, args...)
+	}
+}
+
 // RunAt will run program name at dir with args.
 // Will return the output written fully into a string or error.
 func RunAt(
@@ -36,6 +57,18 @@ func RunAt(
 	name string,
 	fn Funcs,
 	args ...string,
+) (string, error) {
+	return runAt(ctx, dir, allowedStatus, name, fn, nil, args...)
+}
+
+func runAt(
+	ctx context.Context,
+	dir string,
+	allowedStatus int,
+	name string,
+	fn Funcs,
+	stdIn io.Reader,
+	args ...string,
 ) (string, error) {
 	if _, err := fn.LookPath(name); err != nil {
 		return "", err
@@ -48,6 +81,13 @@ func RunAt(
 	var stdErr bytes.Buffer
 
 	runner := fn.MakeRunner(ctx, dir, env, &stdOut, &stdErr, name, args...)
+	if stdIn != nil {
+		if r, ok := runner.(InputRunner); ok {
+			r.SetStdin(stdIn)
+		} else {
+			return "", fmt.Errorf("%s runner can not read input", name)
+		}
+	}
 
 	if err := runner.Run(); err != nil {
 		exiterr, ok := err.(*exec.ExitError)